@@ -0,0 +1,257 @@
+package quic
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+const (
+	defaultAdmissionIPv4PrefixLen = 24
+	defaultAdmissionIPv6PrefixLen = 48
+	// Token buckets that are full are equivalent to buckets that don't exist.
+	// They are removed periodically, to limit the memory usage.
+	admissionBucketCleanupInterval = 10 * time.Second
+	// maxAdmissionBuckets is the maximum number of token buckets, both per IP address and per prefix.
+	maxAdmissionBuckets = 1 << 16
+)
+
+// AdmissionControlConfig configures an AdmissionController.
+type AdmissionControlConfig struct {
+	// PerIPRate is the number of connections per second that a single IP address is allowed to establish.
+	// If zero, the number of connections per IP address is not limited.
+	PerIPRate float64
+	// PerIPBurst is the number of connections that a single IP address is allowed to establish in a burst.
+	// If zero, it defaults to 1.
+	PerIPBurst int
+	// PerPrefixRate is the number of connections per second that all IP addresses in the same prefix
+	// (see IPv4PrefixLen and IPv6PrefixLen) are allowed to establish.
+	// If zero, the number of connections per prefix is not limited.
+	PerPrefixRate float64
+	// PerPrefixBurst is the number of connections that all IP addresses in the same prefix are allowed to establish in a burst.
+	// If zero, it defaults to 1.
+	PerPrefixBurst int
+	// IPv4PrefixLen is the prefix length used to group IPv4 addresses. If zero, it defaults to 24.
+	IPv4PrefixLen int
+	// IPv6PrefixLen is the prefix length used to group IPv6 addresses. If zero, it defaults to 48.
+	IPv6PrefixLen int
+	// RetryThreshold is the number of concurrent handshakes above which the server is considered overloaded.
+	// When overloaded, the server requires clients to validate their address by sending a Retry,
+	// before creating any state for the connection.
+	// If zero, the server doesn't send a Retry when overloaded.
+	RetryThreshold int
+	// MaxConcurrentHandshakes is the maximum number of concurrent handshakes.
+	// New connection attempts beyond this limit are refused with a CONNECTION_REFUSED error.
+	// If zero, the number of concurrent handshakes is not limited.
+	MaxConcurrentHandshakes int
+}
+
+// AdmissionStats are the counters of the decisions made by an AdmissionController.
+type AdmissionStats struct {
+	// Accepted is the number of connection attempts that were accepted.
+	Accepted uint64
+	// Retried is the number of connection attempts that were answered with a Retry, because the server was overloaded.
+	Retried uint64
+	// Refused is the number of connection attempts that were refused, because too many handshakes were in progress.
+	Refused uint64
+	// RateLimitedIP is the number of connection attempts that were dropped by the per-IP rate limit.
+	RateLimitedIP uint64
+	// RateLimitedPrefix is the number of connection attempts that were dropped by the per-prefix rate limit.
+	RateLimitedPrefix uint64
+	// Handshakes is the number of handshakes currently in progress.
+	Handshakes int
+}
+
+type admissionDecision uint8
+
+const (
+	admissionAccept admissionDecision = iota
+	admissionRetry
+	admissionRefuse
+	admissionDrop
+)
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// refill refills the bucket, and reports if the bucket is full
+func (b *tokenBucket) refill(now time.Time, rate float64, burst int) bool {
+	b.tokens += now.Sub(b.lastRefill).Seconds() * rate
+	b.lastRefill = now
+	if b.tokens >= float64(burst) {
+		b.tokens = float64(burst)
+		return true
+	}
+	return false
+}
+
+type tokenBuckets struct {
+	rate       float64
+	burst      int
+	maxBuckets int
+	buckets    map[string]*tokenBucket
+}
+
+func newTokenBuckets(rate float64, burst int) *tokenBuckets {
+	return &tokenBuckets{
+		rate:       rate,
+		burst:      utils.Max(burst, 1),
+		maxBuckets: maxAdmissionBuckets,
+		buckets:    make(map[string]*tokenBucket),
+	}
+}
+
+func (b *tokenBuckets) enabled() bool {
+	return b.rate > 0
+}
+
+// hasToken checks if the bucket for this key has a token left.
+// Buckets are only created when a token is taken.
+func (b *tokenBuckets) hasToken(key string, now time.Time) bool {
+	bucket, ok := b.buckets[key]
+	if !ok {
+		return true
+	}
+	bucket.refill(now, b.rate, b.burst)
+	return bucket.tokens >= 1
+}
+
+func (b *tokenBuckets) take(key string, now time.Time) {
+	bucket, ok := b.buckets[key]
+	if !ok {
+		// Evict an arbitrary bucket, so that connection attempts from many different addresses can't exhaust our memory.
+		// This resets the rate limit for one address (or prefix), which is preferable to dropping all new addresses.
+		if len(b.buckets) >= b.maxBuckets {
+			for k := range b.buckets {
+				delete(b.buckets, k)
+				break
+			}
+		}
+		bucket = &tokenBucket{tokens: float64(b.burst), lastRefill: now}
+		b.buckets[key] = bucket
+	}
+	bucket.tokens--
+}
+
+func (b *tokenBuckets) removeFull(now time.Time) {
+	for key, bucket := range b.buckets {
+		if bucket.refill(now, b.rate, b.burst) {
+			delete(b.buckets, key)
+		}
+	}
+}
+
+// An AdmissionController decides if the server accepts new connection attempts.
+// It limits the rate of new connections per IP address and per prefix, and the number of concurrent handshakes.
+// When too many handshakes are in progress, it switches to sending Retry packets,
+// and beyond that, to refusing new connection attempts.
+// It can be shared between multiple servers, and is safe for concurrent use.
+type AdmissionController struct {
+	mutex sync.Mutex
+
+	config        AdmissionControlConfig
+	perIP         *tokenBuckets
+	perPrefix     *tokenBuckets
+	lastCleanup   time.Time
+	handshakes    int
+	stats         AdmissionStats
+	ipv4PrefixLen int
+	ipv6PrefixLen int
+}
+
+// NewAdmissionController creates a new AdmissionController.
+func NewAdmissionController(config *AdmissionControlConfig) *AdmissionController {
+	c := &AdmissionController{
+		config:        *config,
+		perIP:         newTokenBuckets(config.PerIPRate, config.PerIPBurst),
+		perPrefix:     newTokenBuckets(config.PerPrefixRate, config.PerPrefixBurst),
+		lastCleanup:   time.Now(),
+		ipv4PrefixLen: config.IPv4PrefixLen,
+		ipv6PrefixLen: config.IPv6PrefixLen,
+	}
+	if c.ipv4PrefixLen == 0 {
+		c.ipv4PrefixLen = defaultAdmissionIPv4PrefixLen
+	}
+	if c.ipv6PrefixLen == 0 {
+		c.ipv6PrefixLen = defaultAdmissionIPv6PrefixLen
+	}
+	return c
+}
+
+// Stats returns the counters of the decisions made so far.
+func (c *AdmissionController) Stats() AdmissionStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Handshakes = c.handshakes
+	return stats
+}
+
+func (c *AdmissionController) prefix(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return string(ip4.Mask(net.CIDRMask(c.ipv4PrefixLen, 8*net.IPv4len)))
+	}
+	return string(ip.Mask(net.CIDRMask(c.ipv6PrefixLen, 8*net.IPv6len)))
+}
+
+// admit decides how to handle a new connection attempt.
+// If the connection attempt is accepted, handshakeDone must be called once the handshake completes or fails.
+func (c *AdmissionController) admit(remoteAddr net.Addr, addrValidated bool) admissionDecision {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.config.MaxConcurrentHandshakes > 0 && c.handshakes >= c.config.MaxConcurrentHandshakes {
+		c.stats.Refused++
+		return admissionRefuse
+	}
+	// Sending a Retry is cheap. Rate limits only apply to connection attempts that create state.
+	if !addrValidated && c.config.RetryThreshold > 0 && c.handshakes >= c.config.RetryThreshold {
+		c.stats.Retried++
+		return admissionRetry
+	}
+
+	now := time.Now()
+	if now.Sub(c.lastCleanup) >= admissionBucketCleanupInterval {
+		c.perIP.removeFull(now)
+		c.perPrefix.removeFull(now)
+		c.lastCleanup = now
+	}
+	var ip net.IP
+	if udpAddr, ok := remoteAddr.(*net.UDPAddr); ok {
+		ip = udpAddr.IP
+	}
+	if ip != nil {
+		ipKey := string(ip.To16())
+		prefixKey := c.prefix(ip)
+		// Check the prefix first, so that a flood of packets from many addresses in the same prefix
+		// doesn't create a bucket for every address.
+		if c.perPrefix.enabled() && !c.perPrefix.hasToken(prefixKey, now) {
+			c.stats.RateLimitedPrefix++
+			return admissionDrop
+		}
+		if c.perIP.enabled() && !c.perIP.hasToken(ipKey, now) {
+			c.stats.RateLimitedIP++
+			return admissionDrop
+		}
+		if c.perPrefix.enabled() {
+			c.perPrefix.take(prefixKey, now)
+		}
+		if c.perIP.enabled() {
+			c.perIP.take(ipKey, now)
+		}
+	}
+	c.handshakes++
+	c.stats.Accepted++
+	return admissionAccept
+}
+
+func (c *AdmissionController) handshakeDone() {
+	c.mutex.Lock()
+	c.handshakes--
+	c.mutex.Unlock()
+}
@@ -0,0 +1,117 @@
+package quic
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Admission Controller", func() {
+	addr := func(ip string) net.Addr {
+		return &net.UDPAddr{IP: net.ParseIP(ip), Port: 1234}
+	}
+
+	It("accepts everything by default", func() {
+		c := NewAdmissionController(&AdmissionControlConfig{})
+		for i := 0; i < 100; i++ {
+			Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+		}
+		Expect(c.Stats()).To(Equal(AdmissionStats{Accepted: 100, Handshakes: 100}))
+	})
+
+	It("limits the number of connection attempts per IP", func() {
+		c := NewAdmissionController(&AdmissionControlConfig{PerIPRate: 1, PerIPBurst: 2})
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionDrop))
+		Expect(c.admit(addr("192.0.2.2"), false)).To(Equal(admissionAccept))
+		stats := c.Stats()
+		Expect(stats.Accepted).To(BeEquivalentTo(3))
+		Expect(stats.RateLimitedIP).To(BeEquivalentTo(1))
+	})
+
+	It("refills the token buckets", func() {
+		c := NewAdmissionController(&AdmissionControlConfig{PerIPRate: 20})
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionDrop))
+		time.Sleep(scaleDuration(60 * time.Millisecond))
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+	})
+
+	It("limits the number of connection attempts per IPv4 prefix", func() {
+		c := NewAdmissionController(&AdmissionControlConfig{PerPrefixRate: 1, PerPrefixBurst: 2})
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("192.0.2.2"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("192.0.2.3"), false)).To(Equal(admissionDrop))
+		Expect(c.admit(addr("192.0.3.1"), false)).To(Equal(admissionAccept))
+		Expect(c.Stats().RateLimitedPrefix).To(BeEquivalentTo(1))
+	})
+
+	It("limits the number of connection attempts per IPv6 prefix", func() {
+		c := NewAdmissionController(&AdmissionControlConfig{PerPrefixRate: 1, IPv6PrefixLen: 32})
+		Expect(c.admit(addr("2001:db8:1::1"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("2001:db8:2::1"), false)).To(Equal(admissionDrop))
+		Expect(c.admit(addr("2001:db9::1"), false)).To(Equal(admissionAccept))
+	})
+
+	It("doesn't take a token from the IP bucket if the prefix is rate limited", func() {
+		c := NewAdmissionController(&AdmissionControlConfig{PerIPRate: 1, PerPrefixRate: 1})
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("192.0.2.2"), false)).To(Equal(admissionDrop))
+		Expect(c.perIP.buckets).To(HaveLen(1))
+	})
+
+	It("sends a Retry when overloaded", func() {
+		c := NewAdmissionController(&AdmissionControlConfig{RetryThreshold: 2})
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionRetry))
+		// connection attempts with a validated address are accepted
+		Expect(c.admit(addr("192.0.2.1"), true)).To(Equal(admissionAccept))
+		Expect(c.Stats()).To(Equal(AdmissionStats{Accepted: 3, Retried: 1, Handshakes: 3}))
+		c.handshakeDone()
+		c.handshakeDone()
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+	})
+
+	It("refuses connection attempts when there are too many concurrent handshakes", func() {
+		c := NewAdmissionController(&AdmissionControlConfig{RetryThreshold: 1, MaxConcurrentHandshakes: 2})
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionRetry))
+		Expect(c.admit(addr("192.0.2.1"), true)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("192.0.2.1"), true)).To(Equal(admissionRefuse))
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionRefuse))
+		Expect(c.Stats()).To(Equal(AdmissionStats{Accepted: 2, Retried: 1, Refused: 2, Handshakes: 2}))
+		c.handshakeDone()
+		Expect(c.admit(addr("192.0.2.1"), true)).To(Equal(admissionAccept))
+	})
+
+	It("removes full token buckets", func() {
+		c := NewAdmissionController(&AdmissionControlConfig{PerIPRate: 1000, PerPrefixRate: 1000})
+		Expect(c.admit(addr("192.0.2.1"), false)).To(Equal(admissionAccept))
+		Expect(c.admit(addr("2001:db8::1"), false)).To(Equal(admissionAccept))
+		Expect(c.perIP.buckets).To(HaveLen(2))
+		Expect(c.perPrefix.buckets).To(HaveLen(2))
+		c.lastCleanup = time.Now().Add(-admissionBucketCleanupInterval)
+		time.Sleep(5 * time.Millisecond)
+		Expect(c.admit(addr("192.0.2.2"), false)).To(Equal(admissionAccept))
+		Expect(c.perIP.buckets).To(HaveLen(1))
+		Expect(c.perPrefix.buckets).To(HaveLen(1))
+	})
+
+	It("limits the number of token buckets", func() {
+		c := NewAdmissionController(&AdmissionControlConfig{PerIPRate: 0.001, PerPrefixRate: 0.001, PerPrefixBurst: 100})
+		c.perIP.maxBuckets = 3
+		c.perPrefix.maxBuckets = 2
+		for i := 1; i <= 10; i++ {
+			Expect(c.admit(addr(fmt.Sprintf("192.0.%d.1", i)), false)).To(Equal(admissionAccept))
+			Expect(len(c.perIP.buckets)).To(BeNumerically("<=", 3))
+			Expect(len(c.perPrefix.buckets)).To(BeNumerically("<=", 2))
+		}
+		// the bucket for the last address is never evicted
+		Expect(c.admit(addr("192.0.10.1"), false)).To(Equal(admissionDrop))
+	})
+})
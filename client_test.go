@@ -439,7 +439,7 @@ var _ = Describe("Client", func() {
 			Eventually(connCreated).Should(BeClosed())
 
 			// check that the connection is not closed
-			Expect(sconn.Write([]byte("foobar"))).To(Succeed())
+			Expect(sconn.Write([]byte("foobar"), 0, protocol.ECNNon)).To(Succeed())
 
 			manager.EXPECT().Destroy()
 			close(run)
@@ -5,6 +5,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 )
@@ -19,6 +20,23 @@ func (c *Config) handshakeTimeout() time.Duration {
 	return utils.Max(protocol.DefaultHandshakeTimeout, 2*c.HandshakeIdleTimeout)
 }
 
+func (c *Config) congestionControl() func(congestion.ConnectionInfo) congestion.CongestionController {
+	if c.CongestionControl != nil {
+		return c.CongestionControl
+	}
+	if c.UseBBR {
+		return congestion.NewBBR
+	}
+	return congestion.NewReno
+}
+
+func (c *Config) streamScheduler() StreamScheduler {
+	if c.StreamScheduler != nil {
+		return c.StreamScheduler()
+	}
+	return NewRoundRobinStreamScheduler()
+}
+
 func validateConfig(config *Config) error {
 	if config == nil {
 		return nil
@@ -29,6 +47,26 @@ func validateConfig(config *Config) error {
 	if config.MaxIncomingUniStreams > 1<<60 {
 		return errors.New("invalid value for Config.MaxIncomingUniStreams")
 	}
+	if config.DatagramSendQueueLen < 0 {
+		return errors.New("invalid value for Config.DatagramSendQueueLen")
+	}
+	if config.DatagramDropPolicy > DatagramDropNewest {
+		return errors.New("invalid value for Config.DatagramDropPolicy")
+	}
+	if pa := config.PreferredAddress; pa != nil {
+		if pa.Conn == nil {
+			return errors.New("invalid value for Config.PreferredAddress: packet conn not set")
+		}
+		if pa.IPv4 == nil && pa.IPv6 == nil {
+			return errors.New("invalid value for Config.PreferredAddress: neither IPv4 nor IPv6 address set")
+		}
+		if pa.IPv4 != nil && pa.IPv4.IP.To4() == nil {
+			return errors.New("invalid value for Config.PreferredAddress: invalid IPv4 address")
+		}
+		if pa.IPv6 != nil && (pa.IPv6.IP.To16() == nil || pa.IPv6.IP.To4() != nil) {
+			return errors.New("invalid value for Config.PreferredAddress: invalid IPv6 address")
+		}
+	}
 	return nil
 }
 
@@ -52,7 +90,8 @@ func populateServerConfig(config *Config) *Config {
 // it may be called with nil
 func populateClientConfig(config *Config, createdPacketConn bool) *Config {
 	defaultConnIDLen := protocol.DefaultConnectionIDLength
-	if createdPacketConn {
+	// Multipath requires non-zero-length connection IDs.
+	if createdPacketConn && (config == nil || !config.EnableMultipath) {
 		defaultConnIDLen = 0
 	}
 
@@ -112,12 +151,17 @@ func populateConfig(config *Config, defaultConnIDLen int) *Config {
 	if connIDGenerator == nil {
 		connIDGenerator = &protocol.DefaultConnectionIDGenerator{ConnLen: conIDLen}
 	}
+	pathScheduler := config.PathScheduler
+	if pathScheduler == nil {
+		pathScheduler = &minRTTPathScheduler{}
+	}
 
 	return &Config{
 		Versions:                         versions,
 		HandshakeIdleTimeout:             handshakeIdleTimeout,
 		MaxIdleTimeout:                   idleTimeout,
 		MaxTokenAge:                      config.MaxTokenAge,
+		TokenKeys:                        config.TokenKeys,
 		MaxRetryTokenAge:                 config.MaxRetryTokenAge,
 		RequireAddressValidation:         config.RequireAddressValidation,
 		KeepAlivePeriod:                  config.KeepAlivePeriod,
@@ -133,9 +177,23 @@ func populateConfig(config *Config, defaultConnIDLen int) *Config {
 		StatelessResetKey:                config.StatelessResetKey,
 		TokenStore:                       config.TokenStore,
 		EnableDatagrams:                  config.EnableDatagrams,
+		DatagramSendQueueLen:             config.DatagramSendQueueLen,
+		DatagramDropPolicy:               config.DatagramDropPolicy,
 		DisablePathMTUDiscovery:          config.DisablePathMTUDiscovery,
 		DisableVersionNegotiationPackets: config.DisableVersionNegotiationPackets,
 		Tracer:                           config.Tracer,
 		UseBBR:                           config.UseBBR,
+		CongestionControl:                config.CongestionControl,
+		PreferredAddress:                 config.PreferredAddress,
+		EnableMultipath:                  config.EnableMultipath,
+		StreamScheduler:                  config.StreamScheduler,
+		EnableResetStreamAt:              config.EnableResetStreamAt,
+		EnableAckFrequency:               config.EnableAckFrequency,
+		PathScheduler:                    pathScheduler,
+		ZeroRTTAntiReplay:                config.ZeroRTTAntiReplay,
+		Allow0RTT:                        config.Allow0RTT,
+		GetSessionTicketData:             config.GetSessionTicketData,
+		AdmissionController:              config.AdmissionController,
+		ShutdownErrorCode:                config.ShutdownErrorCode,
 	}
 }
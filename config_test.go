@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/congestion"
 	mocklogging "github.com/lucas-clemente/quic-go/internal/mocks/logging"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 
@@ -30,6 +31,24 @@ var _ = Describe("Config", func() {
 		It("errors on too large values for MaxIncomingUniStreams", func() {
 			Expect(validateConfig(&Config{MaxIncomingUniStreams: 1<<60 + 1})).To(MatchError("invalid value for Config.MaxIncomingUniStreams"))
 		})
+
+		It("errors on invalid datagram send queue settings", func() {
+			Expect(validateConfig(&Config{DatagramSendQueueLen: 10, DatagramDropPolicy: DatagramDropNewest})).To(Succeed())
+			Expect(validateConfig(&Config{DatagramSendQueueLen: -1})).To(MatchError("invalid value for Config.DatagramSendQueueLen"))
+			Expect(validateConfig(&Config{DatagramDropPolicy: DatagramDropNewest + 1})).To(MatchError("invalid value for Config.DatagramDropPolicy"))
+		})
+
+		It("validates the preferred address", func() {
+			conn := &net.UDPConn{}
+			ipv4 := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 443}
+			ipv6 := &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 443}
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv4: ipv4, IPv6: ipv6, Conn: conn}})).To(Succeed())
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv6: ipv6, Conn: conn}})).To(Succeed())
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv4: ipv4}})).To(MatchError("invalid value for Config.PreferredAddress: packet conn not set"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{Conn: conn}})).To(MatchError("invalid value for Config.PreferredAddress: neither IPv4 nor IPv6 address set"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv4: ipv6, Conn: conn}})).To(MatchError("invalid value for Config.PreferredAddress: invalid IPv4 address"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv6: ipv4, Conn: conn}})).To(MatchError("invalid value for Config.PreferredAddress: invalid IPv6 address"))
+		})
 	})
 
 	configWithNonZeroNonFunctionFields := func() *Config {
@@ -45,7 +64,7 @@ var _ = Describe("Config", func() {
 			}
 
 			switch fn := typ.Field(i).Name; fn {
-			case "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease":
+			case "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "CongestionControl", "StreamScheduler", "Allow0RTT", "GetSessionTicketData":
 				// Can't compare functions.
 			case "Versions":
 				f.Set(reflect.ValueOf([]VersionNumber{1, 2, 3}))
@@ -61,6 +80,8 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(2 * time.Hour))
 			case "MaxRetryTokenAge":
 				f.Set(reflect.ValueOf(2 * time.Minute))
+			case "TokenKeys":
+				f.Set(reflect.ValueOf([]TokenKey{{1, 2, 3}, {4, 5, 6}}))
 			case "TokenStore":
 				f.Set(reflect.ValueOf(NewLRUTokenStore(2, 3)))
 			case "InitialStreamReceiveWindow":
@@ -89,6 +110,26 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(mocklogging.NewMockTracer(mockCtrl)))
 			case "UseBBR":
 				f.Set(reflect.ValueOf(false))
+			case "PreferredAddress":
+				f.Set(reflect.ValueOf(&PreferredAddress{IPv4: &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 443}}))
+			case "EnableMultipath":
+				f.Set(reflect.ValueOf(true))
+			case "PathScheduler":
+				f.Set(reflect.ValueOf(&minRTTPathScheduler{}))
+			case "ZeroRTTAntiReplay":
+				f.Set(reflect.ValueOf(NewZeroRTTAntiReplayCache(time.Minute)))
+			case "DatagramSendQueueLen":
+				f.Set(reflect.ValueOf(64))
+			case "DatagramDropPolicy":
+				f.Set(reflect.ValueOf(DatagramDropNewest))
+			case "EnableResetStreamAt":
+				f.Set(reflect.ValueOf(true))
+			case "EnableAckFrequency":
+				f.Set(reflect.ValueOf(true))
+			case "AdmissionController":
+				f.Set(reflect.ValueOf(NewAdmissionController(&AdmissionControlConfig{MaxConcurrentHandshakes: 10})))
+			case "ShutdownErrorCode":
+				f.Set(reflect.ValueOf(ApplicationErrorCode(0x42)))
 			default:
 				Fail(fmt.Sprintf("all fields must be accounted for, but saw unknown field %q", fn))
 			}
@@ -106,18 +147,54 @@ var _ = Describe("Config", func() {
 		Expect(c.handshakeTimeout()).To(Equal(11 * time.Second))
 	})
 
+	Context("congestion control", func() {
+		isFunc := func(f, expected func(congestion.ConnectionInfo) congestion.CongestionController) bool {
+			return reflect.ValueOf(f).Pointer() == reflect.ValueOf(expected).Pointer()
+		}
+
+		It("uses NewReno by default", func() {
+			Expect(isFunc((&Config{}).congestionControl(), congestion.NewReno)).To(BeTrue())
+		})
+
+		It("uses BBR, if configured", func() {
+			Expect(isFunc((&Config{UseBBR: true}).congestionControl(), congestion.NewBBR)).To(BeTrue())
+		})
+
+		It("uses the configured congestion controller", func() {
+			c := &Config{UseBBR: true, CongestionControl: congestion.NewCubic}
+			Expect(isFunc(c.congestionControl(), congestion.NewCubic)).To(BeTrue())
+		})
+	})
+
+	Context("stream scheduler", func() {
+		It("uses round-robin scheduling by default", func() {
+			Expect((&Config{}).streamScheduler()).To(BeAssignableToTypeOf(&roundRobinScheduler{}))
+		})
+
+		It("uses the configured stream scheduler", func() {
+			c := &Config{StreamScheduler: NewWeightedFairStreamScheduler}
+			Expect(c.streamScheduler()).To(BeAssignableToTypeOf(&weightedFairScheduler{}))
+		})
+	})
+
 	Context("cloning", func() {
 		It("clones function fields", func() {
-			var calledAddrValidation, calledAllowConnectionWindowIncrease bool
+			var calledAddrValidation, calledAllowConnectionWindowIncrease, calledAllow0RTT, calledGetSessionTicketData bool
 			c1 := &Config{
 				AllowConnectionWindowIncrease: func(Connection, uint64) bool { calledAllowConnectionWindowIncrease = true; return true },
 				RequireAddressValidation:      func(net.Addr) bool { calledAddrValidation = true; return true },
+				Allow0RTT:                     func(net.Addr, []byte) bool { calledAllow0RTT = true; return true },
+				GetSessionTicketData:          func(net.Addr) []byte { calledGetSessionTicketData = true; return nil },
 			}
 			c2 := c1.Clone()
 			c2.RequireAddressValidation(&net.UDPAddr{})
 			Expect(calledAddrValidation).To(BeTrue())
 			c2.AllowConnectionWindowIncrease(nil, 1234)
 			Expect(calledAllowConnectionWindowIncrease).To(BeTrue())
+			c2.Allow0RTT(&net.UDPAddr{}, nil)
+			Expect(calledAllow0RTT).To(BeTrue())
+			c2.GetSessionTicketData(&net.UDPAddr{})
+			Expect(calledGetSessionTicketData).To(BeTrue())
 		})
 
 		It("clones non-function fields", func() {
@@ -166,12 +243,15 @@ var _ = Describe("Config", func() {
 			Expect(c.MaxIncomingUniStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingUniStreams))
 			Expect(c.DisableVersionNegotiationPackets).To(BeFalse())
 			Expect(c.DisablePathMTUDiscovery).To(BeFalse())
+			Expect(c.EnableMultipath).To(BeFalse())
+			Expect(c.PathScheduler).To(BeAssignableToTypeOf(&minRTTPathScheduler{}))
 		})
 
 		It("populates empty fields with default values, for the server", func() {
 			c := populateServerConfig(&Config{})
 			Expect(c.ConnectionIDLength).To(Equal(protocol.DefaultConnectionIDLength))
 			Expect(c.RequireAddressValidation).ToNot(BeNil())
+			Expect(c.ZeroRTTAntiReplay).To(BeNil())
 		})
 
 		It("sets a default connection ID length if we didn't create the conn, for the client", func() {
@@ -183,5 +263,10 @@ var _ = Describe("Config", func() {
 			c := populateClientConfig(&Config{}, true)
 			Expect(c.ConnectionIDLength).To(BeZero())
 		})
+
+		It("sets a default connection ID length if we created the conn and multipath is enabled, for the client", func() {
+			c := populateClientConfig(&Config{EnableMultipath: true}, true)
+			Expect(c.ConnectionIDLength).To(Equal(protocol.DefaultConnectionIDLength))
+		})
 	})
 })
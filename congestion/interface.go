@@ -0,0 +1,97 @@
+// Package congestion defines the interface between quic-go and congestion control algorithms.
+// It also contains the congestion control algorithms that ship with quic-go.
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
+)
+
+type (
+	// A ByteCount is used to count bytes.
+	ByteCount = protocol.ByteCount
+	// The PacketNumber is the packet number of a packet.
+	PacketNumber = protocol.PacketNumber
+	// The Perspective is the role of a QUIC endpoint (client or server).
+	Perspective = protocol.Perspective
+	// Bandwidth (bps)
+	Bandwidth = congestion.Bandwidth
+	// The RTTStats contain the RTT estimate of a connection (or a path of a connection).
+	RTTStats = utils.RTTStats
+)
+
+const (
+	// BitsPerSecond is 1 bit per second
+	BitsPerSecond = congestion.BitsPerSecond
+	// BytesPerSecond is 1 byte per second
+	BytesPerSecond = congestion.BytesPerSecond
+)
+
+// ConnectionInfo contains the information that a congestion controller is created with.
+// A new congestion controller is created for every connection,
+// when a connection migrates to a new path, and for every additional path of a multipath connection.
+type ConnectionInfo struct {
+	Perspective Perspective
+	// The initial maximum datagram size.
+	// If Path MTU Discovery finds a larger MTU, SetMaxDatagramSize is called.
+	InitialMaxDatagramSize ByteCount
+	// The RTT estimate. It is updated before OnPacketAcked is called.
+	// Congestion controllers must not modify it.
+	RTTStats *RTTStats
+	// The tracer for the connection. It is nil if tracing is disabled,
+	// and for additional paths of a multipath connection.
+	Tracer logging.ConnectionTracer
+}
+
+// A CongestionController performs congestion control and pacing.
+// All methods are called from the connection's run loop, implementations don't need to be safe for concurrent use.
+type CongestionController interface {
+	// TimeUntilSend returns when the next packet may be sent, according to the pacer.
+	TimeUntilSend(bytesInFlight ByteCount) time.Time
+	// HasPacingBudget says if the pacer allows sending of a (full-size) packet right now.
+	HasPacingBudget() bool
+	// OnPacketSent is called for every packet sent.
+	// isRetransmittable says if the packet is ack-eliciting, and therefore counts towards bytesInFlight.
+	OnPacketSent(sentTime time.Time, bytesInFlight ByteCount, packetNumber PacketNumber, bytes ByteCount, isRetransmittable bool)
+	// CanSend says if the congestion window allows sending of more data.
+	CanSend(bytesInFlight ByteCount) bool
+	// MaybeExitSlowStart is called when an ACK is received, before OnPacketAcked is called for the acknowledged packets.
+	MaybeExitSlowStart()
+	// OnPacketAcked is called for every ack-eliciting packet that is acknowledged.
+	OnPacketAcked(number PacketNumber, ackedBytes ByteCount, priorInFlight ByteCount, eventTime time.Time)
+	// OnPacketLost is called for every ack-eliciting packet that is declared lost.
+	OnPacketLost(number PacketNumber, lostBytes ByteCount, priorInFlight ByteCount)
+	// OnECNCongestionEvent is called when an ACK increases the ECN-CE count.
+	OnECNCongestionEvent(largestAcked PacketNumber, priorInFlight ByteCount)
+	// OnRetransmissionTimeout is called when the PTO timer fires.
+	OnRetransmissionTimeout(packetsRetransmitted bool)
+	// SetMaxDatagramSize is called when Path MTU Discovery increases the maximum datagram size.
+	SetMaxDatagramSize(ByteCount)
+
+	// InSlowStart, InRecovery and GetCongestionWindow are used for logging.
+	InSlowStart() bool
+	InRecovery() bool
+	GetCongestionWindow() ByteCount
+	// BandwidthEstimate gets the current estimate of bandwidth in bps.
+	// It is exposed to the application by Connection.BandwidthEstimate and PathInfo.BandwidthEstimate, and used by the PathScheduler.
+	BandwidthEstimate() Bandwidth
+}
+
+// An AckFrequencyRequester is a CongestionController that determines how often the peer should send ACKs,
+// using the ACK frequency extension (draft-ietf-quic-ack-frequency).
+// It is only used if the ACK frequency extension was negotiated (see Config.EnableAckFrequency).
+// If the congestion controller doesn't implement this interface, the ACK rate is derived from the congestion window.
+type AckFrequencyRequester interface {
+	// AckFrequency is called every time an ACK is received.
+	// It returns the number of ack-eliciting packets the peer may receive without sending an ACK,
+	// and the maximum amount of time the peer may delay an ACK.
+	// If maxAckDelay is 0, the peer's max_ack_delay is used.
+	// A new ACK_FREQUENCY frame is sent when the returned values change.
+	AckFrequency() (ackElicitingThreshold uint64, maxAckDelay time.Duration)
+}
+
+var _ CongestionController = congestion.SendAlgorithmWithDebugInfos(nil)
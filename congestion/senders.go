@@ -0,0 +1,37 @@
+package congestion
+
+import "github.com/lucas-clemente/quic-go/internal/congestion"
+
+// NewReno creates a congestion controller using NewReno (RFC 9002).
+// This is the congestion controller used by default.
+func NewReno(info ConnectionInfo) CongestionController {
+	return congestion.NewCubicSender(
+		congestion.DefaultClock{},
+		info.RTTStats,
+		info.InitialMaxDatagramSize,
+		true, // use Reno
+		info.Tracer,
+	)
+}
+
+// NewCubic creates a congestion controller using CUBIC (RFC 8312).
+func NewCubic(info ConnectionInfo) CongestionController {
+	return congestion.NewCubicSender(
+		congestion.DefaultClock{},
+		info.RTTStats,
+		info.InitialMaxDatagramSize,
+		false, // use Cubic
+		info.Tracer,
+	)
+}
+
+// NewBBR creates a congestion controller using BBR.
+func NewBBR(info ConnectionInfo) CongestionController {
+	return congestion.NewBBRSender(info.RTTStats, info.InitialMaxDatagramSize)
+}
+
+// NewBBRv2 creates a congestion controller using BBRv2.
+// Compared to BBR, it reacts to packet loss and ECN, and is therefore better suited for lossy and shallow-buffered links.
+func NewBBRv2(info ConnectionInfo) CongestionController {
+	return congestion.NewBBRv2Sender(congestion.DefaultClock{}, info.RTTStats, info.InitialMaxDatagramSize, info.Tracer)
+}
@@ -0,0 +1,71 @@
+package congestion
+
+import (
+	"time"
+
+	mocklogging "github.com/lucas-clemente/quic-go/internal/mocks/logging"
+	"github.com/lucas-clemente/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Congestion Controllers", func() {
+	const maxDatagramSize = 1234
+
+	newInfo := func() ConnectionInfo {
+		return ConnectionInfo{
+			InitialMaxDatagramSize: maxDatagramSize,
+			RTTStats:               &RTTStats{},
+		}
+	}
+
+	// windowAfterLoss returns the congestion window after sending a full congestion window of packets,
+	// and losing one of them
+	windowAfterLoss := func(cc CongestionController) ByteCount {
+		var bytesInFlight ByteCount
+		var pn PacketNumber
+		for cc.CanSend(bytesInFlight) {
+			pn++
+			cc.OnPacketSent(time.Now(), bytesInFlight, pn, maxDatagramSize, true)
+			bytesInFlight += maxDatagramSize
+		}
+		cc.OnPacketLost(pn, maxDatagramSize, bytesInFlight)
+		return cc.GetCongestionWindow()
+	}
+
+	for name, constructor := range map[string]func(ConnectionInfo) CongestionController{
+		"NewReno": NewReno,
+		"Cubic":   NewCubic,
+		"BBR":     NewBBR,
+		"BBRv2":   NewBBRv2,
+	} {
+		name := name
+		constructor := constructor
+
+		It(name+" starts in slow start", func() {
+			cc := constructor(newInfo())
+			Expect(cc.InSlowStart()).To(BeTrue())
+			Expect(cc.InRecovery()).To(BeFalse())
+			Expect(cc.GetCongestionWindow()).To(BeNumerically(">=", 4*maxDatagramSize))
+			Expect(cc.CanSend(0)).To(BeTrue())
+			Expect(cc.HasPacingBudget()).To(BeTrue())
+		})
+	}
+
+	It("reduces the congestion window on packet loss, for NewReno and Cubic", func() {
+		for _, cc := range []CongestionController{NewReno(newInfo()), NewCubic(newInfo())} {
+			initialWindow := cc.GetCongestionWindow()
+			Expect(windowAfterLoss(cc)).To(BeNumerically("<", initialWindow))
+			Expect(cc.InSlowStart()).To(BeFalse())
+		}
+	})
+
+	It("passes the tracer to NewReno", func() {
+		tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+		tracer.EXPECT().UpdatedCongestionState(logging.CongestionStateSlowStart)
+		info := newInfo()
+		info.Tracer = tracer
+		NewReno(info)
+	})
+})
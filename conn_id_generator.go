@@ -15,6 +15,8 @@ type connIDGenerator struct {
 
 	activeSrcConnIDs        map[uint64]protocol.ConnectionID
 	initialClientDestConnID *protocol.ConnectionID // nil for the client
+	// the connection ID sent in the preferred_address transport parameter, until it is registered
+	preferredAddressConnID *protocol.ConnectionID
 
 	addConnectionID        func(protocol.ConnectionID)
 	getStatelessResetToken func(protocol.ConnectionID) protocol.StatelessResetToken
@@ -62,8 +64,6 @@ func (m *connIDGenerator) SetMaxActiveConnIDs(limit uint64) error {
 	// connection IDs the peer will store. This limit includes the connection ID
 	// used during the handshake, and the one sent in the preferred_address
 	// transport parameter.
-	// We currently don't send the preferred_address transport parameter,
-	// so we can issue (limit - 1) connection IDs.
 	for i := uint64(len(m.activeSrcConnIDs)); i < utils.Min(limit, protocol.MaxIssuedConnectionIDs); i++ {
 		if err := m.issueNewConnID(); err != nil {
 			return err
@@ -99,6 +99,24 @@ func (m *connIDGenerator) Retire(seq uint64, sentWithDestConnID protocol.Connect
 	return m.issueNewConnID()
 }
 
+// IssuePreferredAddressConnID issues the connection ID that is sent in the preferred_address transport parameter.
+// It must be called before any other connection ID is issued, since this connection ID has sequence number 1.
+// The peer can't use this connection ID before the handshake completes,
+// so it is only registered when SetHandshakeComplete is called.
+func (m *connIDGenerator) IssuePreferredAddressConnID() (protocol.ConnectionID, error) {
+	if m.highestSeq != 0 {
+		return protocol.ConnectionID{}, fmt.Errorf("connection ID %d was already issued", m.highestSeq)
+	}
+	connID, err := m.generator.GenerateConnectionID()
+	if err != nil {
+		return protocol.ConnectionID{}, err
+	}
+	m.highestSeq = 1
+	m.activeSrcConnIDs[1] = connID
+	m.preferredAddressConnID = &connID
+	return connID, nil
+}
+
 func (m *connIDGenerator) issueNewConnID() error {
 	connID, err := m.generator.GenerateConnectionID()
 	if err != nil {
@@ -120,6 +138,10 @@ func (m *connIDGenerator) SetHandshakeComplete() {
 		m.retireConnectionID(*m.initialClientDestConnID)
 		m.initialClientDestConnID = nil
 	}
+	if m.preferredAddressConnID != nil {
+		m.addConnectionID(*m.preferredAddressConnID)
+		m.preferredAddressConnID = nil
+	}
 }
 
 func (m *connIDGenerator) RemoveAll() {
@@ -132,6 +154,22 @@ func (m *connIDGenerator) RemoveAll() {
 }
 
 func (m *connIDGenerator) ReplaceWithClosed(pers protocol.Perspective, connClose []byte) {
+	m.replaceWithClosed(m.ConnectionIDs(), pers, connClose)
+}
+
+// SequenceNumber returns the sequence number of a connection ID that is currently in use.
+// When using multipath, the sequence number identifies the path that a packet was received on.
+func (m *connIDGenerator) SequenceNumber(connID protocol.ConnectionID) (uint64, bool) {
+	for seq, c := range m.activeSrcConnIDs {
+		if c == connID {
+			return seq, true
+		}
+	}
+	return 0, false
+}
+
+// ConnectionIDs returns all connection IDs that are currently in use for this connection.
+func (m *connIDGenerator) ConnectionIDs() []protocol.ConnectionID {
 	connIDs := make([]protocol.ConnectionID, 0, len(m.activeSrcConnIDs)+1)
 	if m.initialClientDestConnID != nil {
 		connIDs = append(connIDs, *m.initialClientDestConnID)
@@ -139,5 +177,5 @@ func (m *connIDGenerator) ReplaceWithClosed(pers protocol.Perspective, connClose
 	for _, connID := range m.activeSrcConnIDs {
 		connIDs = append(connIDs, connID)
 	}
-	m.replaceWithClosed(connIDs, pers, connClose)
+	return connIDs
 }
@@ -75,6 +75,25 @@ var _ = Describe("Connection ID Generator", func() {
 		Expect(queuedFrames).To(HaveLen(protocol.MaxIssuedConnectionIDs - 1))
 	})
 
+	It("issues the connection ID for the preferred address", func() {
+		connID, err := g.IssuePreferredAddressConnID()
+		Expect(err).ToNot(HaveOccurred())
+		// the connection ID is sent in the transport parameters, not in a NEW_CONNECTION_ID frame
+		Expect(queuedFrames).To(BeEmpty())
+		Expect(g.ConnectionIDs()).To(ContainElement(connID))
+		// the connection ID is registered when the handshake completes
+		Expect(addedConnIDs).To(BeEmpty())
+		g.SetHandshakeComplete()
+		Expect(addedConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+		// the preferred address connection ID counts towards the limit
+		Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+		Expect(queuedFrames).To(HaveLen(2))
+		Expect(queuedFrames[0].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(2))
+		Expect(queuedFrames[1].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(3))
+		_, err = g.IssuePreferredAddressConnID()
+		Expect(err).To(MatchError("connection ID 3 was already issued"))
+	})
+
 	// SetMaxActiveConnIDs is called twice when dialing a 0-RTT connection:
 	// once for the restored from the old connections, once when we receive the transport parameters
 	Context("dealing with 0-RTT", func() {
@@ -162,6 +181,19 @@ var _ = Describe("Connection ID Generator", func() {
 		Expect(retiredConnIDs[0]).To(Equal(initialClientDestConnID))
 	})
 
+	It("looks up the sequence number of a connection ID", func() {
+		Expect(g.SetMaxActiveConnIDs(3)).To(Succeed())
+		seq, ok := g.SequenceNumber(initialConnID)
+		Expect(ok).To(BeTrue())
+		Expect(seq).To(BeZero())
+		nf := queuedFrames[1].(*wire.NewConnectionIDFrame)
+		seq, ok = g.SequenceNumber(nf.ConnectionID)
+		Expect(ok).To(BeTrue())
+		Expect(seq).To(BeEquivalentTo(2))
+		_, ok = g.SequenceNumber(protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad}))
+		Expect(ok).To(BeFalse())
+	})
+
 	It("removes all connection IDs", func() {
 		Expect(g.SetMaxActiveConnIDs(5)).To(Succeed())
 		Expect(queuedFrames).To(HaveLen(4))
@@ -24,6 +24,14 @@ type connIDManager struct {
 	highestRetired            uint64
 	activeConnectionID        protocol.ConnectionID
 	activeStatelessResetToken *protocol.StatelessResetToken
+	// The connection ID used to probe a new path.
+	// It is taken out of the queue, such that it is not used on the current path.
+	probingConnID *newConnID
+	// When using multipath, every additional path uses its own connection ID.
+	// These connection IDs are taken out of the queue as well, and indexed by their sequence number.
+	// Connection IDs are not changed while multipath is used, since the sequence number identifies the path.
+	multipath   bool
+	pathConnIDs map[uint64]newConnID
 
 	// We change the connection ID after sending on average
 	// protocol.PacketsPerConnectionID packets. The actual value is randomized
@@ -59,7 +67,11 @@ func (h *connIDManager) Add(f *wire.NewConnectionIDFrame) error {
 	if err := h.add(f); err != nil {
 		return err
 	}
-	if h.queue.Len() >= protocol.MaxActiveConnectionIDs {
+	numConnIDs := h.queue.Len() + len(h.pathConnIDs)
+	if h.probingConnID != nil {
+		numConnIDs++
+	}
+	if numConnIDs >= protocol.MaxActiveConnectionIDs {
 		return &qerr.TransportError{ErrorCode: qerr.ConnectionIDLimitError}
 	}
 	return nil
@@ -89,12 +101,21 @@ func (h *connIDManager) add(f *wire.NewConnectionIDFrame) error {
 			})
 			h.queue.Remove(el)
 		}
+		if h.probingConnID != nil && h.probingConnID.SequenceNumber < f.RetirePriorTo {
+			h.retireProbingConnID()
+		}
 		h.highestRetired = f.RetirePriorTo
 	}
 
 	if f.SequenceNumber == h.activeSequenceNumber {
 		return nil
 	}
+	if h.probingConnID != nil && f.SequenceNumber == h.probingConnID.SequenceNumber {
+		return nil
+	}
+	if _, ok := h.pathConnIDs[f.SequenceNumber]; ok {
+		return nil
+	}
 
 	if err := h.addConnectionID(f.SequenceNumber, f.ConnectionID, f.StatelessResetToken); err != nil {
 		return err
@@ -142,6 +163,13 @@ func (h *connIDManager) addConnectionID(seq uint64, connID protocol.ConnectionID
 }
 
 func (h *connIDManager) updateConnectionID() {
+	h.retireActiveConnectionID()
+	front := h.queue.Remove(h.queue.Front())
+	h.setActiveConnectionID(front)
+	h.addStatelessResetToken(*h.activeStatelessResetToken)
+}
+
+func (h *connIDManager) retireActiveConnectionID() {
 	h.queueControlFrame(&wire.RetireConnectionIDFrame{
 		SequenceNumber: h.activeSequenceNumber,
 	})
@@ -149,20 +177,114 @@ func (h *connIDManager) updateConnectionID() {
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
 	}
+}
 
-	front := h.queue.Remove(h.queue.Front())
-	h.activeSequenceNumber = front.SequenceNumber
-	h.activeConnectionID = front.ConnectionID
-	h.activeStatelessResetToken = &front.StatelessResetToken
+func (h *connIDManager) setActiveConnectionID(c newConnID) {
+	h.activeSequenceNumber = c.SequenceNumber
+	h.activeConnectionID = c.ConnectionID
+	h.activeStatelessResetToken = &c.StatelessResetToken
 	h.packetsSinceLastChange = 0
 	h.packetsPerConnectionID = protocol.PacketsPerConnectionID/2 + uint32(h.rand.Int31n(protocol.PacketsPerConnectionID))
-	h.addStatelessResetToken(*h.activeStatelessResetToken)
+}
+
+// GetForProbing returns a connection ID that can be used to probe a new path.
+// A connection ID must not be used on more than one path (see section 9.5 of RFC 9000),
+// so this connection ID won't be used on the current path.
+// It returns false if the peer didn't provide any unused connection IDs.
+func (h *connIDManager) GetForProbing() (protocol.ConnectionID, bool) {
+	if h.activeConnectionID.Len() == 0 {
+		return h.activeConnectionID, true
+	}
+	if h.probingConnID != nil {
+		return h.probingConnID.ConnectionID, true
+	}
+	if h.queue.Len() == 0 {
+		return protocol.ConnectionID{}, false
+	}
+	front := h.queue.Remove(h.queue.Front())
+	h.probingConnID = &front
+	h.addStatelessResetToken(front.StatelessResetToken)
+	return front.ConnectionID, true
+}
+
+// SwitchToProbingConnID is called when the connection migrates to the probed path.
+// The connection ID used for probing becomes the active connection ID.
+func (h *connIDManager) SwitchToProbingConnID() {
+	if h.probingConnID == nil {
+		return
+	}
+	h.retireActiveConnectionID()
+	h.setActiveConnectionID(*h.probingConnID)
+	h.probingConnID = nil
+}
+
+// AbandonProbingConnID is called when path validation failed.
+// The connection ID used for probing can't be used on the current path, so it is retired.
+func (h *connIDManager) AbandonProbingConnID() {
+	if h.probingConnID == nil {
+		return
+	}
+	h.retireProbingConnID()
+}
+
+func (h *connIDManager) retireProbingConnID() {
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{
+		SequenceNumber: h.probingConnID.SequenceNumber,
+	})
+	h.highestRetired = utils.Max(h.highestRetired, h.probingConnID.SequenceNumber)
+	h.removeStatelessResetToken(h.probingConnID.StatelessResetToken)
+	h.probingConnID = nil
+}
+
+// EnableMultipath is called when multipath was negotiated.
+// From then on, the connection ID used on the initial path isn't changed any more.
+func (h *connIDManager) EnableMultipath() {
+	h.multipath = true
+}
+
+// ActiveSequenceNumber returns the sequence number of the connection ID used on the current path.
+func (h *connIDManager) ActiveSequenceNumber() uint64 {
+	return h.activeSequenceNumber
+}
+
+// GetForPath returns a connection ID for a new path of a multipath connection.
+// It returns false if the peer didn't provide any unused connection IDs.
+// Connection IDs used by a path are not retired when the peer sends a NEW_CONNECTION_ID frame with
+// a Retire Prior To field larger than their sequence number. They are only retired when the path is abandoned.
+func (h *connIDManager) GetForPath() (newConnID, bool) {
+	if h.queue.Len() == 0 {
+		return newConnID{}, false
+	}
+	front := h.queue.Remove(h.queue.Front())
+	if h.pathConnIDs == nil {
+		h.pathConnIDs = make(map[uint64]newConnID)
+	}
+	h.pathConnIDs[front.SequenceNumber] = front
+	h.addStatelessResetToken(front.StatelessResetToken)
+	return front, true
+}
+
+// RetireForPath retires the connection ID used on a path that was abandoned.
+func (h *connIDManager) RetireForPath(seq uint64) {
+	c, ok := h.pathConnIDs[seq]
+	if !ok {
+		return
+	}
+	delete(h.pathConnIDs, seq)
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{SequenceNumber: seq})
+	h.removeStatelessResetToken(c.StatelessResetToken)
 }
 
 func (h *connIDManager) Close() {
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
 	}
+	if h.probingConnID != nil {
+		h.removeStatelessResetToken(h.probingConnID.StatelessResetToken)
+	}
+	for _, c := range h.pathConnIDs {
+		h.removeStatelessResetToken(c.StatelessResetToken)
+	}
 }
 
 // is called when the server performs a Retry
@@ -188,7 +310,7 @@ func (h *connIDManager) SentPacket() {
 }
 
 func (h *connIDManager) shouldUpdateConnID() bool {
-	if !h.handshakeComplete {
+	if !h.handshakeComplete || h.multipath {
 		return false
 	}
 	// initiate the first change as early as possible (after handshake completion)
@@ -361,4 +361,192 @@ var _ = Describe("Connection ID Manager", func() {
 		Expect(removedTokens).To(HaveLen(1))
 		Expect(removedTokens[0]).To(Equal(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}))
 	})
+
+	Context("probing", func() {
+		It("uses the active connection ID for probing, if it is zero-length", func() {
+			m.ChangeInitialConnID(protocol.ConnectionID{})
+			connID, ok := m.GetForProbing()
+			Expect(ok).To(BeTrue())
+			Expect(connID.Len()).To(BeZero())
+		})
+
+		It("doesn't return a connection ID for probing if the peer didn't provide any", func() {
+			_, ok := m.GetForProbing()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("reserves a connection ID for probing", func() {
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			})).To(Succeed())
+			connID, ok := m.GetForProbing()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+			Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}))
+			Expect(m.queue.Len()).To(BeZero())
+			// the same connection ID is returned when probing again
+			connID, ok = m.GetForProbing()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+			// the active connection ID is not affected
+			Expect(m.Get()).To(Equal(initialConnID))
+			Expect(frameQueue).To(BeEmpty())
+			// retransmissions of the NEW_CONNECTION_ID frame are ignored
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			})).To(Succeed())
+			Expect(m.queue.Len()).To(BeZero())
+		})
+
+		It("switches to the probing connection ID", func() {
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			})).To(Succeed())
+			_, ok := m.GetForProbing()
+			Expect(ok).To(BeTrue())
+			m.SwitchToProbingConnID()
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+			Expect(frameQueue).To(HaveLen(1))
+			Expect(frameQueue[0]).To(Equal(&wire.RetireConnectionIDFrame{SequenceNumber: 0}))
+			Expect(removedTokens).To(BeEmpty())
+			m.Close()
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}}))
+		})
+
+		It("retires the probing connection ID when probing is abandoned", func() {
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			})).To(Succeed())
+			_, ok := m.GetForProbing()
+			Expect(ok).To(BeTrue())
+			m.AbandonProbingConnID()
+			Expect(m.Get()).To(Equal(initialConnID))
+			Expect(frameQueue).To(HaveLen(1))
+			Expect(frameQueue[0]).To(Equal(&wire.RetireConnectionIDFrame{SequenceNumber: 1}))
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}}))
+			_, ok = m.GetForProbing()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("retires the probing connection ID when the peer asks to retire it", func() {
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			})).To(Succeed())
+			_, ok := m.GetForProbing()
+			Expect(ok).To(BeTrue())
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      2,
+				ConnectionID:        protocol.ParseConnectionID([]byte{2, 3, 4, 5}),
+				StatelessResetToken: protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1},
+				RetirePriorTo:       2,
+			})).To(Succeed())
+			Expect(frameQueue).To(ContainElement(&wire.RetireConnectionIDFrame{SequenceNumber: 1}))
+			Expect(removedTokens).To(ContainElement(protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}))
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{2, 3, 4, 5})))
+			connID, ok := m.GetForProbing()
+			Expect(ok).To(BeFalse())
+			Expect(connID.Len()).To(BeZero())
+		})
+	})
+
+	Context("multipath", func() {
+		It("doesn't change the connection ID", func() {
+			m.SetHandshakeComplete()
+			m.EnableMultipath()
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			})).To(Succeed())
+			Expect(m.Get()).To(Equal(initialConnID))
+			Expect(m.ActiveSequenceNumber()).To(BeZero())
+		})
+
+		It("reserves connection IDs for paths", func() {
+			_, ok := m.GetForPath()
+			Expect(ok).To(BeFalse())
+			for i := uint64(1); i <= 2; i++ {
+				Expect(m.Add(&wire.NewConnectionIDFrame{
+					SequenceNumber:      i,
+					ConnectionID:        protocol.ParseConnectionID([]byte{byte(i), 2, 3, 4}),
+					StatelessResetToken: protocol.StatelessResetToken{byte(i)},
+				})).To(Succeed())
+			}
+			c, ok := m.GetForPath()
+			Expect(ok).To(BeTrue())
+			Expect(c.SequenceNumber).To(BeEquivalentTo(1))
+			Expect(c.ConnectionID).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+			Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{1}))
+			c, ok = m.GetForPath()
+			Expect(ok).To(BeTrue())
+			Expect(c.SequenceNumber).To(BeEquivalentTo(2))
+			_, ok = m.GetForPath()
+			Expect(ok).To(BeFalse())
+			// retransmissions of the NEW_CONNECTION_ID frame are ignored
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1},
+			})).To(Succeed())
+			Expect(m.queue.Len()).To(BeZero())
+			// the active connection ID is not affected
+			Expect(m.Get()).To(Equal(initialConnID))
+			Expect(frameQueue).To(BeEmpty())
+		})
+
+		It("counts connection IDs used on paths towards the limit", func() {
+			for i := uint64(1); i < protocol.MaxActiveConnectionIDs; i++ {
+				Expect(m.Add(&wire.NewConnectionIDFrame{
+					SequenceNumber:      i,
+					ConnectionID:        protocol.ParseConnectionID([]byte{byte(i), 2, 3, 4}),
+					StatelessResetToken: protocol.StatelessResetToken{byte(i)},
+				})).To(Succeed())
+			}
+			_, ok := m.GetForPath()
+			Expect(ok).To(BeTrue())
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      protocol.MaxActiveConnectionIDs,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 3, 3, 7}),
+				StatelessResetToken: protocol.StatelessResetToken{42},
+			})).To(MatchError(&qerr.TransportError{ErrorCode: qerr.ConnectionIDLimitError}))
+		})
+
+		It("retires connection IDs of abandoned paths", func() {
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1},
+			})).To(Succeed())
+			c, ok := m.GetForPath()
+			Expect(ok).To(BeTrue())
+			m.RetireForPath(c.SequenceNumber)
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{1}}))
+			// retiring it again is a no-op
+			m.RetireForPath(c.SequenceNumber)
+			Expect(frameQueue).To(HaveLen(1))
+		})
+
+		It("removes the stateless reset tokens of paths when it is closed", func() {
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1},
+			})).To(Succeed())
+			_, ok := m.GetForPath()
+			Expect(ok).To(BeTrue())
+			m.Close()
+			Expect(removedTokens).To(ContainElement(protocol.StatelessResetToken{1}))
+		})
+	})
 })
@@ -0,0 +1,126 @@
+package quic
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// connRunners is a connRunner that forwards all calls to the packet handler maps
+// of all paths that a connection is reachable on.
+// This is necessary after a connection migrated to a new path:
+// The peer might still send packets on the old path, and packets on the new path
+// are received on a different packet conn.
+type connRunners struct {
+	runners []connRunner
+
+	resetTokens map[protocol.StatelessResetToken]packetHandler
+	// Retired connection IDs are only removed from the runners after a delay.
+	// They need to be removed explicitly when a runner is removed.
+	retiredConnIDs []protocol.ConnectionID
+}
+
+var _ connRunner = &connRunners{}
+
+func newConnRunners(runner connRunner) *connRunners {
+	return &connRunners{
+		runners:     []connRunner{runner},
+		resetTokens: make(map[protocol.StatelessResetToken]packetHandler),
+	}
+}
+
+// AddRunner adds a new runner, and registers the given connection IDs,
+// as well as all stateless reset tokens that are currently in use, with that runner.
+// It returns false if the runner is already in use.
+func (r *connRunners) AddRunner(runner connRunner, connIDs []protocol.ConnectionID, handler packetHandler) bool {
+	for _, runnerInUse := range r.runners {
+		if runnerInUse == runner {
+			return false
+		}
+	}
+	for _, connID := range connIDs {
+		runner.Add(connID, handler)
+	}
+	for token, h := range r.resetTokens {
+		runner.AddResetToken(token, h)
+	}
+	r.runners = append(r.runners, runner)
+	return true
+}
+
+// RemoveRunner removes the given connection IDs, as well as all stateless reset tokens, from a runner,
+// and stops using that runner.
+func (r *connRunners) RemoveRunner(runner connRunner, connIDs []protocol.ConnectionID) {
+	for i, runnerInUse := range r.runners {
+		if runnerInUse != runner {
+			continue
+		}
+		for _, connID := range connIDs {
+			runner.Remove(connID)
+		}
+		for _, connID := range r.retiredConnIDs {
+			runner.Remove(connID)
+		}
+		for token := range r.resetTokens {
+			runner.RemoveResetToken(token)
+		}
+		r.runners = append(r.runners[:i], r.runners[i+1:]...)
+		return
+	}
+}
+
+// RetainRunner removes the connection from all runners except for the given runner.
+func (r *connRunners) RetainRunner(runner connRunner, connIDs []protocol.ConnectionID) {
+	for _, runnerInUse := range append([]connRunner{}, r.runners...) {
+		if runnerInUse != runner {
+			r.RemoveRunner(runnerInUse, connIDs)
+		}
+	}
+}
+
+func (r *connRunners) Add(connID protocol.ConnectionID, handler packetHandler) bool {
+	added := true
+	for _, runner := range r.runners {
+		if !runner.Add(connID, handler) {
+			added = false
+		}
+	}
+	return added
+}
+
+// GetStatelessResetToken uses the first runner.
+// All runners use the same stateless reset key, if one is configured.
+func (r *connRunners) GetStatelessResetToken(connID protocol.ConnectionID) protocol.StatelessResetToken {
+	return r.runners[0].GetStatelessResetToken(connID)
+}
+
+func (r *connRunners) Retire(connID protocol.ConnectionID) {
+	r.retiredConnIDs = append(r.retiredConnIDs, connID)
+	for _, runner := range r.runners {
+		runner.Retire(connID)
+	}
+}
+
+func (r *connRunners) Remove(connID protocol.ConnectionID) {
+	for _, runner := range r.runners {
+		runner.Remove(connID)
+	}
+}
+
+func (r *connRunners) ReplaceWithClosed(connIDs []protocol.ConnectionID, pers protocol.Perspective, connClosePacket []byte) {
+	for _, runner := range r.runners {
+		runner.ReplaceWithClosed(connIDs, pers, connClosePacket)
+	}
+}
+
+func (r *connRunners) AddResetToken(token protocol.StatelessResetToken, handler packetHandler) {
+	r.resetTokens[token] = handler
+	for _, runner := range r.runners {
+		runner.AddResetToken(token, handler)
+	}
+}
+
+func (r *connRunners) RemoveResetToken(token protocol.StatelessResetToken) {
+	delete(r.resetTokens, token)
+	for _, runner := range r.runners {
+		runner.RemoveResetToken(token)
+	}
+}
@@ -0,0 +1,123 @@
+package quic
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Runners", func() {
+	var (
+		runners *connRunners
+		runner1 *MockConnRunner
+		handler *MockPacketHandler
+	)
+
+	BeforeEach(func() {
+		runner1 = NewMockConnRunner(mockCtrl)
+		handler = NewMockPacketHandler(mockCtrl)
+		runners = newConnRunners(runner1)
+	})
+
+	It("forwards calls to the runner", func() {
+		connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		runner1.EXPECT().Add(connID, handler).Return(true)
+		Expect(runners.Add(connID, handler)).To(BeTrue())
+		runner1.EXPECT().GetStatelessResetToken(connID).Return(protocol.StatelessResetToken{42})
+		Expect(runners.GetStatelessResetToken(connID)).To(Equal(protocol.StatelessResetToken{42}))
+		runner1.EXPECT().Retire(connID)
+		runners.Retire(connID)
+		runner1.EXPECT().Remove(connID)
+		runners.Remove(connID)
+	})
+
+	It("registers the connection with a new runner", func() {
+		connID1 := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		connID2 := protocol.ParseConnectionID([]byte{5, 6, 7, 8})
+		runner1.EXPECT().AddResetToken(protocol.StatelessResetToken{1}, handler)
+		runners.AddResetToken(protocol.StatelessResetToken{1}, handler)
+		runner1.EXPECT().AddResetToken(protocol.StatelessResetToken{2}, handler)
+		runners.AddResetToken(protocol.StatelessResetToken{2}, handler)
+		runner1.EXPECT().RemoveResetToken(protocol.StatelessResetToken{1})
+		runners.RemoveResetToken(protocol.StatelessResetToken{1})
+
+		runner2 := NewMockConnRunner(mockCtrl)
+		runner2.EXPECT().Add(connID1, handler)
+		runner2.EXPECT().Add(connID2, handler)
+		runner2.EXPECT().AddResetToken(protocol.StatelessResetToken{2}, handler)
+		runners.AddRunner(runner2, []protocol.ConnectionID{connID1, connID2}, handler)
+		// adding the same runner again is a no-op
+		runners.AddRunner(runner2, []protocol.ConnectionID{connID1, connID2}, handler)
+
+		connID3 := protocol.ParseConnectionID([]byte{9, 10, 11, 12})
+		runner1.EXPECT().Add(connID3, handler).Return(true)
+		runner2.EXPECT().Add(connID3, handler).Return(true)
+		Expect(runners.Add(connID3, handler)).To(BeTrue())
+		runner1.EXPECT().Retire(connID1)
+		runner2.EXPECT().Retire(connID1)
+		runners.Retire(connID1)
+		runner1.EXPECT().ReplaceWithClosed([]protocol.ConnectionID{connID2, connID3}, protocol.PerspectiveClient, []byte("foobar"))
+		runner2.EXPECT().ReplaceWithClosed([]protocol.ConnectionID{connID2, connID3}, protocol.PerspectiveClient, []byte("foobar"))
+		runners.ReplaceWithClosed([]protocol.ConnectionID{connID2, connID3}, protocol.PerspectiveClient, []byte("foobar"))
+	})
+
+	It("reports if adding a connection ID failed on any runner", func() {
+		runner2 := NewMockConnRunner(mockCtrl)
+		runners.AddRunner(runner2, nil, handler)
+		connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		runner1.EXPECT().Add(connID, handler).Return(true)
+		runner2.EXPECT().Add(connID, handler).Return(false)
+		Expect(runners.Add(connID, handler)).To(BeFalse())
+	})
+
+	It("removes a runner", func() {
+		connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		runner1.EXPECT().AddResetToken(protocol.StatelessResetToken{1}, handler)
+		runners.AddResetToken(protocol.StatelessResetToken{1}, handler)
+		runner2 := NewMockConnRunner(mockCtrl)
+		runner2.EXPECT().Add(connID, handler)
+		runner2.EXPECT().AddResetToken(protocol.StatelessResetToken{1}, handler)
+		runners.AddRunner(runner2, []protocol.ConnectionID{connID}, handler)
+
+		runner2.EXPECT().Remove(connID)
+		runner2.EXPECT().RemoveResetToken(protocol.StatelessResetToken{1})
+		runners.RemoveRunner(runner2, []protocol.ConnectionID{connID})
+		runner1.EXPECT().Retire(connID)
+		runners.Retire(connID)
+	})
+
+	It("removes retired connection IDs when removing a runner", func() {
+		connID1 := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		connID2 := protocol.ParseConnectionID([]byte{5, 6, 7, 8})
+		runner2 := NewMockConnRunner(mockCtrl)
+		runner2.EXPECT().Add(connID1, handler)
+		runner2.EXPECT().Add(connID2, handler)
+		runners.AddRunner(runner2, []protocol.ConnectionID{connID1, connID2}, handler)
+		runner1.EXPECT().Retire(connID1)
+		runner2.EXPECT().Retire(connID1)
+		runners.Retire(connID1)
+
+		runner2.EXPECT().Remove(connID1)
+		runner2.EXPECT().Remove(connID2)
+		runners.RemoveRunner(runner2, []protocol.ConnectionID{connID2})
+	})
+
+	It("retains a single runner", func() {
+		connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		runner2 := NewMockConnRunner(mockCtrl)
+		runner2.EXPECT().Add(connID, handler)
+		runners.AddRunner(runner2, []protocol.ConnectionID{connID}, handler)
+		runner3 := NewMockConnRunner(mockCtrl)
+		runner3.EXPECT().Add(connID, handler)
+		runners.AddRunner(runner3, []protocol.ConnectionID{connID}, handler)
+
+		runner1.EXPECT().Remove(connID)
+		runner3.EXPECT().Remove(connID)
+		runners.RetainRunner(runner2, []protocol.ConnectionID{connID})
+		runner2.EXPECT().GetStatelessResetToken(connID).Return(protocol.StatelessResetToken{42})
+		Expect(runners.GetStatelessResetToken(connID)).To(Equal(protocol.StatelessResetToken{42}))
+		runner2.EXPECT().Retire(connID)
+		runners.Retire(connID)
+	})
+})
@@ -3,6 +3,7 @@ package quic
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -27,6 +28,7 @@ import (
 type unpacker interface {
 	UnpackLongHeader(hdr *wire.Header, rcvTime time.Time, data []byte) (*unpackedPacket, error)
 	UnpackShortHeader(rcvTime time.Time, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error)
+	UnpackShortHeaderOnPath(data []byte, pathID uint64, largestRcvd protocol.PacketNumber) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error)
 }
 
 type streamGetter interface {
@@ -54,11 +56,13 @@ type streamManager interface {
 type cryptoStreamHandler interface {
 	RunHandshake()
 	ChangeConnectionID(protocol.ConnectionID)
+	ChangeVersion(protocol.VersionNumber)
 	SetLargest1RTTAcked(protocol.PacketNumber) error
 	SetHandshakeConfirmed()
 	GetSessionTicket() ([]byte, error)
 	io.Closer
 	ConnectionState() handshake.ConnectionState
+	KeyPhase() protocol.KeyPhase
 }
 
 type packetInfo struct {
@@ -76,6 +80,8 @@ type receivedPacket struct {
 	ecn protocol.ECN
 
 	info *packetInfo
+	// the packet conn that this packet was received on
+	rcvConn rawConn
 }
 
 func (p *receivedPacket) Size() protocol.ByteCount { return protocol.ByteCount(len(p.data)) }
@@ -88,6 +94,7 @@ func (p *receivedPacket) Clone() *receivedPacket {
 		buffer:     p.buffer,
 		ecn:        p.ecn,
 		info:       p.info,
+		rcvConn:    p.rcvConn,
 	}
 }
 
@@ -143,11 +150,15 @@ type connection struct {
 	srcConnIDLen int
 
 	perspective protocol.Perspective
+	// The version used for the first Initial packet.
+	// It differs from version if compatible version negotiation was performed.
+	origVersion protocol.VersionNumber
 	version     protocol.VersionNumber
 	config      *Config
 
-	conn      sendConn
+	conn      *migratableSendConn
 	sendQueue sender
+	runners   *connRunners
 
 	streamsMap      streamManager
 	connIDManager   *connIDManager
@@ -175,6 +186,12 @@ type connection struct {
 
 	receivedPackets  chan *receivedPacket
 	sendingScheduled chan struct{}
+	// stats is a snapshot of the connection's statistics.
+	// It is updated by the run loop, such that Stats doesn't need to access the run loop's state.
+	statsMutex sync.Mutex
+	stats      ConnectionStats
+	// abandonedPathsStats are the statistics of all paths that were abandoned
+	abandonedPathsStats ackhandler.Stats
 
 	closeOnce sync.Once
 	// closeChan is used to notify the run loop that it should terminate
@@ -217,6 +234,37 @@ type connection struct {
 	keepAliveInterval time.Duration
 
 	datagramQueue *datagramQueue
+	// the maximum size of a message sent in a DATAGRAM frame, accessed atomically
+	maxDatagramSize int64
+
+	pathProber *pathProber
+	pathProbe  *pathProbe // the path that is currently being validated
+	// The packet conn that packets on the current path are received on.
+	// Set when the first packet is received, and updated when the connection migrates.
+	rcvConn rawConn
+	// receivedOnProbedPath is set while handling a packet that was received on the path that is being validated
+	receivedOnProbedPath        bool
+	largestRcvdNonProbingPacket protocol.PacketNumber
+
+	// resetStreamAt is set if both endpoints enabled reliable stream resets, see draft-ietf-quic-reliable-stream-reset.
+	// It is read by the streams when CancelWriteAt is called.
+	resetStreamAt utils.AtomicBool
+
+	// ackFrequency is set if both endpoints enabled the ACK frequency extension, see draft-ietf-quic-ack-frequency.
+	// If set, we send ACK_FREQUENCY frames to ask the peer to reduce its ACK rate.
+	ackFrequency bool
+	// lastAckFrequencyFrame is the last ACK_FREQUENCY frame received from the peer.
+	// It is applied to every path added later.
+	lastAckFrequencyFrame *wire.AckFrequencyFrame
+
+	// multipath is set if both endpoints enabled multipath, see draft-ietf-quic-multipath.
+	multipath      bool
+	sealingManager sealingManager
+	initialPath    *path   // only set if multipath is used
+	paths          []*path // all paths, except for the initial path
+	pathQueue      *pathQueue
+	// currentPath is set while handling a packet that was received on one of the additional paths
+	currentPath *path
 
 	logID  string
 	tracer logging.ConnectionTracer
@@ -232,6 +280,7 @@ var (
 var newConnection = func(
 	conn sendConn,
 	runner connRunner,
+	preferredAddressRunner connRunner,
 	origDestConnID protocol.ConnectionID,
 	retrySrcConnID *protocol.ConnectionID,
 	clientDestConnID protocol.ConnectionID,
@@ -249,7 +298,7 @@ var newConnection = func(
 	v protocol.VersionNumber,
 ) quicConn {
 	s := &connection{
-		conn:                  conn,
+		conn:                  newMigratableSendConn(conn),
 		config:                conf,
 		handshakeDestConnID:   destConnID,
 		srcConnIDLen:          srcConnID.Len(),
@@ -259,6 +308,7 @@ var newConnection = func(
 		handshakeCompleteChan: make(chan struct{}),
 		tracer:                tracer,
 		logger:                logger,
+		origVersion:           v,
 		version:               v,
 	}
 	if origDestConnID.Len() > 0 {
@@ -266,20 +316,21 @@ var newConnection = func(
 	} else {
 		s.logID = destConnID.String()
 	}
+	s.runners = newConnRunners(runner)
 	s.connIDManager = newConnIDManager(
 		destConnID,
-		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
-		runner.RemoveResetToken,
+		func(token protocol.StatelessResetToken) { s.runners.AddResetToken(token, s) },
+		s.runners.RemoveResetToken,
 		s.queueControlFrame,
 	)
 	s.connIDGenerator = newConnIDGenerator(
 		srcConnID,
 		&clientDestConnID,
-		func(connID protocol.ConnectionID) { runner.Add(connID, s) },
-		runner.GetStatelessResetToken,
-		runner.Remove,
-		runner.Retire,
-		runner.ReplaceWithClosed,
+		func(connID protocol.ConnectionID) { s.runners.Add(connID, s) },
+		s.runners.GetStatelessResetToken,
+		s.runners.Remove,
+		s.runners.Retire,
+		s.runners.ReplaceWithClosed,
 		s.queueControlFrame,
 		s.config.ConnectionIDGenerator,
 		s.version,
@@ -295,7 +346,7 @@ var newConnection = func(
 		s.tracer,
 		s.logger,
 		s.version,
-		conf.UseBBR,
+		conf.congestionControl(),
 	)
 	initialStream := newCryptoStream()
 	handshakeStream := newCryptoStream()
@@ -309,18 +360,35 @@ var newConnection = func(
 		MaxUniStreamNum:                 protocol.StreamNum(s.config.MaxIncomingUniStreams),
 		MaxAckDelay:                     protocol.MaxAckDelayInclGranularity,
 		AckDelayExponent:                protocol.AckDelayExponent,
-		DisableActiveMigration:          true,
 		StatelessResetToken:             &statelessResetToken,
 		OriginalDestinationConnectionID: origDestConnID,
 		ActiveConnectionIDLimit:         protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID:       srcConnID,
 		RetrySourceConnectionID:         retrySrcConnID,
+		GreaseQUICBit:                   true,
+		EnableMultipath:                 s.config.EnableMultipath,
+		EnableResetStreamAt:             s.config.EnableResetStreamAt,
+		VersionInformation: &wire.VersionInformation{
+			ChosenVersion:     s.version,
+			AvailableVersions: s.config.Versions,
+		},
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = protocol.MaxDatagramFrameSize
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
+	if s.config.EnableAckFrequency {
+		params.MinAckDelay = protocol.MinAckDelay
+	}
+	if s.config.PreferredAddress != nil && preferredAddressRunner != nil {
+		pa, err := s.newPreferredAddress(preferredAddressRunner)
+		if err != nil {
+			s.logger.Errorf("Not sending the preferred_address transport parameter: %s", err)
+		} else {
+			params.PreferredAddress = pa
+		}
+	}
 	if s.tracer != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -336,18 +404,22 @@ var newConnection = func(
 			onError:          s.closeLocal,
 			dropKeys:         s.dropEncryptionLevel,
 			onHandshakeComplete: func() {
-				runner.Retire(clientDestConnID)
+				s.runners.Retire(clientDestConnID)
 				close(s.handshakeCompleteChan)
 			},
 		},
 		tlsConf,
 		enable0RTT,
+		s.config.ZeroRTTAntiReplay,
+		s.config.Allow0RTT,
+		s.config.GetSessionTicketData,
 		s.rttStats,
 		tracer,
 		logger,
 		s.version,
 	)
 	s.cryptoStreamHandler = cs
+	s.sealingManager = cs
 	s.packer = newPacketPacker(
 		srcConnID,
 		s.connIDManager.Get,
@@ -363,7 +435,7 @@ var newConnection = func(
 		s.perspective,
 		s.version,
 	)
-	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen, s.version)
+	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen, s.version, params.GreaseQUICBit)
 	s.cryptoStreamManager = newCryptoStreamManager(cs, initialStream, handshakeStream, s.oneRTTStream)
 	return s
 }
@@ -385,7 +457,7 @@ var newClientConnection = func(
 	v protocol.VersionNumber,
 ) quicConn {
 	s := &connection{
-		conn:                  conn,
+		conn:                  newMigratableSendConn(conn),
 		config:                conf,
 		origDestConnID:        destConnID,
 		handshakeDestConnID:   destConnID,
@@ -396,22 +468,24 @@ var newClientConnection = func(
 		logger:                logger,
 		tracer:                tracer,
 		versionNegotiated:     hasNegotiatedVersion,
+		origVersion:           v,
 		version:               v,
 	}
+	s.runners = newConnRunners(runner)
 	s.connIDManager = newConnIDManager(
 		destConnID,
-		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
-		runner.RemoveResetToken,
+		func(token protocol.StatelessResetToken) { s.runners.AddResetToken(token, s) },
+		s.runners.RemoveResetToken,
 		s.queueControlFrame,
 	)
 	s.connIDGenerator = newConnIDGenerator(
 		srcConnID,
 		nil,
-		func(connID protocol.ConnectionID) { runner.Add(connID, s) },
-		runner.GetStatelessResetToken,
-		runner.Remove,
-		runner.Retire,
-		runner.ReplaceWithClosed,
+		func(connID protocol.ConnectionID) { s.runners.Add(connID, s) },
+		s.runners.GetStatelessResetToken,
+		s.runners.Remove,
+		s.runners.Retire,
+		s.runners.ReplaceWithClosed,
 		s.queueControlFrame,
 		s.config.ConnectionIDGenerator,
 		s.version,
@@ -427,7 +501,7 @@ var newClientConnection = func(
 		s.tracer,
 		s.logger,
 		s.version,
-		conf.UseBBR,
+		conf.congestionControl(),
 	)
 	initialStream := newCryptoStream()
 	handshakeStream := newCryptoStream()
@@ -444,12 +518,22 @@ var newClientConnection = func(
 		DisableActiveMigration:         true,
 		ActiveConnectionIDLimit:        protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID:      srcConnID,
+		GreaseQUICBit:                  true,
+		EnableMultipath:                s.config.EnableMultipath,
+		EnableResetStreamAt:            s.config.EnableResetStreamAt,
+		VersionInformation: &wire.VersionInformation{
+			ChosenVersion:     s.version,
+			AvailableVersions: s.config.Versions,
+		},
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = protocol.MaxDatagramFrameSize
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
+	if s.config.EnableAckFrequency {
+		params.MinAckDelay = protocol.MinAckDelay
+	}
 	if s.tracer != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -475,8 +559,9 @@ var newClientConnection = func(
 	)
 	s.clientHelloWritten = clientHelloWritten
 	s.cryptoStreamHandler = cs
+	s.sealingManager = cs
 	s.cryptoStreamManager = newCryptoStreamManager(cs, initialStream, handshakeStream, newCryptoStream())
-	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen, s.version)
+	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen, s.version, params.GreaseQUICBit)
 	s.packer = newPacketPacker(
 		srcConnID,
 		s.connIDManager.Get,
@@ -508,7 +593,7 @@ var newClientConnection = func(
 func (s *connection) preSetup() {
 	s.sendQueue = newSendQueue(s.conn)
 	s.retransmissionQueue = newRetransmissionQueue(s.version)
-	s.frameParser = wire.NewFrameParser(s.config.EnableDatagrams, s.version)
+	s.frameParser = wire.NewFrameParser(s.config.EnableDatagrams, s.config.EnableMultipath, s.config.EnableResetStreamAt, s.config.EnableAckFrequency, s.version)
 	s.rttStats = &utils.RTTStats{}
 	s.connFlowController = flowcontrol.NewConnectionFlowController(
 		protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
@@ -532,7 +617,7 @@ func (s *connection) preSetup() {
 		s.perspective,
 		s.version,
 	)
-	s.framer = newFramer(s.streamsMap, s.version)
+	s.framer = newFramer(s.streamsMap, s.config.streamScheduler(), s.version)
 	s.receivedPackets = make(chan *receivedPacket, protocol.MaxConnUnprocessedPackets)
 	s.closeChan = make(chan closeError, 1)
 	s.sendingScheduled = make(chan struct{}, 1)
@@ -543,7 +628,10 @@ func (s *connection) preSetup() {
 	s.creationTime = now
 
 	s.windowUpdateQueue = newWindowUpdateQueue(s.streamsMap, s.connFlowController, s.framer.QueueControlFrame)
-	s.datagramQueue = newDatagramQueue(s.scheduleSending, s.logger)
+	s.datagramQueue = newDatagramQueue(s.scheduleSending, s.config.DatagramSendQueueLen, s.config.DatagramDropPolicy, s.logger)
+	s.pathProber = newPathProber(s.scheduleSending)
+	s.pathQueue = newPathQueue(s.scheduleSending)
+	s.largestRcvdNonProbingPacket = protocol.InvalidPacketNumber
 }
 
 // run the connection main loop
@@ -593,6 +681,10 @@ runLoop:
 		default:
 		}
 
+		if s.datagramQueue != nil {
+			s.datagramQueue.CallLostCallbacks()
+		}
+		s.updateStats()
 		s.maybeResetTimer()
 
 		var processedUndecryptablePacket bool
@@ -673,6 +765,13 @@ runLoop:
 				s.closeLocal(err)
 			}
 		}
+		for _, p := range s.paths {
+			if timeout := p.sentPacketHandler.GetLossDetectionTimeout(); !timeout.IsZero() && timeout.Before(now) {
+				if err := p.sentPacketHandler.OnLossDetectionTimeout(); err != nil {
+					s.closeLocal(err)
+				}
+			}
+		}
 
 		if keepAliveTime := s.nextKeepAliveTime(); !keepAliveTime.IsZero() && !now.Before(keepAliveTime) {
 			// send a PING frame since there is no activity in the connection
@@ -691,6 +790,13 @@ runLoop:
 			}
 		}
 
+		if err := s.handlePathProbes(now); err != nil {
+			s.closeLocal(err)
+		}
+		if err := s.handlePaths(now); err != nil {
+			s.closeLocal(err)
+		}
+
 		if s.sendQueue.WouldBlock() {
 			// The send queue is still busy sending out packets.
 			// Wait until there's space to enqueue new packets.
@@ -710,6 +816,7 @@ runLoop:
 	s.cryptoStreamHandler.Close()
 	<-handshaking
 	s.handleCloseError(&closeErr)
+	s.updateStats()
 	if e := (&errCloseForRecreating{}); !errors.As(closeErr.err, &e) && s.tracer != nil {
 		s.tracer.Close()
 	}
@@ -736,10 +843,32 @@ func (s *connection) supportsDatagrams() bool {
 	return s.peerParams.MaxDatagramFrameSize > 0
 }
 
+// updateMaxDatagramSize updates the maximum size of a message that can be sent in a DATAGRAM frame.
+// It is called when the peer's transport parameters are applied, and when the maximum packet size changes.
+func (s *connection) updateMaxDatagramSize() {
+	var maxSize protocol.ByteCount
+	if s.supportsDatagrams() {
+		maxPacketSize := getMaxPacketSize(s.conn.RemoteAddr())
+		if s.mtuDiscoverer != nil {
+			maxPacketSize = s.mtuDiscoverer.CurrentSize()
+		}
+		if s.peerParams.MaxUDPPayloadSize != 0 {
+			maxPacketSize = utils.Min(maxPacketSize, s.peerParams.MaxUDPPayloadSize)
+		}
+		// Assume the largest possible short header (a connection ID of maximum length and a 4 byte packet number).
+		// All AEADs used by QUIC have a 16 byte authentication tag.
+		maxFrameSize := maxPacketSize - 1 - protocol.MaxConnIDLen - protocol.ByteCount(protocol.PacketNumberLen4) - 16
+		maxFrameSize = utils.Min(maxFrameSize, s.peerParams.MaxDatagramFrameSize)
+		maxSize = (&wire.DatagramFrame{DataLenPresent: true}).MaxDataLen(maxFrameSize, s.version)
+	}
+	atomic.StoreInt64(&s.maxDatagramSize, int64(maxSize))
+}
+
 func (s *connection) ConnectionState() ConnectionState {
 	return ConnectionState{
 		TLS:               s.cryptoStreamHandler.ConnectionState(),
 		SupportsDatagrams: s.supportsDatagrams(),
+		SupportsMultipath: s.multipath,
 		Version:           s.version,
 	}
 }
@@ -766,14 +895,21 @@ func (s *connection) maybeResetTimer() {
 		} else {
 			deadline = s.idleTimeoutStartTime().Add(s.idleTimeout)
 		}
+		if s.pathProbe != nil {
+			deadline = utils.MinTime(deadline, s.nextPathProbeTimeout())
+		}
 	}
 
-	s.timer.SetTimer(
-		deadline,
-		s.receivedPacketHandler.GetAlarmTimeout(),
-		s.sentPacketHandler.GetLossDetectionTimeout(),
-		s.pacingDeadline,
-	)
+	ackAlarm := s.receivedPacketHandler.GetAlarmTimeout()
+	lossTime := s.sentPacketHandler.GetLossDetectionTimeout()
+	for _, p := range s.paths {
+		ackAlarm = utils.MinNonZeroTime(ackAlarm, p.receivedPacketHandler.GetAlarmTimeout())
+		lossTime = utils.MinNonZeroTime(lossTime, p.sentPacketHandler.GetLossDetectionTimeout())
+		if !p.probe.validated || p.probe.response != nil {
+			deadline = utils.MinTime(deadline, p.probe.NextTimeout(s.pathValidationPTO()))
+		}
+	}
+	s.timer.SetTimer(deadline, ackAlarm, lossTime, s.pacingDeadline)
 }
 
 func (s *connection) idleTimeoutStartTime() time.Time {
@@ -820,27 +956,46 @@ func (s *connection) handleHandshakeConfirmed() {
 	s.handshakeConfirmed = true
 	s.sentPacketHandler.SetHandshakeConfirmed()
 	s.cryptoStreamHandler.SetHandshakeConfirmed()
+	s.startMTUDiscovery()
+	if s.perspective == protocol.PerspectiveClient && s.peerParams.PreferredAddress != nil {
+		s.probePreferredAddress()
+	}
+}
 
-	if !s.config.DisablePathMTUDiscovery {
-		maxPacketSize := s.peerParams.MaxUDPPayloadSize
-		if maxPacketSize == 0 {
-			maxPacketSize = protocol.MaxByteCount
-		}
-		maxPacketSize = utils.Min(maxPacketSize, protocol.MaxPacketBufferSize)
-		s.mtuDiscoverer = newMTUDiscoverer(
-			s.rttStats,
-			getMaxPacketSize(s.conn.RemoteAddr()),
-			maxPacketSize,
-			func(size protocol.ByteCount) {
-				s.sentPacketHandler.SetMaxDatagramSize(size)
-				s.packer.SetMaxPacketSize(size)
-			},
-		)
+// startMTUDiscovery starts path MTU discovery on the current path.
+// It is called when the handshake is confirmed, and after the connection migrated to a new path.
+func (s *connection) startMTUDiscovery() {
+	if s.config.DisablePathMTUDiscovery {
+		return
+	}
+	maxPacketSize := s.peerParams.MaxUDPPayloadSize
+	if maxPacketSize == 0 {
+		maxPacketSize = protocol.MaxByteCount
 	}
+	maxPacketSize = utils.Min(maxPacketSize, protocol.MaxPacketBufferSize)
+	var discoverer mtuDiscoverer
+	discoverer = newMTUDiscoverer(
+		s.rttStats,
+		getMaxPacketSize(s.conn.RemoteAddr()),
+		maxPacketSize,
+		func(size protocol.ByteCount) {
+			// Ignore probe packets that were sent on a path that we already migrated away from.
+			if s.mtuDiscoverer != discoverer {
+				return
+			}
+			s.sentPacketHandler.SetMaxDatagramSize(size)
+			s.packer.SetMaxPacketSize(size)
+			s.updateMaxDatagramSize()
+		},
+	)
+	s.mtuDiscoverer = discoverer
 }
 
 func (s *connection) handlePacketImpl(rp *receivedPacket) bool {
 	s.sentPacketHandler.ReceivedBytes(rp.Size())
+	if s.rcvConn == nil {
+		s.rcvConn = rp.rcvConn
+	}
 
 	if wire.IsVersionNegotiationPacket(rp.data) {
 		s.handleVersionNegotiationPacket(rp)
@@ -891,7 +1046,7 @@ func (s *connection) handlePacketImpl(rp *receivedPacket) bool {
 			}
 			lastConnID = hdr.DestConnectionID
 
-			if hdr.Version != s.version {
+			if hdr.Version != s.version && !s.acceptsCompatibleVersion(hdr) && !s.acceptsOriginalVersion(hdr) {
 				if s.tracer != nil {
 					s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), protocol.ByteCount(len(data)), logging.PacketDropUnexpectedVersion)
 				}
@@ -919,7 +1074,11 @@ func (s *connection) handlePacketImpl(rp *receivedPacket) bool {
 			if counter > 0 {
 				p.buffer.Split()
 			}
-			processed = s.handleShortHeaderPacket(p, destConnID)
+			if connID, pathID, ok := s.additionalPathID(p); ok {
+				processed = s.handleShortHeaderPacketOnPath(p, connID, pathID)
+			} else {
+				processed = s.handleShortHeaderPacket(p, destConnID)
+			}
 			break
 		}
 	}
@@ -972,10 +1131,71 @@ func (s *connection) handleShortHeaderPacket(p *receivedPacket, destConnID proto
 			)
 		}
 	}
-	if err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log); err != nil {
+	var onProbedPath bool
+	if s.perspective == protocol.PerspectiveServer && !s.isOnCurrentPath(p) {
+		onProbedPath = s.handlePacketOnNewPath(p)
+	}
+	s.receivedOnProbedPath = onProbedPath
+	isNonProbing, err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log)
+	s.receivedOnProbedPath = false
+	if err != nil {
 		s.closeLocal(err)
 		return false
 	}
+	if isNonProbing && pn > s.largestRcvdNonProbingPacket {
+		s.largestRcvdNonProbingPacket = pn
+		if s.pathProbe != nil && s.pathProbe.peerInitiated {
+			s.pathProbe.receivedNonProbingPacket = onProbedPath
+			if onProbedPath && s.pathProbe.validated {
+				s.migrate()
+			}
+		}
+	}
+	return true
+}
+
+// isOnCurrentPath says if a packet was received on the path that the connection is currently using.
+// A path is identified by the peer's address and by the packet conn that the packet was received on.
+func (s *connection) isOnCurrentPath(p *receivedPacket) bool {
+	if p.rcvConn != nil && s.rcvConn != nil && p.rcvConn != s.rcvConn {
+		return false
+	}
+	return isSameAddr(p.remoteAddr, s.conn.RemoteAddr())
+}
+
+// handlePacketOnNewPath is called when the server receives a 1-RTT packet on a new path.
+// This happens when the peer's address changes, or when the peer migrates to the preferred address.
+// It starts validating the new path, unless that path is already being validated.
+// The validation of another path is only replaced once it has been in progress for a PTO.
+// It returns true if the new path is being validated.
+func (s *connection) handlePacketOnNewPath(p *receivedPacket) bool {
+	if !s.handshakeConfirmed {
+		return false
+	}
+	if s.pathProbe == nil || s.pathProbe.rcvConn != p.rcvConn || !isSameAddr(s.pathProbe.conn.RemoteAddr(), p.remoteAddr) {
+		if s.pathProbe != nil {
+			// Packets with spoofed source addresses could otherwise restart path validation over and over again,
+			// preventing the validation of the peer's new address from ever succeeding.
+			// Only abandon the path validation in progress once it had enough time to complete.
+			if p.rcvTime.Sub(s.pathProbe.started) < s.pathValidationPTO() {
+				s.logger.Debugf("Not validating new path to %s: validation of the path to %s in progress", p.remoteAddr, s.pathProbe.conn.RemoteAddr())
+				return false
+			}
+			s.abandonPathProbe(errors.New("peer moved to a different address"))
+		}
+		var conn sendConn
+		if p.rcvConn != nil {
+			conn = newSendConn(p.rcvConn, p.remoteAddr, p.info)
+		} else {
+			conn = s.conn.WithRemoteAddr(p.remoteAddr, p.info)
+		}
+		if err := s.startPathProbe(newPeerPathProbe(conn, p.rcvConn), p.rcvTime); err != nil {
+			s.logger.Debugf("Not validating new path to %s: %s", p.remoteAddr, err)
+			return false
+		}
+		s.logger.Debugf("Peer's address changed to %s. Validating new path.", p.remoteAddr)
+	}
+	s.pathProbe.bytesReceived += p.Size()
 	return true
 }
 
@@ -1010,11 +1230,24 @@ func (s *connection) handleLongHeaderPacket(p *receivedPacket, hdr *wire.Header)
 		return false
 	}
 
+	// The server performed compatible version negotiation.
+	// Only switch to the negotiated version if the packet can be decrypted.
+	prevVersion := s.version
+	if hdr.Version != s.version && s.acceptsCompatibleVersion(hdr) {
+		s.switchVersion(hdr.Version)
+	}
+
 	packet, err := s.unpacker.UnpackLongHeader(hdr, p.rcvTime, p.data)
 	if err != nil {
+		if s.version != prevVersion {
+			s.switchVersion(prevVersion)
+		}
 		wasQueued = s.handleUnpackError(err, p, logging.PacketTypeFromHeader(hdr))
 		return false
 	}
+	if s.version != prevVersion {
+		s.logger.Infof("Server performed compatible version negotiation. Switched to QUIC version %s.", s.version)
+	}
 
 	if s.logger.Debug() {
 		s.logger.Debugf("<- Reading packet %d (%d bytes) for connection %s, %s", packet.hdr.PacketNumber, p.Size(), hdr.DestConnectionID, packet.encryptionLevel)
@@ -1138,6 +1371,34 @@ func (s *connection) handleRetryPacket(hdr *wire.Header, data []byte) bool /* wa
 	return true
 }
 
+// acceptsCompatibleVersion says if a packet using a different version than the one currently in use is accepted.
+// A server performing compatible version negotiation (RFC 9368) responds to the client's first Initial
+// with Initial packets using the negotiated version.
+func (s *connection) acceptsCompatibleVersion(hdr *wire.Header) bool {
+	return s.perspective == protocol.PerspectiveClient &&
+		!s.receivedFirstPacket &&
+		hdr.Type == protocol.PacketTypeInitial &&
+		protocol.IsCompatibleVersion(s.version, hdr.Version) &&
+		protocol.IsSupportedVersion(s.config.Versions, hdr.Version)
+}
+
+// acceptsOriginalVersion says if a packet using the client's original version is accepted,
+// after the server switched to a compatible version (see chooseVersion).
+// Until it receives the server's first Initial, the client keeps sending Initial packets using the original version.
+// The server rejects 0-RTT when switching versions, so only Initial packets need to be accepted.
+func (s *connection) acceptsOriginalVersion(hdr *wire.Header) bool {
+	return s.perspective == protocol.PerspectiveServer &&
+		!s.handshakeComplete &&
+		hdr.Type == protocol.PacketTypeInitial &&
+		hdr.Version == s.origVersion
+}
+
+func (s *connection) switchVersion(v protocol.VersionNumber) {
+	s.version = v
+	s.packer.SetVersion(v)
+	s.cryptoStreamHandler.ChangeVersion(v)
+}
+
 func (s *connection) handleVersionNegotiationPacket(p *receivedPacket) {
 	if s.perspective == protocol.PerspectiveServer || // servers never receive version negotiation packets
 		s.receivedFirstPacket || s.versionNegotiated { // ignore delayed / duplicated version negotiation packets
@@ -1200,15 +1461,9 @@ func (s *connection) handleUnpackedPacket(
 ) error {
 	if !s.receivedFirstPacket {
 		s.receivedFirstPacket = true
-		if !s.versionNegotiated && s.tracer != nil {
-			var clientVersions, serverVersions []protocol.VersionNumber
-			switch s.perspective {
-			case protocol.PerspectiveClient:
-				clientVersions = s.config.Versions
-			case protocol.PerspectiveServer:
-				serverVersions = s.config.Versions
-			}
-			s.tracer.NegotiatedVersion(s.version, clientVersions, serverVersions)
+		// The server reports the negotiated version when receiving the client's transport parameters.
+		if s.perspective == protocol.PerspectiveClient && !s.versionNegotiated && s.tracer != nil {
+			s.tracer.NegotiatedVersion(s.version, s.config.Versions, nil)
 		}
 		// The server can change the source connection ID with the first Handshake packet.
 		if s.perspective == protocol.PerspectiveClient && packet.hdr.IsLongHeader && packet.hdr.SrcConnectionID != s.handshakeDestConnID {
@@ -1248,7 +1503,7 @@ func (s *connection) handleUnpackedPacket(
 			s.tracer.ReceivedLongHeaderPacket(packet.hdr, packetSize, frames)
 		}
 	}
-	isAckEliciting, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log)
+	isAckEliciting, _, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log)
 	if err != nil {
 		return err
 	}
@@ -1262,16 +1517,16 @@ func (s *connection) handleUnpackedShortHeaderPacket(
 	ecn protocol.ECN,
 	rcvTime time.Time,
 	log func([]logging.Frame),
-) error {
+) (isNonProbing bool, _ error) {
 	s.lastPacketReceivedTime = rcvTime
 	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
 	s.keepAlivePingSent = false
 
-	isAckEliciting, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log)
+	isAckEliciting, isNonProbing, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log)
 	if err != nil {
-		return err
+		return false, err
 	}
-	return s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting)
+	return isNonProbing, s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting)
 }
 
 func (s *connection) handleFrames(
@@ -1279,14 +1534,14 @@ func (s *connection) handleFrames(
 	destConnID protocol.ConnectionID,
 	encLevel protocol.EncryptionLevel,
 	log func([]logging.Frame),
-) (isAckEliciting bool, _ error) {
+) (isAckEliciting, isNonProbing bool, _ error) {
 	// Only used for tracing.
 	// If we're not tracing, this slice will always remain empty.
 	var frames []wire.Frame
 	for len(data) > 0 {
 		l, frame, err := s.frameParser.ParseNext(data, encLevel)
 		if err != nil {
-			return false, err
+			return false, false, err
 		}
 		data = data[l:]
 		if frame == nil {
@@ -1295,11 +1550,14 @@ func (s *connection) handleFrames(
 		if ackhandler.IsFrameAckEliciting(frame) {
 			isAckEliciting = true
 		}
+		if !wire.IsProbingFrame(frame) {
+			isNonProbing = true
+		}
 		// Only process frames now if we're not logging.
 		// If we're logging, we need to make sure that the packet_received event is logged first.
 		if log == nil {
 			if err := s.handleFrame(frame, encLevel, destConnID); err != nil {
-				return false, err
+				return false, false, err
 			}
 		} else {
 			frames = append(frames, frame)
@@ -1314,7 +1572,7 @@ func (s *connection) handleFrames(
 		log(fs)
 		for _, frame := range frames {
 			if err := s.handleFrame(frame, encLevel, destConnID); err != nil {
-				return false, err
+				return false, false, err
 			}
 		}
 	}
@@ -1351,8 +1609,7 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 	case *wire.PathChallengeFrame:
 		s.handlePathChallengeFrame(frame)
 	case *wire.PathResponseFrame:
-		// since we don't send PATH_CHALLENGEs, we don't expect PATH_RESPONSEs
-		err = errors.New("unexpected PATH_RESPONSE frame")
+		s.handlePathResponseFrame(frame)
 	case *wire.NewTokenFrame:
 		err = s.handleNewTokenFrame(frame)
 	case *wire.NewConnectionIDFrame:
@@ -1363,6 +1620,17 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 		err = s.handleHandshakeDoneFrame()
 	case *wire.DatagramFrame:
 		err = s.handleDatagramFrame(frame)
+	case *wire.AckMPFrame:
+		err = s.handleAckMPFrame(frame)
+		wire.PutAckFrame(frame.AckFrame)
+	case *wire.PathAbandonFrame:
+		err = s.handlePathAbandonFrame(frame)
+	case *wire.PathStatusFrame:
+		err = s.handlePathStatusFrame(frame)
+	case *wire.AckFrequencyFrame:
+		err = s.handleAckFrequencyFrame(frame)
+	case *wire.ImmediateAckFrame:
+		s.handleImmediateAckFrame()
 	default:
 		err = fmt.Errorf("unexpected frame type: %s", reflect.ValueOf(&frame).Elem().Type().Name())
 	}
@@ -1472,9 +1740,47 @@ func (s *connection) handleStopSendingFrame(frame *wire.StopSendingFrame) error
 }
 
 func (s *connection) handlePathChallengeFrame(frame *wire.PathChallengeFrame) {
+	if s.currentPath != nil {
+		data := frame.Data
+		s.currentPath.probe.response = &data
+		return
+	}
+	if s.receivedOnProbedPath {
+		// The PATH_RESPONSE needs to be sent on the path that the PATH_CHALLENGE was received on,
+		// see section 8.2.2 of RFC 9000.
+		data := frame.Data
+		s.pathProbe.response = &data
+		return
+	}
 	s.queueControlFrame(&wire.PathResponseFrame{Data: frame.Data})
 }
 
+func (s *connection) handlePathResponseFrame(frame *wire.PathResponseFrame) {
+	for _, p := range s.paths {
+		if !p.probe.validated && p.probe.IsResponse(frame.Data) {
+			s.handlePathValidated(p)
+			return
+		}
+	}
+	if s.pathProbe == nil || !s.pathProbe.IsResponse(frame.Data) {
+		// This might be a late response for a path that we already migrated to (or gave up on).
+		s.logger.Debugf("Ignoring PATH_RESPONSE frame that doesn't match any PATH_CHALLENGE.")
+		return
+	}
+	if s.pathProbe.validated {
+		return
+	}
+	s.pathProbe.validated = true
+	if s.tracer != nil {
+		s.tracer.UpdatedMigrationState(logging.MigrationStateProbingSuccessful, s.pathProbe.conn.LocalAddr(), s.pathProbe.conn.RemoteAddr())
+	}
+	if s.pathProbe.peerInitiated && !s.pathProbe.receivedNonProbingPacket {
+		// Only migrate once the peer sends non-probing packets on the new path.
+		return
+	}
+	s.migrate()
+}
+
 func (s *connection) handleNewTokenFrame(frame *wire.NewTokenFrame) error {
 	if s.perspective == protocol.PerspectiveServer {
 		return &qerr.TransportError{
@@ -1520,9 +1826,41 @@ func (s *connection) handleAckFrame(frame *wire.AckFrame, encLevel protocol.Encr
 	if s.perspective == protocol.PerspectiveClient && !s.handshakeConfirmed {
 		s.handleHandshakeConfirmed()
 	}
+	if s.ackFrequency {
+		if f := s.sentPacketHandler.GetAckFrequencyFrame(); f != nil {
+			s.queueControlFrame(f)
+		}
+	}
 	return s.cryptoStreamHandler.SetLargest1RTTAcked(frame.LargestAcked())
 }
 
+func (s *connection) handleAckFrequencyFrame(f *wire.AckFrequencyFrame) error {
+	if f.RequestMaxAckDelay < protocol.MinAckDelay {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("requested max ack delay (%s) smaller than min_ack_delay (%s)", f.RequestMaxAckDelay, protocol.MinAckDelay),
+		}
+	}
+	if s.lastAckFrequencyFrame != nil && f.SequenceNumber <= s.lastAckFrequencyFrame.SequenceNumber {
+		return nil
+	}
+	s.lastAckFrequencyFrame = f
+	// The ACK frequency applies to all paths.
+	s.receivedPacketHandler.SetAckFrequency(f)
+	for _, p := range s.paths {
+		p.receivedPacketHandler.SetAckFrequency(f)
+	}
+	return nil
+}
+
+func (s *connection) handleImmediateAckFrame() {
+	if s.currentPath != nil {
+		s.currentPath.receivedPacketHandler.QueueImmediateAck()
+		return
+	}
+	s.receivedPacketHandler.QueueImmediateAck()
+}
+
 func (s *connection) handleDatagramFrame(f *wire.DatagramFrame) error {
 	if f.Length(s.version) > protocol.MaxDatagramFrameSize {
 		return &qerr.TransportError{
@@ -1623,6 +1961,8 @@ func (s *connection) handleCloseError(closeErr *closeError) {
 	if s.datagramQueue != nil {
 		s.datagramQueue.CloseWithError(e)
 	}
+	s.pathProber.CloseWithError(e)
+	s.pathQueue.CloseWithError(e)
 
 	if s.tracer != nil && !errors.As(e, &recreateErr) {
 		s.tracer.ClosedConnection(e)
@@ -1677,19 +2017,25 @@ func (s *connection) restoreTransportParameters(params *wire.TransportParameters
 	s.connIDGenerator.SetMaxActiveConnIDs(params.ActiveConnectionIDLimit)
 	s.connFlowController.UpdateSendWindow(params.InitialMaxData)
 	s.streamsMap.UpdateLimits(params)
+	s.updateMaxDatagramSize()
 }
 
 func (s *connection) handleTransportParameters(params *wire.TransportParameters) {
 	if err := s.checkTransportParameters(params); err != nil {
-		s.closeLocal(&qerr.TransportError{
-			ErrorCode:    qerr.TransportParameterError,
-			ErrorMessage: err.Error(),
-		})
+		transportErr, ok := err.(*qerr.TransportError)
+		if !ok {
+			transportErr = &qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: err.Error(),
+			}
+		}
+		s.closeLocal(transportErr)
 	}
 	s.peerParams = params
 	// On the client side we have to wait for handshake completion.
 	// During a 0-RTT connection, we are only allowed to use the new transport parameters for 1-RTT packets.
 	if s.perspective == protocol.PerspectiveServer {
+		s.chooseVersion(params.VersionInformation)
 		s.applyTransportParameters()
 		// On the server side, the early connection is ready as soon as we processed
 		// the client's transport parameters.
@@ -1709,6 +2055,9 @@ func (s *connection) checkTransportParameters(params *wire.TransportParameters)
 	if params.InitialSourceConnectionID != s.handshakeDestConnID {
 		return fmt.Errorf("expected initial_source_connection_id to equal %s, is %s", s.handshakeDestConnID, params.InitialSourceConnectionID)
 	}
+	if err := s.checkVersionInformation(params.VersionInformation); err != nil {
+		return err
+	}
 
 	if s.perspective == protocol.PerspectiveServer {
 		return nil
@@ -1730,6 +2079,54 @@ func (s *connection) checkTransportParameters(params *wire.TransportParameters)
 	return nil
 }
 
+// checkVersionInformation validates the version_information transport parameter (RFC 9368).
+func (s *connection) checkVersionInformation(vi *wire.VersionInformation) error {
+	if vi == nil {
+		if s.perspective == protocol.PerspectiveClient && s.version != s.origVersion {
+			return &qerr.TransportError{
+				ErrorCode:    qerr.VersionNegotiationErrorErrorCode,
+				ErrorMessage: "server switched the version without sending a version_information",
+			}
+		}
+		return nil
+	}
+	if vi.ChosenVersion != s.version {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.VersionNegotiationErrorErrorCode,
+			ErrorMessage: fmt.Sprintf("expected chosen version to equal %s, is %s", s.version, vi.ChosenVersion),
+		}
+	}
+	// If we received a Version Negotiation packet, make sure that we would have chosen
+	// the same version based on the versions that the server actually supports.
+	// This prevents an attacker from forcing a downgrade by injecting a Version Negotiation packet.
+	if s.perspective == protocol.PerspectiveClient && s.versionNegotiated {
+		if v, ok := protocol.ChooseSupportedVersion(s.config.Versions, vi.AvailableVersions); !ok || v != s.origVersion {
+			return &qerr.TransportError{
+				ErrorCode:    qerr.VersionNegotiationErrorErrorCode,
+				ErrorMessage: fmt.Sprintf("version downgrade detected (using %s, server supports %s)", s.origVersion, vi.AvailableVersions),
+			}
+		}
+	}
+	return nil
+}
+
+// chooseVersion is called by the server when it receives the client's transport parameters.
+// If we prefer a version that the client supports and that is compatible with the version of
+// the client's first Initial, we switch to that version (compatible version negotiation, RFC 9368).
+func (s *connection) chooseVersion(vi *wire.VersionInformation) {
+	var clientVersions []protocol.VersionNumber
+	if vi != nil {
+		clientVersions = vi.AvailableVersions
+		if v := protocol.ChooseCompatibleVersion(s.version, s.config.Versions, vi.AvailableVersions); v != s.version {
+			s.logger.Infof("Switching to QUIC version %s.", v)
+			s.switchVersion(v)
+		}
+	}
+	if s.tracer != nil {
+		s.tracer.NegotiatedVersion(s.version, clientVersions, s.config.Versions)
+	}
+}
+
 func (s *connection) applyTransportParameters() {
 	params := s.peerParams
 	// Our local idle timeout will always be > 0.
@@ -1741,17 +2138,30 @@ func (s *connection) applyTransportParameters() {
 	s.connFlowController.UpdateSendWindow(params.InitialMaxData)
 	s.rttStats.SetMaxAckDelay(params.MaxAckDelay)
 	s.connIDGenerator.SetMaxActiveConnIDs(params.ActiveConnectionIDLimit)
+	s.updateMaxDatagramSize()
 	if params.StatelessResetToken != nil {
 		s.connIDManager.SetStatelessResetToken(*params.StatelessResetToken)
 	}
-	// We don't support connection migration yet, so we don't have any use for the preferred_address.
+	// The connection migrates to the preferred address once the handshake is confirmed.
 	if params.PreferredAddress != nil {
-		// Retire the connection ID.
 		s.connIDManager.AddFromPreferredAddress(params.PreferredAddress.ConnectionID, params.PreferredAddress.StatelessResetToken)
 	}
+	s.resetStreamAt.Set(s.config.EnableResetStreamAt && params.EnableResetStreamAt)
+	if s.config.EnableAckFrequency && params.MinAckDelay > 0 {
+		s.ackFrequency = true
+		s.sentPacketHandler.EnableAckFrequency(params.MinAckDelay)
+	}
+	// Paths are identified by the sequence numbers of the connection IDs used on them.
+	// Multipath can't be used if either endpoint uses zero-length connection IDs.
+	if s.config.EnableMultipath && params.EnableMultipath && s.srcConnIDLen > 0 && params.InitialSourceConnectionID.Len() > 0 {
+		s.enableMultipath()
+	}
 }
 
 func (s *connection) sendPackets() error {
+	if len(s.paths) > 0 {
+		return s.sendPacketsOnPaths()
+	}
 	s.pacingDeadline = time.Time{}
 
 	var sentPacket bool // only used in for packets sent in send mode SendAny
@@ -1831,7 +2241,7 @@ func (s *connection) maybeSendAckOnlyPacket() error {
 			s.sentPacketHandler.SentPacket(p.ToAckHandlerPacket(time.Now(), s.retransmissionQueue))
 		}
 		s.connIDManager.SentPacket()
-		s.sendQueue.Send(packet.buffer)
+		s.sendQueue.Send(packet.buffer, 0, protocol.ECNNon)
 		return nil
 	}
 
@@ -1847,17 +2257,26 @@ func (s *connection) maybeSendAckOnlyPacket() error {
 }
 
 func (s *connection) sendProbePacket(encLevel protocol.EncryptionLevel) error {
+	packet, err := s.packProbePacket(s.sentPacketHandler, s.packer, encLevel)
+	if err != nil {
+		return err
+	}
+	s.sendPackedPacket(packet, time.Now())
+	return nil
+}
+
+func (s *connection) packProbePacket(sentPacketHandler ackhandler.SentPacketHandler, packer packer, encLevel protocol.EncryptionLevel) (*packedPacket, error) {
 	// Queue probe packets until we actually send out a packet,
 	// or until there are no more packets to queue.
 	var packet *packedPacket
 	for {
-		if wasQueued := s.sentPacketHandler.QueueProbePacket(encLevel); !wasQueued {
+		if wasQueued := sentPacketHandler.QueueProbePacket(encLevel); !wasQueued {
 			break
 		}
 		var err error
-		packet, err = s.packer.MaybePackProbePacket(encLevel)
+		packet, err = packer.MaybePackProbePacket(encLevel)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if packet != nil {
 			break
@@ -1876,23 +2295,19 @@ func (s *connection) sendProbePacket(encLevel protocol.EncryptionLevel) error {
 			panic("unexpected encryption level")
 		}
 		var err error
-		packet, err = s.packer.MaybePackProbePacket(encLevel)
+		packet, err = packer.MaybePackProbePacket(encLevel)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if packet == nil || packet.packetContents == nil {
-		return fmt.Errorf("connection BUG: couldn't pack %s probe packet", encLevel)
+		return nil, fmt.Errorf("connection BUG: couldn't pack %s probe packet", encLevel)
 	}
-	s.sendPackedPacket(packet, time.Now())
-	return nil
+	return packet, nil
 }
 
 func (s *connection) sendPacket() (bool, error) {
-	if isBlocked, offset := s.connFlowController.IsNewlyBlocked(); isBlocked {
-		s.framer.QueueControlFrame(&wire.DataBlockedFrame{MaximumData: offset})
-	}
-	s.windowUpdateQueue.QueueAll()
+	s.queueFlowControlFrames()
 
 	now := time.Now()
 	if !s.handshakeConfirmed {
@@ -1909,7 +2324,7 @@ func (s *connection) sendPacket() (bool, error) {
 			s.sentPacketHandler.SentPacket(p.ToAckHandlerPacket(now, s.retransmissionQueue))
 		}
 		s.connIDManager.SentPacket()
-		s.sendQueue.Send(packet.buffer)
+		s.sendQueue.Send(packet.buffer, 0, protocol.ECNNon)
 		return true, nil
 	}
 	if !s.config.DisablePathMTUDiscovery && s.mtuDiscoverer.ShouldSendProbe(now) {
@@ -1920,6 +2335,9 @@ func (s *connection) sendPacket() (bool, error) {
 		s.sendPackedPacket(packet, now)
 		return true, nil
 	}
+	if s.conn.capabilities().GSO {
+		return s.sendPacketsWithGSO(now)
+	}
 	packet, err := s.packer.PackPacket(false)
 	if err != nil || packet == nil {
 		return false, err
@@ -1928,14 +2346,88 @@ func (s *connection) sendPacket() (bool, error) {
 	return true, nil
 }
 
+// sendPacketsWithGSO packs as many packets as congestion control and pacing allow into a single buffer.
+// All packets but the last one have the same size,
+// so they can be sent out in a single sendmsg call using Generic Segmentation Offload (GSO).
+func (s *connection) sendPacketsWithGSO(now time.Time) (bool, error) {
+	buf := getLargePacketBuffer()
+	ecn := ecnMode(s.sentPacketHandler, s.conn, true)
+	maxPacketSize := protocol.MaxPacketBufferSize
+	var segmentSize protocol.ByteCount
+	for numPackets := 1; ; numPackets++ {
+		packet, err := s.packer.AppendPacket(buf, maxPacketSize, false)
+		if err != nil {
+			buf.Release()
+			return false, err
+		}
+		if packet == nil {
+			break
+		}
+		s.registerPackedPacket(&packedPacket{buffer: buf, packetContents: packet}, ecn, now)
+		// The first packet determines the segment size.
+		if segmentSize == 0 {
+			segmentSize = packet.length
+			maxPacketSize = segmentSize
+		}
+		// A packet smaller than the segment size has to be the last one.
+		if packet.length < segmentSize {
+			break
+		}
+		if numPackets == maxGSOSegments || protocol.ByteCount(cap(buf.Data)-len(buf.Data)) < segmentSize {
+			break
+		}
+		if s.sentPacketHandler.SendMode() != ackhandler.SendAny || !s.sentPacketHandler.HasPacingBudget() {
+			break
+		}
+		// All packets sent in the same sendmsg call have the same ECN marking.
+		if ecnMode(s.sentPacketHandler, s.conn, true) != ecn {
+			break
+		}
+	}
+	if buf.Len() == 0 {
+		buf.Release()
+		return false, nil
+	}
+	var gsoSize uint16
+	if buf.Len() > segmentSize {
+		gsoSize = uint16(segmentSize)
+	}
+	s.sendQueue.Send(buf, gsoSize, ecn)
+	return true, nil
+}
+
+func (s *connection) queueFlowControlFrames() {
+	if isBlocked, offset := s.connFlowController.IsNewlyBlocked(); isBlocked {
+		s.framer.QueueControlFrame(&wire.DataBlockedFrame{MaximumData: offset})
+	}
+	s.windowUpdateQueue.QueueAll()
+}
+
 func (s *connection) sendPackedPacket(packet *packedPacket, now time.Time) {
+	ecn := ecnMode(s.sentPacketHandler, s.conn, packet.EncryptionLevel() == protocol.Encryption1RTT)
+	s.registerPackedPacket(packet, ecn, now)
+	s.sendQueue.Send(packet.buffer, 0, ecn)
+}
+
+// registerPackedPacket does the bookkeeping for a packet that is sent with the ECN codepoint ecn.
+func (s *connection) registerPackedPacket(packet *packedPacket, ecn protocol.ECN, now time.Time) {
 	if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && packet.IsAckEliciting() {
 		s.firstAckElicitingPacketAfterIdleSentTime = now
 	}
 	s.logPacket(packet)
-	s.sentPacketHandler.SentPacket(packet.ToAckHandlerPacket(now, s.retransmissionQueue))
+	ap := packet.ToAckHandlerPacket(now, s.retransmissionQueue)
+	ap.ECN = ecn
+	s.sentPacketHandler.SentPacket(ap)
 	s.connIDManager.SentPacket()
-	s.sendQueue.Send(packet.buffer)
+}
+
+// ecnMode returns the ECN codepoint to use for a packet sent on conn.
+// Packets are only marked if the underlying connection allows setting the ECN bits.
+func ecnMode(sph ackhandler.SentPacketHandler, conn sendConn, isShortHeaderPacket bool) protocol.ECN {
+	if !conn.capabilities().ECN {
+		return protocol.ECNNon
+	}
+	return sph.ECNMode(isShortHeaderPacket)
 }
 
 func (s *connection) sendConnectionClose(e error) ([]byte, error) {
@@ -1957,7 +2449,7 @@ func (s *connection) sendConnectionClose(e error) ([]byte, error) {
 		return nil, err
 	}
 	s.logCoalescedPacket(packet)
-	return packet.buffer.Data, s.conn.Write(packet.buffer.Data)
+	return packet.buffer.Data, s.conn.Write(packet.buffer.Data, 0, protocol.ECNNon)
 }
 
 func (s *connection) logPacketContents(p *packetContents) {
@@ -2002,7 +2494,7 @@ func (s *connection) logCoalescedPacket(packet *coalescedPacket) {
 
 func (s *connection) logPacket(packet *packedPacket) {
 	if s.logger.Debug() {
-		s.logger.Debugf("-> Sending packet %d (%d bytes) for connection %s, %s", packet.header.PacketNumber, packet.buffer.Len(), s.logID, packet.EncryptionLevel())
+		s.logger.Debugf("-> Sending packet %d (%d bytes) for connection %s, %s", packet.header.PacketNumber, packet.length, s.logID, packet.EncryptionLevel())
 	}
 	s.logPacketContents(packet.packetContents)
 }
@@ -2102,55 +2594,986 @@ func (s *connection) onHasStreamData(id protocol.StreamID) {
 	s.scheduleSending()
 }
 
+func (s *connection) setStreamPriority(id protocol.StreamID, prio StreamPriority) {
+	s.framer.SetStreamPriority(id, prio)
+}
+
+func (s *connection) supportsResetStreamAt() bool {
+	return s.resetStreamAt.Get()
+}
+
 func (s *connection) onStreamCompleted(id protocol.StreamID) {
 	if err := s.streamsMap.DeleteStream(id); err != nil {
 		s.closeLocal(err)
 	}
+	s.framer.RemoveStream(id)
 }
 
 func (s *connection) SendMessage(p []byte) error {
+	return s.sendMessage(p, nil, nil)
+}
+
+func (s *connection) SendMessageWithCallbacks(p []byte, onAcked, onLost func()) error {
+	return s.sendMessage(p, onAcked, onLost)
+}
+
+func (s *connection) sendMessage(p []byte, onAcked, onLost func()) error {
 	if !s.supportsDatagrams() {
 		return errors.New("datagram support disabled")
 	}
 
-	f := &wire.DatagramFrame{DataLenPresent: true}
-	if protocol.ByteCount(len(p)) > f.MaxDataLen(s.peerParams.MaxDatagramFrameSize, s.version) {
-		return errors.New("message too large")
+	if maxSize := s.MaxDatagramSize(); int64(len(p)) > maxSize {
+		return &DatagramTooLargeError{MaxDataLen: maxSize}
 	}
+	f := &wire.DatagramFrame{DataLenPresent: true}
 	f.Data = make([]byte, len(p))
 	copy(f.Data, p)
-	return s.datagramQueue.AddAndWait(f)
+	if s.config.DatagramSendQueueLen > 0 {
+		return s.datagramQueue.Add(f, onAcked, onLost)
+	}
+	return s.datagramQueue.AddAndWait(f, onAcked, onLost)
 }
 
 func (s *connection) ReceiveMessage() ([]byte, error) {
+	return s.ReceiveMessageContext(context.Background())
+}
+
+func (s *connection) ReceiveMessageContext(ctx context.Context) ([]byte, error) {
 	if !s.config.EnableDatagrams {
 		return nil, errors.New("datagram support disabled")
 	}
-	return s.datagramQueue.Receive()
+	return s.datagramQueue.Receive(ctx)
 }
 
-func (s *connection) LocalAddr() net.Addr {
-	return s.conn.LocalAddr()
+func (s *connection) MaxDatagramSize() int64 {
+	return atomic.LoadInt64(&s.maxDatagramSize)
 }
 
-func (s *connection) RemoteAddr() net.Addr {
-	return s.conn.RemoteAddr()
+func (s *connection) MigrateTo(conn net.PacketConn) error {
+	if s.perspective == protocol.PerspectiveServer {
+		return errors.New("only the client can migrate a connection")
+	}
+	return s.pathProber.AddAndWait(newMigrationPathProbe(conn, s.RemoteAddr()))
 }
 
-func (s *connection) getPerspective() protocol.Perspective {
-	return s.perspective
+// handlePathProbes starts validating the next path that the application wants to migrate to,
+// and sends PATH_CHALLENGE and PATH_RESPONSE frames on the path that is currently being validated.
+func (s *connection) handlePathProbes(now time.Time) error {
+	if s.pathProbe == nil {
+		probe := s.pathProber.Dequeue()
+		if probe == nil {
+			return nil
+		}
+		if err := s.startPathProbe(probe, now); err != nil {
+			probe.result <- err
+			return nil
+		}
+	}
+	if !now.Before(s.pathProbe.deadline) {
+		s.abandonPathProbe(errors.New("path validation timed out"))
+		return nil
+	}
+	if !s.pathProbe.CanSend() {
+		return nil
+	}
+	nextChallenge := s.nextPathChallengeTime()
+	sendChallenge := !nextChallenge.IsZero() && !now.Before(nextChallenge)
+	if !sendChallenge && s.pathProbe.response == nil {
+		return nil
+	}
+	return s.sendPathProbePacket(now, sendChallenge)
 }
 
-func (s *connection) GetVersion() protocol.VersionNumber {
-	return s.version
+func (s *connection) startPathProbe(probe *pathProbe, now time.Time) error {
+	if !s.handshakeConfirmed {
+		return errors.New("can't migrate before the handshake is confirmed")
+	}
+	// The disable_active_migration transport parameter doesn't apply to migrating to the preferred address,
+	// see section 18.2 of RFC 9000.
+	if !probe.peerInitiated && !probe.toPreferredAddress && s.peerParams.DisableActiveMigration {
+		return errors.New("peer disabled active connection migration")
+	}
+	if s.multipath && !probe.peerInitiated {
+		return errors.New("can't migrate a multipath connection, use AddPath instead")
+	}
+	var connID protocol.ConnectionID
+	var ok bool
+	if s.multipath {
+		// When using multipath, the initial path is identified by the sequence number of its connection ID.
+		// If the peer's address changes, the connection ID doesn't change.
+		connID, ok = s.connIDManager.Get(), true
+	} else {
+		connID, ok = s.connIDManager.GetForProbing()
+	}
+	if !ok {
+		if !probe.peerInitiated {
+			return errors.New("no unused connection ID available")
+		}
+		// If the peer's address changed due to NAT rebinding, we may continue using the current connection ID,
+		// see section 9.5 of RFC 9000.
+		connID = s.connIDManager.Get()
+	}
+	if probe.pconn != nil {
+		runner, err := getMultiplexer().AddConn(probe.pconn, s.config.ConnectionIDGenerator.ConnectionIDLen(), s.config.StatelessResetKey, s.config.Tracer)
+		if err != nil {
+			return err
+		}
+		probe.runner = runner
+	}
+	probe.connID = connID
+	if probe.runner != nil {
+		s.runners.AddRunner(probe.runner, s.connIDGenerator.ConnectionIDs(), s)
+	}
+	probe.started = now
+	probe.deadline = now.Add(3 * s.pathValidationPTO())
+	s.pathProbe = probe
+	if s.tracer != nil {
+		s.tracer.UpdatedMigrationState(logging.MigrationStateProbingStarted, probe.conn.LocalAddr(), probe.conn.RemoteAddr())
+	}
+	return nil
 }
 
-func (s *connection) NextConnection() Connection {
-	<-s.HandshakeComplete().Done()
-	s.streamsMap.UseResetMaps()
-	return s
+// pathValidationPTO is the PTO used for path validation.
+// The PTO is based on the RTT of the current path, but at least protocol.MinPathValidationPTO.
+func (s *connection) pathValidationPTO() time.Duration {
+	return utils.Max(s.rttStats.PTO(true), protocol.MinPathValidationPTO)
 }
 
-func (s *connection) BandwidthEstimate() Bandwidth {
-	return s.sentPacketHandler.GetBandwidthEstimate()
+// nextPathChallengeTime returns the time when the next PATH_CHALLENGE is due on the path that is being validated.
+// It returns the zero value if no more PATH_CHALLENGE frames will be sent on this path.
+func (s *connection) nextPathChallengeTime() time.Time {
+	return s.pathProbe.NextChallengeTime(s.pathValidationPTO())
+}
+
+func (s *connection) nextPathProbeTimeout() time.Time {
+	return s.pathProbe.NextTimeout(s.pathValidationPTO())
+}
+
+// sendPathProbePacket sends a packet on the path that is being validated.
+// PATH_CHALLENGE frames are not retransmitted. Instead, a new PATH_CHALLENGE frame is sent when the timer fires.
+func (s *connection) sendPathProbePacket(now time.Time, sendChallenge bool) error {
+	probe := s.pathProbe
+	packet, err := s.packPathProbePacket(probe, s.packer, now, sendChallenge)
+	if err != nil {
+		return err
+	}
+	s.logPacket(packet)
+	s.sentPacketHandler.SentPacket(packet.ToAckHandlerPacket(now, s.retransmissionQueue))
+	// Don't use ECN before the path is validated.
+	err = probe.conn.Write(packet.buffer.Data, 0, protocol.ECNNon)
+	packet.buffer.Release()
+	if err != nil {
+		s.abandonPathProbe(fmt.Errorf("sending on the new path failed: %w", err))
+	}
+	return nil
+}
+
+// packPathProbePacket packs a packet containing a PATH_CHALLENGE and / or a PATH_RESPONSE frame.
+func (s *connection) packPathProbePacket(probe *pathProbe, packer packer, now time.Time, sendChallenge bool) (*packedPacket, error) {
+	var frames []ackhandler.Frame
+	if probe.response != nil {
+		frames = append(frames, ackhandler.Frame{
+			Frame:  &wire.PathResponseFrame{Data: *probe.response},
+			OnLost: func(wire.Frame) {},
+		})
+		probe.response = nil
+	}
+	var challengeData [8]byte
+	if sendChallenge {
+		if _, err := rand.Read(challengeData[:]); err != nil {
+			return nil, err
+		}
+		frames = append(frames, ackhandler.Frame{
+			Frame:  &wire.PathChallengeFrame{Data: challengeData},
+			OnLost: func(wire.Frame) {},
+		})
+	}
+	size := utils.Min(protocol.ByteCount(protocol.MinInitialPacketSize), probe.AmplificationWindow())
+	packet, err := packer.PackPathProbePacket(probe.connID, frames, size)
+	if err != nil {
+		return nil, err
+	}
+	if sendChallenge {
+		probe.challenges = append(probe.challenges, challengeData)
+		probe.lastChallengeSent = now
+	}
+	probe.bytesSent += protocol.ByteCount(len(packet.buffer.Data))
+	return packet, nil
+}
+
+// migrate switches to the path that was just validated.
+func (s *connection) migrate() {
+	probe := s.pathProbe
+	s.pathProbe = nil
+	s.logger.Infof("Migrating connection to new path (%s -> %s).", probe.conn.LocalAddr(), probe.conn.RemoteAddr())
+	// If only the peer's port changed, this is most likely a NAT rebinding, and the path didn't change.
+	// There's no need to reset the congestion controller and the RTT estimate, see section 9.4 of RFC 9000.
+	resetCongestion := !probe.peerInitiated ||
+		!isSameIP(probe.conn.RemoteAddr(), s.conn.RemoteAddr()) ||
+		!isSameIP(probe.conn.LocalAddr(), s.conn.LocalAddr())
+	s.conn.Migrate(probe.conn)
+	if probe.rcvConn != nil {
+		s.rcvConn = probe.rcvConn
+	}
+	if probe.runner != nil {
+		// Stop receiving packets on the old path.
+		// This allows the application to close the packet conn used on that path.
+		s.runners.RetainRunner(probe.runner, s.connIDGenerator.ConnectionIDs())
+	}
+	s.connIDManager.SwitchToProbingConnID()
+	if resetCongestion {
+		s.sentPacketHandler.OnConnectionMigration()
+		// The MTU of the new path is not known yet.
+		// This is not necessary after a NAT rebinding. The congestion controller doesn't allow decreasing the datagram size anyway.
+		s.packer.SetMaxPacketSize(getMaxPacketSize(s.conn.RemoteAddr()))
+		s.packer.HandleTransportParameters(s.peerParams)
+		s.startMTUDiscovery()
+	}
+	s.updateMaxDatagramSize()
+	if s.tracer != nil {
+		s.tracer.UpdatedMigrationState(logging.MigrationStateMigrationComplete, probe.conn.LocalAddr(), probe.conn.RemoteAddr())
+	}
+	probe.result <- nil
+}
+
+func (s *connection) abandonPathProbe(err error) {
+	s.logger.Debugf("Abandoning path validation: %s", err)
+	probe := s.pathProbe
+	s.pathProbe = nil
+	if probe.runner != nil {
+		s.runners.RemoveRunner(probe.runner, s.connIDGenerator.ConnectionIDs())
+	}
+	s.connIDManager.AbandonProbingConnID()
+	if s.tracer != nil {
+		s.tracer.UpdatedMigrationState(logging.MigrationStateProbingAbandoned, probe.conn.LocalAddr(), probe.conn.RemoteAddr())
+	}
+	probe.result <- err
+}
+
+// newPreferredAddress registers the connection with the packet conn that receives packets sent to the preferred address,
+// and issues the connection ID for the preferred address.
+// It is called from the constructor, and must not call into the connection's first runner,
+// since the server holds the lock of its packet handler map while constructing the connection.
+func (s *connection) newPreferredAddress(runner connRunner) (*wire.PreferredAddress, error) {
+	s.runners.AddRunner(runner, s.connIDGenerator.ConnectionIDs(), s)
+	connID, err := s.connIDGenerator.IssuePreferredAddressConnID()
+	if err != nil {
+		return nil, err
+	}
+	pa := &wire.PreferredAddress{
+		IPv4:                net.IPv4zero.To4(),
+		IPv6:                net.IPv6zero,
+		ConnectionID:        connID,
+		StatelessResetToken: s.runners.GetStatelessResetToken(connID),
+	}
+	if addr := s.config.PreferredAddress.IPv4; addr != nil {
+		pa.IPv4 = addr.IP.To4()
+		pa.IPv4Port = uint16(addr.Port)
+	}
+	if addr := s.config.PreferredAddress.IPv6; addr != nil {
+		pa.IPv6 = addr.IP.To16()
+		pa.IPv6Port = uint16(addr.Port)
+	}
+	return pa, nil
+}
+
+// probePreferredAddress starts validating the path to the server's preferred address.
+// The connection migrates to the preferred address as soon as the path is validated.
+func (s *connection) probePreferredAddress() {
+	if s.pathProbe != nil {
+		return
+	}
+	pa := s.peerParams.PreferredAddress
+	// Use the address of the same address family as the address the handshake was performed with.
+	ip, port := pa.IPv6, pa.IPv6Port
+	if addr, ok := s.conn.RemoteAddr().(*net.UDPAddr); ok && addr.IP.To4() != nil {
+		ip, port = pa.IPv4, pa.IPv4Port
+	}
+	if port == 0 || ip.IsUnspecified() {
+		s.logger.Debugf("Not migrating to the preferred address: no address of the same address family.")
+		return
+	}
+	remote := &net.UDPAddr{IP: ip, Port: int(port)}
+	if err := s.startPathProbe(newPreferredAddressPathProbe(s.conn.WithRemoteAddr(remote, nil)), time.Now()); err != nil {
+		s.logger.Debugf("Not migrating to the preferred address: %s", err)
+		return
+	}
+	s.logger.Debugf("Validating the path to the preferred address %s.", remote)
+}
+
+func (s *connection) AddPath(conn net.PacketConn) (Path, error) {
+	if s.perspective == protocol.PerspectiveServer {
+		return nil, errors.New("only the client can add paths")
+	}
+	runner, err := getMultiplexer().AddConn(conn, s.config.ConnectionIDGenerator.ConnectionIDLen(), s.config.StatelessResetKey, s.config.Tracer)
+	if err != nil {
+		return nil, err
+	}
+	sconn := newSendPconn(conn, s.RemoteAddr())
+	p := &path{
+		conn:     sconn,
+		runner:   runner,
+		probe:    newPathProbe(sconn, runner),
+		requests: s.pathQueue,
+	}
+	if err := s.pathQueue.Do(&pathRequest{action: pathActionAdd, path: p}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// enableMultipath is called when both endpoints enabled multipath.
+// The initial path continues to use the connection's packet number space, congestion controller and RTT estimate.
+func (s *connection) enableMultipath() {
+	s.multipath = true
+	s.connIDManager.EnableMultipath()
+	s.initialPath = &path{
+		initial:               true,
+		sendID:                s.connIDManager.ActiveSequenceNumber(),
+		rcvIDKnown:            true,
+		conn:                  s.conn,
+		rttStats:              s.rttStats,
+		sentPacketHandler:     s.sentPacketHandler,
+		receivedPacketHandler: s.receivedPacketHandler,
+		packer:                s.packer,
+	}
+}
+
+// handlePaths handles the application's requests to add, change and close paths,
+// and sends PATH_CHALLENGE and PATH_RESPONSE frames on paths that are being validated.
+func (s *connection) handlePaths(now time.Time) error {
+	// Requests are only handled once the handshake is confirmed.
+	// Until then, it's not known if the peer supports multipath.
+	if !s.handshakeConfirmed {
+		return nil
+	}
+	for r := s.pathQueue.Dequeue(); r != nil; r = s.pathQueue.Dequeue() {
+		switch r.action {
+		case pathActionAdd:
+			r.path.probe.result = r.result
+			if err := s.startPath(r.path, now); err != nil {
+				r.result <- err
+			}
+		case pathActionSetStatus:
+			r.result <- s.setPathStatus(r.path, r.status)
+		case pathActionClose:
+			r.result <- s.closePath(r.path)
+		}
+	}
+
+	for _, p := range append([]*path{}, s.paths...) {
+		probe := p.probe
+		if !probe.validated && !now.Before(probe.deadline) {
+			s.abandonPath(p, errors.New("path validation timed out"))
+			continue
+		}
+		if !probe.CanSend() {
+			continue
+		}
+		nextChallenge := probe.NextChallengeTime(s.pathValidationPTO())
+		sendChallenge := !nextChallenge.IsZero() && !now.Before(nextChallenge)
+		if !sendChallenge && probe.response == nil {
+			continue
+		}
+		packet, err := s.packPathProbePacket(probe, p.packer, now, sendChallenge)
+		if err != nil {
+			return err
+		}
+		s.sendPackedPacketOnPath(p, packet, now)
+	}
+	return nil
+}
+
+// startPath starts validating a path that was added by the application.
+func (s *connection) startPath(p *path, now time.Time) error {
+	if !s.multipath {
+		return errors.New("peer doesn't support multipath")
+	}
+	c, ok := s.connIDManager.GetForPath()
+	if !ok {
+		return errors.New("no unused connection ID available")
+	}
+	p.sendID = c.SequenceNumber
+	p.connID = c.ConnectionID
+	if !s.runners.AddRunner(p.runner, s.connIDGenerator.ConnectionIDs(), s) {
+		// The packet conn is already used by another path.
+		p.runner = nil
+	}
+	s.setupPath(p, now)
+	s.logger.Debugf("Validating new path %d (%s -> %s).", p.sendID, p.conn.LocalAddr(), p.conn.RemoteAddr())
+	return nil
+}
+
+// newPeerPath creates a new path when the server receives a packet using a new connection ID.
+// It returns nil if the path can't be used.
+func (s *connection) newPeerPath(rp *receivedPacket, pathID uint64) *path {
+	if s.perspective == protocol.PerspectiveClient {
+		return nil
+	}
+	c, ok := s.connIDManager.GetForPath()
+	if !ok {
+		s.logger.Debugf("Not using new path %d: no unused connection ID available.", pathID)
+		return nil
+	}
+	var conn sendConn
+	if rp.rcvConn != nil {
+		conn = newSendConn(rp.rcvConn, rp.remoteAddr, rp.info)
+	} else {
+		conn = s.conn.WithRemoteAddr(rp.remoteAddr, rp.info)
+	}
+	p := &path{
+		sendID:     c.SequenceNumber,
+		connID:     c.ConnectionID,
+		rcvID:      pathID,
+		rcvIDKnown: true,
+		conn:       conn,
+		rcvConn:    rp.rcvConn,
+		probe:      newPeerPathProbe(conn, rp.rcvConn),
+		requests:   s.pathQueue,
+	}
+	s.setupPath(p, rp.rcvTime)
+	s.logger.Debugf("Peer added path %d (%s -> %s). Validating path.", pathID, conn.LocalAddr(), conn.RemoteAddr())
+	return p
+}
+
+// setupPath sets up the packet number space, congestion controller and RTT estimate of a new path.
+func (s *connection) setupPath(p *path, now time.Time) {
+	p.rttStats = &utils.RTTStats{}
+	p.rttStats.SetMaxAckDelay(s.peerParams.MaxAckDelay)
+	p.sentPacketHandler, p.receivedPacketHandler = ackhandler.NewPathAckHandler(
+		getMaxPacketSize(p.conn.RemoteAddr()),
+		p.rttStats,
+		s.perspective,
+		s.logger,
+		s.version,
+		s.config.congestionControl(),
+	)
+	if s.lastAckFrequencyFrame != nil {
+		p.receivedPacketHandler.SetAckFrequency(s.lastAckFrequencyFrame)
+	}
+	packer := newPacketPacker(
+		protocol.ConnectionID{}, // only used for long header packets
+		func() protocol.ConnectionID { return p.connID },
+		nil,
+		nil,
+		p.sentPacketHandler,
+		s.retransmissionQueue,
+		p.conn.RemoteAddr(),
+		&pathSealingManager{sealingManager: s.sealingManager, pathID: p.sendID},
+		s.framer,
+		p.receivedPacketHandler,
+		s.datagramQueue,
+		s.perspective,
+		s.version,
+	)
+	packer.ackMPIdentifier = &p.rcvID
+	packer.HandleTransportParameters(s.peerParams)
+	p.packer = packer
+	p.probe.connID = p.connID
+	p.probe.deadline = now.Add(3 * s.pathValidationPTO())
+	s.paths = append(s.paths, p)
+}
+
+func (s *connection) handlePathValidated(p *path) {
+	p.probe.validated = true
+	s.logger.Infof("Validated path %d (%s -> %s).", p.sendID, p.conn.LocalAddr(), p.conn.RemoteAddr())
+	p.probe.result <- nil
+}
+
+func (s *connection) setPathStatus(p *path, status PathStatus) error {
+	if p.abandoned {
+		return errPathClosed
+	}
+	p.status = status
+	p.statusSeq++
+	f := &wire.PathStatusFrame{
+		PathIdentifier: p.sendID,
+		SequenceNumber: p.statusSeq,
+		Status:         wire.PathStatusAvailable,
+	}
+	if status == PathStatusStandby {
+		f.Status = wire.PathStatusStandby
+	}
+	s.queueControlFrame(f)
+	return nil
+}
+
+// closePath abandons a path, and informs the peer by sending a PATH_ABANDON frame.
+func (s *connection) closePath(p *path) error {
+	if p.abandoned {
+		return errPathClosed
+	}
+	s.queueControlFrame(&wire.PathAbandonFrame{PathIdentifier: p.sendID})
+	s.abandonPath(p, errPathClosed)
+	return nil
+}
+
+// abandonPath stops using a path.
+// All packets that are still outstanding on this path are declared lost.
+func (s *connection) abandonPath(p *path, err error) {
+	s.logger.Debugf("Abandoning path %d: %s", p.sendID, err)
+	p.abandoned = true
+	p.sentPacketHandler.Abandon()
+	s.connIDManager.RetireForPath(p.sendID)
+	if p.runner != nil {
+		s.runners.RemoveRunner(p.runner, s.connIDGenerator.ConnectionIDs())
+	}
+	for i, path := range s.paths {
+		if path == p {
+			s.paths = append(s.paths[:i], s.paths[i+1:]...)
+			break
+		}
+	}
+	s.abandonedPathsStats.Add(p.sentPacketHandler.GetStats())
+	if !p.probe.validated {
+		p.probe.result <- err
+	}
+}
+
+// pathBySendID returns the path that uses the connection ID with the given sequence number for sending.
+func (s *connection) pathBySendID(id uint64) *path {
+	if s.initialPath.sendID == id {
+		return s.initialPath
+	}
+	for _, p := range s.paths {
+		if p.sendID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// pathByRcvID returns the path on which the peer uses the connection ID with the given sequence number.
+func (s *connection) pathByRcvID(id uint64) *path {
+	if s.initialPath.rcvID == id {
+		return s.initialPath
+	}
+	for _, p := range s.paths {
+		if p.rcvIDKnown && p.rcvID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// additionalPathID determines if a 1-RTT packet was received on one of the additional paths of a multipath connection.
+// Paths are identified by the sequence number of the connection ID that the peer uses on that path.
+func (s *connection) additionalPathID(p *receivedPacket) (protocol.ConnectionID, uint64, bool) {
+	if !s.multipath || !s.handshakeConfirmed {
+		return protocol.ConnectionID{}, 0, false
+	}
+	connID, err := wire.ParseConnectionID(p.data, s.srcConnIDLen)
+	if err != nil {
+		return protocol.ConnectionID{}, 0, false
+	}
+	seq, ok := s.connIDGenerator.SequenceNumber(connID)
+	if !ok || seq == s.initialPath.rcvID {
+		return protocol.ConnectionID{}, 0, false
+	}
+	return connID, seq, true
+}
+
+// pathForReceivedPacket returns the path that a packet was received on.
+// The client doesn't know which connection ID the server uses on a new path,
+// until it receives the first packet on that path.
+func (s *connection) pathForReceivedPacket(rp *receivedPacket, pathID uint64) *path {
+	if p := s.pathByRcvID(pathID); p != nil {
+		return p
+	}
+	if rp.rcvConn == nil {
+		return nil
+	}
+	for _, p := range s.paths {
+		if !p.rcvIDKnown && isSameAddr(p.conn.LocalAddr(), rp.rcvConn.LocalAddr()) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *connection) handleShortHeaderPacketOnPath(rp *receivedPacket, destConnID protocol.ConnectionID, pathID uint64) bool {
+	var wasQueued bool
+
+	defer func() {
+		// Put back the packet buffer if the packet wasn't queued for later decryption.
+		if !wasQueued {
+			rp.buffer.Decrement()
+		}
+	}()
+
+	p := s.pathForReceivedPacket(rp, pathID)
+	var largestRcvd protocol.PacketNumber
+	if p != nil {
+		largestRcvd = p.largestRcvdPN
+	}
+	pn, pnLen, keyPhase, data, err := s.unpacker.UnpackShortHeaderOnPath(rp.data, pathID, largestRcvd)
+	if err != nil {
+		wasQueued = s.handleUnpackError(err, rp, logging.PacketType1RTT)
+		return false
+	}
+	if p == nil {
+		if p = s.newPeerPath(rp, pathID); p == nil {
+			if s.tracer != nil {
+				s.tracer.DroppedPacket(logging.PacketType1RTT, rp.Size(), logging.PacketDropUnknownConnectionID)
+			}
+			return false
+		}
+	}
+	if !p.rcvIDKnown {
+		p.rcvID = pathID
+		p.rcvIDKnown = true
+	}
+
+	if s.logger.Debug() {
+		s.logger.Debugf("<- Reading packet %d (%d bytes) for connection %s, 1-RTT, path %d", pn, rp.Size(), destConnID, pathID)
+		wire.LogShortHeader(s.logger, destConnID, pn, pnLen, keyPhase)
+	}
+
+	if p.receivedPacketHandler.IsPotentiallyDuplicate(pn, protocol.Encryption1RTT) {
+		s.logger.Debugf("Dropping (potentially) duplicate packet.")
+		if s.tracer != nil {
+			s.tracer.DroppedPacket(logging.PacketType1RTT, rp.Size(), logging.PacketDropDuplicate)
+		}
+		return false
+	}
+	p.largestRcvdPN = utils.Max(p.largestRcvdPN, pn)
+	p.probe.bytesReceived += rp.Size()
+
+	var log func([]logging.Frame)
+	if s.tracer != nil {
+		log = func(frames []logging.Frame) {
+			s.tracer.ReceivedShortHeaderPacket(
+				&logging.ShortHeader{
+					DestConnectionID: destConnID,
+					PacketNumber:     pn,
+					PacketNumberLen:  pnLen,
+					KeyPhase:         keyPhase,
+				},
+				rp.Size(),
+				frames,
+			)
+		}
+	}
+	s.lastPacketReceivedTime = rp.rcvTime
+	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
+	s.keepAlivePingSent = false
+
+	s.currentPath = p
+	isAckEliciting, _, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log)
+	s.currentPath = nil
+	if err == nil {
+		err = p.receivedPacketHandler.ReceivedPacket(pn, rp.ecn, protocol.Encryption1RTT, rp.rcvTime, isAckEliciting)
+	}
+	if err != nil {
+		s.closeLocal(err)
+		return false
+	}
+	return true
+}
+
+func (s *connection) handleAckMPFrame(frame *wire.AckMPFrame) error {
+	if !s.multipath {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: "received ACK_MP frame, although multipath was not negotiated",
+		}
+	}
+	p := s.pathBySendID(frame.PathIdentifier)
+	if p == nil {
+		// The path might already have been abandoned.
+		return nil
+	}
+	if p.initial {
+		return s.handleAckFrame(frame.AckFrame, protocol.Encryption1RTT)
+	}
+	_, err := p.sentPacketHandler.ReceivedAck(frame.AckFrame, protocol.Encryption1RTT, s.lastPacketReceivedTime)
+	return err
+}
+
+func (s *connection) handlePathAbandonFrame(frame *wire.PathAbandonFrame) error {
+	if !s.multipath {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: "received PATH_ABANDON frame, although multipath was not negotiated",
+		}
+	}
+	p := s.pathByRcvID(frame.PathIdentifier)
+	if p == nil {
+		return nil
+	}
+	if p.initial {
+		// The initial path is never abandoned, since the connection's packet number space belongs to it.
+		// Only use it if no other path is available.
+		p.peerStatus = PathStatusStandby
+		return nil
+	}
+	s.abandonPath(p, fmt.Errorf("peer abandoned path (error code %d: %s)", frame.ErrorCode, frame.ReasonPhrase))
+	return nil
+}
+
+func (s *connection) handlePathStatusFrame(frame *wire.PathStatusFrame) error {
+	if !s.multipath {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: "received PATH_STATUS frame, although multipath was not negotiated",
+		}
+	}
+	p := s.pathByRcvID(frame.PathIdentifier)
+	if p == nil {
+		return nil
+	}
+	// PATH_STATUS frames might be reordered.
+	if p.receivedPeerStatus && frame.SequenceNumber <= p.peerStatusSeq {
+		return nil
+	}
+	switch frame.Status {
+	case wire.PathStatusStandby:
+		p.peerStatus = PathStatusStandby
+	case wire.PathStatusAvailable:
+		p.peerStatus = PathStatusAvailable
+	default:
+		return &qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			ErrorMessage: fmt.Sprintf("invalid path status %d", frame.Status),
+		}
+	}
+	p.receivedPeerStatus = true
+	p.peerStatusSeq = frame.SequenceNumber
+	return nil
+}
+
+// sendPacketsOnPaths is used instead of sendPackets once a multipath connection uses more than one path.
+// Probe packets and packets that only contain acknowledgements are sent on the path they belong to.
+// For all other packets, the path scheduler selects the path.
+func (s *connection) sendPacketsOnPaths() error {
+	s.pacingDeadline = time.Time{}
+	now := time.Now()
+
+	paths := make([]*path, 0, len(s.paths)+1)
+	paths = append(paths, s.initialPath)
+	for _, p := range s.paths {
+		if p.IsUsable() {
+			paths = append(paths, p)
+		}
+	}
+	for _, p := range paths {
+		for p.IsUsable() && p.sentPacketHandler.SendMode() == ackhandler.SendPTOAppData {
+			if p.initial && s.sendQueue.WouldBlock() {
+				break
+			}
+			if err := s.sendProbePacketOnPath(p, now); err != nil {
+				return err
+			}
+		}
+	}
+	for {
+		p := s.selectPath(paths)
+		if p == nil {
+			break
+		}
+		sent, err := s.sendPacketOnPath(p, now)
+		if err != nil {
+			return err
+		}
+		if !sent {
+			break
+		}
+		// Prioritize receiving of packets over sending out more packets.
+		if len(s.receivedPackets) > 0 {
+			s.pacingDeadline = deadlineSendImmediately
+			return nil
+		}
+		if s.sendQueue.WouldBlock() {
+			return nil
+		}
+	}
+	for _, p := range paths {
+		if !p.IsUsable() || (p.initial && s.sendQueue.WouldBlock()) {
+			continue
+		}
+		packet, err := p.packer.PackPacket(true)
+		if err != nil {
+			return err
+		}
+		if packet != nil {
+			s.sendPackedPacketOnPath(p, packet, now)
+		}
+	}
+	return nil
+}
+
+// selectPath uses the path scheduler to select the path that the next packet is sent on.
+// It returns nil if all paths are congestion limited or limited by pacing.
+func (s *connection) selectPath(paths []*path) *path {
+	var available, standby []*path
+	for _, p := range paths {
+		if !p.IsUsable() || p.sentPacketHandler.SendMode() != ackhandler.SendAny {
+			continue
+		}
+		if !p.sentPacketHandler.HasPacingBudget() {
+			deadline := p.sentPacketHandler.TimeUntilSend()
+			if deadline.IsZero() {
+				deadline = deadlineSendImmediately
+			}
+			s.pacingDeadline = utils.MinNonZeroTime(s.pacingDeadline, deadline)
+			continue
+		}
+		if p.IsStandby() {
+			standby = append(standby, p)
+		} else {
+			available = append(available, p)
+		}
+	}
+	candidates := available
+	if len(candidates) == 0 {
+		candidates = standby
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	infos := make([]PathInfo, len(candidates))
+	for i, p := range candidates {
+		infos[i] = p.Info()
+	}
+	i := s.config.PathScheduler.SelectPath(infos)
+	if i < 0 || i >= len(candidates) {
+		return nil
+	}
+	return candidates[i]
+}
+
+func (s *connection) sendPacketOnPath(p *path, now time.Time) (bool, error) {
+	if p.initial {
+		return s.sendPacket()
+	}
+	s.queueFlowControlFrames()
+	packet, err := p.packer.PackPacket(false)
+	if err != nil || packet == nil {
+		return false, err
+	}
+	s.sendPackedPacketOnPath(p, packet, now)
+	return true, nil
+}
+
+func (s *connection) sendProbePacketOnPath(p *path, now time.Time) error {
+	if p.initial {
+		return s.sendProbePacket(protocol.Encryption1RTT)
+	}
+	packet, err := s.packProbePacket(p.sentPacketHandler, p.packer, protocol.Encryption1RTT)
+	if err != nil {
+		return err
+	}
+	s.sendPackedPacketOnPath(p, packet, now)
+	return nil
+}
+
+// sendPackedPacketOnPath sends a packet on a path.
+// Packets on the initial path are sent using the send queue, packets on all other paths are written directly.
+func (s *connection) sendPackedPacketOnPath(p *path, packet *packedPacket, now time.Time) {
+	if p.initial {
+		s.sendPackedPacket(packet, now)
+		return
+	}
+	if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && packet.IsAckEliciting() {
+		s.firstAckElicitingPacketAfterIdleSentTime = now
+	}
+	s.logPacket(packet)
+	ecn := ecnMode(p.sentPacketHandler, p.conn, true)
+	ap := packet.ToAckHandlerPacket(now, s.retransmissionQueue)
+	ap.ECN = ecn
+	p.sentPacketHandler.SentPacket(ap)
+	err := p.conn.Write(packet.buffer.Data, 0, ecn)
+	packet.buffer.Release()
+	if err != nil && !p.abandoned {
+		s.queueControlFrame(&wire.PathAbandonFrame{PathIdentifier: p.sendID})
+		s.abandonPath(p, fmt.Errorf("sending on the path failed: %w", err))
+	}
+}
+
+func (s *connection) LocalAddr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+func (s *connection) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+func (s *connection) getPerspective() protocol.Perspective {
+	return s.perspective
+}
+
+func (s *connection) GetVersion() protocol.VersionNumber {
+	return s.version
+}
+
+func (s *connection) NextConnection() Connection {
+	<-s.HandshakeComplete().Done()
+	s.streamsMap.UseResetMaps()
+	return s
+}
+
+func (s *connection) BandwidthEstimate() Bandwidth {
+	return s.sentPacketHandler.GetBandwidthEstimate()
+}
+
+func (s *connection) Stats() ConnectionStats {
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+	return s.stats
+}
+
+// updateStats updates the snapshot returned by Stats.
+// It must only be called from the run loop.
+func (s *connection) updateStats() {
+	stats := s.getStats()
+	s.statsMutex.Lock()
+	s.stats = stats
+	s.statsMutex.Unlock()
+}
+
+func (s *connection) getStats() ConnectionStats {
+	stats := s.sentPacketHandler.GetStats()
+	for _, p := range s.paths {
+		stats.Add(p.sentPacketHandler.GetStats())
+	}
+	stats.Add(s.abandonedPathsStats)
+	return ConnectionStats{
+		SmoothedRTT:          s.rttStats.SmoothedRTT(),
+		MinRTT:               s.rttStats.MinRTT(),
+		LatestRTT:            s.rttStats.LatestRTT(),
+		RTTVariance:          s.rttStats.MeanDeviation(),
+		CongestionWindow:     uint64(stats.CongestionWindow),
+		BytesInFlight:        uint64(stats.BytesInFlight),
+		MaxDatagramSize:      uint64(stats.MaxDatagramSize),
+		PacketsSent:          stats.PacketsSent,
+		BytesSent:            uint64(stats.BytesSent),
+		PacketsReceived:      stats.PacketsReceived,
+		BytesReceived:        uint64(stats.BytesReceived),
+		PacketsLost:          stats.PacketsLost,
+		BytesLost:            uint64(stats.BytesLost),
+		PacketsRetransmitted: stats.PacketsRetransmitted,
+		BytesRetransmitted:   uint64(stats.BytesRetransmitted),
+		PTOCount:             stats.PTOCount,
+		KeyPhase:             uint64(s.cryptoStreamHandler.KeyPhase()),
+	}
+}
+
+// isSameAddr says if two addresses are equal.
+func isSameAddr(a, b net.Addr) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	udpA, okA := a.(*net.UDPAddr)
+	udpB, okB := b.(*net.UDPAddr)
+	if okA && okB {
+		return udpA.IP.Equal(udpB.IP) && udpA.Port == udpB.Port && udpA.Zone == udpB.Zone
+	}
+	return a.Network() == b.Network() && a.String() == b.String()
+}
+
+// isSameIP says if two addresses have the same IP address, ignoring the port.
+func isSameIP(a, b net.Addr) bool {
+	udpA, okA := a.(*net.UDPAddr)
+	udpB, okB := b.(*net.UDPAddr)
+	return okA && okB && udpA.IP.Equal(udpB.IP)
 }
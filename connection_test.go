@@ -81,7 +81,8 @@ var _ = Describe("Connection", func() {
 		mconn = NewMockSendConn(mockCtrl)
 		mconn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
 		mconn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
-		tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader)
+		mconn.EXPECT().capabilities().AnyTimes()
+		tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader, nil)
 		Expect(err).ToNot(HaveOccurred())
 		tracer = mocklogging.NewMockConnectionTracer(mockCtrl)
 		tracer.EXPECT().NegotiatedVersion(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(1)
@@ -91,6 +92,7 @@ var _ = Describe("Connection", func() {
 		conn = newConnection(
 			mconn,
 			connRunner,
+			nil,
 			protocol.ConnectionID{},
 			nil,
 			clientDestConnID,
@@ -112,6 +114,8 @@ var _ = Describe("Connection", func() {
 		packer = NewMockPacker(mockCtrl)
 		conn.packer = packer
 		cryptoSetup = mocks.NewMockCryptoSetup(mockCtrl)
+		// the run loop updates the snapshot of the connection's statistics
+		cryptoSetup.EXPECT().KeyPhase().AnyTimes()
 		conn.cryptoStreamHandler = cryptoSetup
 		conn.handshakeComplete = true
 		conn.idleTimeout = time.Hour
@@ -164,6 +168,50 @@ var _ = Describe("Connection", func() {
 				err := conn.handleAckFrame(f, protocol.EncryptionHandshake)
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("queues an ACK_FREQUENCY frame, if the ACK frequency extension was negotiated", func() {
+				conn.ackFrequency = true
+				f := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 3}}}
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sph.EXPECT().ReceivedAck(f, protocol.Encryption1RTT, gomock.Any()).Return(true, nil)
+				ackFrequencyFrame := &wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestMaxAckDelay: 10 * time.Millisecond}
+				sph.EXPECT().GetAckFrequencyFrame().Return(ackFrequencyFrame)
+				cryptoSetup.EXPECT().SetLargest1RTTAcked(protocol.PacketNumber(3))
+				conn.sentPacketHandler = sph
+				conn.handshakeConfirmed = true
+				Expect(conn.handleAckFrame(f, protocol.Encryption1RTT)).To(Succeed())
+				frames, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount)
+				Expect(frames).To(Equal([]ackhandler.Frame{{Frame: ackFrequencyFrame}}))
+			})
+		})
+
+		Context("handling ACK frequency frames", func() {
+			var rph *mockackhandler.MockReceivedPacketHandler
+
+			BeforeEach(func() {
+				rph = mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+				conn.receivedPacketHandler = rph
+			})
+
+			It("applies the values requested in ACK_FREQUENCY frames", func() {
+				f := &wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestMaxAckDelay: 10 * time.Millisecond}
+				rph.EXPECT().SetAckFrequency(f)
+				Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				// frames with old sequence numbers are ignored
+				Expect(conn.handleFrame(&wire.AckFrequencyFrame{SequenceNumber: 1, RequestMaxAckDelay: 20 * time.Millisecond}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+
+			It("rejects ACK_FREQUENCY frames that request a max ack delay smaller than the min_ack_delay", func() {
+				f := &wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: protocol.MinAckDelay - 1}
+				err := conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})
+				Expect(err).To(BeAssignableToTypeOf(&qerr.TransportError{}))
+				Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.ProtocolViolation))
+			})
+
+			It("queues an ACK when receiving an IMMEDIATE_ACK frame", func() {
+				rph.EXPECT().QueueImmediateAck()
+				Expect(conn.handleFrame(&wire.ImmediateAckFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
 		})
 
 		Context("handling RESET_STREAM frames", func() {
@@ -283,9 +331,8 @@ var _ = Describe("Connection", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("rejects PATH_RESPONSE frames", func() {
-			err := conn.handleFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}, protocol.Encryption1RTT, protocol.ConnectionID{})
-			Expect(err).To(MatchError("unexpected PATH_RESPONSE frame"))
+		It("ignores PATH_RESPONSE frames that don't match any PATH_CHALLENGE", func() {
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
 		})
 
 		It("handles PATH_CHALLENGE frames", func() {
@@ -389,6 +436,60 @@ var _ = Describe("Connection", func() {
 		Expect(conn.GetVersion()).To(Equal(protocol.VersionNumber(4242)))
 	})
 
+	It("sends the preferred_address transport parameter", func() {
+		preferredAddressRunner := NewMockConnRunner(mockCtrl)
+		preferredAddressRunner.EXPECT().Add(srcConnID, gomock.Any())
+		preferredAddressRunner.EXPECT().Add(clientDestConnID, gomock.Any())
+		var connID protocol.ConnectionID
+		connRunner.EXPECT().GetStatelessResetToken(gomock.Any()).DoAndReturn(func(c protocol.ConnectionID) protocol.StatelessResetToken {
+			connID = c
+			return protocol.StatelessResetToken{1, 2, 3}
+		})
+		var params *wire.TransportParameters
+		tracer.EXPECT().SentTransportParameters(gomock.Any()).Do(func(p *wire.TransportParameters) { params = p })
+		tracer.EXPECT().UpdatedCongestionState(gomock.Any())
+		tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader, nil)
+		Expect(err).ToNot(HaveOccurred())
+		c := newConnection(
+			mconn,
+			connRunner,
+			preferredAddressRunner,
+			protocol.ConnectionID{},
+			nil,
+			clientDestConnID,
+			destConnID,
+			srcConnID,
+			protocol.StatelessResetToken{},
+			populateServerConfig(&Config{
+				PreferredAddress: &PreferredAddress{
+					IPv4: &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 443},
+					Conn: NewMockPacketConn(mockCtrl),
+				},
+			}),
+			nil, // tls.Config
+			tokenGenerator,
+			false,
+			false,
+			tracer,
+			1234,
+			utils.DefaultLogger,
+			protocol.VersionTLS,
+		)
+		Expect(params.PreferredAddress).ToNot(BeNil())
+		Expect(params.PreferredAddress.IPv4.Equal(net.IPv4(192, 0, 2, 1))).To(BeTrue())
+		Expect(params.PreferredAddress.IPv4Port).To(BeEquivalentTo(443))
+		Expect(params.PreferredAddress.IPv6.IsUnspecified()).To(BeTrue())
+		Expect(params.PreferredAddress.IPv6Port).To(BeZero())
+		Expect(params.PreferredAddress.ConnectionID).To(Equal(connID))
+		Expect(params.PreferredAddress.StatelessResetToken).To(Equal(protocol.StatelessResetToken{1, 2, 3}))
+		// the connection ID is registered with both runners once the handshake completes
+		connRunner.EXPECT().Retire(clientDestConnID)
+		preferredAddressRunner.EXPECT().Retire(clientDestConnID)
+		connRunner.EXPECT().Add(connID, c)
+		preferredAddressRunner.EXPECT().Add(connID, c)
+		c.(*connection).connIDGenerator.SetHandshakeComplete()
+	})
+
 	Context("closing", func() {
 		var (
 			runErr         chan error
@@ -430,7 +531,7 @@ var _ = Describe("Connection", func() {
 				Expect(e.ErrorMessage).To(BeEmpty())
 				return &coalescedPacket{buffer: buffer}, nil
 			})
-			mconn.EXPECT().Write([]byte("connection close"))
+			mconn.EXPECT().Write([]byte("connection close"), gomock.Any(), protocol.ECNNon)
 			gomock.InOrder(
 				tracer.EXPECT().ClosedConnection(gomock.Any()).Do(func(e error) {
 					var appErr *ApplicationError
@@ -445,13 +546,86 @@ var _ = Describe("Connection", func() {
 			Expect(conn.Context().Done()).To(BeClosed())
 		})
 
+		It("returns statistics, also after the connection was closed", func() {
+			conn.rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().GetStats().Return(ackhandler.Stats{
+				PacketsSent:      1,
+				BytesSent:        1000,
+				BytesInFlight:    1000,
+				CongestionWindow: 10000,
+				MaxDatagramSize:  1252,
+			}).AnyTimes()
+			conn.sentPacketHandler = sph
+			cryptoSetup = mocks.NewMockCryptoSetup(mockCtrl)
+			cryptoSetup.EXPECT().KeyPhase().Return(protocol.KeyPhase(3)).AnyTimes()
+			conn.cryptoStreamHandler = cryptoSetup
+			runConn()
+			Eventually(func() uint64 { return conn.Stats().PacketsSent }).Should(BeEquivalentTo(1))
+			stats := conn.Stats()
+			Expect(stats.SmoothedRTT).To(Equal(100 * time.Millisecond))
+			Expect(stats.LatestRTT).To(Equal(100 * time.Millisecond))
+			Expect(stats.MinRTT).To(Equal(100 * time.Millisecond))
+			Expect(stats.RTTVariance).To(Equal(50 * time.Millisecond))
+			Expect(stats.PacketsSent).To(BeEquivalentTo(1))
+			Expect(stats.BytesSent).To(BeEquivalentTo(1000))
+			Expect(stats.BytesInFlight).To(BeEquivalentTo(1000))
+			Expect(stats.CongestionWindow).To(BeEquivalentTo(10000))
+			Expect(stats.MaxDatagramSize).To(BeEquivalentTo(1252))
+			Expect(stats.KeyPhase).To(BeEquivalentTo(3))
+
+			streamManager.EXPECT().CloseWithError(gomock.Any())
+			expectReplaceWithClosed()
+			cryptoSetup.EXPECT().Close()
+			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
+			tracer.EXPECT().ClosedConnection(gomock.Any())
+			tracer.EXPECT().Close()
+			conn.shutdown()
+			Eventually(conn.Context().Done()).Should(BeClosed())
+			Expect(conn.Stats()).To(Equal(stats))
+		})
+
+		It("returns statistics when called from the run loop", func() {
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().TimeUntilSend().AnyTimes()
+			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().GetStats().Return(ackhandler.Stats{PacketsSent: 42}).AnyTimes()
+			conn.sentPacketHandler = sph
+			runConn()
+			Eventually(func() uint64 { return conn.Stats().PacketsSent }).Should(BeEquivalentTo(42))
+			// Stats is called from the run loop, e.g. when the tracer or the PathScheduler calls it.
+			statsChan := make(chan ConnectionStats, 1)
+			packer.EXPECT().PackCoalescedPacket(false).DoAndReturn(func(bool) (*coalescedPacket, error) {
+				statsChan <- conn.Stats()
+				return nil, nil
+			})
+			conn.scheduleSending()
+			var stats ConnectionStats
+			Eventually(statsChan).Should(Receive(&stats))
+			Expect(stats.PacketsSent).To(BeEquivalentTo(42))
+
+			streamManager.EXPECT().CloseWithError(gomock.Any())
+			expectReplaceWithClosed()
+			cryptoSetup.EXPECT().Close()
+			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
+			tracer.EXPECT().ClosedConnection(gomock.Any())
+			tracer.EXPECT().Close()
+			conn.shutdown()
+			Eventually(conn.Context().Done()).Should(BeClosed())
+		})
+
 		It("only closes once", func() {
 			runConn()
 			streamManager.EXPECT().CloseWithError(gomock.Any())
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			conn.shutdown()
@@ -470,7 +644,7 @@ var _ = Describe("Connection", func() {
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
 			packer.EXPECT().PackApplicationClose(expectedErr).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			gomock.InOrder(
 				tracer.EXPECT().ClosedConnection(expectedErr),
 				tracer.EXPECT().Close(),
@@ -491,7 +665,7 @@ var _ = Describe("Connection", func() {
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
 			packer.EXPECT().PackConnectionClose(expectedErr).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			gomock.InOrder(
 				tracer.EXPECT().ClosedConnection(expectedErr),
 				tracer.EXPECT().Close(),
@@ -540,7 +714,7 @@ var _ = Describe("Connection", func() {
 				close(returned)
 			}()
 			Consistently(returned).ShouldNot(BeClosed())
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			conn.shutdown()
@@ -575,7 +749,7 @@ var _ = Describe("Connection", func() {
 			// don't EXPECT any calls to packer.PackPacket()
 			conn.handlePacket(&receivedPacket{
 				rcvTime:    time.Now(),
-				remoteAddr: &net.UDPAddr{},
+				remoteAddr: remoteAddr,
 				buffer:     getPacketBuffer(),
 				data:       buf.Bytes(),
 			})
@@ -586,9 +760,10 @@ var _ = Describe("Connection", func() {
 		It("closes when the sendQueue encounters an error", func() {
 			conn.handshakeConfirmed = true
 			sconn := NewMockSendConn(mockCtrl)
-			sconn.EXPECT().Write(gomock.Any()).Return(io.ErrClosedPipe).AnyTimes()
+			sconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon).Return(io.ErrClosedPipe).AnyTimes()
 			conn.sendQueue = newSendQueue(sconn)
 			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
 			sph.EXPECT().GetLossDetectionTimeout().Return(time.Now().Add(time.Hour)).AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
@@ -821,7 +996,7 @@ var _ = Describe("Connection", func() {
 			// make the go routine return
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			conn.closeLocal(errors.New("close"))
 			Eventually(conn.Context().Done()).Should(BeClosed())
 		})
@@ -859,7 +1034,7 @@ var _ = Describe("Connection", func() {
 			expectReplaceWithClosed()
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			conn.closeLocal(errors.New("close"))
 			Eventually(conn.Context().Done()).Should(BeClosed())
 		})
@@ -898,7 +1073,7 @@ var _ = Describe("Connection", func() {
 			expectReplaceWithClosed()
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			conn.closeLocal(errors.New("close"))
 			Eventually(conn.Context().Done()).Should(BeClosed())
 		})
@@ -919,7 +1094,7 @@ var _ = Describe("Connection", func() {
 				close(done)
 			}()
 			expectReplaceWithClosed()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			packet := getPacket(&wire.ExtendedHeader{
 				Header:          wire.Header{DestConnectionID: srcConnID},
 				PacketNumberLen: protocol.PacketNumberLen1,
@@ -952,7 +1127,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			conn.shutdown()
 			Eventually(conn.Context().Done()).Should(BeClosed())
 		})
@@ -973,7 +1148,7 @@ var _ = Describe("Connection", func() {
 				close(done)
 			}()
 			expectReplaceWithClosed()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			packet := getPacket(&wire.ExtendedHeader{
 				Header:          wire.Header{DestConnectionID: srcConnID},
 				PacketNumberLen: protocol.PacketNumberLen1,
@@ -1049,15 +1224,251 @@ var _ = Describe("Connection", func() {
 		})
 
 		Context("updating the remote address", func() {
-			It("doesn't support connection migration", func() {
-				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(10), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* one PADDING frame */, nil)
+			var (
+				sph     *mockackhandler.MockSentPacketHandler
+				newConn *MockSendConn
+			)
+
+			getShortHeaderPacket := func(remoteAddr net.Addr, size int) *receivedPacket {
 				packet := getPacket(&wire.ExtendedHeader{
 					Header:          wire.Header{DestConnectionID: srcConnID},
 					PacketNumberLen: protocol.PacketNumberLen1,
 				}, nil)
-				packet.remoteAddr = &net.IPAddr{IP: net.IPv4(192, 168, 0, 100)}
-				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), protocol.ByteCount(len(packet.data)), gomock.Any())
-				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+				packet.data = append(packet.data, make([]byte, size-len(packet.data))...)
+				packet.remoteAddr = remoteAddr
+				return packet
+			}
+
+			expectPacket := func(pn protocol.PacketNumber, frames ...wire.Frame) {
+				var data []byte
+				for _, f := range frames {
+					var err error
+					data, err = f.Append(data, conn.version)
+					Expect(err).ToNot(HaveOccurred())
+				}
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero, data, nil)
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any())
+			}
+
+			expectPathProbePacket := func(size protocol.ByteCount) *[]ackhandler.Frame {
+				var frames []ackhandler.Frame
+				packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), size).DoAndReturn(func(_ protocol.ConnectionID, fs []ackhandler.Frame, size protocol.ByteCount) (*packedPacket, error) {
+					frames = fs
+					buffer := getPacketBuffer()
+					buffer.Data = buffer.Data[:size]
+					return &packedPacket{
+						buffer:         buffer,
+						packetContents: &packetContents{header: &wire.ExtendedHeader{PacketNumber: 10}, frames: fs},
+					}, nil
+				})
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				sph.EXPECT().SentPacket(gomock.Any())
+				newConn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
+				return &frames
+			}
+
+			BeforeEach(func() {
+				sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sph.EXPECT().ReceivedBytes(gomock.Any()).AnyTimes()
+				conn.sentPacketHandler = sph
+				conn.peerParams = &wire.TransportParameters{}
+				newConn = NewMockSendConn(mockCtrl)
+				newConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+				newConn.EXPECT().capabilities().AnyTimes()
+			})
+
+			It("doesn't migrate before the handshake is confirmed", func() {
+				expectPacket(10, &wire.PingFrame{})
+				Expect(conn.handlePacketImpl(getShortHeaderPacket(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 1234}, 100))).To(BeTrue())
+				Expect(conn.pathProbe).To(BeNil())
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			It("validates the new path, and migrates to it", func() {
+				conn.handshakeConfirmed = true
+				newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 1234}
+				mconn.EXPECT().WithRemoteAddr(newAddr, gomock.Any()).Return(newConn)
+				newConn.EXPECT().RemoteAddr().Return(newAddr).AnyTimes()
+				expectPacket(10, &wire.PingFrame{})
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingStarted, localAddr, newAddr)
+				Expect(conn.handlePacketImpl(getShortHeaderPacket(newAddr, 500))).To(BeTrue())
+				Expect(conn.pathProbe).ToNot(BeNil())
+				// packets are still sent to the old address
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+
+				frames := expectPathProbePacket(protocol.MinInitialPacketSize)
+				Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+				Expect(*frames).To(HaveLen(1))
+				challenge := (*frames)[0].Frame.(*wire.PathChallengeFrame)
+
+				// The IP address changed. Reset the congestion controller.
+				sph.EXPECT().OnConnectionMigration()
+				packer.EXPECT().SetMaxPacketSize(gomock.Any())
+				packer.EXPECT().HandleTransportParameters(conn.peerParams)
+				gomock.InOrder(
+					tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingSuccessful, localAddr, newAddr),
+					tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateMigrationComplete, localAddr, newAddr),
+				)
+				Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challenge.Data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				Expect(conn.pathProbe).To(BeNil())
+				Expect(conn.RemoteAddr()).To(Equal(newAddr))
+			})
+
+			It("doesn't let packets from another address abandon the path validation in progress right away", func() {
+				conn.handshakeConfirmed = true
+				newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 1234}
+				otherAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 200), Port: 4321}
+				mconn.EXPECT().WithRemoteAddr(newAddr, gomock.Any()).Return(newConn)
+				newConn.EXPECT().RemoteAddr().Return(newAddr).AnyTimes()
+				now := time.Now()
+				expectPacket(10, &wire.PingFrame{})
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingStarted, localAddr, newAddr)
+				p := getShortHeaderPacket(newAddr, 500)
+				p.rcvTime = now
+				Expect(conn.handlePacketImpl(p)).To(BeTrue())
+				probe := conn.pathProbe
+				Expect(probe).ToNot(BeNil())
+
+				// a packet from another address doesn't abandon the path validation
+				expectPacket(11, &wire.PingFrame{})
+				p = getShortHeaderPacket(otherAddr, 500)
+				p.rcvTime = now.Add(conn.pathValidationPTO() - time.Millisecond)
+				Expect(conn.handlePacketImpl(p)).To(BeTrue())
+				Expect(conn.pathProbe).To(Equal(probe))
+
+				// ... unless the path validation had enough time to complete
+				otherConn := NewMockSendConn(mockCtrl)
+				otherConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+				otherConn.EXPECT().RemoteAddr().Return(otherAddr).AnyTimes()
+				mconn.EXPECT().WithRemoteAddr(otherAddr, gomock.Any()).Return(otherConn)
+				expectPacket(12, &wire.PingFrame{})
+				gomock.InOrder(
+					tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingAbandoned, localAddr, newAddr),
+					tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingStarted, localAddr, otherAddr),
+				)
+				p = getShortHeaderPacket(otherAddr, 500)
+				p.rcvTime = now.Add(conn.pathValidationPTO())
+				Expect(conn.handlePacketImpl(p)).To(BeTrue())
+				Expect(conn.pathProbe).ToNot(Equal(probe))
+				Expect(probe.result).To(Receive(MatchError("peer moved to a different address")))
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			It("respects the anti-amplification limit", func() {
+				conn.handshakeConfirmed = true
+				newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 1234}
+				mconn.EXPECT().WithRemoteAddr(newAddr, gomock.Any()).Return(newConn)
+				newConn.EXPECT().RemoteAddr().Return(newAddr).AnyTimes()
+				expectPacket(10, &wire.PingFrame{})
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingStarted, localAddr, newAddr)
+				Expect(conn.handlePacketImpl(getShortHeaderPacket(newAddr, 15))).To(BeTrue())
+				Expect(conn.pathProbe).ToNot(BeNil())
+				// 3 * 15 bytes is not enough to send a PATH_CHALLENGE
+				Expect(conn.nextPathProbeTimeout()).To(Equal(conn.pathProbe.deadline))
+				Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+
+				expectPacket(11, &wire.PingFrame{})
+				Expect(conn.handlePacketImpl(getShortHeaderPacket(newAddr, 85))).To(BeTrue())
+				Expect(conn.nextPathProbeTimeout()).To(BeTemporally("<", time.Now()))
+				expectPathProbePacket(3 * 100)
+				Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+				Expect(conn.pathProbe.CanSend()).To(BeFalse())
+			})
+
+			It("responds to PATH_CHALLENGE frames on the new path, and only migrates after receiving a non-probing packet", func() {
+				conn.handshakeConfirmed = true
+				// only the port changed
+				newAddr := &net.UDPAddr{IP: remoteAddr.IP, Port: remoteAddr.Port + 1}
+				mconn.EXPECT().WithRemoteAddr(newAddr, gomock.Any()).Return(newConn)
+				newConn.EXPECT().RemoteAddr().Return(newAddr).AnyTimes()
+				expectPacket(10, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}})
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingStarted, localAddr, newAddr)
+				Expect(conn.handlePacketImpl(getShortHeaderPacket(newAddr, 1200))).To(BeTrue())
+
+				frames := expectPathProbePacket(protocol.MinInitialPacketSize)
+				Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+				Expect(*frames).To(HaveLen(2))
+				Expect((*frames)[0].Frame).To(Equal(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}))
+				challenge := (*frames)[1].Frame.(*wire.PathChallengeFrame)
+
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingSuccessful, localAddr, newAddr)
+				Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challenge.Data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				Expect(conn.pathProbe).ToNot(BeNil())
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+
+				// Only the port changed. Neither the congestion controller nor the MTU are reset.
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateMigrationComplete, localAddr, newAddr)
+				expectPacket(11, &wire.PingFrame{})
+				Expect(conn.handlePacketImpl(getShortHeaderPacket(newAddr, 100))).To(BeTrue())
+				Expect(conn.pathProbe).To(BeNil())
+				Expect(conn.RemoteAddr()).To(Equal(newAddr))
+			})
+
+			It("validates the path when the client migrates to the preferred address", func() {
+				conn.handshakeConfirmed = true
+				conn.rcvConn = &basicConn{PacketConn: NewMockPacketConn(mockCtrl)}
+				preferredAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 443}
+				pconn := NewMockPacketConn(mockCtrl)
+				pconn.EXPECT().LocalAddr().Return(preferredAddr).AnyTimes()
+				preferredConn := &basicConn{PacketConn: pconn}
+
+				// The client uses the same address. The packet is received on a different packet conn.
+				expectPacket(10, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}})
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingStarted, preferredAddr, remoteAddr)
+				p := getShortHeaderPacket(remoteAddr, 1200)
+				p.rcvConn = preferredConn
+				Expect(conn.handlePacketImpl(p)).To(BeTrue())
+				Expect(conn.pathProbe).ToNot(BeNil())
+
+				var frames []ackhandler.Frame
+				packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), gomock.Any()).DoAndReturn(func(_ protocol.ConnectionID, fs []ackhandler.Frame, size protocol.ByteCount) (*packedPacket, error) {
+					frames = fs
+					return &packedPacket{
+						buffer:         getPacketBuffer(),
+						packetContents: &packetContents{header: &wire.ExtendedHeader{PacketNumber: 10}, frames: fs},
+					}, nil
+				})
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				sph.EXPECT().SentPacket(gomock.Any())
+				// the packet is sent from the preferred address
+				pconn.EXPECT().WriteTo(gomock.Any(), remoteAddr)
+				Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+				Expect(frames).To(HaveLen(2))
+				challenge := frames[1].Frame.(*wire.PathChallengeFrame)
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingSuccessful, preferredAddr, remoteAddr)
+				Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challenge.Data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+
+				// The local address changed. Reset the congestion controller.
+				sph.EXPECT().OnConnectionMigration()
+				packer.EXPECT().SetMaxPacketSize(gomock.Any())
+				packer.EXPECT().HandleTransportParameters(conn.peerParams)
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateMigrationComplete, preferredAddr, remoteAddr)
+				expectPacket(11, &wire.PingFrame{})
+				p = getShortHeaderPacket(remoteAddr, 100)
+				p.rcvConn = preferredConn
+				Expect(conn.handlePacketImpl(p)).To(BeTrue())
+				Expect(conn.pathProbe).To(BeNil())
+				Expect(conn.LocalAddr()).To(Equal(preferredAddr))
+				Expect(conn.rcvConn).To(Equal(preferredConn))
+			})
+
+			It("doesn't migrate if the peer sent a non-probing packet with a higher packet number on the old path", func() {
+				conn.handshakeConfirmed = true
+				newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 1234}
+				mconn.EXPECT().WithRemoteAddr(newAddr, gomock.Any()).Return(newConn)
+				newConn.EXPECT().RemoteAddr().Return(newAddr).AnyTimes()
+				expectPacket(10, &wire.PingFrame{})
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingStarted, localAddr, newAddr)
+				Expect(conn.handlePacketImpl(getShortHeaderPacket(newAddr, 500))).To(BeTrue())
+				expectPacket(11, &wire.PingFrame{})
+				Expect(conn.handlePacketImpl(getShortHeaderPacket(remoteAddr, 500))).To(BeTrue())
+
+				frames := expectPathProbePacket(protocol.MinInitialPacketSize)
+				Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+				challenge := (*frames)[0].Frame.(*wire.PathChallengeFrame)
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingSuccessful, localAddr, newAddr)
+				Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challenge.Data}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
 			})
 		})
 
@@ -1200,7 +1611,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sender.EXPECT().Close()
@@ -1221,6 +1632,7 @@ var _ = Describe("Connection", func() {
 		It("sends packets", func() {
 			conn.handshakeConfirmed = true
 			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
 			sph.EXPECT().TimeUntilSend().AnyTimes()
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
@@ -1233,12 +1645,125 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackPacket(false).Return(nil, nil).AnyTimes()
 			sent := make(chan struct{})
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any()).Do(func(packet *packetBuffer) { close(sent) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).Do(func(packet *packetBuffer, _ uint16, _ protocol.ECN) { close(sent) })
 			tracer.EXPECT().SentPacket(p.header, p.buffer.Len(), nil, []logging.Frame{})
 			conn.scheduleSending()
 			Eventually(sent).Should(BeClosed())
 		})
 
+		It("sends ECN-marked packets, if the connection supports it", func() {
+			conn.handshakeConfirmed = true
+			ecnConn := NewMockSendConn(mockCtrl)
+			ecnConn.EXPECT().capabilities().Return(connCapabilities{ECN: true}).AnyTimes()
+			ecnConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+			ecnConn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
+			conn.conn.Migrate(ecnConn)
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
+			sph.EXPECT().TimeUntilSend().AnyTimes()
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().ECNMode(true).Return(protocol.ECT0)
+			sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) {
+				Expect(p.ECN).To(Equal(protocol.ECT0))
+			})
+			conn.sentPacketHandler = sph
+			runConn()
+			p := getPacket(1)
+			packer.EXPECT().PackPacket(false).Return(p, nil)
+			packer.EXPECT().PackPacket(false).Return(nil, nil).AnyTimes()
+			sent := make(chan struct{})
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECT0).Do(func(*packetBuffer, uint16, protocol.ECN) { close(sent) })
+			tracer.EXPECT().SentPacket(p.header, p.buffer.Len(), nil, []logging.Frame{})
+			conn.scheduleSending()
+			Eventually(sent).Should(BeClosed())
+			// the CONNECTION_CLOSE is sent on mconn
+			conn.conn.Migrate(mconn)
+		})
+
+		It("sends multiple packets in a single batch, if the connection supports GSO", func() {
+			conn.handshakeConfirmed = true
+			gsoConn := NewMockSendConn(mockCtrl)
+			gsoConn.EXPECT().capabilities().Return(connCapabilities{GSO: true}).AnyTimes()
+			gsoConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+			gsoConn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
+			conn.conn.Migrate(gsoConn)
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
+			sph.EXPECT().TimeUntilSend().AnyTimes()
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().SentPacket(gomock.Any()).Times(4)
+			conn.sentPacketHandler = sph
+			runConn()
+			appendPacket := func(pn protocol.PacketNumber, size protocol.ByteCount) func(*packetBuffer, protocol.ByteCount, bool) (*packetContents, error) {
+				return func(buf *packetBuffer, _ protocol.ByteCount, _ bool) (*packetContents, error) {
+					buf.Data = append(buf.Data, make([]byte, size)...)
+					return &packetContents{header: &wire.ExtendedHeader{PacketNumber: pn}, length: size}, nil
+				}
+			}
+			gomock.InOrder(
+				packer.EXPECT().AppendPacket(gomock.Any(), protocol.MaxPacketBufferSize, false).DoAndReturn(appendPacket(1, 1000)),
+				packer.EXPECT().AppendPacket(gomock.Any(), protocol.ByteCount(1000), false).DoAndReturn(appendPacket(2, 1000)),
+				packer.EXPECT().AppendPacket(gomock.Any(), protocol.ByteCount(1000), false).DoAndReturn(appendPacket(3, 1000)),
+				// a packet smaller than the segment size ends the batch
+				packer.EXPECT().AppendPacket(gomock.Any(), protocol.ByteCount(1000), false).DoAndReturn(appendPacket(4, 500)),
+			)
+			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), false).AnyTimes()
+			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
+			sent := make(chan struct{})
+			sender.EXPECT().Send(gomock.Any(), uint16(1000), protocol.ECNNon).Do(func(p *packetBuffer, _ uint16, _ protocol.ECN) {
+				Expect(p.Len()).To(BeEquivalentTo(3500))
+				close(sent)
+			})
+			conn.scheduleSending()
+			Eventually(sent).Should(BeClosed())
+			// the CONNECTION_CLOSE is sent on mconn
+			conn.conn.Migrate(mconn)
+		})
+
+		It("limits the number of packets sent in a single batch", func() {
+			conn.handshakeConfirmed = true
+			gsoConn := NewMockSendConn(mockCtrl)
+			gsoConn.EXPECT().capabilities().Return(connCapabilities{GSO: true}).AnyTimes()
+			gsoConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+			gsoConn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
+			conn.conn.Migrate(gsoConn)
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
+			sph.EXPECT().TimeUntilSend().AnyTimes()
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sph.EXPECT().SentPacket(gomock.Any()).AnyTimes()
+			conn.sentPacketHandler = sph
+			runConn()
+			var pn protocol.PacketNumber
+			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), false).DoAndReturn(func(buf *packetBuffer, _ protocol.ByteCount, _ bool) (*packetContents, error) {
+				pn++
+				buf.Data = append(buf.Data, make([]byte, 100)...)
+				return &packetContents{header: &wire.ExtendedHeader{PacketNumber: pn}, length: 100}, nil
+			}).Times(100)
+			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), false).AnyTimes()
+			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(100)
+			sent := make(chan struct{})
+			gomock.InOrder(
+				sender.EXPECT().Send(gomock.Any(), uint16(100), protocol.ECNNon).Do(func(p *packetBuffer, _ uint16, _ protocol.ECN) {
+					Expect(p.Len()).To(BeEquivalentTo(maxGSOSegments * 100))
+				}),
+				sender.EXPECT().Send(gomock.Any(), uint16(100), protocol.ECNNon).Do(func(p *packetBuffer, _ uint16, _ protocol.ECN) {
+					Expect(p.Len()).To(BeEquivalentTo((100 - maxGSOSegments) * 100))
+					close(sent)
+				}),
+			)
+			conn.scheduleSending()
+			Eventually(sent).Should(BeClosed())
+			// the CONNECTION_CLOSE is sent on mconn
+			conn.conn.Migrate(mconn)
+		})
+
 		It("doesn't send packets if there's nothing to send", func() {
 			conn.handshakeConfirmed = true
 			runConn()
@@ -1250,6 +1775,7 @@ var _ = Describe("Connection", func() {
 
 		It("sends ACK only packets", func() {
 			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
 			sph.EXPECT().TimeUntilSend().AnyTimes()
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAck)
@@ -1264,6 +1790,7 @@ var _ = Describe("Connection", func() {
 		It("adds a BLOCKED frame when it is connection-level flow control blocked", func() {
 			conn.handshakeConfirmed = true
 			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
 			sph.EXPECT().TimeUntilSend().AnyTimes()
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
@@ -1279,7 +1806,7 @@ var _ = Describe("Connection", func() {
 			conn.connFlowController = fc
 			runConn()
 			sent := make(chan struct{})
-			sender.EXPECT().Send(gomock.Any()).Do(func(packet *packetBuffer) { close(sent) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).Do(func(packet *packetBuffer, _ uint16, _ protocol.ECN) { close(sent) })
 			tracer.EXPECT().SentPacket(p.header, p.length, nil, []logging.Frame{})
 			conn.scheduleSending()
 			Eventually(sent).Should(BeClosed())
@@ -1289,6 +1816,7 @@ var _ = Describe("Connection", func() {
 
 		It("doesn't send when the SentPacketHandler doesn't allow it", func() {
 			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendNone).AnyTimes()
 			sph.EXPECT().TimeUntilSend().AnyTimes()
@@ -1322,6 +1850,7 @@ var _ = Describe("Connection", func() {
 
 				It("sends a probe packet", func() {
 					sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+					sph.EXPECT().GetStats().AnyTimes()
 					sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 					sph.EXPECT().TimeUntilSend().AnyTimes()
 					sph.EXPECT().SendMode().Return(sendMode)
@@ -1335,7 +1864,7 @@ var _ = Describe("Connection", func() {
 					conn.sentPacketHandler = sph
 					runConn()
 					sent := make(chan struct{})
-					sender.EXPECT().Send(gomock.Any()).Do(func(packet *packetBuffer) { close(sent) })
+					sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).Do(func(packet *packetBuffer, _ uint16, _ protocol.ECN) { close(sent) })
 					tracer.EXPECT().SentPacket(p.header, p.length, gomock.Any(), gomock.Any())
 					conn.scheduleSending()
 					Eventually(sent).Should(BeClosed())
@@ -1343,6 +1872,7 @@ var _ = Describe("Connection", func() {
 
 				It("sends a PING as a probe packet", func() {
 					sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+					sph.EXPECT().GetStats().AnyTimes()
 					sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 					sph.EXPECT().TimeUntilSend().AnyTimes()
 					sph.EXPECT().SendMode().Return(sendMode)
@@ -1356,7 +1886,7 @@ var _ = Describe("Connection", func() {
 					conn.sentPacketHandler = sph
 					runConn()
 					sent := make(chan struct{})
-					sender.EXPECT().Send(gomock.Any()).Do(func(packet *packetBuffer) { close(sent) })
+					sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).Do(func(packet *packetBuffer, _ uint16, _ protocol.ECN) { close(sent) })
 					tracer.EXPECT().SentPacket(p.header, p.length, gomock.Any(), gomock.Any())
 					conn.scheduleSending()
 					Eventually(sent).Should(BeClosed())
@@ -1377,6 +1907,7 @@ var _ = Describe("Connection", func() {
 		BeforeEach(func() {
 			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			conn.handshakeConfirmed = true
 			conn.handshakeComplete = true
@@ -1392,7 +1923,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sender.EXPECT().Close()
@@ -1409,7 +1940,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackPacket(false).Return(getPacket(10), nil)
 			packer.EXPECT().PackPacket(false).Return(getPacket(11), nil)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any()).Times(2)
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).Times(2)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1426,7 +1957,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackPacket(false).Return(getPacket(10), nil)
 			packer.EXPECT().PackPacket(false).Return(nil, nil)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any())
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1443,7 +1974,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny)
 			packer.EXPECT().PackPacket(true).Return(getPacket(10), nil)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any())
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1462,7 +1993,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().SendMode().Return(ackhandler.SendAck)
 			packer.EXPECT().PackPacket(false).Return(getPacket(100), nil)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any())
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1489,7 +2020,7 @@ var _ = Describe("Connection", func() {
 			)
 			written := make(chan struct{}, 2)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { written <- struct{}{} }).Times(2)
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { written <- struct{}{} }).Times(2)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1512,7 +2043,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackPacket(false).Return(getPacket(1002), nil)
 			written := make(chan struct{}, 3)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { written <- struct{}{} }).Times(3)
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { written <- struct{}{} }).Times(3)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1541,7 +2072,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
 			packer.EXPECT().PackPacket(false).Return(getPacket(1000), nil)
 			packer.EXPECT().PackPacket(false).Return(nil, nil)
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { close(written) })
 			available <- struct{}{}
 			Eventually(written).Should(BeClosed())
 		})
@@ -1564,7 +2095,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
 			packer.EXPECT().PackPacket(false).Return(getPacket(1000), nil)
 			packer.EXPECT().PackPacket(false).Return(nil, nil)
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { close(written) })
 
 			conn.scheduleSending()
 			time.Sleep(scaleDuration(50 * time.Millisecond))
@@ -1580,7 +2111,7 @@ var _ = Describe("Connection", func() {
 			written := make(chan struct{}, 1)
 			sender.EXPECT().WouldBlock()
 			sender.EXPECT().WouldBlock().Return(true).Times(2)
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { written <- struct{}{} })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { written <- struct{}{} })
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
@@ -1599,7 +2130,7 @@ var _ = Describe("Connection", func() {
 			sender.EXPECT().WouldBlock().AnyTimes()
 			packer.EXPECT().PackPacket(false).Return(getPacket(1001), nil)
 			packer.EXPECT().PackPacket(false).Return(nil, nil)
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { written <- struct{}{} })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { written <- struct{}{} })
 			available <- struct{}{}
 			Eventually(written).Should(Receive())
 
@@ -1633,7 +2164,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().SendMode().Return(ackhandler.SendNone)
 			written := make(chan struct{}, 1)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(p *packetBuffer) { written <- struct{}{} })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).DoAndReturn(func(p *packetBuffer, _ uint16, _ protocol.ECN) { written <- struct{}{} })
 			mtuDiscoverer.EXPECT().ShouldSendProbe(gomock.Any()).Return(true)
 			ping := ackhandler.Frame{Frame: &wire.PingFrame{}}
 			mtuDiscoverer.EXPECT().GetPing().Return(ping, protocol.ByteCount(1234))
@@ -1665,7 +2196,7 @@ var _ = Describe("Connection", func() {
 			streamManager.EXPECT().CloseWithError(gomock.Any())
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			sender.EXPECT().Close()
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
@@ -1675,6 +2206,7 @@ var _ = Describe("Connection", func() {
 
 		It("sends when scheduleSending is called", func() {
 			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().TimeUntilSend().AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
@@ -1693,7 +2225,7 @@ var _ = Describe("Connection", func() {
 			time.Sleep(50 * time.Millisecond)
 			// only EXPECT calls after scheduleSending is called
 			written := make(chan struct{})
-			sender.EXPECT().Send(gomock.Any()).Do(func(*packetBuffer) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).Do(func(*packetBuffer, uint16, protocol.ECN) { close(written) })
 			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			conn.scheduleSending()
 			Eventually(written).Should(BeClosed())
@@ -1703,6 +2235,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackPacket(false).Return(getPacket(1234), nil)
 			packer.EXPECT().PackPacket(false).Return(nil, nil)
 			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
@@ -1717,7 +2250,7 @@ var _ = Describe("Connection", func() {
 			conn.receivedPacketHandler = rph
 
 			written := make(chan struct{})
-			sender.EXPECT().Send(gomock.Any()).Do(func(*packetBuffer) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), protocol.ECNNon).Do(func(*packetBuffer, uint16, protocol.ECN) { close(written) })
 			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			go func() {
 				defer GinkgoRecover()
@@ -1732,6 +2265,7 @@ var _ = Describe("Connection", func() {
 		conn.handshakeComplete = false
 		conn.handshakeConfirmed = false
 		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+		sph.EXPECT().GetStats().AnyTimes()
 		conn.sentPacketHandler = sph
 		buffer := getPacketBuffer()
 		buffer.Data = append(buffer.Data, []byte("foobar")...)
@@ -1787,7 +2321,7 @@ var _ = Describe("Connection", func() {
 		)
 
 		sent := make(chan struct{})
-		mconn.EXPECT().Write([]byte("foobar")).Do(func([]byte) { close(sent) })
+		mconn.EXPECT().Write([]byte("foobar"), gomock.Any(), protocol.ECNNon).Do(func([]byte, uint16, protocol.ECN) { close(sent) })
 
 		go func() {
 			defer GinkgoRecover()
@@ -1803,7 +2337,7 @@ var _ = Describe("Connection", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		conn.shutdown()
@@ -1814,6 +2348,7 @@ var _ = Describe("Connection", func() {
 		packer.EXPECT().PackCoalescedPacket(false).AnyTimes()
 		finishHandshake := make(chan struct{})
 		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+		sph.EXPECT().GetStats().AnyTimes()
 		conn.sentPacketHandler = sph
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 		sph.EXPECT().TimeUntilSend().AnyTimes()
@@ -1838,7 +2373,7 @@ var _ = Describe("Connection", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		conn.shutdown()
@@ -1883,7 +2418,7 @@ var _ = Describe("Connection", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		conn.shutdown()
@@ -1905,7 +2440,7 @@ var _ = Describe("Connection", func() {
 		}()
 		handshakeCtx := conn.HandshakeComplete()
 		Consistently(handshakeCtx.Done()).ShouldNot(BeClosed())
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 		conn.closeLocal(errors.New("handshake error"))
 		Consistently(handshakeCtx.Done()).ShouldNot(BeClosed())
 		Eventually(conn.Context().Done()).Should(BeClosed())
@@ -1913,13 +2448,14 @@ var _ = Describe("Connection", func() {
 
 	It("sends a HANDSHAKE_DONE frame when the handshake completes", func() {
 		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+		sph.EXPECT().GetStats().AnyTimes()
 		sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 		sph.EXPECT().TimeUntilSend().AnyTimes()
 		sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
 		sph.EXPECT().SetHandshakeConfirmed()
 		sph.EXPECT().SentPacket(gomock.Any())
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 		tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		conn.sentPacketHandler = sph
 		done := make(chan struct{})
@@ -1942,7 +2478,7 @@ var _ = Describe("Connection", func() {
 			cryptoSetup.EXPECT().RunHandshake()
 			cryptoSetup.EXPECT().SetHandshakeConfirmed()
 			cryptoSetup.EXPECT().GetSessionTicket()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			close(conn.handshakeCompleteChan)
 			conn.run()
 		}()
@@ -1970,7 +2506,7 @@ var _ = Describe("Connection", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		conn.shutdown()
@@ -1994,7 +2530,7 @@ var _ = Describe("Connection", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		Expect(conn.CloseWithError(0x1337, testErr.Error())).To(Succeed())
@@ -2022,6 +2558,118 @@ var _ = Describe("Connection", func() {
 			conn.handleTransportParameters(params)
 			Expect(conn.earlyConnReady()).To(BeClosed())
 		})
+
+		It("limits the size of datagrams", func() {
+			Expect(conn.MaxDatagramSize()).To(BeZero())
+			params := &wire.TransportParameters{
+				MaxDatagramFrameSize:      1000,
+				InitialSourceConnectionID: destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().HandleTransportParameters(params)
+			connRunner.EXPECT().GetStatelessResetToken(gomock.Any()).AnyTimes()
+			connRunner.EXPECT().Add(gomock.Any(), conn).AnyTimes()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+			// limited by the max_datagram_frame_size: 1000 bytes, minus 3 bytes for the frame header
+			Expect(conn.MaxDatagramSize()).To(BeEquivalentTo(997))
+
+			conn.peerParams.MaxDatagramFrameSize = protocol.MaxByteCount
+			mtuDiscoverer := NewMockMtuDiscoverer(mockCtrl)
+			mtuDiscoverer.EXPECT().CurrentSize().Return(protocol.ByteCount(1400))
+			conn.mtuDiscoverer = mtuDiscoverer
+			conn.updateMaxDatagramSize()
+			// limited by the MTU: 1400 bytes, minus the largest possible short header (25 bytes), the AEAD overhead (16 bytes), and the frame header (3 bytes)
+			Expect(conn.MaxDatagramSize()).To(BeEquivalentTo(1356))
+			Expect(conn.SendMessage(make([]byte, 1357))).To(MatchError(&DatagramTooLargeError{MaxDataLen: 1356}))
+		})
+	})
+
+	Context("compatible version negotiation", func() {
+		var params *wire.TransportParameters
+
+		BeforeEach(func() {
+			params = &wire.TransportParameters{
+				InitialSourceConnectionID: destConnID,
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     protocol.Version1,
+					AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.Version2},
+				},
+			}
+			conn.origVersion = protocol.Version1
+			conn.version = protocol.Version1
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().HandleTransportParameters(params)
+			connRunner.EXPECT().GetStatelessResetToken(gomock.Any()).AnyTimes()
+			connRunner.EXPECT().Add(gomock.Any(), conn).AnyTimes()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+		})
+
+		It("switches to a compatible version that it prefers", func() {
+			conn.config.Versions = []protocol.VersionNumber{protocol.Version2, protocol.Version1}
+			packer.EXPECT().SetVersion(protocol.Version2)
+			cryptoSetup.EXPECT().ChangeVersion(protocol.Version2)
+			conn.handleTransportParameters(params)
+			Expect(conn.GetVersion()).To(Equal(protocol.Version2))
+		})
+
+		It("accepts Initial packets using the original version until the handshake completes", func() {
+			unpacker := NewMockUnpacker(mockCtrl)
+			conn.unpacker = unpacker
+			conn.handshakeComplete = false
+			conn.receivedFirstPacket = true
+			conn.config.Versions = []protocol.VersionNumber{protocol.Version2, protocol.Version1}
+			packer.EXPECT().SetVersion(protocol.Version2)
+			cryptoSetup.EXPECT().ChangeVersion(protocol.Version2)
+			conn.handleTransportParameters(params)
+			Expect(conn.GetVersion()).To(Equal(protocol.Version2))
+
+			getInitialPacket := func() *receivedPacket {
+				hdr := &wire.ExtendedHeader{
+					Header: wire.Header{
+						IsLongHeader:     true,
+						Type:             protocol.PacketTypeInitial,
+						DestConnectionID: srcConnID,
+						SrcConnectionID:  destConnID,
+						Version:          protocol.Version1,
+						Length:           2,
+					},
+					PacketNumberLen: protocol.PacketNumberLen1,
+				}
+				buf := &bytes.Buffer{}
+				Expect(hdr.Write(buf, protocol.Version1)).To(Succeed())
+				return &receivedPacket{data: append(buf.Bytes(), 0), buffer: getPacketBuffer(), rcvTime: time.Now()}
+			}
+
+			unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(hdr *wire.Header, _ time.Time, _ []byte) (*unpackedPacket, error) {
+				Expect(hdr.Version).To(Equal(protocol.Version1))
+				return &unpackedPacket{
+					hdr:             &wire.ExtendedHeader{Header: *hdr},
+					data:            []byte{0}, // one PADDING frame
+					encryptionLevel: protocol.EncryptionInitial,
+				}, nil
+			})
+			tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any())
+			Expect(conn.handlePacketImpl(getInitialPacket())).To(BeTrue())
+			Expect(conn.GetVersion()).To(Equal(protocol.Version2))
+
+			conn.handshakeComplete = true
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, gomock.Any(), logging.PacketDropUnexpectedVersion)
+			Expect(conn.handlePacketImpl(getInitialPacket())).To(BeFalse())
+		})
+
+		It("keeps the version if it is preferred", func() {
+			conn.config.Versions = []protocol.VersionNumber{protocol.Version1, protocol.Version2}
+			conn.handleTransportParameters(params)
+			Expect(conn.GetVersion()).To(Equal(protocol.Version1))
+		})
+
+		It("doesn't switch to a version that the client doesn't support", func() {
+			conn.config.Versions = []protocol.VersionNumber{protocol.Version2, protocol.Version1}
+			params.VersionInformation.AvailableVersions = []protocol.VersionNumber{protocol.Version1}
+			conn.handleTransportParameters(params)
+			Expect(conn.GetVersion()).To(Equal(protocol.Version1))
+		})
 	})
 
 	Context("keep-alives", func() {
@@ -2055,7 +2703,7 @@ var _ = Describe("Connection", func() {
 			streamManager.EXPECT().CloseWithError(gomock.Any())
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			conn.shutdown()
@@ -2191,7 +2839,7 @@ var _ = Describe("Connection", func() {
 			// make the go routine return
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			conn.shutdown()
 			Eventually(conn.Context().Done()).Should(BeClosed())
 		})
@@ -2268,7 +2916,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			conn.shutdown()
@@ -2389,6 +3037,7 @@ var _ = Describe("Client Connection", func() {
 		mconn = NewMockSendConn(mockCtrl)
 		mconn.EXPECT().RemoteAddr().Return(&net.UDPAddr{}).AnyTimes()
 		mconn.EXPECT().LocalAddr().Return(&net.UDPAddr{}).AnyTimes()
+		mconn.EXPECT().capabilities().AnyTimes()
 		if tlsConf == nil {
 			tlsConf = &tls.Config{}
 		}
@@ -2416,6 +3065,8 @@ var _ = Describe("Client Connection", func() {
 		packer = NewMockPacker(mockCtrl)
 		conn.packer = packer
 		cryptoSetup = mocks.NewMockCryptoSetup(mockCtrl)
+		// the run loop updates the snapshot of the connection's statistics
+		cryptoSetup.EXPECT().KeyPhase().AnyTimes()
 		conn.cryptoStreamHandler = cryptoSetup
 		conn.sentFirstPacket = true
 	})
@@ -2453,7 +3104,7 @@ var _ = Describe("Client Connection", func() {
 		packer.EXPECT().PackApplicationClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		expectReplaceWithClosed()
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		conn.shutdown()
@@ -2488,6 +3139,85 @@ var _ = Describe("Client Connection", func() {
 		Expect(conn.handleLongHeaderPacket(&receivedPacket{buffer: getPacketBuffer()}, hdr)).To(BeTrue())
 	})
 
+	Context("compatible version negotiation", func() {
+		var unpacker *MockUnpacker
+
+		getInitialPacket := func(v protocol.VersionNumber) *receivedPacket {
+			buf := &bytes.Buffer{}
+			Expect((&wire.ExtendedHeader{
+				Header: wire.Header{
+					IsLongHeader:     true,
+					Type:             protocol.PacketTypeInitial,
+					SrcConnectionID:  destConnID,
+					DestConnectionID: srcConnID,
+					Length:           2 + 6,
+					Version:          v,
+				},
+				PacketNumberLen: protocol.PacketNumberLen2,
+			}).Write(buf, v)).To(Succeed())
+			return &receivedPacket{
+				data:   append(buf.Bytes(), []byte("foobar")...),
+				buffer: getPacketBuffer(),
+			}
+		}
+
+		BeforeEach(func() {
+			quicConf.Versions = []protocol.VersionNumber{protocol.Version1, protocol.Version2}
+		})
+
+		JustBeforeEach(func() {
+			unpacker = NewMockUnpacker(mockCtrl)
+			conn.unpacker = unpacker
+			conn.origVersion = protocol.Version1
+			conn.version = protocol.Version1
+		})
+
+		It("switches to the version used by the server's first Initial packet", func() {
+			gomock.InOrder(
+				packer.EXPECT().SetVersion(protocol.Version2),
+				cryptoSetup.EXPECT().ChangeVersion(protocol.Version2),
+				unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(hdr *wire.Header, _ time.Time, _ []byte) (*unpackedPacket, error) {
+					Expect(hdr.Version).To(Equal(protocol.Version2))
+					return &unpackedPacket{
+						hdr:             &wire.ExtendedHeader{Header: *hdr},
+						data:            []byte{0}, // one PADDING frame
+						encryptionLevel: protocol.EncryptionInitial,
+					}, nil
+				}),
+			)
+			tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any())
+			Expect(conn.handlePacketImpl(getInitialPacket(protocol.Version2))).To(BeTrue())
+			Expect(conn.GetVersion()).To(Equal(protocol.Version2))
+		})
+
+		It("switches back if the packet can't be decrypted", func() {
+			gomock.InOrder(
+				packer.EXPECT().SetVersion(protocol.Version2),
+				cryptoSetup.EXPECT().ChangeVersion(protocol.Version2),
+				unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, handshake.ErrDecryptionFailed),
+				packer.EXPECT().SetVersion(protocol.Version1),
+				cryptoSetup.EXPECT().ChangeVersion(protocol.Version1),
+			)
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, gomock.Any(), logging.PacketDropPayloadDecryptError)
+			Expect(conn.handlePacketImpl(getInitialPacket(protocol.Version2))).To(BeFalse())
+			Expect(conn.GetVersion()).To(Equal(protocol.Version1))
+		})
+
+		It("doesn't switch to a version that it doesn't support", func() {
+			conn.config.Versions = []protocol.VersionNumber{protocol.Version1}
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, gomock.Any(), logging.PacketDropUnexpectedVersion)
+			Expect(conn.handlePacketImpl(getInitialPacket(protocol.Version2))).To(BeFalse())
+			Expect(conn.GetVersion()).To(Equal(protocol.Version1))
+		})
+
+		It("doesn't switch versions after receiving the first packet", func() {
+			conn.receivedFirstPacket = true
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, gomock.Any(), logging.PacketDropUnexpectedVersion)
+			Expect(conn.handlePacketImpl(getInitialPacket(protocol.Version2))).To(BeFalse())
+			Expect(conn.GetVersion()).To(Equal(protocol.Version1))
+		})
+	})
+
 	It("handles HANDSHAKE_DONE frames", func() {
 		conn.peerParams = &wire.TransportParameters{}
 		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
@@ -2528,6 +3258,235 @@ var _ = Describe("Client Connection", func() {
 		Eventually(areConnsRunning).Should(BeFalse())
 	})
 
+	Context("connection migration", func() {
+		var (
+			sph        *mockackhandler.MockSentPacketHandler
+			newConn    *MockSendConn
+			newRunner  *MockConnRunner
+			probe      *pathProbe
+			resetToken protocol.StatelessResetToken
+		)
+		newConnID := protocol.ParseConnectionID([]byte{1, 3, 3, 7})
+		newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+
+		JustBeforeEach(func() {
+			sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			conn.sentPacketHandler = sph
+			conn.handshakeConfirmed = true
+			conn.peerParams = &wire.TransportParameters{}
+			resetToken = protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+			Expect(conn.connIDManager.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        newConnID,
+				StatelessResetToken: resetToken,
+			})).To(Succeed())
+			newConn = NewMockSendConn(mockCtrl)
+			newConn.EXPECT().LocalAddr().Return(newAddr).AnyTimes()
+			newConn.EXPECT().capabilities().AnyTimes()
+			newConn.EXPECT().RemoteAddr().Return(&net.UDPAddr{}).AnyTimes()
+			newRunner = NewMockConnRunner(mockCtrl)
+			probe = newPathProbe(newConn, newRunner)
+			conn.pathProber.queue <- probe
+		})
+
+		expectPathProbe := func() {
+			connRunner.EXPECT().AddResetToken(resetToken, conn)
+			newRunner.EXPECT().Add(srcConnID, conn)
+			newRunner.EXPECT().AddResetToken(resetToken, conn)
+			tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingStarted, newAddr, &net.UDPAddr{})
+		}
+
+		packPathProbe := func(_ protocol.ConnectionID, frames []ackhandler.Frame, _ protocol.ByteCount) (*packedPacket, error) {
+			return &packedPacket{
+				buffer: getPacketBuffer(),
+				packetContents: &packetContents{
+					header: &wire.ExtendedHeader{PacketNumber: 10},
+					frames: frames,
+				},
+			}, nil
+		}
+
+		It("probes the new path and migrates when the PATH_RESPONSE is received", func() {
+			expectPathProbe()
+			var challengeData [8]byte
+			packer.EXPECT().PackPathProbePacket(newConnID, gomock.Any(), protocol.ByteCount(protocol.MinInitialPacketSize)).DoAndReturn(func(connID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount) (*packedPacket, error) {
+				Expect(frames).To(HaveLen(1))
+				challengeData = frames[0].Frame.(*wire.PathChallengeFrame).Data
+				return packPathProbe(connID, frames, size)
+			})
+			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any())
+			newConn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
+			Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+			Expect(conn.pathProbe).To(Equal(probe))
+			Expect(conn.LocalAddr()).To(Equal(&net.UDPAddr{}))
+
+			// a PATH_RESPONSE that doesn't match the PATH_CHALLENGE is ignored
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(conn.pathProbe).To(Equal(probe))
+
+			sph.EXPECT().OnConnectionMigration()
+			packer.EXPECT().SetMaxPacketSize(gomock.Any())
+			packer.EXPECT().HandleTransportParameters(conn.peerParams)
+			// the connection is removed from the old packet conn
+			connRunner.EXPECT().Remove(srcConnID)
+			connRunner.EXPECT().RemoveResetToken(resetToken)
+			gomock.InOrder(
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingSuccessful, newAddr, &net.UDPAddr{}),
+				tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateMigrationComplete, newAddr, &net.UDPAddr{}),
+			)
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challengeData}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(conn.pathProbe).To(BeNil())
+			Expect(probe.result).To(Receive(BeNil()))
+			Expect(conn.LocalAddr()).To(Equal(newAddr))
+			Expect(conn.connIDManager.Get()).To(Equal(newConnID))
+		})
+
+		It("resends the PATH_CHALLENGE and gives up when path validation times out", func() {
+			expectPathProbe()
+			packer.EXPECT().PackPathProbePacket(newConnID, gomock.Any(), gomock.Any()).DoAndReturn(packPathProbe).Times(protocol.MaxPathChallenges)
+			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(protocol.MaxPathChallenges)
+			sph.EXPECT().SentPacket(gomock.Any()).Times(protocol.MaxPathChallenges)
+			newConn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon).Times(protocol.MaxPathChallenges)
+			now := time.Now()
+			Expect(conn.handlePathProbes(now)).To(Succeed())
+			deadline := probe.deadline
+			Expect(deadline).To(BeTemporally(">", now))
+			for i := 1; i < protocol.MaxPathChallenges; i++ {
+				// nothing is sent before the timer expires
+				Expect(conn.handlePathProbes(conn.nextPathProbeTimeout().Add(-time.Millisecond))).To(Succeed())
+				Expect(probe.challenges).To(HaveLen(i))
+				Expect(conn.handlePathProbes(conn.nextPathProbeTimeout())).To(Succeed())
+			}
+			Expect(probe.challenges).To(HaveLen(protocol.MaxPathChallenges))
+			Expect(conn.nextPathProbeTimeout()).To(Equal(deadline))
+
+			connRunner.EXPECT().RemoveResetToken(resetToken)
+			newRunner.EXPECT().Remove(srcConnID)
+			newRunner.EXPECT().RemoveResetToken(resetToken)
+			tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingAbandoned, newAddr, &net.UDPAddr{})
+			Expect(conn.handlePathProbes(deadline)).To(Succeed())
+			Expect(conn.pathProbe).To(BeNil())
+			Expect(probe.result).To(Receive(MatchError("path validation timed out")))
+			Expect(conn.LocalAddr()).To(Equal(&net.UDPAddr{}))
+			Expect(conn.connIDManager.Get()).To(Equal(destConnID))
+		})
+
+		It("gives up when sending on the new path fails", func() {
+			expectPathProbe()
+			packer.EXPECT().PackPathProbePacket(newConnID, gomock.Any(), gomock.Any()).DoAndReturn(packPathProbe)
+			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any())
+			newConn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon).Return(errors.New("test error"))
+			connRunner.EXPECT().RemoveResetToken(resetToken)
+			newRunner.EXPECT().Remove(srcConnID)
+			newRunner.EXPECT().RemoveResetToken(resetToken)
+			tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingAbandoned, newAddr, &net.UDPAddr{})
+			Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+			Expect(conn.pathProbe).To(BeNil())
+			Expect(probe.result).To(Receive(MatchError("sending on the new path failed: test error")))
+		})
+
+		It("refuses to migrate if the peer disabled active migration", func() {
+			conn.peerParams.DisableActiveMigration = true
+			Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+			Expect(conn.pathProbe).To(BeNil())
+			Expect(probe.result).To(Receive(MatchError("peer disabled active connection migration")))
+		})
+
+		It("refuses to migrate before the handshake is confirmed", func() {
+			conn.handshakeConfirmed = false
+			Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+			Expect(conn.pathProbe).To(BeNil())
+			Expect(probe.result).To(Receive(MatchError("can't migrate before the handshake is confirmed")))
+		})
+
+		Context("migrating to a new packet conn", func() {
+			var (
+				mockMultiplexer *MockMultiplexer
+				origMultiplexer multiplexer
+				pconn           *MockPacketConn
+			)
+
+			JustBeforeEach(func() {
+				mockMultiplexer = NewMockMultiplexer(mockCtrl)
+				origMultiplexer = getMultiplexer()
+				connMuxer = mockMultiplexer
+				pconn = NewMockPacketConn(mockCtrl)
+				pconn.EXPECT().LocalAddr().Return(newAddr).AnyTimes()
+				// replace the path probe queued in the JustBeforeEach above
+				<-conn.pathProber.queue
+				probe = newMigrationPathProbe(pconn, &net.UDPAddr{})
+				conn.pathProber.queue <- probe
+			})
+
+			AfterEach(func() {
+				connMuxer = origMultiplexer
+			})
+
+			It("doesn't register the packet conn if the connection can't migrate", func() {
+				conn.peerParams.DisableActiveMigration = true
+				Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+				Expect(conn.pathProbe).To(BeNil())
+				Expect(probe.result).To(Receive(MatchError("peer disabled active connection migration")))
+			})
+
+			It("registers the packet conn when path validation starts", func() {
+				// the connection ID for the new path is reserved before the packet conn is registered
+				connRunner.EXPECT().AddResetToken(resetToken, conn)
+				mockMultiplexer.EXPECT().AddConn(pconn, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("test error"))
+				Expect(conn.handlePathProbes(time.Now())).To(Succeed())
+				Expect(conn.pathProbe).To(BeNil())
+				Expect(probe.result).To(Receive(MatchError("test error")))
+			})
+		})
+	})
+
+	Context("migrating to the preferred address", func() {
+		preferredConnID := protocol.ParseConnectionID([]byte{1, 3, 3, 7})
+		resetToken := protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+
+		JustBeforeEach(func() {
+			conn.handshakeConfirmed = true
+			conn.peerParams = &wire.TransportParameters{
+				// doesn't apply to migrating to the preferred address
+				DisableActiveMigration: true,
+				PreferredAddress: &wire.PreferredAddress{
+					IPv4:                net.IPv4(192, 0, 2, 1).To4(),
+					IPv4Port:            443,
+					IPv6:                net.ParseIP("2001:db8::1"),
+					IPv6Port:            443,
+					ConnectionID:        preferredConnID,
+					StatelessResetToken: resetToken,
+				},
+			}
+			Expect(conn.connIDManager.AddFromPreferredAddress(preferredConnID, resetToken)).To(Succeed())
+		})
+
+		It("starts validating the path to the preferred address", func() {
+			preferredAddr := &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 443}
+			newConn := NewMockSendConn(mockCtrl)
+			newConn.EXPECT().LocalAddr().Return(&net.UDPAddr{}).AnyTimes()
+			newConn.EXPECT().capabilities().AnyTimes()
+			newConn.EXPECT().RemoteAddr().Return(preferredAddr).AnyTimes()
+			// the handshake was performed over IPv6
+			mconn.EXPECT().WithRemoteAddr(preferredAddr, nil).Return(newConn)
+			connRunner.EXPECT().AddResetToken(resetToken, conn)
+			tracer.EXPECT().UpdatedMigrationState(logging.MigrationStateProbingStarted, &net.UDPAddr{}, preferredAddr)
+			conn.probePreferredAddress()
+			Expect(conn.pathProbe).ToNot(BeNil())
+			Expect(conn.pathProbe.toPreferredAddress).To(BeTrue())
+			Expect(conn.pathProbe.connID).To(Equal(preferredConnID))
+		})
+
+		It("doesn't migrate if the server didn't send an address of the same address family", func() {
+			conn.peerParams.PreferredAddress.IPv6 = net.IPv6zero
+			conn.peerParams.PreferredAddress.IPv6Port = 0
+			conn.probePreferredAddress()
+			Expect(conn.pathProbe).To(BeNil())
+		})
+	})
+
 	Context("handling tokens", func() {
 		var mockTokenStore *MockTokenStore
 
@@ -2560,6 +3519,7 @@ var _ = Describe("Client Connection", func() {
 
 		It("closes and returns the right error", func() {
 			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetStats().AnyTimes()
 			conn.sentPacketHandler = sph
 			sph.EXPECT().ReceivedBytes(gomock.Any())
 			sph.EXPECT().PeekPacketNumber(protocol.EncryptionInitial).Return(protocol.PacketNumber(128), protocol.PacketNumberLen4)
@@ -2716,7 +3676,7 @@ var _ = Describe("Client Connection", func() {
 					packer.EXPECT().PackConnectionClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil).MaxTimes(1)
 				}
 				cryptoSetup.EXPECT().Close()
-				mconn.EXPECT().Write(gomock.Any())
+				mconn.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon)
 				gomock.InOrder(
 					tracer.EXPECT().ClosedConnection(gomock.Any()),
 					tracer.EXPECT().Close(),
@@ -2841,6 +3801,66 @@ var _ = Describe("Client Connection", func() {
 			})))
 		})
 
+		It("errors if the server's chosen version doesn't match the version in use", func() {
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				StatelessResetToken:             &protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     protocol.Version2,
+					AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.Version2},
+				},
+			}
+			conn.version = protocol.Version1
+			expectClose(false)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+			Eventually(errChan).Should(Receive(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.VersionNegotiationErrorErrorCode,
+				ErrorMessage: "expected chosen version to equal v1, is v2",
+			})))
+		})
+
+		It("errors if the server switched the version without sending a version_information", func() {
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				StatelessResetToken:             &protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			}
+			conn.origVersion = protocol.Version1
+			conn.version = protocol.Version2
+			expectClose(false)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+			Eventually(errChan).Should(Receive(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.VersionNegotiationErrorErrorCode,
+				ErrorMessage: "server switched the version without sending a version_information",
+			})))
+		})
+
+		It("detects a version downgrade after a Version Negotiation packet", func() {
+			conn.config.Versions = []protocol.VersionNumber{protocol.Version2, protocol.Version1}
+			conn.versionNegotiated = true
+			conn.origVersion = protocol.Version1
+			conn.version = protocol.Version1
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				StatelessResetToken:             &protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     protocol.Version1,
+					AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.Version2},
+				},
+			}
+			expectClose(false)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+			Eventually(errChan).Should(Receive(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.VersionNegotiationErrorErrorCode,
+				ErrorMessage: "version downgrade detected (using v1, server supports [v1 v2])",
+			})))
+		})
+
 		It("errors if the transport parameters contain a wrong original_destination_connection_id", func() {
 			conn.origDestConnID = protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef})
 			params := &wire.TransportParameters{
@@ -1,75 +1,175 @@
 package quic
 
 import (
+	"context"
+	"sync"
+
+	"github.com/lucas-clemente/quic-go/internal/ackhandler"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
 
+type queuedDatagram struct {
+	frame   *wire.DatagramFrame
+	onAcked func()
+	onLost  func()
+
+	dequeued chan struct{} // only set for datagrams queued by AddAndWait
+}
+
 type datagramQueue struct {
-	sendQueue chan *wire.DatagramFrame
-	nextFrame *wire.DatagramFrame
-	rcvQueue  chan []byte
+	mutex       sync.Mutex
+	sendQueue   []*queuedDatagram
+	maxQueueLen int
+	dropPolicy  DatagramDropPolicy
+
+	// addAndWaitSlot limits the number of datagrams queued by AddAndWait to one at a time
+	addAndWaitSlot chan struct{}
+
+	nextFrame *queuedDatagram
+	// lostCallbacks are the onLost callbacks of datagrams dropped from the send queue.
+	// They are called from the run loop, see CallLostCallbacks.
+	lostCallbacks []func()
+	rcvQueue      chan []byte
 
 	closeErr error
 	closed   chan struct{}
 
 	hasData func()
 
-	dequeued chan struct{}
-
 	logger utils.Logger
 }
 
-func newDatagramQueue(hasData func(), logger utils.Logger) *datagramQueue {
+func newDatagramQueue(hasData func(), maxQueueLen int, dropPolicy DatagramDropPolicy, logger utils.Logger) *datagramQueue {
 	return &datagramQueue{
-		hasData:   hasData,
-		sendQueue: make(chan *wire.DatagramFrame, 1),
-		rcvQueue:  make(chan []byte, protocol.DatagramRcvQueueLen),
-		dequeued:  make(chan struct{}),
-		closed:    make(chan struct{}),
-		logger:    logger,
+		hasData:        hasData,
+		maxQueueLen:    maxQueueLen,
+		dropPolicy:     dropPolicy,
+		addAndWaitSlot: make(chan struct{}, 1),
+		rcvQueue:       make(chan []byte, protocol.DatagramRcvQueueLen),
+		closed:         make(chan struct{}),
+		logger:         logger,
 	}
 }
 
 // AddAndWait queues a new DATAGRAM frame for sending.
 // It blocks until the frame has been dequeued.
-func (h *datagramQueue) AddAndWait(f *wire.DatagramFrame) error {
+func (h *datagramQueue) AddAndWait(f *wire.DatagramFrame, onAcked, onLost func()) error {
+	d := &queuedDatagram{frame: f, onAcked: onAcked, onLost: onLost, dequeued: make(chan struct{})}
 	select {
-	case h.sendQueue <- f:
-		h.hasData()
+	case h.addAndWaitSlot <- struct{}{}:
 	case <-h.closed:
 		return h.closeErr
 	}
+	h.mutex.Lock()
+	h.sendQueue = append(h.sendQueue, d)
+	h.mutex.Unlock()
+	h.hasData()
 
 	select {
-	case <-h.dequeued:
+	case <-d.dequeued:
 		return nil
 	case <-h.closed:
 		return h.closeErr
 	}
 }
 
+// Add queues a new DATAGRAM frame for sending, without blocking.
+// If the queue is full, a DATAGRAM frame is dropped according to the drop policy.
+// The onLost callback of a dropped frame is called from the run loop.
+func (h *datagramQueue) Add(f *wire.DatagramFrame, onAcked, onLost func()) error {
+	d := &queuedDatagram{frame: f, onAcked: onAcked, onLost: onLost}
+	var dropped *queuedDatagram
+	h.mutex.Lock()
+	// Check for closing while holding the mutex.
+	// This makes sure that CloseWithError calls the onLost callback of every dropped frame.
+	select {
+	case <-h.closed:
+		h.mutex.Unlock()
+		return h.closeErr
+	default:
+	}
+	if len(h.sendQueue) >= h.maxQueueLen {
+		switch h.dropPolicy {
+		case DatagramDropNewest:
+			dropped = d
+		default:
+			dropped = h.sendQueue[0]
+			h.sendQueue[0] = nil
+			h.sendQueue = h.sendQueue[1:]
+		}
+	}
+	if dropped != d {
+		h.sendQueue = append(h.sendQueue, d)
+	}
+	hasLostCallback := dropped != nil && dropped.onLost != nil
+	if hasLostCallback {
+		h.lostCallbacks = append(h.lostCallbacks, dropped.onLost)
+	}
+	h.mutex.Unlock()
+
+	if dropped != nil {
+		h.logger.Debugf("Dropping DATAGRAM frame (%d bytes payload), send queue full", len(dropped.frame.Data))
+	}
+	if dropped != d || hasLostCallback {
+		h.hasData()
+	}
+	return nil
+}
+
+// CallLostCallbacks calls the onLost callbacks of the DATAGRAM frames that were dropped from the send queue.
+// It is called from the run loop.
+func (h *datagramQueue) CallLostCallbacks() {
+	h.mutex.Lock()
+	lostCallbacks := h.lostCallbacks
+	h.lostCallbacks = nil
+	h.mutex.Unlock()
+	for _, onLost := range lostCallbacks {
+		onLost()
+	}
+}
+
 // Peek gets the next DATAGRAM frame for sending.
 // If actually sent out, Pop needs to be called before the next call to Peek.
 func (h *datagramQueue) Peek() *wire.DatagramFrame {
 	if h.nextFrame != nil {
-		return h.nextFrame
+		return h.nextFrame.frame
 	}
-	select {
-	case h.nextFrame = <-h.sendQueue:
-		h.dequeued <- struct{}{}
-	default:
+	h.mutex.Lock()
+	if len(h.sendQueue) == 0 {
+		h.mutex.Unlock()
 		return nil
 	}
-	return h.nextFrame
+	h.nextFrame = h.sendQueue[0]
+	h.sendQueue[0] = nil
+	h.sendQueue = h.sendQueue[1:]
+	h.mutex.Unlock()
+	if h.nextFrame.dequeued != nil {
+		<-h.addAndWaitSlot
+		close(h.nextFrame.dequeued)
+	}
+	return h.nextFrame.frame
 }
 
-func (h *datagramQueue) Pop() {
+// Pop removes the DATAGRAM frame returned by Peek from the queue.
+// It returns the frame along with the callbacks that need to be called when it is acknowledged or lost.
+func (h *datagramQueue) Pop() ackhandler.Frame {
 	if h.nextFrame == nil {
 		panic("datagramQueue BUG: Pop called for nil frame")
 	}
+	d := h.nextFrame
 	h.nextFrame = nil
+	// DATAGRAM frames are never retransmitted.
+	// Set OnLost to a no-op, such that the default callback (which would retransmit the frame) is not set.
+	f := ackhandler.Frame{Frame: d.frame, OnLost: func(wire.Frame) {}}
+	if d.onLost != nil {
+		f.OnLost = func(wire.Frame) { d.onLost() }
+	}
+	if d.onAcked != nil {
+		f.OnAcked = func(wire.Frame) { d.onAcked() }
+	}
+	return f
 }
 
 // HandleDatagramFrame handles a received DATAGRAM frame.
@@ -84,16 +184,23 @@ func (h *datagramQueue) HandleDatagramFrame(f *wire.DatagramFrame) {
 }
 
 // Receive gets a received DATAGRAM frame.
-func (h *datagramQueue) Receive() ([]byte, error) {
+func (h *datagramQueue) Receive(ctx context.Context) ([]byte, error) {
 	select {
 	case data := <-h.rcvQueue:
 		return data, nil
 	case <-h.closed:
 		return nil, h.closeErr
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
 }
 
+// CloseWithError closes the queue.
+// It calls the onLost callbacks of the DATAGRAM frames that were dropped from the send queue.
 func (h *datagramQueue) CloseWithError(e error) {
+	h.mutex.Lock()
 	h.closeErr = e
 	close(h.closed)
+	h.mutex.Unlock()
+	h.CallLostCallbacks()
 }
@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"context"
 	"errors"
 
 	"github.com/lucas-clemente/quic-go/internal/utils"
@@ -16,7 +17,7 @@ var _ = Describe("Datagram Queue", func() {
 
 	BeforeEach(func() {
 		queued = make(chan struct{}, 100)
-		queue = newDatagramQueue(func() { queued <- struct{}{} }, utils.DefaultLogger)
+		queue = newDatagramQueue(func() { queued <- struct{}{} }, 0, DatagramDropOldest, utils.DefaultLogger)
 	})
 
 	Context("sending", func() {
@@ -30,7 +31,7 @@ var _ = Describe("Datagram Queue", func() {
 			go func() {
 				defer GinkgoRecover()
 				defer close(done)
-				Expect(queue.AddAndWait(frame)).To(Succeed())
+				Expect(queue.AddAndWait(frame, nil, nil)).To(Succeed())
 			}()
 
 			Eventually(queued).Should(HaveLen(1))
@@ -46,9 +47,9 @@ var _ = Describe("Datagram Queue", func() {
 			sent := make(chan struct{}, 1)
 			go func() {
 				defer GinkgoRecover()
-				Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+				Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foo")}, nil, nil)).To(Succeed())
 				sent <- struct{}{}
-				Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+				Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("bar")}, nil, nil)).To(Succeed())
 				sent <- struct{}{}
 			}()
 
@@ -63,11 +64,31 @@ var _ = Describe("Datagram Queue", func() {
 			Expect(f.Data).To(Equal([]byte("bar")))
 		})
 
+		It("queues only one datagram at a time", func() {
+			errChan := make(chan error, 2)
+			for _, data := range []string{"foo", "bar"} {
+				go func(data string) {
+					defer GinkgoRecover()
+					errChan <- queue.AddAndWait(&wire.DatagramFrame{Data: []byte(data)}, nil, nil)
+				}(data)
+			}
+
+			Eventually(queued).Should(HaveLen(1))
+			Consistently(queued).Should(HaveLen(1))
+			f := queue.Peek()
+			Expect(f).ToNot(BeNil())
+			Eventually(errChan).Should(Receive(BeNil()))
+			queue.Pop()
+			Eventually(queued).Should(HaveLen(2))
+			Expect(queue.Peek()).ToNot(Equal(f))
+			Eventually(errChan).Should(Receive(BeNil()))
+		})
+
 		It("closes", func() {
 			errChan := make(chan error, 1)
 			go func() {
 				defer GinkgoRecover()
-				errChan <- queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foobar")})
+				errChan <- queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foobar")}, nil, nil)
 			}()
 
 			Consistently(errChan).ShouldNot(Receive())
@@ -76,14 +97,164 @@ var _ = Describe("Datagram Queue", func() {
 		})
 	})
 
+	Context("sending, without blocking", func() {
+		var dropped []string
+
+		newQueue := func(policy DatagramDropPolicy) {
+			queue = newDatagramQueue(func() { queued <- struct{}{} }, 2, policy, utils.DefaultLogger)
+		}
+
+		add := func(data string) {
+			ExpectWithOffset(1, queue.Add(
+				&wire.DatagramFrame{Data: []byte(data)},
+				nil,
+				func() { dropped = append(dropped, data) },
+			)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			dropped = nil
+		})
+
+		It("queues datagrams", func() {
+			newQueue(DatagramDropOldest)
+			add("foo")
+			add("bar")
+			Expect(queued).To(HaveLen(2))
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			queue.Pop()
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+			queue.Pop()
+			Expect(queue.Peek()).To(BeNil())
+			Expect(dropped).To(BeEmpty())
+		})
+
+		It("drops the oldest datagram when the queue is full", func() {
+			newQueue(DatagramDropOldest)
+			add("foo")
+			add("bar")
+			add("baz")
+			queue.CallLostCallbacks()
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+			Expect(dropped).To(Equal([]string{"foo"}))
+			queue.Pop()
+			Expect(queue.Peek().Data).To(Equal([]byte("baz")))
+		})
+
+		It("drops the newest datagram when the queue is full", func() {
+			newQueue(DatagramDropNewest)
+			add("foo")
+			add("bar")
+			add("baz")
+			queue.CallLostCallbacks()
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			Expect(dropped).To(Equal([]string{"baz"}))
+			queue.Pop()
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+		})
+
+		It("doesn't drop a datagram that was already dequeued", func() {
+			newQueue(DatagramDropOldest)
+			add("foo")
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			add("bar")
+			add("baz")
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			Expect(dropped).To(BeEmpty())
+			add("qux")
+			queue.CallLostCallbacks()
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			Expect(dropped).To(Equal([]string{"bar"}))
+		})
+
+		It("calls the onLost callback of a dropped datagram from the run loop, not when adding", func() {
+			newQueue(DatagramDropNewest)
+			add("foo")
+			add("bar")
+			Expect(queued).To(HaveLen(2))
+			add("baz")
+			Expect(dropped).To(BeEmpty())
+			// the run loop is notified, so it can call the callback
+			Expect(queued).To(HaveLen(3))
+			queue.CallLostCallbacks()
+			Expect(dropped).To(Equal([]string{"baz"}))
+			// the callback is only called once
+			queue.CallLostCallbacks()
+			Expect(dropped).To(Equal([]string{"baz"}))
+		})
+
+		It("calls the onLost callbacks of dropped datagrams when closed", func() {
+			newQueue(DatagramDropOldest)
+			add("foo")
+			add("bar")
+			add("baz")
+			Expect(dropped).To(BeEmpty())
+			queue.CloseWithError(errors.New("test error"))
+			Expect(dropped).To(Equal([]string{"foo"}))
+		})
+
+		It("errors after the queue was closed", func() {
+			newQueue(DatagramDropOldest)
+			queue.CloseWithError(errors.New("test error"))
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, nil, nil)).To(MatchError("test error"))
+		})
+	})
+
+	Context("delivery callbacks", func() {
+		It("doesn't retransmit a DATAGRAM frame, if no callbacks are set", func() {
+			queue = newDatagramQueue(func() {}, 10, DatagramDropOldest, utils.DefaultLogger)
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, nil, nil)).To(Succeed())
+			Expect(queue.Peek()).ToNot(BeNil())
+			f := queue.Pop()
+			Expect(f.Frame).To(Equal(&wire.DatagramFrame{Data: []byte("foobar")}))
+			Expect(f.OnLost).ToNot(BeNil())
+			Expect(f.OnAcked).To(BeNil())
+			f.OnLost(f.Frame) // make sure this is a no-op
+		})
+
+		It("calls the callbacks when the frame is acknowledged or lost", func() {
+			queue = newDatagramQueue(func() {}, 10, DatagramDropOldest, utils.DefaultLogger)
+			var acked, lost []string
+			for _, data := range []string{"foo", "bar"} {
+				data := data
+				Expect(queue.Add(
+					&wire.DatagramFrame{Data: []byte(data)},
+					func() { acked = append(acked, data) },
+					func() { lost = append(lost, data) },
+				)).To(Succeed())
+			}
+			Expect(queue.Peek()).ToNot(BeNil())
+			f1 := queue.Pop()
+			Expect(queue.Peek()).ToNot(BeNil())
+			f2 := queue.Pop()
+			f1.OnAcked(f1.Frame)
+			f2.OnLost(f2.Frame)
+			Expect(acked).To(Equal([]string{"foo"}))
+			Expect(lost).To(Equal([]string{"bar"}))
+		})
+
+		It("sets the callbacks for datagrams sent with AddAndWait", func() {
+			var acked bool
+			go func() {
+				defer GinkgoRecover()
+				Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foobar")}, func() { acked = true }, nil)).To(Succeed())
+			}()
+			Eventually(queued).Should(HaveLen(1))
+			Expect(queue.Peek()).ToNot(BeNil())
+			f := queue.Pop()
+			f.OnAcked(f.Frame)
+			Expect(acked).To(BeTrue())
+		})
+	})
+
 	Context("receiving", func() {
 		It("receives DATAGRAM frames", func() {
 			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")})
 			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")})
-			data, err := queue.Receive()
+			data, err := queue.Receive(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(data).To(Equal([]byte("foo")))
-			data, err = queue.Receive()
+			data, err = queue.Receive(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(data).To(Equal([]byte("bar")))
 		})
@@ -92,7 +263,7 @@ var _ = Describe("Datagram Queue", func() {
 			c := make(chan []byte, 1)
 			go func() {
 				defer GinkgoRecover()
-				data, err := queue.Receive()
+				data, err := queue.Receive(context.Background())
 				Expect(err).ToNot(HaveOccurred())
 				c <- data
 			}()
@@ -102,11 +273,25 @@ var _ = Describe("Datagram Queue", func() {
 			Eventually(c).Should(Receive(Equal([]byte("foobar"))))
 		})
 
+		It("unblocks when the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				_, err := queue.Receive(ctx)
+				errChan <- err
+			}()
+
+			Consistently(errChan).ShouldNot(Receive())
+			cancel()
+			Eventually(errChan).Should(Receive(Equal(context.Canceled)))
+		})
+
 		It("closes", func() {
 			errChan := make(chan error, 1)
 			go func() {
 				defer GinkgoRecover()
-				_, err := queue.Receive()
+				_, err := queue.Receive(context.Background())
 				errChan <- err
 			}()
 
@@ -39,6 +39,8 @@ const (
 	KeyUpdateError            = qerr.KeyUpdateError
 	AEADLimitReached          = qerr.AEADLimitReached
 	NoViablePathError         = qerr.NoViablePathError
+
+	VersionNegotiationErrorErrorCode = qerr.VersionNegotiationErrorErrorCode
 )
 
 // A StreamError is used for Stream.CancelRead and Stream.CancelWrite.
@@ -56,3 +58,18 @@ func (e *StreamError) Is(target error) bool {
 func (e *StreamError) Error() string {
 	return fmt.Sprintf("stream %d canceled with error code %d", e.StreamID, e.ErrorCode)
 }
+
+// A DatagramTooLargeError is returned by Connection.SendMessage if the message is too large to be sent in a datagram.
+type DatagramTooLargeError struct {
+	// MaxDataLen is the maximum message size that can currently be sent, see Connection.MaxDatagramSize.
+	MaxDataLen int64
+}
+
+func (e *DatagramTooLargeError) Is(target error) bool {
+	_, ok := target.(*DatagramTooLargeError)
+	return ok
+}
+
+func (e *DatagramTooLargeError) Error() string {
+	return fmt.Sprintf("message too large (maximum: %d bytes)", e.MaxDataLen)
+}
@@ -21,15 +21,18 @@ type frameSorterEntry struct {
 type frameSorter struct {
 	queue   map[protocol.ByteCount]frameSorterEntry
 	readPos protocol.ByteCount
-	gaps    *list.List[byteInterval]
+	// maxPos is the offset beyond which data is discarded, see Truncate
+	maxPos protocol.ByteCount
+	gaps   *list.List[byteInterval]
 }
 
 var errDuplicateStreamData = errors.New("duplicate stream data")
 
 func newFrameSorter() *frameSorter {
 	s := frameSorter{
-		gaps:  list.New[byteInterval](),
-		queue: make(map[protocol.ByteCount]frameSorterEntry),
+		gaps:   list.New[byteInterval](),
+		queue:  make(map[protocol.ByteCount]frameSorterEntry),
+		maxPos: protocol.MaxByteCount,
 	}
 	s.gaps.PushFront(byteInterval{Start: 0, End: protocol.MaxByteCount})
 	return &s
@@ -47,9 +50,12 @@ func (s *frameSorter) Push(data []byte, offset protocol.ByteCount, doneCb func()
 }
 
 func (s *frameSorter) push(data []byte, offset protocol.ByteCount, doneCb func()) error {
-	if len(data) == 0 {
+	if len(data) == 0 || offset >= s.maxPos {
 		return errDuplicateStreamData
 	}
+	if offset+protocol.ByteCount(len(data)) > s.maxPos {
+		data = data[:s.maxPos-offset]
+	}
 
 	start := offset
 	end := offset + protocol.ByteCount(len(data))
@@ -224,6 +230,30 @@ func (s *frameSorter) Pop() (protocol.ByteCount, []byte, func()) {
 	return offset, entry.Data, entry.DoneCb
 }
 
+// Truncate discards all data at and beyond offset.
+// Data for this range that is pushed later is discarded as well.
+func (s *frameSorter) Truncate(offset protocol.ByteCount) {
+	if offset >= s.maxPos {
+		return
+	}
+	s.maxPos = offset
+	for pos, entry := range s.queue {
+		end := pos + protocol.ByteCount(len(entry.Data))
+		if end <= offset {
+			continue
+		}
+		if pos < offset {
+			entry.Data = entry.Data[:offset-pos]
+			s.queue[pos] = entry
+			continue
+		}
+		delete(s.queue, pos)
+		if entry.DoneCb != nil {
+			entry.DoneCb()
+		}
+	}
+}
+
 // HasMoreData says if there is any more data queued at *any* offset.
 func (s *frameSorter) HasMoreData() bool {
 	return len(s.queue) > 0
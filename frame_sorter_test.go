@@ -122,6 +122,49 @@ var _ = Describe("frame sorter", func() {
 		Expect(s.HasMoreData()).To(BeFalse())
 	})
 
+	Context("truncating", func() {
+		It("discards data beyond the truncation offset", func() {
+			cb1, t1 := getCallback()
+			cb2, t2 := getCallback()
+			cb3, t3 := getCallback()
+			Expect(s.Push([]byte("foo"), 0, cb1)).To(Succeed())
+			Expect(s.Push([]byte("bar"), 3, cb2)).To(Succeed())
+			Expect(s.Push([]byte("baz"), 10, cb3)).To(Succeed())
+			s.Truncate(5)
+			checkCallbackCalled(t3)
+			_, data, doneCb := s.Pop()
+			Expect(data).To(Equal([]byte("foo")))
+			Expect(doneCb).ToNot(BeNil())
+			doneCb()
+			checkCallbackCalled(t1)
+			offset, data, doneCb := s.Pop()
+			Expect(offset).To(BeEquivalentTo(3))
+			Expect(data).To(Equal([]byte("ba")))
+			doneCb()
+			checkCallbackCalled(t2)
+			Expect(s.HasMoreData()).To(BeFalse())
+		})
+
+		It("cuts data pushed after truncating", func() {
+			s.Truncate(5)
+			cb1, t1 := getCallback()
+			Expect(s.Push([]byte("foobar"), 0, nil)).To(Succeed())
+			Expect(s.Push([]byte("baz"), 5, cb1)).To(Succeed())
+			checkCallbackCalled(t1)
+			_, data, _ := s.Pop()
+			Expect(data).To(Equal([]byte("fooba")))
+			Expect(s.HasMoreData()).To(BeFalse())
+		})
+
+		It("doesn't increase the truncation offset", func() {
+			s.Truncate(3)
+			s.Truncate(5)
+			Expect(s.Push([]byte("foobar"), 0, nil)).To(Succeed())
+			_, data, _ := s.Pop()
+			Expect(data).To(Equal([]byte("foo")))
+		})
+	})
+
 	Context("Gap handling", func() {
 		var dataCounter uint8
 
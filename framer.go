@@ -17,6 +17,8 @@ type framer interface {
 	AppendControlFrames([]ackhandler.Frame, protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount)
 
 	AddActiveStream(protocol.StreamID)
+	SetStreamPriority(protocol.StreamID, StreamPriority)
+	RemoveStream(protocol.StreamID)
 	AppendStreamFrames([]ackhandler.Frame, protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount)
 
 	Handle0RTTRejection() error
@@ -29,7 +31,9 @@ type framerI struct {
 	version      protocol.VersionNumber
 
 	activeStreams map[protocol.StreamID]struct{}
-	streamQueue   []protocol.StreamID
+	streamQueue   StreamScheduler
+	// priorities holds the priorities of streams that don't use the default priority
+	priorities map[protocol.StreamID]StreamPriority
 
 	controlFrameMutex sync.Mutex
 	controlFrames     []wire.Frame
@@ -39,18 +43,21 @@ var _ framer = &framerI{}
 
 func newFramer(
 	streamGetter streamGetter,
+	scheduler StreamScheduler,
 	v protocol.VersionNumber,
 ) framer {
 	return &framerI{
 		streamGetter:  streamGetter,
 		activeStreams: make(map[protocol.StreamID]struct{}),
+		streamQueue:   scheduler,
+		priorities:    make(map[protocol.StreamID]StreamPriority),
 		version:       v,
 	}
 }
 
 func (f *framerI) HasData() bool {
 	f.mutex.Lock()
-	hasData := len(f.streamQueue) > 0
+	hasData := f.streamQueue.Len() > 0
 	f.mutex.Unlock()
 	if hasData {
 		return true
@@ -87,24 +94,50 @@ func (f *framerI) AppendControlFrames(frames []ackhandler.Frame, maxLen protocol
 func (f *framerI) AddActiveStream(id protocol.StreamID) {
 	f.mutex.Lock()
 	if _, ok := f.activeStreams[id]; !ok {
-		f.streamQueue = append(f.streamQueue, id)
+		f.streamQueue.Add(id, f.priority(id))
 		f.activeStreams[id] = struct{}{}
 	}
 	f.mutex.Unlock()
 }
 
+func (f *framerI) SetStreamPriority(id protocol.StreamID, prio StreamPriority) {
+	f.mutex.Lock()
+	if prio == defaultStreamPriority {
+		delete(f.priorities, id)
+	} else {
+		f.priorities[id] = prio
+	}
+	if _, ok := f.activeStreams[id]; ok {
+		f.streamQueue.SetPriority(id, prio)
+	}
+	f.mutex.Unlock()
+}
+
+// RemoveStream is called when a stream is completed.
+func (f *framerI) RemoveStream(id protocol.StreamID) {
+	f.mutex.Lock()
+	delete(f.priorities, id)
+	f.mutex.Unlock()
+}
+
+func (f *framerI) priority(id protocol.StreamID) StreamPriority {
+	if prio, ok := f.priorities[id]; ok {
+		return prio
+	}
+	return defaultStreamPriority
+}
+
 func (f *framerI) AppendStreamFrames(frames []ackhandler.Frame, maxLen protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount) {
 	var length protocol.ByteCount
 	var lastFrame *ackhandler.Frame
 	f.mutex.Lock()
 	// pop STREAM frames, until less than MinStreamFrameSize bytes are left in the packet
-	numActiveStreams := len(f.streamQueue)
+	numActiveStreams := f.streamQueue.Len()
 	for i := 0; i < numActiveStreams; i++ {
 		if protocol.MinStreamFrameSize+length > maxLen {
 			break
 		}
-		id := f.streamQueue[0]
-		f.streamQueue = f.streamQueue[1:]
+		id := f.streamQueue.Pop()
 		// This should never return an error. Better check it anyway.
 		// The stream will only be in the streamQueue, if it enqueued itself there.
 		str, err := f.streamGetter.GetOrOpenSendStream(id)
@@ -119,8 +152,12 @@ func (f *framerI) AppendStreamFrames(frames []ackhandler.Frame, maxLen protocol.
 		// the STREAM frame (which will always have the DataLen set).
 		remainingLen += quicvarint.Len(uint64(remainingLen))
 		frame, hasMoreData := str.popStreamFrame(remainingLen)
-		if hasMoreData { // put the stream back in the queue (at the end)
-			f.streamQueue = append(f.streamQueue, id)
+		if hasMoreData { // put the stream back in the queue
+			var n protocol.ByteCount
+			if frame != nil {
+				n = frame.Frame.(*wire.StreamFrame).DataLen()
+			}
+			f.streamQueue.Requeue(id, f.priority(id), int64(n))
 		} else { // no more data to send. Stream is not active any more
 			delete(f.activeStreams, id)
 		}
@@ -149,7 +186,7 @@ func (f *framerI) Handle0RTTRejection() error {
 	defer f.mutex.Unlock()
 
 	f.controlFrameMutex.Lock()
-	f.streamQueue = f.streamQueue[:0]
+	f.streamQueue.Reset()
 	for id := range f.activeStreams {
 		delete(f.activeStreams, id)
 	}
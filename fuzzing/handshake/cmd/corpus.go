@@ -106,6 +106,9 @@ func main() {
 		runner,
 		config,
 		false,
+		nil,
+		nil,
+		nil,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
@@ -401,6 +401,9 @@ func runHandshake(runConfig [confLen]byte, messageConfig uint8, clientConf *tls.
 		runner,
 		serverConf,
 		enable0RTTServer,
+		nil,
+		nil,
+		nil,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
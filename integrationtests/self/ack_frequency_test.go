@@ -0,0 +1,103 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+	quicproxy "github.com/lucas-clemente/quic-go/integrationtests/tools/proxy"
+	"github.com/lucas-clemente/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK frequency", func() {
+	data := GeneratePRData(5 * 1024 * 1024)
+
+	// In this test, the client downloads a large amount of data from the server.
+	// The server's congestion window grows large, and the server asks the client to reduce its ACK rate.
+	download := func(enableAckFrequency bool) (numDataPackets, numAckPackets, numAckFrequencyFrames int) {
+		serverTracer := newPacketTracer()
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				EnableAckFrequency: enableAckFrequency,
+				Tracer:             newTracer(func() logging.ConnectionTracer { return serverTracer }),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return 5 * time.Millisecond },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		clientTracer := newPacketTracer()
+		conn, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				EnableAckFrequency: enableAckFrequency,
+				Tracer:             newTracer(func() logging.ConnectionTracer { return clientTracer }),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		rcvdData, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rcvdData).To(Equal(data))
+		Expect(conn.CloseWithError(0, "")).To(Succeed())
+
+		for _, p := range clientTracer.getRcvdShortHeaderPackets() {
+			for _, f := range p.frames {
+				switch f.(type) {
+				case *logging.StreamFrame:
+					numDataPackets++
+				case *logging.AckFrequencyFrame:
+					numAckFrequencyFrames++
+				}
+			}
+		}
+		for _, p := range serverTracer.getRcvdShortHeaderPackets() {
+			for _, f := range p.frames {
+				if _, ok := f.(*logging.AckFrame); ok {
+					numAckPackets++
+				}
+			}
+		}
+		fmt.Fprintf(GinkgoWriter, "ACK frequency enabled: %t. Received %d ACKs for %d packets (%d ACK_FREQUENCY frames).\n", enableAckFrequency, numAckPackets, numDataPackets, numAckFrequencyFrames)
+		return
+	}
+
+	// On localhost, the receiver processes packets in large batches, and sends far fewer ACKs than one every two packets anyway.
+	// We therefore only check that ACK_FREQUENCY frames are sent, and that the transfer succeeds.
+	It("doesn't send ACK_FREQUENCY frames if the extension is disabled", func() {
+		_, _, numAckFrequencyFrames := download(false)
+		Expect(numAckFrequencyFrames).To(BeZero())
+	})
+
+	It("requests a lower ACK rate when the congestion window grows", func() {
+		_, _, numAckFrequencyFrames := download(true)
+		Expect(numAckFrequencyFrames).ToNot(BeZero())
+	})
+})
@@ -313,6 +313,80 @@ var _ = Describe("Stream Cancelations", func() {
 		})
 	})
 
+	Context("canceling the write side with a reliable size", func() {
+		It("delivers the data up to the reliable size", func() {
+			const reliableSize = 10000
+			server, err := quic.ListenAddr(
+				"localhost:0",
+				getTLSConfig(),
+				getQuicConfig(&quic.Config{EnableResetStreamAt: true}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Close()
+
+			go func() {
+				defer GinkgoRecover()
+				conn, err := server.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				for i := 0; i < numStreams; i++ {
+					go func() {
+						defer GinkgoRecover()
+						str, err := conn.OpenUniStreamSync(context.Background())
+						Expect(err).ToNot(HaveOccurred())
+						_, err = str.Write(PRData)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(str.CancelWriteAt(quic.StreamErrorCode(str.StreamID()), reliableSize)).To(Succeed())
+					}()
+				}
+			}()
+
+			conn, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{MaxIncomingUniStreams: numStreams / 2, EnableResetStreamAt: true}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			var wg sync.WaitGroup
+			wg.Add(numStreams)
+			for i := 0; i < numStreams; i++ {
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					str, err := conn.AcceptUniStream(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					data, err := io.ReadAll(str)
+					Expect(err).To(MatchError(&quic.StreamError{
+						StreamID:  str.StreamID(),
+						ErrorCode: quic.StreamErrorCode(str.StreamID()),
+					}))
+					Expect(len(data)).To(BeNumerically(">=", reliableSize))
+					Expect(data).To(Equal(PRData[:len(data)]))
+				}()
+			}
+			wg.Wait()
+			Expect(conn.CloseWithError(0, "")).To(Succeed())
+		})
+
+		It("doesn't allow reliable resets if the peer doesn't support them", func() {
+			server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer server.Close()
+
+			conn, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{EnableResetStreamAt: true}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.CancelWriteAt(42, 3)).To(MatchError("peer doesn't support reliable stream resets"))
+		})
+	})
+
 	Context("canceling both read and write side", func() {
 		It("downloads data when both sides cancel streams immediately", func() {
 			server, err := quic.ListenAddr("localhost:0", getTLSConfig(), nil)
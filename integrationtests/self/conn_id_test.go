@@ -1,15 +1,19 @@
 package self_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"fmt"
 	"io"
 	mrand "math/rand"
 	"net"
+	"sync/atomic"
 
 	"github.com/lucas-clemente/quic-go"
+	quicproxy "github.com/lucas-clemente/quic-go/integrationtests/tools/proxy"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quiclb"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -119,4 +123,46 @@ var _ = Describe("Connection ID lengths tests", func() {
 		defer ln.Close()
 		runClient(ln.Addr(), clientConf)
 	})
+
+	It("routes packets using QUIC-LB connection IDs", func() {
+		key := make([]byte, 16)
+		rand.Read(key)
+		lbConf := &quiclb.Config{ConfigID: 1, ServerIDLen: 3, NonceLen: 8, Key: key}
+		serverID := []byte{0xde, 0xca, 0xfb}
+		gen, err := quiclb.NewGenerator(lbConf, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		decoder, err := quiclb.NewDecoder(lbConf)
+		Expect(err).ToNot(HaveOccurred())
+
+		ln := runServer(getQuicConfig(&quic.Config{ConnectionIDGenerator: gen}))
+		defer ln.Close()
+
+		var numRouted, numUnroutable, numWrongServerID int32
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DropPacket: func(dir quicproxy.Direction, data []byte) bool {
+				if dir != quicproxy.DirectionIncoming {
+					return false
+				}
+				sid, err := decoder.ServerIDFromPacket(data)
+				switch {
+				case err == quiclb.ErrUnroutable:
+					atomic.AddInt32(&numUnroutable, 1)
+				case err != nil || !bytes.Equal(sid, serverID):
+					atomic.AddInt32(&numWrongServerID, 1)
+				default:
+					atomic.AddInt32(&numRouted, 1)
+				}
+				return false
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		runClient(proxy.LocalAddr(), getQuicConfig(nil))
+		Expect(atomic.LoadInt32(&numRouted)).To(BeNumerically(">", 5))
+		// only the Initial packets sent before receiving the server's first packet are unroutable
+		Expect(atomic.LoadInt32(&numUnroutable)).To(BeNumerically(">=", 1))
+		Expect(atomic.LoadInt32(&numWrongServerID)).To(BeZero())
+	})
 })
@@ -191,4 +191,112 @@ var _ = Describe("Datagram test", func() {
 			})
 		})
 	}
+
+	It("reports which datagrams were acknowledged and which were lost", func() {
+		const num = 100
+		ln, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				EnableDatagrams:      true,
+				DatagramSendQueueLen: num,
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			// drop 10% of Short Header packets sent from the server
+			DropPacket: func(dir quicproxy.Direction, packet []byte) bool {
+				if dir == quicproxy.DirectionIncoming || wire.IsLongHeaderPacket(packet[0]) {
+					return false
+				}
+				return mrand.Int()%10 == 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		var acked, lost int32
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < num; i++ {
+				b := make([]byte, 8)
+				binary.BigEndian.PutUint64(b, uint64(i))
+				// with a send queue, SendMessageWithCallbacks doesn't block
+				Expect(conn.SendMessageWithCallbacks(
+					b,
+					func() { atomic.AddInt32(&acked, 1) },
+					func() { atomic.AddInt32(&lost, 1) },
+				)).To(Succeed())
+			}
+			Eventually(func() int32 { return atomic.LoadInt32(&acked) + atomic.LoadInt32(&lost) }).Should(BeEquivalentTo(num))
+		}()
+
+		conn, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		var counter int
+		receiveDone := make(chan struct{})
+		go func() {
+			defer close(receiveDone)
+			for {
+				if _, err := conn.ReceiveMessage(); err != nil {
+					return
+				}
+				counter++
+			}
+		}()
+		Eventually(done).Should(BeClosed())
+		conn.CloseWithError(0, "")
+		Eventually(receiveDone).Should(BeClosed())
+		fmt.Fprintf(GinkgoWriter, "Received %d datagrams. %d were acknowledged, %d were lost.\n", counter, atomic.LoadInt32(&acked), atomic.LoadInt32(&lost))
+		Expect(atomic.LoadInt32(&acked)).To(And(BeNumerically(">", 0), BeNumerically("<=", counter)))
+	})
+
+	It("reports the maximum datagram size, and unblocks ReceiveMessageContext", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{EnableDatagrams: true}))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+		}()
+
+		conn, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+
+		maxSize := conn.MaxDatagramSize()
+		Expect(maxSize).To(BeNumerically(">", 1000))
+		err = conn.SendMessage(make([]byte, maxSize+1))
+		Expect(err).To(MatchError(&quic.DatagramTooLargeError{MaxDataLen: maxSize}))
+		Expect(conn.SendMessage(make([]byte, maxSize))).To(Succeed())
+		data, err := serverConn.ReceiveMessage()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(HaveLen(int(maxSize)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(20*time.Millisecond))
+		defer cancel()
+		_, err = serverConn.ReceiveMessageContext(ctx)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
 })
@@ -0,0 +1,74 @@
+package self_test
+
+import (
+	"context"
+	"io"
+	"runtime"
+
+	"github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ECN", func() {
+	It("marks packets, and validates the ECN counts reported by the peer", func() {
+		if runtime.GOOS != "linux" {
+			Skip("sending ECN-marked packets is only supported on Linux")
+		}
+
+		serverTracer := newPacketTracer()
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				Tracer: newTracer(func() logging.ConnectionTracer { return serverTracer }),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		data := GeneratePRData(200 * 1024)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		conn, err := quic.DialAddr(
+			server.Addr().String(),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		b, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(Equal(data))
+		Expect(conn.CloseWithError(0, "")).To(Succeed())
+
+		var maxECT0, maxECNCE uint64
+		for _, p := range serverTracer.getRcvdShortHeaderPackets() {
+			for _, f := range p.frames {
+				if ack, ok := f.(*logging.AckFrame); ok {
+					if ack.ECT0 > maxECT0 {
+						maxECT0 = ack.ECT0
+					}
+					if ack.ECNCE > maxECNCE {
+						maxECNCE = ack.ECNCE
+					}
+				}
+			}
+		}
+		// After ECN validation succeeded, the server marks all 1-RTT packets, not only the testing packets.
+		Expect(maxECT0).To(BeNumerically(">", 10))
+		Expect(maxECNCE).To(BeZero())
+	})
+})
@@ -148,7 +148,7 @@ var _ = Describe("Handshake tests", func() {
 				Expect(clientTracer.serverVersions).To(BeEmpty())
 				Expect(serverTracer.chosen).To(Equal(expectedVersion))
 				Expect(serverTracer.serverVersions).To(Equal(serverConfig.Versions))
-				Expect(serverTracer.clientVersions).To(BeEmpty())
+				Expect(serverTracer.clientVersions).To(Equal(protocol.SupportedVersions))
 			})
 
 			It("when the client supports more versions than the server supports", func() {
@@ -179,7 +179,50 @@ var _ = Describe("Handshake tests", func() {
 				Expect(clientTracer.serverVersions).To(ContainElements(supportedVersions)) // may contain greased versions
 				Expect(serverTracer.chosen).To(Equal(expectedVersion))
 				Expect(serverTracer.serverVersions).To(Equal(serverConfig.Versions))
-				Expect(serverTracer.clientVersions).To(BeEmpty())
+				Expect(serverTracer.clientVersions).To(Equal(clientVersions))
+			})
+
+			It("switches to a compatible version preferred by the server", func() {
+				serverConfig.Versions = []protocol.VersionNumber{protocol.Version2, protocol.Version1}
+				serverTracer := &versionNegotiationTracer{}
+				serverConfig.Tracer = newTracer(func() logging.ConnectionTracer { return serverTracer })
+				runServer(getTLSConfig())
+				defer server.Close()
+				clientTracer := &versionNegotiationTracer{}
+				conn, err := quic.DialAddr(
+					fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+					getTLSClientConfig(),
+					getQuicConfig(&quic.Config{
+						Versions: []protocol.VersionNumber{protocol.Version1, protocol.Version2},
+						Tracer:   newTracer(func() logging.ConnectionTracer { return clientTracer }),
+					}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conn.(versioner).GetVersion()).To(Equal(protocol.Version2))
+				str, err := conn.OpenStream()
+				Expect(err).ToNot(HaveOccurred())
+				_, err = str.Write([]byte("foobar"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.Close()).To(Succeed())
+				Expect(conn.CloseWithError(0, "")).To(Succeed())
+				Expect(clientTracer.chosen).To(Equal(protocol.Version2))
+				Expect(clientTracer.receivedVersionNegotiation).To(BeFalse())
+				Expect(serverTracer.chosen).To(Equal(protocol.Version2))
+				Expect(serverTracer.clientVersions).To(Equal([]protocol.VersionNumber{protocol.Version1, protocol.Version2}))
+			})
+
+			It("doesn't switch to a version that the client doesn't support", func() {
+				serverConfig.Versions = []protocol.VersionNumber{protocol.Version2, protocol.Version1}
+				runServer(getTLSConfig())
+				defer server.Close()
+				conn, err := quic.DialAddr(
+					fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+					getTLSClientConfig(),
+					getQuicConfig(&quic.Config{Versions: []protocol.VersionNumber{protocol.Version1}}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conn.(versioner).GetVersion()).To(Equal(protocol.Version1))
+				Expect(conn.CloseWithError(0, "")).To(Succeed())
 			})
 		})
 	}
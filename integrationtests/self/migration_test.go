@@ -0,0 +1,236 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// natRebinder forwards packets between a client and a server.
+// Rebind switches to a new socket for sending to the server, simulating a NAT rebinding.
+type natRebinder struct {
+	serverAddr net.Addr
+	clientConn *net.UDPConn
+
+	mutex      sync.Mutex
+	clientAddr net.Addr
+	serverConn *net.UDPConn
+}
+
+func newNATRebinder(serverAddr net.Addr) *natRebinder {
+	clientConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	Expect(err).ToNot(HaveOccurred())
+	r := &natRebinder{serverAddr: serverAddr, clientConn: clientConn}
+	r.Rebind()
+	go func() {
+		b := make([]byte, protocol.MaxPacketBufferSize)
+		for {
+			n, addr, err := clientConn.ReadFrom(b)
+			if err != nil {
+				return
+			}
+			r.mutex.Lock()
+			r.clientAddr = addr
+			conn := r.serverConn
+			r.mutex.Unlock()
+			conn.WriteTo(b[:n], serverAddr)
+		}
+	}()
+	return r
+}
+
+func (r *natRebinder) LocalAddr() net.Addr { return r.clientConn.LocalAddr() }
+
+// ServerFacingAddr returns the address that the server sees.
+func (r *natRebinder) ServerFacingAddr() net.Addr {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.serverConn.LocalAddr()
+}
+
+func (r *natRebinder) Rebind() {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	Expect(err).ToNot(HaveOccurred())
+	r.mutex.Lock()
+	r.serverConn = conn
+	r.mutex.Unlock()
+	go func() {
+		b := make([]byte, protocol.MaxPacketBufferSize)
+		for {
+			n, _, err := conn.ReadFrom(b)
+			if err != nil {
+				return
+			}
+			r.mutex.Lock()
+			clientAddr := r.clientAddr
+			r.mutex.Unlock()
+			r.clientConn.WriteTo(b[:n], clientAddr)
+		}
+	}()
+}
+
+var _ = Describe("Connection Migration", func() {
+	var (
+		serverConf  *quic.Config
+		ln          quic.Listener
+		serverConns chan quic.Connection
+	)
+
+	BeforeEach(func() {
+		serverConf = getQuicConfig(nil)
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		ln, err = quic.ListenAddr("localhost:0", getTLSConfig(), serverConf)
+		Expect(err).ToNot(HaveOccurred())
+		serverConns = make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			serverConns <- conn
+			for {
+				str, err := conn.AcceptStream(context.Background())
+				if err != nil {
+					return
+				}
+				go func() {
+					defer GinkgoRecover()
+					defer str.Close()
+					_, err := io.Copy(str, str)
+					Expect(err).ToNot(HaveOccurred())
+				}()
+			}
+		}()
+	})
+
+	AfterEach(func() {
+		Expect(ln.Close()).To(Succeed())
+	})
+
+	echo := func(conn quic.Connection, msg string) {
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write([]byte(msg))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(msg))
+	}
+
+	It("migrates the client to a new path", func() {
+		conn1, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		defer conn1.Close()
+		conn, err := quic.Dial(
+			conn1,
+			ln.Addr(),
+			"localhost",
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		var serverConn quic.Connection
+		Eventually(serverConns).Should(Receive(&serverConn))
+		echo(conn, "foo")
+		Expect(serverConn.RemoteAddr().String()).To(Equal(conn1.LocalAddr().String()))
+
+		conn2, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		defer conn2.Close()
+		Expect(conn.MigrateTo(conn2)).To(Succeed())
+		Expect(conn.LocalAddr()).To(Equal(conn2.LocalAddr()))
+		// the old packet conn is not used anymore
+		Expect(conn1.Close()).To(Succeed())
+		echo(conn, "bar")
+		Eventually(func() string { return serverConn.RemoteAddr().String() }).Should(Equal(conn2.LocalAddr().String()))
+		echo(conn, "baz")
+	})
+
+	It("handles NAT rebindings", func() {
+		rebinder := newNATRebinder(ln.Addr())
+		conn, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", rebinder.LocalAddr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		var serverConn quic.Connection
+		Eventually(serverConns).Should(Receive(&serverConn))
+		echo(conn, "foo")
+		Expect(serverConn.RemoteAddr().String()).To(Equal(rebinder.ServerFacingAddr().String()))
+
+		rebinder.Rebind()
+		echo(conn, "bar")
+		Eventually(func() string { return serverConn.RemoteAddr().String() }, scaleDuration(time.Second)).Should(Equal(rebinder.ServerFacingAddr().String()))
+		echo(conn, "baz")
+	})
+
+	Context("with a preferred address", func() {
+		var preferredConn *net.UDPConn
+
+		BeforeEach(func() {
+			var err error
+			preferredConn, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+			Expect(err).ToNot(HaveOccurred())
+			serverConf.PreferredAddress = &quic.PreferredAddress{
+				IPv4: preferredConn.LocalAddr().(*net.UDPAddr),
+				Conn: preferredConn,
+			}
+		})
+
+		AfterEach(func() {
+			Expect(preferredConn.Close()).To(Succeed())
+		})
+
+		It("migrates the client to the preferred address", func() {
+			conn, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			var serverConn quic.Connection
+			Eventually(serverConns).Should(Receive(&serverConn))
+			echo(conn, "foo")
+			Eventually(func() string { return conn.RemoteAddr().String() }, scaleDuration(time.Second)).Should(Equal(preferredConn.LocalAddr().String()))
+			echo(conn, "bar")
+			Eventually(func() string { return serverConn.LocalAddr().String() }, scaleDuration(time.Second)).Should(Equal(preferredConn.LocalAddr().String()))
+			echo(conn, "baz")
+		})
+
+		It("closes connections that migrated to the preferred address when the server is closed", func() {
+			conn, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			var serverConn quic.Connection
+			Eventually(serverConns).Should(Receive(&serverConn))
+			echo(conn, "foo")
+			Eventually(func() string { return serverConn.LocalAddr().String() }, scaleDuration(time.Second)).Should(Equal(preferredConn.LocalAddr().String()))
+
+			Expect(ln.Close()).To(Succeed())
+			Eventually(serverConn.Context().Done()).Should(BeClosed())
+			Eventually(conn.Context().Done()).Should(BeClosed())
+		})
+	})
+})
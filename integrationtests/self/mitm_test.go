@@ -95,8 +95,17 @@ var _ = Describe("MITM test", func() {
 
 					sendRandomPacketsOfSameType := func(conn net.PacketConn, remoteAddr net.Addr, raw []byte) {
 						defer GinkgoRecover()
-						hdr, _, _, err := wire.ParsePacket(raw, connIDLen)
-						Expect(err).ToNot(HaveOccurred())
+						var hdr *wire.Header
+						if wire.IsLongHeaderPacket(raw[0]) {
+							var err error
+							hdr, _, _, err = wire.ParsePacket(raw, connIDLen)
+							Expect(err).ToNot(HaveOccurred())
+						} else {
+							// 1-RTT packets might have a greased QUIC bit, so don't use wire.ParsePacket
+							connID, err := wire.ParseConnectionID(raw, connIDLen)
+							Expect(err).ToNot(HaveOccurred())
+							hdr = &wire.Header{DestConnectionID: connID}
+						}
 						replyHdr := &wire.ExtendedHeader{
 							Header: wire.Header{
 								IsLongHeader:     hdr.IsLongHeader,
@@ -314,7 +323,7 @@ var _ = Describe("MITM test", func() {
 				It("fails when a forged version negotiation packet is sent to client", func() {
 					done := make(chan struct{})
 					delayCb := func(dir quicproxy.Direction, raw []byte) time.Duration {
-						if dir == quicproxy.DirectionIncoming {
+						if dir == quicproxy.DirectionIncoming && wire.IsLongHeaderPacket(raw[0]) {
 							defer GinkgoRecover()
 
 							hdr, _, _, err := wire.ParsePacket(raw, connIDLen)
@@ -355,7 +364,7 @@ var _ = Describe("MITM test", func() {
 					var initialPacketIntercepted bool
 					done := make(chan struct{})
 					delayCb := func(dir quicproxy.Direction, raw []byte) time.Duration {
-						if dir == quicproxy.DirectionIncoming && !initialPacketIntercepted {
+						if dir == quicproxy.DirectionIncoming && !initialPacketIntercepted && wire.IsLongHeaderPacket(raw[0]) {
 							defer GinkgoRecover()
 							defer close(done)
 
@@ -389,7 +398,7 @@ var _ = Describe("MITM test", func() {
 					done := make(chan struct{})
 					var injected bool
 					delayCb := func(dir quicproxy.Direction, raw []byte) time.Duration {
-						if dir == quicproxy.DirectionIncoming {
+						if dir == quicproxy.DirectionIncoming && wire.IsLongHeaderPacket(raw[0]) {
 							defer GinkgoRecover()
 
 							hdr, _, _, err := wire.ParsePacket(raw, connIDLen)
@@ -417,7 +426,7 @@ var _ = Describe("MITM test", func() {
 					done := make(chan struct{})
 					var injected bool
 					delayCb := func(dir quicproxy.Direction, raw []byte) time.Duration {
-						if dir == quicproxy.DirectionIncoming {
+						if dir == quicproxy.DirectionIncoming && wire.IsLongHeaderPacket(raw[0]) {
 							defer GinkgoRecover()
 
 							hdr, _, _, err := wire.ParsePacket(raw, connIDLen)
@@ -0,0 +1,128 @@
+package self_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+
+	"github.com/lucas-clemente/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// A lastPathScheduler always sends on the path that was added last.
+type lastPathScheduler struct {
+	maxPaths int32 // the maximum number of paths passed to SelectPath
+}
+
+func (s *lastPathScheduler) SelectPath(paths []quic.PathInfo) int {
+	if int32(len(paths)) > atomic.LoadInt32(&s.maxPaths) {
+		atomic.StoreInt32(&s.maxPaths, int32(len(paths)))
+	}
+	return len(paths) - 1
+}
+
+var _ = Describe("Multipath", func() {
+	var (
+		ln          quic.Listener
+		serverConns chan quic.Connection
+	)
+
+	BeforeEach(func() {
+		var err error
+		ln, err = quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{EnableMultipath: true}))
+		Expect(err).ToNot(HaveOccurred())
+		serverConns = make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			serverConns <- conn
+			for {
+				str, err := conn.AcceptStream(context.Background())
+				if err != nil {
+					return
+				}
+				go func() {
+					defer GinkgoRecover()
+					defer str.Close()
+					_, err := io.Copy(str, str)
+					Expect(err).ToNot(HaveOccurred())
+				}()
+			}
+		}()
+	})
+
+	AfterEach(func() {
+		Expect(ln.Close()).To(Succeed())
+	})
+
+	echo := func(conn quic.Connection, data []byte) {
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			_, err := str.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+		received, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(received).To(Equal(data))
+	}
+
+	It("sends on an additional path", func() {
+		conn1, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		defer conn1.Close()
+		scheduler := &lastPathScheduler{}
+		conn, err := quic.Dial(
+			conn1,
+			ln.Addr(),
+			"localhost",
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableMultipath: true, PathScheduler: scheduler}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Expect(conn.ConnectionState().SupportsMultipath).To(BeTrue())
+		var serverConn quic.Connection
+		Eventually(serverConns).Should(Receive(&serverConn))
+		Expect(serverConn.ConnectionState().SupportsMultipath).To(BeTrue())
+		echo(conn, GeneratePRData(10*1024))
+
+		conn2, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		defer conn2.Close()
+		path, err := conn.AddPath(conn2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(path.LocalAddr()).To(Equal(conn2.LocalAddr()))
+		Expect(path.RemoteAddr().String()).To(Equal(ln.Addr().String()))
+		echo(conn, GeneratePRData(100*1024))
+		Expect(atomic.LoadInt32(&scheduler.maxPaths)).To(BeEquivalentTo(2))
+
+		// the connection falls back to the initial path when the additional path is closed
+		Expect(path.Close()).To(Succeed())
+		echo(conn, GeneratePRData(10*1024))
+	})
+
+	It("doesn't use multipath if the peer doesn't support it", func() {
+		conn, err := quic.DialAddr(
+			ln.Addr().String(),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Expect(conn.ConnectionState().SupportsMultipath).To(BeFalse())
+		pconn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		defer pconn.Close()
+		_, err = conn.AddPath(pconn)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,89 @@
+package self_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Graceful Shutdown", func() {
+	var server quic.Listener
+
+	dial := func() (quic.Connection, error) {
+		return quic.DialAddr(
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+	}
+
+	startServer := func(conf *quic.Config) {
+		var err error
+		server, err = quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(conf))
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	AfterEach(func() {
+		Expect(server.Close()).To(Succeed())
+	})
+
+	It("refuses new connections, and waits for existing connections to close", func() {
+		startServer(nil)
+		conn, err := dial()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(server.Shutdown(context.Background())).To(Succeed())
+		}()
+		time.Sleep(scaleDuration(25 * time.Millisecond)) // wait for the server to start shutting down
+
+		_, err = dial()
+		Expect(err).To(HaveOccurred())
+		var transportErr *quic.TransportError
+		Expect(errors.As(err, &transportErr)).To(BeTrue())
+		Expect(transportErr.ErrorCode).To(Equal(quic.ConnectionRefused))
+
+		// the existing connection is still usable
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		Consistently(done).ShouldNot(BeClosed())
+
+		Expect(conn.CloseWithError(0, "")).To(Succeed())
+		Eventually(done).Should(BeClosed())
+		_, err = server.Accept(context.Background())
+		Expect(err).To(MatchError(quic.ErrServerClosed))
+	})
+
+	It("closes the remaining connections when the context expires", func() {
+		startServer(&quic.Config{ShutdownErrorCode: 0x42})
+		conn, err := dial()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = server.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(50*time.Millisecond))
+		defer cancel()
+		Expect(server.Shutdown(ctx)).To(MatchError(context.DeadlineExceeded))
+
+		Eventually(conn.Context().Done()).Should(BeClosed())
+		_, err = conn.AcceptStream(context.Background())
+		var appErr *quic.ApplicationError
+		Expect(errors.As(err, &appErr)).To(BeTrue())
+		Expect(appErr.Remote).To(BeTrue())
+		Expect(appErr.ErrorCode).To(BeEquivalentTo(0x42))
+	})
+})
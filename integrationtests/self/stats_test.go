@@ -0,0 +1,136 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+	quicproxy "github.com/lucas-clemente/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Statistics", func() {
+	It("reports the RTT, and the packets sent, received and lost", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		const rtt = 20 * time.Millisecond
+		var numPackets int32
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return rtt / 2 },
+			// drop every 20th packet sent by the server, after the handshake
+			DropPacket: func(dir quicproxy.Direction, _ []byte) bool {
+				if dir != quicproxy.DirectionOutgoing {
+					return false
+				}
+				n := atomic.AddInt32(&numPackets, 1)
+				return n > 10 && n%20 == 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+			serverConnChan <- conn
+		}()
+
+		conn, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := conn.AcceptStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		clientStats := conn.Stats()
+		Expect(clientStats.SmoothedRTT).To(BeNumerically(">=", rtt))
+		Expect(clientStats.MinRTT).To(BeNumerically(">=", rtt))
+		Expect(clientStats.BytesReceived).To(BeNumerically(">", len(PRData)))
+		Expect(clientStats.PacketsReceived).To(BeNumerically(">", len(PRData)/1500))
+		Expect(clientStats.PacketsSent).ToNot(BeZero())
+		Expect(clientStats.MaxDatagramSize).ToNot(BeZero())
+
+		conn.CloseWithError(0, "")
+		Eventually(serverConn.Context().Done()).Should(BeClosed())
+		serverStats := serverConn.Stats()
+		Expect(serverStats.SmoothedRTT).To(BeNumerically(">=", rtt))
+		Expect(serverStats.BytesSent).To(BeNumerically(">", len(PRData)))
+		Expect(serverStats.PacketsSent).To(BeNumerically(">=", clientStats.PacketsReceived))
+		Expect(serverStats.PacketsReceived).To(BeNumerically(">", clientStats.PacketsSent)) // the client also sent a CONNECTION_CLOSE
+		Expect(serverStats.PacketsLost).ToNot(BeZero())
+		Expect(serverStats.PacketsRetransmitted).To(BeNumerically(">=", serverStats.PacketsLost))
+		Expect(serverStats.CongestionWindow).ToNot(BeZero())
+	})
+
+	It("reports when a stream is blocked by flow control", func() {
+		ln, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				InitialStreamReceiveWindow: 10000,
+				MaxStreamReceiveWindow:     10000,
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		serverStrChan := make(chan quic.Stream, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverStrChan <- str
+		}()
+
+		conn, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		// the server never reads from the stream, so this Write blocks
+		go str.Write(PRData)
+
+		var serverStr quic.Stream
+		Eventually(serverStrChan).Should(Receive(&serverStr))
+		Eventually(func() bool { return str.Stats().BlockedByStreamFlowControl }).Should(BeTrue())
+		stats := str.Stats()
+		Expect(stats.BlockedByConnectionFlowControl).To(BeFalse())
+		Expect(stats.SendWindow).To(BeZero())
+		Expect(stats.BytesWritten).To(BeNumerically(">=", 10000))
+		Eventually(func() uint64 { return str.Stats().BytesAcked }).Should(BeEquivalentTo(10000))
+
+		serverStats := serverStr.Stats()
+		Expect(serverStats.BytesReceived).To(BeEquivalentTo(10000))
+		Expect(serverStats.BytesRead).To(BeZero())
+		Expect(serverStats.ReceiveWindow).To(BeZero())
+	})
+})
@@ -22,6 +22,40 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+// replayingSessionCache returns the same session ticket over and over again
+type replayingSessionCache struct {
+	tls.ClientSessionCache
+
+	mutex sync.Mutex
+	state *tls.ClientSessionState
+}
+
+func (c *replayingSessionCache) Get(sessionKey string) (*tls.ClientSessionState, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.state == nil {
+		state, ok := c.ClientSessionCache.Get(sessionKey)
+		if !ok {
+			return nil, false
+		}
+		c.state = state
+	}
+	// qtls modifies the session state when using it
+	state := *c.state
+	return &state, true
+}
+
+func (c *replayingSessionCache) Put(string, *tls.ClientSessionState) {}
+
+type zeroRTTRejectionTracer struct {
+	logging.NullConnectionTracer
+	reasons chan logging.ZeroRTTRejectionReason
+}
+
+func (t *zeroRTTRejectionTracer) Rejected0RTT(reason logging.ZeroRTTRejectionReason) {
+	t.reasons <- reason
+}
+
 var _ = Describe("0-RTT", func() {
 	rtt := scaleDuration(5 * time.Millisecond)
 
@@ -34,7 +68,7 @@ var _ = Describe("0-RTT", func() {
 				proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
 					RemoteAddr: fmt.Sprintf("localhost:%d", serverPort),
 					DelayPacket: func(_ quicproxy.Direction, data []byte) time.Duration {
-						for len(data) > 0 {
+						for len(data) > 0 && wire.IsLongHeaderPacket(data[0]) {
 							hdr, _, rest, err := wire.ParsePacket(data, 0)
 							Expect(err).ToNot(HaveOccurred())
 							if hdr.Type == protocol.PacketType0RTT {
@@ -335,6 +369,9 @@ var _ = Describe("0-RTT", func() {
 				proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
 					RemoteAddr: fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
 					DelayPacket: func(_ quicproxy.Direction, data []byte) time.Duration {
+						if !wire.IsLongHeaderPacket(data[0]) {
+							return rtt / 2
+						}
 						hdr, _, _, err := wire.ParsePacket(data, 0)
 						Expect(err).ToNot(HaveOccurred())
 						if hdr.Type == protocol.PacketType0RTT {
@@ -343,6 +380,9 @@ var _ = Describe("0-RTT", func() {
 						return rtt / 2
 					},
 					DropPacket: func(_ quicproxy.Direction, data []byte) bool {
+						if !wire.IsLongHeaderPacket(data[0]) {
+							return false
+						}
 						hdr, _, _, err := wire.ParsePacket(data, 0)
 						Expect(err).ToNot(HaveOccurred())
 						if hdr.Type == protocol.PacketType0RTT {
@@ -489,6 +529,66 @@ var _ = Describe("0-RTT", func() {
 				Expect(conn.CloseWithError(0, "")).To(Succeed())
 			})
 
+			It("sends datagrams in 0-RTT", func() {
+				tlsConf, clientConf := dialAndReceiveSessionTicket(getQuicConfig(&quic.Config{
+					Versions:        []protocol.VersionNumber{version},
+					EnableDatagrams: true,
+				}))
+
+				tracer := newPacketTracer()
+				ln, err := quic.ListenAddrEarly(
+					"localhost:0",
+					tlsConf,
+					getQuicConfig(&quic.Config{
+						Versions:        []protocol.VersionNumber{version},
+						EnableDatagrams: true,
+						Tracer:          newTracer(func() logging.ConnectionTracer { return tracer }),
+					}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+				proxy, _ := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
+				defer proxy.Close()
+
+				conn, err := quic.DialAddrEarly(
+					fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+					clientConf,
+					getQuicConfig(&quic.Config{
+						Versions:        []protocol.VersionNumber{version},
+						EnableDatagrams: true,
+					}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer conn.CloseWithError(0, "")
+				// The client remembers the server's max_datagram_frame_size from the session ticket.
+				Expect(conn.MaxDatagramSize()).To(BeNumerically(">", 0))
+				Expect(conn.SendMessage([]byte("foobar"))).To(Succeed())
+				Expect(conn.ConnectionState().SupportsDatagrams).To(BeTrue())
+
+				serverConn, err := ln.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				data, err := serverConn.ReceiveMessage()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte("foobar")))
+				Expect(serverConn.ConnectionState().TLS.Used0RTT).To(BeTrue())
+				Expect(conn.ConnectionState().TLS.Used0RTT).To(BeTrue())
+				Expect(serverConn.CloseWithError(0, "")).To(Succeed())
+				Eventually(conn.Context().Done()).Should(BeClosed())
+
+				var sentIn0RTT bool
+				for _, p := range tracer.getRcvdLongHeaderPackets() {
+					if p.hdr.Type != protocol.PacketType0RTT {
+						continue
+					}
+					for _, f := range p.frames {
+						if _, ok := f.(*logging.DatagramFrame); ok {
+							sentIn0RTT = true
+						}
+					}
+				}
+				Expect(sentIn0RTT).To(BeTrue())
+			})
+
 			It("rejects 0-RTT when the server's stream limit decreased", func() {
 				const maxStreams = 42
 				tlsConf, clientConf := dialAndReceiveSessionTicket(getQuicConfig(&quic.Config{
@@ -547,6 +647,72 @@ var _ = Describe("0-RTT", func() {
 				Expect(get0RTTPackets(tracer.getRcvdLongHeaderPackets())).To(BeEmpty())
 			})
 
+			It("rejects 0-RTT when a session ticket is replayed", func() {
+				tlsConf, clientConf := dialAndReceiveSessionTicket(nil)
+				clientConf.ClientSessionCache = &replayingSessionCache{ClientSessionCache: clientConf.ClientSessionCache}
+
+				tracer := &zeroRTTRejectionTracer{reasons: make(chan logging.ZeroRTTRejectionReason, 10)}
+				ln, err := quic.ListenAddrEarly(
+					"localhost:0",
+					tlsConf,
+					getQuicConfig(&quic.Config{
+						Versions:          []protocol.VersionNumber{version},
+						ZeroRTTAntiReplay: quic.NewZeroRTTAntiReplayCache(time.Minute),
+						Tracer:            newTracer(func() logging.ConnectionTracer { return tracer }),
+					}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+				proxy, _ := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
+				defer proxy.Close()
+
+				transfer0RTTData(ln, proxy.LocalPort(), clientConf, nil, PRData)
+				Consistently(tracer.reasons).ShouldNot(Receive())
+				// the session ticket can only be used for 0-RTT once
+				check0RTTRejected(ln, proxy.LocalPort(), clientConf)
+				Expect(tracer.reasons).To(Receive(Equal(logging.ZeroRTTRejectedReplay)))
+			})
+
+			It("rejects 0-RTT when the application rejects it", func() {
+				tlsConf, clientConf := dialAndReceiveSessionTicket(getQuicConfig(&quic.Config{
+					Versions:             []protocol.VersionNumber{version},
+					GetSessionTicketData: func(net.Addr) []byte { return []byte("session data") },
+				}))
+
+				type allow0RTTCall struct {
+					remote net.Addr
+					data   []byte
+				}
+				calls := make(chan allow0RTTCall, 1)
+				tracer := &zeroRTTRejectionTracer{reasons: make(chan logging.ZeroRTTRejectionReason, 10)}
+				ln, err := quic.ListenAddrEarly(
+					"localhost:0",
+					tlsConf,
+					getQuicConfig(&quic.Config{
+						Versions: []protocol.VersionNumber{version},
+						Allow0RTT: func(remote net.Addr, data []byte) bool {
+							calls <- allow0RTTCall{remote: remote, data: data}
+							return false
+						},
+						Tracer: newTracer(func() logging.ConnectionTracer { return tracer }),
+					}),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+				proxy, num0RTTPackets := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
+				defer proxy.Close()
+
+				check0RTTRejected(ln, proxy.LocalPort(), clientConf)
+				var call allow0RTTCall
+				Expect(calls).To(Receive(&call))
+				Expect(call.data).To(Equal([]byte("session data")))
+				Expect(call.remote.(*net.UDPAddr).IP.IsLoopback()).To(BeTrue())
+				Expect(tracer.reasons).To(Receive(Equal(logging.ZeroRTTRejectedByApplication)))
+
+				// The client should send 0-RTT packets, but the server doesn't process them.
+				Expect(atomic.LoadUint32(num0RTTPackets)).ToNot(BeZero())
+			})
+
 			DescribeTable("flow control limits",
 				func(addFlowControlLimit func(*quic.Config, uint64)) {
 					tracer := newPacketTracer()
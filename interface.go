@@ -3,11 +3,12 @@ package quic
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"time"
 
-	"github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/logging"
@@ -47,6 +48,17 @@ type TokenStore interface {
 	Put(key string, token *ClientToken)
 }
 
+// A ZeroRTTAntiReplay protects the server against replays of 0-RTT data.
+// It is consulted every time a client attempts 0-RTT using a session ticket.
+type ZeroRTTAntiReplay interface {
+	// Accept is called when a client attempts 0-RTT.
+	// The ticketID uniquely identifies the session ticket, issuedAt is the time the ticket was issued.
+	// Since a session ticket may only be used for 0-RTT once, it must return false if the ticket was already used.
+	// When rejected, the handshake falls back to a 1-RTT handshake.
+	// It may be called concurrently from different connections.
+	Accept(ticketID []byte, issuedAt time.Time) bool
+}
+
 // Err0RTTRejected is the returned from:
 // * Open{Uni}Stream{Sync}
 // * Accept{Uni}Stream
@@ -90,6 +102,9 @@ type ReceiveStream interface {
 	// Read will unblock immediately, and future Read calls will fail.
 	// When called multiple times or after reading the io.EOF it is a no-op.
 	CancelRead(StreamErrorCode)
+	// Stats returns the statistics of the stream.
+	// Only the receive-side fields of the StreamStats are populated.
+	Stats() StreamStats
 	// SetReadDeadline sets the deadline for future Read calls and
 	// any currently-blocked Read call.
 	// A zero value for t means Read will not time out.
@@ -119,6 +134,14 @@ type SendStream interface {
 	// Write will unblock immediately, and future calls to Write will fail.
 	// When called multiple times or after closing the stream it is a no-op.
 	CancelWrite(StreamErrorCode)
+	// CancelWriteAt aborts sending on this stream, but guarantees that the first reliableSize bytes
+	// written to the stream are delivered to the peer, see draft-ietf-quic-reliable-stream-reset.
+	// Data written beyond reliableSize is not guaranteed to be delivered.
+	// Write will unblock immediately, and future calls to Write will fail.
+	// It returns an error if the peer doesn't support reliable stream resets (see Config.EnableResetStreamAt),
+	// or if reliableSize is larger than the number of bytes written.
+	// After the stream was canceled, calling CancelWriteAt again can only lower the reliable size.
+	CancelWriteAt(code StreamErrorCode, reliableSize uint64) error
 	// The Context is canceled as soon as the write-side of the stream is closed.
 	// This happens when Close() or CancelWrite() is called, or when the peer
 	// cancels the read-side of their stream.
@@ -129,6 +152,16 @@ type SendStream interface {
 	// some data was successfully written.
 	// A zero value for t means Write will not time out.
 	SetWriteDeadline(t time.Time) error
+	// SetPriority sets the priority of the stream, following the model of RFC 9218.
+	// The urgency ranges from 0 (highest priority) to 7 (lowest priority), larger values are treated as 7.
+	// Data of incremental streams is interleaved with data of other streams of the same urgency,
+	// while non-incremental streams are sent one after the other.
+	// How the priority is used depends on the Config.StreamScheduler. By default, priorities are ignored.
+	// By default, streams have an urgency of 3 and are incremental.
+	SetPriority(urgency uint8, incremental bool)
+	// Stats returns the statistics of the stream.
+	// Only the send-side fields of the StreamStats are populated.
+	Stats() StreamStats
 }
 
 // A Connection is a QUIC connection between two peers.
@@ -185,12 +218,114 @@ type Connection interface {
 	ConnectionState() ConnectionState
 
 	// SendMessage sends a message as a datagram, as specified in RFC 9221.
+	// Depending on Config.DatagramSendQueueLen, it either blocks until the message was dequeued for sending,
+	// or it queues the message and returns immediately.
 	SendMessage([]byte) error
+	// SendMessageWithCallbacks sends a message as a datagram, like SendMessage.
+	// onAcked is called when the packet containing the message is acknowledged by the peer,
+	// onLost is called when that packet is declared lost, or when the message is dropped from the send queue.
+	// A message sent in a packet that is retransmitted as a probe packet is also declared lost.
+	// Either callback may be nil. They are called from the connection's run loop and must not block.
+	// Neither callback is called if the connection is closed before the packet is acknowledged or declared lost.
+	SendMessageWithCallbacks(msg []byte, onAcked, onLost func()) error
 	// ReceiveMessage gets a message received in a datagram, as specified in RFC 9221.
 	ReceiveMessage() ([]byte, error)
+	// ReceiveMessageContext gets a message received in a datagram, like ReceiveMessage.
+	// It returns the context's error when the context is canceled before a message is received.
+	ReceiveMessageContext(context.Context) ([]byte, error)
+	// MaxDatagramSize returns the maximum size of a message that can currently be sent using SendMessage.
+	// It depends on the peer's max_datagram_frame_size transport parameter and on the current path MTU,
+	// and is 0 if the peer doesn't support datagrams or the handshake hasn't completed yet.
+	// Sending a larger message fails with a DatagramTooLargeError.
+	MaxDatagramSize() int64
 
 	// BandwidthEstimate gets the current estimate of bandwidth in bps
 	BandwidthEstimate() Bandwidth
+	// Stats returns a snapshot of statistics about the connection.
+	// The snapshot is updated by the connection every time it processes an event,
+	// so it may lag slightly behind the connection's current state.
+	// It may be called concurrently, from callbacks (e.g. from the Tracer or the PathScheduler),
+	// and after the connection was closed.
+	Stats() ConnectionStats
+
+	// MigrateTo migrates the connection to a new path, using the given packet conn for sending and receiving.
+	// The new path is validated first (see section 8.2 of RFC 9000): The connection only switches to
+	// the new path once the peer responded to the PATH_CHALLENGE sent on that path.
+	// It blocks until the new path was validated, or path validation failed.
+	// After migrating, the congestion controller and the RTT estimate are reset.
+	// Only the client can migrate a connection, and only after the handshake was confirmed.
+	// After migrating, the connection stops using the old packet conn, so the application may close it.
+	// The packet conn is not closed when the connection is closed, or when it migrates away from it.
+	// quic-go starts reading from it when path validation starts, and keeps reading until the application closes it,
+	// so the application is responsible for closing the packet conn once it is not used anymore.
+	// If multipath was negotiated, AddPath must be used instead.
+	MigrateTo(net.PacketConn) error
+	// AddPath adds a new path to a connection that uses multipath (draft-ietf-quic-multipath-04),
+	// using the given packet conn for sending and receiving.
+	// The new path is validated first. It blocks until the path was validated, or path validation failed.
+	// Once the path is validated, packets are sent on all available paths, as chosen by the PathScheduler.
+	// Only the client can add paths, and only after the handshake was confirmed.
+	// The packet conn is not closed when the path or the connection is closed.
+	// Packets are read from it until the application closes it, so the application needs to close it
+	// after closing the path (or the connection).
+	AddPath(net.PacketConn) (Path, error)
+}
+
+// PathStatus is the status of a path of a multipath connection, see section 6 of draft-ietf-quic-multipath-04.
+type PathStatus uint8
+
+const (
+	// PathStatusAvailable means that the path can be used to send packets.
+	PathStatusAvailable PathStatus = iota
+	// PathStatusStandby means that the path should only be used if no available path can be used.
+	PathStatusStandby
+)
+
+func (s PathStatus) String() string {
+	switch s {
+	case PathStatusAvailable:
+		return "available"
+	case PathStatusStandby:
+		return "standby"
+	default:
+		return fmt.Sprintf("unknown path status: %d", s)
+	}
+}
+
+// A Path is a path of a multipath connection.
+type Path interface {
+	// LocalAddr returns the local address of the path.
+	LocalAddr() net.Addr
+	// RemoteAddr returns the address of the peer on this path.
+	RemoteAddr() net.Addr
+	// SetStatus sets the status of the path, and informs the peer about the new status.
+	SetStatus(PathStatus) error
+	// Close abandons the path. The peer is informed by a PATH_ABANDON frame.
+	// It doesn't close the connection, nor the packet conn that was passed to Connection.AddPath.
+	Close() error
+}
+
+// PathInfo contains information about a path, which is used by a PathScheduler to select a path.
+type PathInfo struct {
+	LocalAddr  net.Addr
+	RemoteAddr net.Addr
+	Status     PathStatus
+	// SmoothedRTT is the smoothed RTT measured on this path.
+	// It is zero if no RTT sample has been obtained yet.
+	SmoothedRTT time.Duration
+	// BandwidthEstimate is the bandwidth estimate of the congestion controller of this path.
+	BandwidthEstimate Bandwidth
+}
+
+// A PathScheduler decides which path of a multipath connection a packet is sent on.
+type PathScheduler interface {
+	// SelectPath is called before sending a packet.
+	// It is only passed paths that are not congestion limited.
+	// Paths in standby are only passed if no available path can be used.
+	// It returns the index of the path to send the packet on.
+	// Returning an invalid index (e.g. -1) means that no packet is sent.
+	// The same PathScheduler might be used by multiple connections concurrently.
+	SelectPath([]PathInfo) int
 }
 
 // An EarlyConnection is a connection that is handshaking.
@@ -211,6 +346,9 @@ type EarlyConnection interface {
 // StatelessResetKey is a key used to derive stateless reset tokens.
 type StatelessResetKey [32]byte
 
+// TokenKey is a key used to protect address validation tokens (Retry tokens and tokens sent in NEW_TOKEN frames).
+type TokenKey [32]byte
+
 // A ConnectionID is a QUIC Connection ID, as defined in RFC 9000.
 // It is not able to handle QUIC Connection IDs longer than 20 bytes,
 // as they are allowed by RFC 8999.
@@ -279,6 +417,13 @@ type Config struct {
 	// for tokens that were issued on a previous connection.
 	// If not set, it defaults to 24 hours. Only valid for a server.
 	MaxTokenAge time.Duration
+	// TokenKeys are the keys used to protect address validation tokens.
+	// The first key is used to issue new tokens, the remaining keys are only used to validate tokens,
+	// allowing the keys to be rotated without invalidating tokens that were already issued.
+	// Servers sharing the same keys (e.g. behind a load balancer) accept each other's tokens.
+	// If not set, a random key is generated. Only valid for a server.
+	// The keys can be changed on a running server using Listener.SetTokenKeys.
+	TokenKeys []TokenKey
 	// The TokenStore stores tokens received from the server.
 	// Tokens are used to skip address validation on future connection attempts.
 	// The key used to store tokens is the ServerName from the tls.Config, if set
@@ -319,6 +464,11 @@ type Config struct {
 	MaxIncomingUniStreams int64
 	// The StatelessResetKey is used to generate stateless reset tokens.
 	// If no key is configured, sending of stateless resets is disabled.
+	// The key can be rotated on a running server using Listener.SetStatelessResetKeys.
+	// Connections can't be handed off to another process, e.g. when restarting the server:
+	// Their state (the packet protection keys, in-flight packets, and stream and flow control state) can't be exported.
+	// A process that takes over the UDP socket should use the same key, so that it resets the connections of the old process,
+	// and clients can reconnect immediately instead of waiting for the idle timeout.
 	StatelessResetKey *StatelessResetKey
 	// KeepAlivePeriod defines whether this peer will periodically send a packet to keep the connection alive.
 	// If set to 0, then no keep alive is sent. Otherwise, the keep alive is sent on that period (or at most
@@ -334,18 +484,212 @@ type Config struct {
 	DisableVersionNegotiationPackets bool
 	// Enable QUIC datagram support (RFC 9221).
 	EnableDatagrams bool
-	Tracer          logging.Tracer
-	// UseBBR switches between CUBIC (false) and BBR (true) being used as a congestion control algro
+	// DatagramSendQueueLen is the maximum number of datagrams that are queued for sending.
+	// If 0, SendMessage blocks until the datagram has been dequeued for sending.
+	// Otherwise, SendMessage never blocks: if the queue is full, a datagram is dropped according to the DatagramDropPolicy.
+	DatagramSendQueueLen int
+	// DatagramDropPolicy determines which datagram is dropped when the datagram send queue is full.
+	// It is only used if DatagramSendQueueLen is set.
+	DatagramDropPolicy DatagramDropPolicy
+	Tracer             logging.Tracer
+	// UseBBR switches between NewReno (false) and BBR (true) being used as a congestion control algorithm.
+	// It is ignored if CongestionControl is set.
 	UseBBR bool
+	// CongestionControl creates the congestion controller for a connection.
+	// It is called for every new connection, when a connection migrates to a new path,
+	// and for every additional path of a multipath connection.
+	// The congestion package contains ready-made implementations (NewReno, NewCubic, NewBBR and NewBBRv2).
+	// If not set, NewReno is used (or NewBBR, if UseBBR is set).
+	CongestionControl func(congestion.ConnectionInfo) congestion.CongestionController
+	// PreferredAddress is an address that clients are asked to migrate to after the handshake.
+	// Only valid for a server.
+	PreferredAddress *PreferredAddress
+	// EnableMultipath enables the multipath extension (draft-ietf-quic-multipath-04).
+	// It is only used if the peer supports multipath as well, and if both endpoints use non-zero-length connection IDs.
+	// Paths are added by the client using Connection.AddPath.
+	EnableMultipath bool
+	// PathScheduler selects the path that packets are sent on, if multipath is used.
+	// If not set, the path with the lowest smoothed RTT is used.
+	PathScheduler PathScheduler
+	// StreamScheduler creates the StreamScheduler for a connection,
+	// which determines the order in which data of different streams is sent.
+	// If not set, all streams take turns, ignoring the priorities set by SendStream.SetPriority
+	// (see NewRoundRobinStreamScheduler).
+	StreamScheduler func() StreamScheduler
+	// EnableResetStreamAt enables reliable stream resets (draft-ietf-quic-reliable-stream-reset).
+	// SendStream.CancelWriteAt can only be used if the peer enabled reliable stream resets as well.
+	EnableResetStreamAt bool
+	// EnableAckFrequency enables the ACK frequency extension (draft-ietf-quic-ack-frequency).
+	// If the peer supports it as well, we honor its requests to send ACKs less frequently,
+	// and ask it to reduce its ACK rate when our congestion window grows large.
+	// Congestion controllers can request a specific ACK rate by implementing congestion.AckFrequencyRequester.
+	EnableAckFrequency bool
+	// ZeroRTTAntiReplay detects replays of 0-RTT data.
+	// Servers sharing the key used to encrypt session tickets should use a ZeroRTTAntiReplay backed by a shared store,
+	// otherwise a ticket issued by one server can be replayed against the others.
+	// If not set, replays of 0-RTT data are not detected, and the application must make sure that 0-RTT data is idempotent.
+	// A single server can use NewZeroRTTAntiReplayCache.
+	// Only valid for a server accepting 0-RTT (see ListenEarly).
+	ZeroRTTAntiReplay ZeroRTTAntiReplay
+	// Allow0RTT is called by the server every time a client attempts 0-RTT.
+	// The sessionData is the data returned by GetSessionTicketData when the session ticket was issued.
+	// If it returns false, 0-RTT is rejected, and the handshake falls back to a 1-RTT handshake.
+	// If not set, 0-RTT is accepted (unless it is rejected for other reasons).
+	// Only valid for a server accepting 0-RTT (see ListenEarly).
+	Allow0RTT func(remote net.Addr, sessionData []byte) bool
+	// GetSessionTicketData is called by the server when issuing a session ticket.
+	// The returned data is stored in the (encrypted) session ticket, and passed to Allow0RTT
+	// when the client uses the ticket for 0-RTT.
+	// Only valid for a server accepting 0-RTT (see ListenEarly).
+	GetSessionTicketData func(remote net.Addr) []byte
+	// AdmissionController limits the rate of new connection attempts, and the number of concurrent handshakes.
+	// The same AdmissionController can be used by multiple servers.
+	// If not set, new connection attempts are only limited by the size of the accept queue.
+	// Only valid for a server.
+	AdmissionController *AdmissionController
+	// ShutdownErrorCode is the application error code used to close the connections
+	// that are still open when the context passed to Listener.Shutdown expires.
+	// Only valid for a server.
+	ShutdownErrorCode ApplicationErrorCode
+}
+
+// StreamPriority is the priority of a stream, following the model of RFC 9218.
+type StreamPriority struct {
+	// Urgency ranges from 0 (highest priority) to 7 (lowest priority).
+	Urgency uint8
+	// Incremental says if data of this stream may be interleaved with data of other streams.
+	Incremental bool
+}
+
+// A StreamScheduler decides which of the streams that have data to send is allowed to send next.
+// A new StreamScheduler is created for every connection (see Config.StreamScheduler).
+// Calls to the StreamScheduler are serialized, so it doesn't need to be safe for concurrent use.
+// NewRoundRobinStreamScheduler, NewStrictPriorityStreamScheduler and NewWeightedFairStreamScheduler
+// return the StreamSchedulers implemented by quic-go.
+type StreamScheduler interface {
+	// Add adds a stream that has data to send.
+	Add(StreamID, StreamPriority)
+	// Requeue adds a stream that still has data to send, after it was returned by Pop and sent n bytes.
+	Requeue(id StreamID, prio StreamPriority, n int64)
+	// Pop removes the stream that is allowed to send next, and returns its stream ID.
+	// It is only called if Len() > 0.
+	Pop() StreamID
+	// SetPriority changes the priority of a stream that was added before.
+	SetPriority(StreamID, StreamPriority)
+	// Len returns the number of streams that have data to send.
+	Len() int
+	// Reset removes all streams. It is called when 0-RTT is rejected.
+	Reset()
+}
+
+// A DatagramDropPolicy determines which datagram is dropped when the datagram send queue is full.
+type DatagramDropPolicy uint8
+
+const (
+	// DatagramDropOldest drops the datagram that has been queued the longest, making room for the new datagram.
+	DatagramDropOldest DatagramDropPolicy = iota
+	// DatagramDropNewest drops the new datagram, keeping the datagrams already queued.
+	DatagramDropNewest
+)
+
+func (p DatagramDropPolicy) String() string {
+	switch p {
+	case DatagramDropOldest:
+		return "drop oldest"
+	case DatagramDropNewest:
+		return "drop newest"
+	default:
+		return fmt.Sprintf("unknown datagram drop policy: %d", p)
+	}
+}
+
+// A PreferredAddress is an address that a server advertises during the handshake,
+// see section 9.6 of RFC 9000.
+// This allows a server that is reachable on a shared (e.g. anycast) address to move clients
+// to a unicast address. Clients validate the path to the preferred address and migrate the
+// connection once the handshake is confirmed.
+type PreferredAddress struct {
+	// IPv4 and IPv6 are the addresses advertised to the client.
+	// At least one of them must be set.
+	IPv4 *net.UDPAddr
+	IPv6 *net.UDPAddr
+	// Conn receives the packets that clients send to the preferred address.
+	// It must not be the packet conn that the server is listening on.
+	// Closing the server closes the connections that migrated to the preferred address,
+	// but it doesn't close this packet conn. The application needs to close it after closing the server.
+	Conn net.PacketConn
 }
 
 // ConnectionState records basic details about a QUIC connection
 type ConnectionState struct {
 	TLS               handshake.ConnectionState
 	SupportsDatagrams bool
+	SupportsMultipath bool
 	Version           VersionNumber
 }
 
+// ConnectionStats contains statistics about a QUIC connection.
+// The RTT values, the congestion window and the bytes in flight are those of the connection's
+// current path (for a multipath connection: of the initial path).
+// The packet and byte counters are summed up over all paths of the connection.
+type ConnectionStats struct {
+	// The RTT values are zero until the first RTT sample was obtained.
+	SmoothedRTT time.Duration
+	MinRTT      time.Duration
+	LatestRTT   time.Duration
+	// RTTVariance is the mean deviation of the RTT samples (rttvar in RFC 9002).
+	RTTVariance time.Duration
+
+	CongestionWindow uint64
+	BytesInFlight    uint64
+	// MaxDatagramSize is the current maximum datagram size, as determined by Path MTU Discovery.
+	MaxDatagramSize uint64
+
+	PacketsSent     uint64
+	BytesSent       uint64
+	PacketsReceived uint64
+	BytesReceived   uint64
+	// PacketsLost and BytesLost count the packets that were declared lost by the loss detection.
+	PacketsLost uint64
+	BytesLost   uint64
+	// PacketsRetransmitted and BytesRetransmitted count the packets whose frames were queued for retransmission.
+	// This happens when a packet is declared lost, and when a probe packet is sent after a PTO.
+	PacketsRetransmitted uint64
+	BytesRetransmitted   uint64
+	// PTOCount is the number of times the probe timeout (PTO) fired.
+	PTOCount uint64
+
+	// KeyPhase is the number of key updates that were performed (see section 6 of RFC 9001).
+	KeyPhase uint64
+}
+
+// StreamStats contains statistics about a stream.
+// For a unidirectional stream, only the fields for the direction of the stream are populated.
+type StreamStats struct {
+	// BytesWritten is the number of bytes that were taken from Write calls so far.
+	// It also counts bytes consumed by a Write call that hasn't returned yet.
+	BytesWritten uint64
+	// BytesAcked is the number of bytes of stream data that were acknowledged by the peer.
+	BytesAcked uint64
+	// BytesRetransmitted is the number of bytes of stream data that were queued for retransmission.
+	BytesRetransmitted uint64
+	// SendWindow is the number of bytes that can be sent before the stream is blocked by flow control,
+	// taking into account both the stream-level and the connection-level flow control limit.
+	// It is updated when data is sent on the stream, and when the peer increases the stream's flow control limit.
+	SendWindow uint64
+	// BlockedByStreamFlowControl and BlockedByConnectionFlowControl are set when there's data to send,
+	// but the respective flow control limit doesn't allow sending it.
+	BlockedByStreamFlowControl     bool
+	BlockedByConnectionFlowControl bool
+
+	// BytesReceived is the highest offset of stream data received from the peer.
+	BytesReceived uint64
+	// BytesRead is the number of bytes returned by Read.
+	BytesRead uint64
+	// ReceiveWindow is the number of bytes the peer is allowed to send before it is blocked by stream-level flow control.
+	ReceiveWindow uint64
+}
+
 // A Listener for incoming QUIC connections
 type Listener interface {
 	// Close the server. All active connections will be closed.
@@ -354,6 +698,21 @@ type Listener interface {
 	Addr() net.Addr
 	// Accept returns new connections. It should be called in a loop.
 	Accept(context.Context) (Connection, error)
+	// Shutdown gracefully shuts down the server.
+	// New connection attempts are refused with a CONNECTION_REFUSED error, while existing connections are allowed to finish.
+	// Once all connections have been closed, or when the context expires, the server is closed.
+	// Connections that are still open when the context expires are closed using the Config.ShutdownErrorCode.
+	Shutdown(context.Context) error
+	// SetTokenKeys replaces the keys used to protect address validation tokens (see Config.TokenKeys).
+	// The first key is used to issue new tokens, the remaining keys are only used to validate tokens.
+	SetTokenKeys([]TokenKey) error
+	// SetStatelessResetKeys replaces the keys used to derive stateless reset tokens.
+	// It accepts at most two keys: the current key, which is used for new connection IDs, and the previous key.
+	// When sending a stateless reset, one packet is sent for every key,
+	// so that connection IDs issued before the rotation can still be reset,
+	// as long as the stateless resets are smaller in total than the packet that triggered them.
+	// This also applies to client connections using the same packet conn.
+	SetStatelessResetKeys([]StatelessResetKey) error
 }
 
 // An EarlyListener listens for incoming QUIC connections,
@@ -365,4 +724,19 @@ type EarlyListener interface {
 	Addr() net.Addr
 	// Accept returns new early connections. It should be called in a loop.
 	Accept(context.Context) (EarlyConnection, error)
+	// Shutdown gracefully shuts down the server.
+	// New connection attempts are refused with a CONNECTION_REFUSED error, while existing connections are allowed to finish.
+	// Once all connections have been closed, or when the context expires, the server is closed.
+	// Connections that are still open when the context expires are closed using the Config.ShutdownErrorCode.
+	Shutdown(context.Context) error
+	// SetTokenKeys replaces the keys used to protect address validation tokens (see Config.TokenKeys).
+	// The first key is used to issue new tokens, the remaining keys are only used to validate tokens.
+	SetTokenKeys([]TokenKey) error
+	// SetStatelessResetKeys replaces the keys used to derive stateless reset tokens.
+	// It accepts at most two keys: the current key, which is used for new connection IDs, and the previous key.
+	// When sending a stateless reset, one packet is sent for every key,
+	// so that connection IDs issued before the rotation can still be reset,
+	// as long as the stateless resets are smaller in total than the packet that triggered them.
+	// This also applies to client connections using the same packet conn.
+	SetStatelessResetKeys([]StatelessResetKey) error
 }
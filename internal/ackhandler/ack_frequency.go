@@ -0,0 +1,91 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/congestion"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+const (
+	// If the congestion controller doesn't request a specific ACK frequency,
+	// we ask the peer to send (roughly) this number of ACKs per congestion window.
+	acksPerCongestionWindow = 8
+	// The maximum ack-eliciting threshold we request if the congestion controller doesn't request a specific ACK frequency.
+	maxAckElicitingThreshold = 10
+	// We only update the requested max ack delay if it changed by more than 1/maxAckDelayChangeDivisor.
+	// This prevents sending an ACK_FREQUENCY frame every time the RTT estimate changes slightly.
+	maxAckDelayChangeDivisor = 4
+)
+
+// The ackFrequencyManager decides when to send ACK_FREQUENCY frames (draft-ietf-quic-ack-frequency).
+// It is only used for the application data packet number space.
+type ackFrequencyManager struct {
+	enabled         bool
+	peerMinAckDelay time.Duration
+
+	nextSeq uint64
+	// The values requested in the last ACK_FREQUENCY frame.
+	// Before the first frame is sent, these are the values the peer uses by default.
+	ackElicitingThreshold uint64
+	maxAckDelay           time.Duration
+}
+
+// Enable enables the sending of ACK_FREQUENCY frames.
+// peerMaxAckDelay is the max_ack_delay the peer uses until it receives the first ACK_FREQUENCY frame.
+func (m *ackFrequencyManager) Enable(peerMinAckDelay, peerMaxAckDelay time.Duration) {
+	m.enabled = true
+	m.peerMinAckDelay = peerMinAckDelay
+	m.ackElicitingThreshold = packetsBeforeAck - 1
+	m.maxAckDelay = peerMaxAckDelay
+}
+
+// Update returns an ACK_FREQUENCY frame, if the requested values differ from the values requested before.
+func (m *ackFrequencyManager) Update(ackElicitingThreshold uint64, maxAckDelay time.Duration) *wire.AckFrequencyFrame {
+	if !m.enabled {
+		return nil
+	}
+	// The peer is not allowed to delay ACKs by less than its min_ack_delay.
+	maxAckDelay = utils.Max(maxAckDelay, m.peerMinAckDelay)
+	delta := maxAckDelay - m.maxAckDelay
+	if delta < 0 {
+		delta = -delta
+	}
+	if ackElicitingThreshold == m.ackElicitingThreshold && delta <= m.maxAckDelay/maxAckDelayChangeDivisor {
+		return nil
+	}
+	m.ackElicitingThreshold = ackElicitingThreshold
+	m.maxAckDelay = maxAckDelay
+	f := &wire.AckFrequencyFrame{
+		SequenceNumber:        m.nextSeq,
+		AckElicitingThreshold: ackElicitingThreshold,
+		RequestMaxAckDelay:    maxAckDelay,
+		// Ask the peer to report reordering before our loss detection declares the packets lost.
+		ReorderingThreshold: packetThreshold - 1,
+	}
+	m.nextSeq++
+	return f
+}
+
+// requestedAckFrequency returns the ack-eliciting threshold and the max ack delay that should be requested from the peer.
+// Congestion controllers can determine these values by implementing the congestion.AckFrequencyRequester.
+// Otherwise, the peer is asked to acknowledge a fraction of the congestion window,
+// and to send ACKs at least 4 times per RTT.
+func requestedAckFrequency(cc congestion.CongestionController, rttStats *utils.RTTStats, maxDatagramSize protocol.ByteCount) (uint64, time.Duration) {
+	maxAckDelay := rttStats.MaxAckDelay()
+	if r, ok := cc.(congestion.AckFrequencyRequester); ok {
+		threshold, delay := r.AckFrequency()
+		if delay > 0 {
+			maxAckDelay = delay
+		}
+		return threshold, maxAckDelay
+	}
+	threshold := uint64(cc.GetCongestionWindow() / maxDatagramSize / acksPerCongestionWindow)
+	threshold = utils.Min(utils.Max(threshold, packetsBeforeAck-1), maxAckElicitingThreshold)
+	if srtt := rttStats.SmoothedRTT(); srtt > 0 {
+		maxAckDelay = utils.Min(maxAckDelay, srtt/4)
+	}
+	return threshold, maxAckDelay
+}
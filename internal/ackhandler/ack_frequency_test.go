@@ -0,0 +1,122 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/mocks"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type ackFrequencyRequestingCongestionController struct {
+	*mocks.MockSendAlgorithmWithDebugInfos
+	threshold   uint64
+	maxAckDelay time.Duration
+}
+
+func (c *ackFrequencyRequestingCongestionController) AckFrequency() (uint64, time.Duration) {
+	return c.threshold, c.maxAckDelay
+}
+
+var _ = Describe("ACK frequency", func() {
+	Context("manager", func() {
+		var m *ackFrequencyManager
+
+		BeforeEach(func() {
+			m = &ackFrequencyManager{}
+		})
+
+		It("doesn't send ACK_FREQUENCY frames before it is enabled", func() {
+			Expect(m.Update(10, 10*time.Millisecond)).To(BeNil())
+		})
+
+		It("doesn't send ACK_FREQUENCY frames if the peer's default values are requested", func() {
+			m.Enable(time.Millisecond, 25*time.Millisecond)
+			Expect(m.Update(1, 25*time.Millisecond)).To(BeNil())
+		})
+
+		It("sends ACK_FREQUENCY frames when the requested values change", func() {
+			m.Enable(time.Millisecond, 25*time.Millisecond)
+			f := m.Update(10, 25*time.Millisecond)
+			Expect(f).ToNot(BeNil())
+			Expect(f.SequenceNumber).To(BeZero())
+			Expect(f.AckElicitingThreshold).To(BeEquivalentTo(10))
+			Expect(f.RequestMaxAckDelay).To(Equal(25 * time.Millisecond))
+			Expect(f.ReorderingThreshold).To(BeEquivalentTo(packetThreshold - 1))
+			Expect(m.Update(10, 25*time.Millisecond)).To(BeNil())
+			f = m.Update(5, 25*time.Millisecond)
+			Expect(f).ToNot(BeNil())
+			Expect(f.SequenceNumber).To(BeEquivalentTo(1))
+			Expect(f.AckElicitingThreshold).To(BeEquivalentTo(5))
+		})
+
+		It("only sends ACK_FREQUENCY frames if the max ack delay changed significantly", func() {
+			m.Enable(time.Millisecond, 20*time.Millisecond)
+			Expect(m.Update(1, 24*time.Millisecond)).To(BeNil())
+			Expect(m.Update(1, 16*time.Millisecond)).To(BeNil())
+			f := m.Update(1, 10*time.Millisecond)
+			Expect(f).ToNot(BeNil())
+			Expect(f.RequestMaxAckDelay).To(Equal(10 * time.Millisecond))
+		})
+
+		It("doesn't request a max ack delay smaller than the peer's min_ack_delay", func() {
+			m.Enable(5*time.Millisecond, 25*time.Millisecond)
+			f := m.Update(1, time.Millisecond)
+			Expect(f).ToNot(BeNil())
+			Expect(f.RequestMaxAckDelay).To(Equal(5 * time.Millisecond))
+		})
+	})
+
+	Context("requested values", func() {
+		var (
+			rttStats *utils.RTTStats
+			cong     *mocks.MockSendAlgorithmWithDebugInfos
+		)
+
+		BeforeEach(func() {
+			rttStats = &utils.RTTStats{}
+			rttStats.SetMaxAckDelay(25 * time.Millisecond)
+			cong = mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+		})
+
+		It("requests the default threshold when the congestion window is small", func() {
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(10 * 1000))
+			threshold, maxAckDelay := requestedAckFrequency(cong, rttStats, 1000)
+			Expect(threshold).To(BeEquivalentTo(1))
+			Expect(maxAckDelay).To(Equal(25 * time.Millisecond))
+		})
+
+		It("requests fewer ACKs when the congestion window is large", func() {
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(64 * 1000))
+			threshold, _ := requestedAckFrequency(cong, rttStats, 1000)
+			Expect(threshold).To(BeEquivalentTo(8))
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(1000 * 1000))
+			threshold, _ = requestedAckFrequency(cong, rttStats, 1000)
+			Expect(threshold).To(BeEquivalentTo(maxAckElicitingThreshold))
+		})
+
+		It("requests ACKs at least 4 times per RTT", func() {
+			rttStats.UpdateRTT(40*time.Millisecond, 0, time.Now())
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(64 * 1000))
+			_, maxAckDelay := requestedAckFrequency(cong, rttStats, 1000)
+			Expect(maxAckDelay).To(Equal(10 * time.Millisecond))
+		})
+
+		It("uses the values requested by the congestion controller", func() {
+			cc := &ackFrequencyRequestingCongestionController{
+				MockSendAlgorithmWithDebugInfos: cong,
+				threshold:                       42,
+				maxAckDelay:                     7 * time.Millisecond,
+			}
+			threshold, maxAckDelay := requestedAckFrequency(cc, rttStats, 1000)
+			Expect(threshold).To(BeEquivalentTo(42))
+			Expect(maxAckDelay).To(Equal(7 * time.Millisecond))
+			cc.maxAckDelay = 0
+			_, maxAckDelay = requestedAckFrequency(cc, rttStats, 1000)
+			Expect(maxAckDelay).To(Equal(25 * time.Millisecond))
+		})
+	})
+})
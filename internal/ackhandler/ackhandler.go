@@ -1,6 +1,7 @@
 package ackhandler
 
 import (
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/logging"
@@ -9,6 +10,7 @@ import (
 // NewAckHandler creates a new SentPacketHandler and a new ReceivedPacketHandler.
 // clientAddressValidated indicates whether the address was validated beforehand by an address validation token.
 // clientAddressValidated has no effect for a client.
+// congestionControl is used to create the congestion controller.
 func NewAckHandler(
 	initialPacketNumber protocol.PacketNumber,
 	initialMaxDatagramSize protocol.ByteCount,
@@ -18,8 +20,31 @@ func NewAckHandler(
 	tracer logging.ConnectionTracer,
 	logger utils.Logger,
 	version protocol.VersionNumber,
-	useBBR bool,
+	congestionControl func(congestion.ConnectionInfo) congestion.CongestionController,
 ) (SentPacketHandler, ReceivedPacketHandler) {
-	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, clientAddressValidated, pers, tracer, logger, useBBR)
+	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, clientAddressValidated, pers, tracer, logger, congestionControl)
 	return sph, newReceivedPacketHandler(sph, rttStats, logger, version)
 }
+
+// NewPathAckHandler creates a new SentPacketHandler and a new ReceivedPacketHandler
+// for an additional path of a multipath connection.
+// Every path uses its own application data packet number space, congestion controller and RTT estimate.
+// Paths are only added after the handshake is confirmed, so there are no Initial and Handshake packet number spaces.
+func NewPathAckHandler(
+	initialMaxDatagramSize protocol.ByteCount,
+	rttStats *utils.RTTStats,
+	pers protocol.Perspective,
+	logger utils.Logger,
+	version protocol.VersionNumber,
+	congestionControl func(congestion.ConnectionInfo) congestion.CongestionController,
+) (SentPacketHandler, ReceivedPacketHandler) {
+	sph := newSentPacketHandler(0, initialMaxDatagramSize, rttStats, true, pers, nil, logger, congestionControl)
+	sph.initialPackets = nil
+	sph.handshakePackets = nil
+	sph.peerCompletedAddressValidation = true
+	sph.handshakeConfirmed = true
+	rph := newReceivedPacketHandler(sph, rttStats, logger, version)
+	rph.DropPackets(protocol.EncryptionInitial)
+	rph.DropPackets(protocol.EncryptionHandshake)
+	return sph, rph
+}
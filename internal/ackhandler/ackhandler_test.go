@@ -0,0 +1,45 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/congestion"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Ack Handler", func() {
+	It("only uses the application data packet number space", func() {
+		sph, rph := NewPathAckHandler(protocol.InitialPacketSizeIPv4, utils.NewRTTStats(), protocol.PerspectiveClient, utils.DefaultLogger, protocol.Version1, congestion.NewReno)
+		h := sph.(*sentPacketHandler)
+		Expect(h.initialPackets).To(BeNil())
+		Expect(h.handshakePackets).To(BeNil())
+		Expect(h.handshakeConfirmed).To(BeTrue())
+		Expect(sph.SendMode()).To(Equal(SendAny))
+		pn, _ := sph.PeekPacketNumber(protocol.Encryption1RTT)
+		Expect(pn).To(BeZero())
+
+		Expect(rph.GetAckFrame(protocol.EncryptionInitial, false)).To(BeNil())
+		Expect(rph.GetAckFrame(protocol.EncryptionHandshake, false)).To(BeNil())
+		Expect(rph.ReceivedPacket(0, protocol.ECNNon, protocol.Encryption1RTT, time.Now(), true)).To(Succeed())
+		ack := rph.GetAckFrame(protocol.Encryption1RTT, false)
+		Expect(ack).ToNot(BeNil())
+		Expect(ack.AckRanges).To(Equal([]wire.AckRange{{Smallest: 0, Largest: 0}}))
+	})
+
+	It("arms the PTO timer right away", func() {
+		sph, _ := NewPathAckHandler(protocol.InitialPacketSizeIPv4, utils.NewRTTStats(), protocol.PerspectiveServer, utils.DefaultLogger, protocol.Version1, congestion.NewReno)
+		sph.SentPacket(&Packet{
+			PacketNumber:    0,
+			Length:          100,
+			EncryptionLevel: protocol.Encryption1RTT,
+			SendTime:        time.Now(),
+			Frames:          []Frame{{Frame: &wire.PingFrame{}, OnLost: func(wire.Frame) {}}},
+		})
+		Expect(sph.GetLossDetectionTimeout()).ToNot(BeZero())
+	})
+})
@@ -0,0 +1,197 @@
+package ackhandler
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+type ecnState uint8
+
+const (
+	ecnStateInitial ecnState = iota
+	ecnStateTesting
+	ecnStateUnknown
+	ecnStateCapable
+	ecnStateFailed
+)
+
+// must fit into an uint8, otherwise numSentTesting and numLostTesting must have a larger type
+const numECNTestingPackets = 10
+
+// The ecnTracker performs ECN validation, as described in section 13.4.2 of RFC 9000.
+// It is only used for 1-RTT packets.
+//
+// The first numECNTestingPackets packets are sent with ECT(0).
+// We then stop marking packets until we either receive an acknowledgement for one of the testing packets
+// (in which case ECN validation succeeds), or all testing packets are declared lost (in which case it fails).
+// Validation also fails if the ECN counts reported by the peer are inconsistent with the packets we sent.
+type ecnTracker struct {
+	state                          ecnState
+	numSentTesting, numLostTesting uint8
+
+	firstTestingPacket protocol.PacketNumber
+	lastTestingPacket  protocol.PacketNumber
+	// The largest packet number acknowledged by an ACK frame that we used for validation.
+	largestAcked protocol.PacketNumber
+
+	numSentECT0, numSentECT1                  uint64
+	numAckedECT0, numAckedECT1, numAckedECNCE uint64
+
+	logger utils.Logger
+}
+
+func newECNTracker(logger utils.Logger) *ecnTracker {
+	return &ecnTracker{
+		firstTestingPacket: protocol.InvalidPacketNumber,
+		lastTestingPacket:  protocol.InvalidPacketNumber,
+		largestAcked:       protocol.InvalidPacketNumber,
+		logger:             logger,
+	}
+}
+
+// Mode returns the ECN codepoint to use for the next packet.
+func (e *ecnTracker) Mode() protocol.ECN {
+	switch e.state {
+	case ecnStateInitial, ecnStateTesting, ecnStateCapable:
+		return protocol.ECT0
+	default:
+		return protocol.ECNNon
+	}
+}
+
+// SentPacket is called for every 1-RTT packet sent.
+func (e *ecnTracker) SentPacket(pn protocol.PacketNumber, ecn protocol.ECN) {
+	switch ecn {
+	case protocol.ECNNon:
+		return
+	case protocol.ECT0:
+		e.numSentECT0++
+	case protocol.ECT1:
+		e.numSentECT1++
+	case protocol.ECNCE:
+		// An endpoint must not send packets marked with ECN-CE.
+		panic("tried to send an ECN-CE packet")
+	}
+	switch e.state {
+	case ecnStateInitial:
+		e.state = ecnStateTesting
+		e.firstTestingPacket = pn
+		fallthrough
+	case ecnStateTesting:
+		e.numSentTesting++
+		if e.numSentTesting >= numECNTestingPackets {
+			e.state = ecnStateUnknown
+			e.lastTestingPacket = pn
+			if e.logger.Debug() {
+				e.logger.Debugf("ECN: sent %d testing packets, waiting for acknowledgements", e.numSentTesting)
+			}
+		}
+	}
+}
+
+// RestartValidation restarts ECN validation, e.g. after the connection migrated to a new path.
+// The ECN counts are cumulative for the packet number space, so they are kept.
+func (e *ecnTracker) RestartValidation() {
+	e.state = ecnStateInitial
+	e.numSentTesting = 0
+	e.numLostTesting = 0
+	e.firstTestingPacket = protocol.InvalidPacketNumber
+	e.lastTestingPacket = protocol.InvalidPacketNumber
+}
+
+// LostPacket is called for every 1-RTT packet that is declared lost.
+func (e *ecnTracker) LostPacket(pn protocol.PacketNumber) {
+	if e.state != ecnStateTesting && e.state != ecnStateUnknown {
+		return
+	}
+	if !e.isTestingPacket(pn) {
+		return
+	}
+	e.numLostTesting++
+	if e.numLostTesting >= numECNTestingPackets {
+		e.failValidation("all testing packets were lost")
+	}
+}
+
+// HandleNewlyAcked processes the ECN counts of an ACK frame.
+// packets are the packets newly acknowledged by this ACK frame, in ascending packet number order.
+// It returns true if the ECN-CE count increased, i.e. if the congestion controller should be notified.
+func (e *ecnTracker) HandleNewlyAcked(packets []*Packet, ect0, ect1, ecnce uint64) (congested bool) {
+	if e.state == ecnStateFailed || len(packets) == 0 {
+		return false
+	}
+	// ECN counts can decrease if ACK frames are reordered.
+	// Only use ACK frames that increase the largest acknowledged packet number for validation.
+	// See section 13.4.2.1 of RFC 9000.
+	largestAcked := packets[len(packets)-1].PacketNumber
+	if largestAcked <= e.largestAcked {
+		return false
+	}
+	e.largestAcked = largestAcked
+
+	var newlyAckedECT0, newlyAckedECT1 uint64
+	var ackedTestingPacket bool
+	for _, p := range packets {
+		switch p.ECN {
+		case protocol.ECT0:
+			newlyAckedECT0++
+		case protocol.ECT1:
+			newlyAckedECT1++
+		default:
+			continue
+		}
+		if e.isTestingPacket(p.PacketNumber) {
+			ackedTestingPacket = true
+		}
+	}
+
+	// The ACK frame newly acknowledges packets that we sent with ECN marks, but doesn't contain any ECN counts.
+	if newlyAckedECT0+newlyAckedECT1 > 0 && ect0+ect1+ecnce == 0 {
+		e.failValidation("ACK frame doesn't contain ECN counts")
+		return false
+	}
+	// The peer reports more ECN marks than we sent.
+	if ect0 > e.numSentECT0 || ect1 > e.numSentECT1 || ect0+ect1+ecnce > e.numSentECT0+e.numSentECT1 {
+		e.failValidation("ECN counts exceed the number of marked packets sent")
+		return false
+	}
+	// ECN counts must never decrease.
+	if ect0 < e.numAckedECT0 || ect1 < e.numAckedECT1 || ecnce < e.numAckedECNCE {
+		e.failValidation("ECN counts decreased")
+		return false
+	}
+	// Every newly acknowledged ECT packet must be accounted for by an increase of the ECT or the ECN-CE count.
+	// Packets might have been re-marked from ECT to ECN-CE by the network, but not from ECT to not-ECT.
+	newECT0 := ect0 - e.numAckedECT0
+	newECT1 := ect1 - e.numAckedECT1
+	newECNCE := ecnce - e.numAckedECNCE
+	if newECT0+newECNCE < newlyAckedECT0 || newECT1+newECNCE < newlyAckedECT1 {
+		e.failValidation("ECN marks were removed by the network")
+		return false
+	}
+	e.numAckedECT0 = ect0
+	e.numAckedECT1 = ect1
+	e.numAckedECNCE = ecnce
+
+	if (e.state == ecnStateTesting || e.state == ecnStateUnknown) && ackedTestingPacket {
+		e.state = ecnStateCapable
+		if e.logger.Debug() {
+			e.logger.Debugf("ECN validation succeeded")
+		}
+	}
+	return newECNCE > 0
+}
+
+func (e *ecnTracker) isTestingPacket(pn protocol.PacketNumber) bool {
+	if e.firstTestingPacket == protocol.InvalidPacketNumber || pn < e.firstTestingPacket {
+		return false
+	}
+	return e.lastTestingPacket == protocol.InvalidPacketNumber || pn <= e.lastTestingPacket
+}
+
+func (e *ecnTracker) failValidation(reason string) {
+	e.state = ecnStateFailed
+	if e.logger.Debug() {
+		e.logger.Debugf("ECN validation failed: %s. Disabling ECN.", reason)
+	}
+}
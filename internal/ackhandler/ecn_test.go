@@ -0,0 +1,156 @@
+package ackhandler
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ECN tracker", func() {
+	var ecn *ecnTracker
+
+	getAckedPackets := func(pns ...protocol.PacketNumber) []*Packet {
+		var packets []*Packet
+		for _, pn := range pns {
+			p := GetPacket()
+			p.PacketNumber = pn
+			p.EncryptionLevel = protocol.Encryption1RTT
+			p.ECN = protocol.ECT0
+			packets = append(packets, p)
+		}
+		return packets
+	}
+
+	sendTestingPackets := func() {
+		for i := 0; i < numECNTestingPackets; i++ {
+			Expect(ecn.Mode()).To(Equal(protocol.ECT0))
+			ecn.SentPacket(protocol.PacketNumber(i), protocol.ECT0)
+		}
+	}
+
+	BeforeEach(func() {
+		ecn = newECNTracker(utils.DefaultLogger)
+	})
+
+	It("sends a limited number of testing packets", func() {
+		sendTestingPackets()
+		Expect(ecn.Mode()).To(Equal(protocol.ECNNon))
+		// packets sent without ECN marks don't change the state
+		ecn.SentPacket(10, protocol.ECNNon)
+		Expect(ecn.Mode()).To(Equal(protocol.ECNNon))
+	})
+
+	It("succeeds validation when a testing packet is acknowledged", func() {
+		sendTestingPackets()
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(0, 1, 2), 3, 0, 0)).To(BeFalse())
+		Expect(ecn.Mode()).To(Equal(protocol.ECT0))
+	})
+
+	It("succeeds validation before all testing packets were sent", func() {
+		ecn.SentPacket(0, protocol.ECT0)
+		ecn.SentPacket(1, protocol.ECT0)
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(0), 1, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateCapable))
+		for i := 2; i < 2*numECNTestingPackets; i++ {
+			ecn.SentPacket(protocol.PacketNumber(i), ecn.Mode())
+		}
+		Expect(ecn.Mode()).To(Equal(protocol.ECT0))
+	})
+
+	It("fails validation when all testing packets are lost", func() {
+		sendTestingPackets()
+		for i := 0; i < numECNTestingPackets; i++ {
+			ecn.LostPacket(protocol.PacketNumber(i))
+		}
+		Expect(ecn.state).To(Equal(ecnStateFailed))
+		Expect(ecn.Mode()).To(Equal(protocol.ECNNon))
+	})
+
+	It("doesn't fail validation if only some testing packets are lost", func() {
+		sendTestingPackets()
+		for i := 0; i < numECNTestingPackets-1; i++ {
+			ecn.LostPacket(protocol.PacketNumber(i))
+		}
+		// lost packets that weren't testing packets don't count
+		ecn.LostPacket(numECNTestingPackets + 1)
+		Expect(ecn.state).To(Equal(ecnStateUnknown))
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(numECNTestingPackets-1), 1, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateCapable))
+	})
+
+	It("fails validation when the ACK frame doesn't contain ECN counts", func() {
+		sendTestingPackets()
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(0, 1), 0, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateFailed))
+	})
+
+	It("fails validation when the ECN counts exceed the number of marked packets sent", func() {
+		sendTestingPackets()
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(0), numECNTestingPackets+1, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateFailed))
+	})
+
+	It("fails validation when the peer reports ECT(1) marks", func() {
+		sendTestingPackets()
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(0), 0, 1, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateFailed))
+	})
+
+	It("fails validation when the ECN counts decrease", func() {
+		sendTestingPackets()
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(0, 1, 2), 3, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateCapable))
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(3), 2, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateFailed))
+	})
+
+	It("fails validation when the network removes ECN marks", func() {
+		sendTestingPackets()
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(0, 1, 2), 2, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateFailed))
+	})
+
+	It("detects congestion when the ECN-CE count increases", func() {
+		sendTestingPackets()
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(0, 1, 2), 2, 0, 1)).To(BeTrue())
+		Expect(ecn.state).To(Equal(ecnStateCapable))
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(3, 4), 4, 0, 1)).To(BeFalse())
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(5), 4, 0, 2)).To(BeTrue())
+		Expect(ecn.state).To(Equal(ecnStateCapable))
+	})
+
+	It("ignores reordered ACK frames", func() {
+		sendTestingPackets()
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(5, 6), 6, 0, 1)).To(BeTrue())
+		// This ACK frame was sent before the one above. It would fail validation.
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(2, 3), 4, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateCapable))
+	})
+
+	It("doesn't do anything after validation failed", func() {
+		sendTestingPackets()
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(0), 0, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateFailed))
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(1), 1, 0, 1)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateFailed))
+	})
+
+	It("restarts validation, keeping the ECN counts", func() {
+		sendTestingPackets()
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(0, 1, 2), 3, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateCapable))
+		ecn.RestartValidation()
+		Expect(ecn.Mode()).To(Equal(protocol.ECT0))
+		for i := 0; i < numECNTestingPackets; i++ {
+			ecn.SentPacket(protocol.PacketNumber(100+i), protocol.ECT0)
+		}
+		Expect(ecn.Mode()).To(Equal(protocol.ECNNon))
+		// packets sent before the restart are not testing packets
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(3, 4), 5, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateUnknown))
+		Expect(ecn.HandleNewlyAcked(getAckedPackets(100), 6, 0, 0)).To(BeFalse())
+		Expect(ecn.state).To(Equal(ecnStateCapable))
+	})
+})
@@ -3,7 +3,7 @@ package ackhandler
 import (
 	"time"
 
-	"github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
@@ -26,6 +26,15 @@ type SentPacketHandler interface {
 	// HasPacingBudget says if the pacer allows sending of a (full size) packet at this moment.
 	HasPacingBudget() bool
 	SetMaxDatagramSize(count protocol.ByteCount)
+	// ECNMode returns the ECN codepoint that should be used for the next packet.
+	// Only short header packets are sent with ECN marks.
+	ECNMode(isShortHeaderPacket bool) protocol.ECN
+	// OnConnectionMigration resets the congestion controller and the RTT estimate.
+	// It is called when the connection is migrated to a new path.
+	OnConnectionMigration()
+	// Abandon queues the frames of all outstanding application data packets for retransmission.
+	// It is called when a path of a multipath connection is abandoned.
+	Abandon()
 
 	// only to be called once the handshake is complete
 	QueueProbePacket(protocol.EncryptionLevel) bool /* was a packet queued */
@@ -38,6 +47,14 @@ type SentPacketHandler interface {
 
 	// GetBandwidthEstimate gets the current estimate of bandwidth in bps
 	GetBandwidthEstimate() congestion.Bandwidth
+	// GetStats returns the statistics collected so far.
+	GetStats() Stats
+
+	// EnableAckFrequency enables sending of ACK_FREQUENCY frames.
+	// It is called once the peer's min_ack_delay is known.
+	EnableAckFrequency(peerMinAckDelay time.Duration)
+	// GetAckFrequencyFrame returns an ACK_FREQUENCY frame, if the ACK rate that should be requested from the peer changed.
+	GetAckFrequencyFrame() *wire.AckFrequencyFrame
 }
 
 type sentPacketTracker interface {
@@ -53,4 +70,10 @@ type ReceivedPacketHandler interface {
 
 	GetAlarmTimeout() time.Time
 	GetAckFrame(encLevel protocol.EncryptionLevel, onlyIfQueued bool) *wire.AckFrame
+
+	// SetAckFrequency applies the values requested in an ACK_FREQUENCY frame to the application data packet number space.
+	SetAckFrequency(*wire.AckFrequencyFrame)
+	// QueueImmediateAck queues an ACK for the application data packet number space.
+	// It is called when an IMMEDIATE_ACK frame is received.
+	QueueImmediateAck()
 }
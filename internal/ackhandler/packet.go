@@ -15,6 +15,7 @@ type Packet struct {
 	Length          protocol.ByteCount
 	EncryptionLevel protocol.EncryptionLevel
 	SendTime        time.Time
+	ECN             protocol.ECN // the ECN codepoint the packet was sent with
 
 	IsPathMTUProbePacket bool // We don't report the loss of Path MTU probe packets to the congestion controller.
 
@@ -37,6 +38,7 @@ func GetPacket() *Packet {
 	p.Length = 0
 	p.EncryptionLevel = protocol.EncryptionLevel(0)
 	p.SendTime = time.Time{}
+	p.ECN = protocol.ECNNon
 	p.IsPathMTUProbePacket = false
 	p.includedInBytesInFlight = false
 	p.declaredLost = false
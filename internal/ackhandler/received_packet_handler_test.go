@@ -165,4 +165,18 @@ var _ = Describe("Received Packet Handler", func() {
 		Expect(handler.ReceivedPacket(4, protocol.ECNNon, protocol.Encryption1RTT, sendTime, true)).To(Succeed())
 		Expect(handler.IsPotentiallyDuplicate(4, protocol.Encryption1RTT)).To(BeTrue())
 	})
+	It("applies the ACK frequency to the application data packet number space", func() {
+		sentPackets.EXPECT().ReceivedPacket(gomock.Any()).AnyTimes()
+		sentPackets.EXPECT().GetLowestPacketNotConfirmedAcked().AnyTimes()
+		handler.SetAckFrequency(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: 10 * time.Millisecond, ReorderingThreshold: 1})
+		// the first packet is always acknowledged immediately
+		Expect(handler.ReceivedPacket(1, protocol.ECNNon, protocol.Encryption1RTT, time.Now(), true)).To(Succeed())
+		Expect(handler.GetAckFrame(protocol.Encryption1RTT, true)).ToNot(BeNil())
+		for pn := protocol.PacketNumber(2); pn < 10; pn++ {
+			Expect(handler.ReceivedPacket(pn, protocol.ECNNon, protocol.Encryption1RTT, time.Now(), true)).To(Succeed())
+		}
+		Expect(handler.GetAckFrame(protocol.Encryption1RTT, true)).To(BeNil())
+		handler.QueueImmediateAck()
+		Expect(handler.GetAckFrame(protocol.Encryption1RTT, true)).ToNot(BeNil())
+	})
 })
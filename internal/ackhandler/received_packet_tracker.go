@@ -10,6 +10,7 @@ import (
 )
 
 // number of ack-eliciting packets received before sending an ack.
+// The peer can change this value by sending an ACK_FREQUENCY frame.
 const packetsBeforeAck = 2
 
 type receivedPacketTracker struct {
@@ -23,10 +24,16 @@ type receivedPacketTracker struct {
 	maxAckDelay time.Duration
 	rttStats    *utils.RTTStats
 
+	// The following values can be changed by the peer using ACK_FREQUENCY frames.
+	packetsBeforeAck    uint64
+	reorderingThreshold uint64
+	// the lowest sequence number of an ACK_FREQUENCY frame that is still processed
+	nextAckFrequencySeq uint64
+
 	hasNewAck bool // true as soon as we received an ack-eliciting new packet
 	ackQueued bool // true once we received more than 2 (or later in the connection 10) ack-eliciting packets
 
-	ackElicitingPacketsReceivedSinceLastAck int
+	ackElicitingPacketsReceivedSinceLastAck uint64
 	ackAlarm                                time.Time
 	lastAck                                 *wire.AckFrame
 
@@ -41,11 +48,13 @@ func newReceivedPacketTracker(
 	version protocol.VersionNumber,
 ) *receivedPacketTracker {
 	return &receivedPacketTracker{
-		packetHistory: newReceivedPacketHistory(),
-		maxAckDelay:   protocol.MaxAckDelay,
-		rttStats:      rttStats,
-		logger:        logger,
-		version:       version,
+		packetHistory:       newReceivedPacketHistory(),
+		maxAckDelay:         protocol.MaxAckDelay,
+		packetsBeforeAck:    packetsBeforeAck,
+		reorderingThreshold: 1,
+		rttStats:            rttStats,
+		logger:              logger,
+		version:             version,
 	}
 }
 
@@ -64,7 +73,7 @@ func (h *receivedPacketTracker) ReceivedPacket(packetNumber protocol.PacketNumbe
 		h.hasNewAck = true
 	}
 	if shouldInstigateAck {
-		h.maybeQueueAck(packetNumber, rcvTime, isMissing)
+		h.maybeQueueAck(packetNumber, rcvTime, ecn, isMissing)
 	}
 	switch ecn {
 	case protocol.ECNNon:
@@ -99,16 +108,43 @@ func (h *receivedPacketTracker) isMissing(p protocol.PacketNumber) bool {
 	return p < h.lastAck.LargestAcked() && !h.lastAck.AcksPacket(p)
 }
 
+// hasNewMissingPackets says if there's a gap that wasn't reported yet.
+// A gap is reported as soon as reorderingThreshold packets were received after it.
 func (h *receivedPacketTracker) hasNewMissingPackets() bool {
-	if h.lastAck == nil {
+	if h.lastAck == nil || h.reorderingThreshold == 0 {
 		return false
 	}
 	highestRange := h.packetHistory.GetHighestAckRange()
-	return highestRange.Smallest > h.lastAck.LargestAcked()+1 && highestRange.Len() == 1
+	return highestRange.Smallest > h.lastAck.LargestAcked()+1 && uint64(highestRange.Len()) == h.reorderingThreshold
+}
+
+// SetAckFrequency applies the values requested by the peer in an ACK_FREQUENCY frame.
+// Frames that were reordered (i.e. that have a lower sequence number than a frame processed before) are ignored.
+func (h *receivedPacketTracker) SetAckFrequency(f *wire.AckFrequencyFrame) {
+	if f.SequenceNumber < h.nextAckFrequencySeq {
+		return
+	}
+	h.nextAckFrequencySeq = f.SequenceNumber + 1
+	h.packetsBeforeAck = f.AckElicitingThreshold + 1
+	h.maxAckDelay = f.RequestMaxAckDelay
+	h.reorderingThreshold = f.ReorderingThreshold
+	if h.logger.Debug() {
+		h.logger.Debugf("\tUpdated ACK frequency: ack-eliciting threshold %d, max ack delay %s, reordering threshold %d", f.AckElicitingThreshold, f.RequestMaxAckDelay, f.ReorderingThreshold)
+	}
+}
+
+// QueueImmediateAck makes sure that an ACK is sent with the next packet.
+// It is called when an IMMEDIATE_ACK frame is received.
+func (h *receivedPacketTracker) QueueImmediateAck() {
+	if !h.ackQueued {
+		h.logger.Debugf("\tQueueing ACK because an IMMEDIATE_ACK frame was received.")
+	}
+	h.ackQueued = true
+	h.ackAlarm = time.Time{}
 }
 
 // maybeQueueAck queues an ACK, if necessary.
-func (h *receivedPacketTracker) maybeQueueAck(pn protocol.PacketNumber, rcvTime time.Time, wasMissing bool) {
+func (h *receivedPacketTracker) maybeQueueAck(pn protocol.PacketNumber, rcvTime time.Time, ecn protocol.ECN, wasMissing bool) {
 	// always acknowledge the first packet
 	if h.lastAck == nil {
 		if !h.ackQueued {
@@ -127,17 +163,28 @@ func (h *receivedPacketTracker) maybeQueueAck(pn protocol.PacketNumber, rcvTime
 	// Send an ACK if this packet was reported missing in an ACK sent before.
 	// Ack decimation with reordering relies on the timer to send an ACK, but if
 	// missing packets we reported in the previous ack, send an ACK immediately.
-	if wasMissing {
+	// The peer can disable this by setting the reordering threshold to 0.
+	if wasMissing && h.reorderingThreshold > 0 {
 		if h.logger.Debug() {
 			h.logger.Debugf("\tQueueing ACK because packet %d was missing before.", pn)
 		}
 		h.ackQueued = true
 	}
 
-	// send an ACK every 2 ack-eliciting packets
-	if h.ackElicitingPacketsReceivedSinceLastAck >= packetsBeforeAck {
+	// Send an ACK immediately if this packet was marked with ECN-CE,
+	// so that the peer can respond to the congestion as fast as possible.
+	// See section 13.2.1 of RFC 9000.
+	if ecn == protocol.ECNCE {
+		if h.logger.Debug() {
+			h.logger.Debugf("\tQueueing ACK because packet %d was ECN-CE marked.", pn)
+		}
+		h.ackQueued = true
+	}
+
+	// send an ACK every 2 ack-eliciting packets (unless the peer requested a different threshold)
+	if h.ackElicitingPacketsReceivedSinceLastAck >= h.packetsBeforeAck {
 		if h.logger.Debug() {
-			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using initial threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, packetsBeforeAck)
+			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, h.packetsBeforeAck)
 		}
 		h.ackQueued = true
 	} else if h.ackAlarm.IsZero() {
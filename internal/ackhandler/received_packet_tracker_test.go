@@ -126,6 +126,15 @@ var _ = Describe("Received Packet Tracker", func() {
 				Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(protocol.MaxAckDelay)))
 			})
 
+			It("queues an ACK for ECN-CE marked packets", func() {
+				receiveAndAck10Packets()
+				// non-ack-eliciting packets don't cause an ACK to be sent, even if they're marked
+				Expect(tracker.ReceivedPacket(11, protocol.ECNCE, time.Now(), false)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+				Expect(tracker.ReceivedPacket(12, protocol.ECNCE, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+			})
+
 			It("queues an ACK if it was reported missing before", func() {
 				receiveAndAck10Packets()
 				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
@@ -175,6 +184,71 @@ var _ = Describe("Received Packet Tracker", func() {
 				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
 				Expect(tracker.GetAckFrame(true)).To(BeNil())
 			})
+
+			Context("ACK frequency", func() {
+				It("uses the ack-eliciting threshold requested by the peer", func() {
+					receiveAndAck10Packets()
+					tracker.SetAckFrequency(&wire.AckFrequencyFrame{AckElicitingThreshold: 4, RequestMaxAckDelay: 10 * time.Millisecond, ReorderingThreshold: 1})
+					for i := 11; i <= 14; i++ {
+						Expect(tracker.ReceivedPacket(protocol.PacketNumber(i), protocol.ECNNon, time.Now(), true)).To(Succeed())
+						Expect(tracker.ackQueued).To(BeFalse())
+					}
+					Expect(tracker.ReceivedPacket(15, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeTrue())
+				})
+
+				It("uses the max ack delay requested by the peer", func() {
+					receiveAndAck10Packets()
+					tracker.SetAckFrequency(&wire.AckFrequencyFrame{AckElicitingThreshold: 4, RequestMaxAckDelay: 10 * time.Millisecond, ReorderingThreshold: 1})
+					rcvTime := time.Now()
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, rcvTime, true)).To(Succeed())
+					Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(10 * time.Millisecond)))
+				})
+
+				It("ignores reordered ACK_FREQUENCY frames", func() {
+					tracker.SetAckFrequency(&wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 4, RequestMaxAckDelay: 10 * time.Millisecond})
+					tracker.SetAckFrequency(&wire.AckFrequencyFrame{SequenceNumber: 0, AckElicitingThreshold: 8, RequestMaxAckDelay: 20 * time.Millisecond})
+					Expect(tracker.packetsBeforeAck).To(BeEquivalentTo(5))
+					Expect(tracker.maxAckDelay).To(Equal(10 * time.Millisecond))
+					tracker.SetAckFrequency(&wire.AckFrequencyFrame{SequenceNumber: 2, AckElicitingThreshold: 8, RequestMaxAckDelay: 20 * time.Millisecond})
+					Expect(tracker.packetsBeforeAck).To(BeEquivalentTo(9))
+					Expect(tracker.maxAckDelay).To(Equal(20 * time.Millisecond))
+				})
+
+				It("doesn't queue ACKs for reordered packets, if the reordering threshold is 0", func() {
+					receiveAndAck10Packets()
+					tracker.SetAckFrequency(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: 10 * time.Millisecond, ReorderingThreshold: 0})
+					Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.GetAckFrame(false)).ToNot(BeNil()) // ACK: 1-10 and 12, missing: 11
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+				})
+
+				It("only reports gaps once the reordering threshold is reached", func() {
+					receiveAndAck10Packets()
+					tracker.SetAckFrequency(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: 10 * time.Millisecond, ReorderingThreshold: 3})
+					// 11 is missing
+					Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.ReceivedPacket(14, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeTrue())
+				})
+
+				It("queues an ACK when an IMMEDIATE_ACK frame is received", func() {
+					receiveAndAck10Packets()
+					tracker.SetAckFrequency(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: 10 * time.Millisecond, ReorderingThreshold: 1})
+					tracker.QueueImmediateAck()
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeTrue())
+					Expect(tracker.GetAlarmTimeout()).To(BeZero())
+					ack := tracker.GetAckFrame(true)
+					Expect(ack).ToNot(BeNil())
+					Expect(ack.LargestAcked()).To(Equal(protocol.PacketNumber(11)))
+				})
+			})
 		})
 
 		Context("ACK generation", func() {
@@ -6,7 +6,7 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
 	"github.com/lucas-clemente/quic-go/internal/utils"
@@ -20,8 +20,6 @@ const (
 	timeThreshold = 9.0 / 8
 	// Maximum reordering in packets before packet threshold loss detection considers a packet lost.
 	packetThreshold = 3
-	// Before validating the client's address, the server won't send more than 3x bytes than it received.
-	amplificationFactor = 3
 	// We use Retry packets to derive an RTT estimate. Make sure we don't set the RTT to a super low value yet.
 	minRTTAfterRetry = 5 * time.Millisecond
 	// The PTO duration uses exponential backoff, but is truncated to a maximum value, as allowed by RFC 8961, section 4.4.
@@ -81,8 +79,14 @@ type sentPacketHandler struct {
 
 	bytesInFlight protocol.ByteCount
 
-	congestion congestion.SendAlgorithmWithDebugInfos
-	rttStats   *utils.RTTStats
+	congestion congestion.CongestionController
+	// newCongestion creates a new congestion controller, when the connection is migrated to a new path
+	newCongestion func() congestion.CongestionController
+	rttStats      *utils.RTTStats
+
+	ecnTracker *ecnTracker
+
+	ackFrequency ackFrequencyManager
 
 	// The number of times a PTO has been sent without receiving an ack.
 	ptoCount uint32
@@ -96,6 +100,10 @@ type sentPacketHandler struct {
 
 	perspective protocol.Perspective
 
+	// stats contains the counters that are not tracked elsewhere.
+	// It is completed by GetStats.
+	stats Stats
+
 	tracer logging.ConnectionTracer
 	logger utils.Logger
 }
@@ -115,23 +123,15 @@ func newSentPacketHandler(
 	pers protocol.Perspective,
 	tracer logging.ConnectionTracer,
 	logger utils.Logger,
-	useBBR bool,
+	congestionControl func(congestion.ConnectionInfo) congestion.CongestionController,
 ) *sentPacketHandler {
-	var congestionControl congestion.SendAlgorithmWithDebugInfos
-
-	if useBBR {
-		congestionControl = congestion.NewBBRSender(
-			rttStats,
-			initialMaxDatagramSize,
-		)
-	} else {
-		congestionControl = congestion.NewCubicSender(
-			congestion.DefaultClock{},
-			rttStats,
-			initialMaxDatagramSize,
-			true, // use Reno
-			tracer,
-		)
+	newCongestion := func() congestion.CongestionController {
+		return congestionControl(congestion.ConnectionInfo{
+			Perspective:            pers,
+			InitialMaxDatagramSize: initialMaxDatagramSize,
+			RTTStats:               rttStats,
+			Tracer:                 tracer,
+		})
 	}
 
 	return &sentPacketHandler{
@@ -141,7 +141,10 @@ func newSentPacketHandler(
 		handshakePackets:               newPacketNumberSpace(0, false, rttStats),
 		appDataPackets:                 newPacketNumberSpace(0, true, rttStats),
 		rttStats:                       rttStats,
-		congestion:                     congestionControl,
+		congestion:                     newCongestion(),
+		newCongestion:                  newCongestion,
+		ecnTracker:                     newECNTracker(logger),
+		stats:                          Stats{MaxDatagramSize: initialMaxDatagramSize},
 		perspective:                    pers,
 		tracer:                         tracer,
 		logger:                         logger,
@@ -223,6 +226,7 @@ func (h *sentPacketHandler) ReceivedBytes(n protocol.ByteCount) {
 }
 
 func (h *sentPacketHandler) ReceivedPacket(l protocol.EncryptionLevel) {
+	h.stats.PacketsReceived++
 	if h.perspective == protocol.PerspectiveServer && l == protocol.EncryptionHandshake && !h.peerAddressValidated {
 		h.peerAddressValidated = true
 		h.setLossDetectionTimer()
@@ -242,10 +246,14 @@ func (h *sentPacketHandler) packetsInFlight() int {
 
 func (h *sentPacketHandler) SentPacket(p *Packet) {
 	h.bytesSent += p.Length
+	h.stats.PacketsSent++
 	// For the client, drop the Initial packet number space when the first Handshake packet is sent.
 	if h.perspective == protocol.PerspectiveClient && p.EncryptionLevel == protocol.EncryptionHandshake && h.initialPackets != nil {
 		h.dropPackets(protocol.EncryptionInitial)
 	}
+	if p.EncryptionLevel == protocol.Encryption1RTT {
+		h.ecnTracker.SentPacket(p.PacketNumber, p.ECN)
+	}
 	isAckEliciting := h.sentPacketImpl(p)
 	if isAckEliciting {
 		h.getPacketNumberSpace(p.EncryptionLevel).history.SentAckElicitingPacket(p)
@@ -346,6 +354,11 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 	if err := h.detectLostPackets(rcvTime, encLevel); err != nil {
 		return false, err
 	}
+	if encLevel == protocol.Encryption1RTT {
+		if congested := h.ecnTracker.HandleNewlyAcked(ackedPackets, ack.ECT0, ack.ECT1, ack.ECNCE); congested {
+			h.congestion.OnECNCongestionEvent(ackedPackets[len(ackedPackets)-1].PacketNumber, priorInFlight)
+		}
+	}
 	var acked1RTTPacket bool
 	for _, p := range ackedPackets {
 		if p.includedInBytesInFlight && !p.declaredLost {
@@ -640,8 +653,13 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 			h.removeFromBytesInFlight(p)
 			h.queueFramesForRetransmission(p)
 			if !p.IsPathMTUProbePacket {
+				h.stats.PacketsLost++
+				h.stats.BytesLost += p.Length
 				h.congestion.OnPacketLost(p.PacketNumber, p.Length, priorInFlight)
 			}
+			if encLevel == protocol.Encryption1RTT {
+				h.ecnTracker.LostPacket(p.PacketNumber)
+			}
 		}
 		return true, nil
 	})
@@ -668,6 +686,7 @@ func (h *sentPacketHandler) OnLossDetectionTimeout() error {
 	// actually packets outstanding.
 	if h.bytesInFlight == 0 && !h.peerCompletedAddressValidation {
 		h.ptoCount++
+		h.stats.PTOCount++
 		h.numProbesToSend++
 		if h.initialPackets != nil {
 			h.ptoMode = SendPTOInitial
@@ -687,6 +706,7 @@ func (h *sentPacketHandler) OnLossDetectionTimeout() error {
 		return nil
 	}
 	h.ptoCount++
+	h.stats.PTOCount++
 	if h.logger.Debug() {
 		h.logger.Debugf("Loss detection alarm for %s fired in PTO mode. PTO count: %d", encLevel, h.ptoCount)
 	}
@@ -784,14 +804,36 @@ func (h *sentPacketHandler) HasPacingBudget() bool {
 }
 
 func (h *sentPacketHandler) SetMaxDatagramSize(s protocol.ByteCount) {
+	h.stats.MaxDatagramSize = s
 	h.congestion.SetMaxDatagramSize(s)
 }
 
+func (h *sentPacketHandler) OnConnectionMigration() {
+	// The RTT and the congestion window of the old path don't apply to the new path.
+	// See section 9.4 of RFC 9000.
+	h.rttStats.OnConnectionMigration()
+	h.congestion = h.newCongestion()
+	// ECN might not work on the new path.
+	h.ecnTracker.RestartValidation()
+	if h.tracer != nil && h.ptoCount != 0 {
+		h.tracer.UpdatedPTOCount(0)
+	}
+	h.ptoCount = 0
+	h.setLossDetectionTimer()
+}
+
+func (h *sentPacketHandler) ECNMode(isShortHeaderPacket bool) protocol.ECN {
+	if !isShortHeaderPacket {
+		return protocol.ECNNon
+	}
+	return h.ecnTracker.Mode()
+}
+
 func (h *sentPacketHandler) isAmplificationLimited() bool {
 	if h.peerAddressValidated {
 		return false
 	}
-	return h.bytesSent >= amplificationFactor*h.bytesReceived
+	return h.bytesSent >= protocol.AmplificationFactor*h.bytesReceived
 }
 
 func (h *sentPacketHandler) QueueProbePacket(encLevel protocol.EncryptionLevel) bool {
@@ -808,10 +850,21 @@ func (h *sentPacketHandler) QueueProbePacket(encLevel protocol.EncryptionLevel)
 	return true
 }
 
+func (h *sentPacketHandler) Abandon() {
+	for p := h.appDataPackets.history.FirstOutstanding(); p != nil; p = h.appDataPackets.history.FirstOutstanding() {
+		h.queueFramesForRetransmission(p)
+		h.removeFromBytesInFlight(p)
+		h.appDataPackets.history.DeclareLost(p)
+	}
+	h.setLossDetectionTimer()
+}
+
 func (h *sentPacketHandler) queueFramesForRetransmission(p *Packet) {
 	if len(p.Frames) == 0 {
 		panic("no frames")
 	}
+	h.stats.PacketsRetransmitted++
+	h.stats.BytesRetransmitted += p.Length
 	for _, f := range p.Frames {
 		f.OnLost(f.Frame)
 	}
@@ -881,3 +934,29 @@ func (h *sentPacketHandler) updateBandwidthEstimate() {
 func (h *sentPacketHandler) GetBandwidthEstimate() congestion.Bandwidth {
 	return congestion.Bandwidth(atomic.LoadUint64(&h.bandwidthEstimate))
 }
+
+func (h *sentPacketHandler) EnableAckFrequency(peerMinAckDelay time.Duration) {
+	h.ackFrequency.Enable(peerMinAckDelay, h.rttStats.MaxAckDelay())
+}
+
+func (h *sentPacketHandler) GetAckFrequencyFrame() *wire.AckFrequencyFrame {
+	// ACK_FREQUENCY frames can only be sent in 1-RTT packets.
+	if !h.handshakeConfirmed {
+		return nil
+	}
+	threshold, maxAckDelay := requestedAckFrequency(h.congestion, h.rttStats, h.stats.MaxDatagramSize)
+	f := h.ackFrequency.Update(threshold, maxAckDelay)
+	if f != nil && h.logger.Debug() {
+		h.logger.Debugf("Requesting ACK frequency: ack-eliciting threshold %d, max ack delay %s", f.AckElicitingThreshold, f.RequestMaxAckDelay)
+	}
+	return f
+}
+
+func (h *sentPacketHandler) GetStats() Stats {
+	stats := h.stats
+	stats.BytesSent = h.bytesSent
+	stats.BytesReceived = h.bytesReceived
+	stats.BytesInFlight = h.bytesInFlight
+	stats.CongestionWindow = h.congestion.GetCongestionWindow()
+	return stats
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/mocks"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
@@ -29,7 +30,7 @@ var _ = Describe("SentPacketHandler", func() {
 	JustBeforeEach(func() {
 		lostPackets = nil
 		rttStats := utils.NewRTTStats()
-		handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, false, perspective, nil, utils.DefaultLogger, false)
+		handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, false, perspective, nil, utils.DefaultLogger, congestion.NewReno)
 		streamFrame = wire.StreamFrame{
 			StreamID: 5,
 			Data:     []byte{0x13, 0x37},
@@ -566,6 +567,45 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.bytesInFlight).To(BeZero())
 		})
 
+		It("calls OnECNCongestionEvent when the ECN-CE count increases", func() {
+			cong.EXPECT().BandwidthEstimate().Times(2)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+			Expect(handler.ECNMode(true)).To(Equal(protocol.ECT0))
+			Expect(handler.ECNMode(false)).To(Equal(protocol.ECNNon))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, ECN: protocol.ECT0}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 2, ECN: protocol.ECT0}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 3, ECN: protocol.ECT0}))
+			gomock.InOrder(
+				cong.EXPECT().MaybeExitSlowStart(),
+				cong.EXPECT().OnECNCongestionEvent(protocol.PacketNumber(2), protocol.ByteCount(3)),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(3), gomock.Any()),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(3), gomock.Any()),
+			)
+			ack := &wire.AckFrame{
+				AckRanges: []wire.AckRange{{Smallest: 1, Largest: 2}},
+				ECT0:      1,
+				ECNCE:     1,
+			}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.ECNMode(true)).To(Equal(protocol.ECT0))
+		})
+
+		It("stops using ECN when ECN validation fails", func() {
+			cong.EXPECT().BandwidthEstimate().Times(2)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, ECN: protocol.ECT0}))
+			gomock.InOrder(
+				cong.EXPECT().MaybeExitSlowStart(),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(1), gomock.Any()),
+			)
+			// the ACK doesn't contain any ECN counts
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.ECNMode(true)).To(Equal(protocol.ECNNon))
+		})
+
 		It("calls OnPacketAcked and OnPacketLost with the right bytes_in_flight value", func() {
 			cong.EXPECT().BandwidthEstimate().Times(4)
 			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
@@ -646,6 +686,33 @@ var _ = Describe("SentPacketHandler", func() {
 			cong.EXPECT().TimeUntilSend(gomock.Any()).Return(t)
 			Expect(handler.TimeUntilSend()).To(Equal(t))
 		})
+
+		It("resets the congestion controller and the RTT stats on connection migration", func() {
+			handler.rttStats.UpdateRTT(time.Second, 0, time.Now())
+			Expect(handler.rttStats.SmoothedRTT()).To(Equal(time.Second))
+			handler.OnConnectionMigration()
+			Expect(handler.congestion).ToNot(Equal(cong))
+			Expect(handler.rttStats.SmoothedRTT()).To(BeZero())
+			// the next RTT sample is used as the initial RTT estimate for the new path
+			handler.rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+			Expect(handler.rttStats.SmoothedRTT()).To(Equal(100 * time.Millisecond))
+		})
+
+		It("uses the congestion controller constructor, also on connection migration", func() {
+			var infos []congestion.ConnectionInfo
+			rttStats := utils.NewRTTStats()
+			h := newSentPacketHandler(0, 1234, rttStats, false, protocol.PerspectiveServer, nil, utils.DefaultLogger, func(info congestion.ConnectionInfo) congestion.CongestionController {
+				infos = append(infos, info)
+				return cong
+			})
+			Expect(infos).To(HaveLen(1))
+			Expect(infos[0].Perspective).To(Equal(protocol.PerspectiveServer))
+			Expect(infos[0].InitialMaxDatagramSize).To(Equal(protocol.ByteCount(1234)))
+			Expect(infos[0].RTTStats).To(BeIdenticalTo(rttStats))
+			Expect(h.congestion).To(Equal(cong))
+			h.OnConnectionMigration()
+			Expect(infos).To(HaveLen(2))
+		})
 	})
 
 	It("doesn't set an alarm if there are no outstanding packets", func() {
@@ -678,6 +745,20 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(queued).To(BeFalse())
 		})
 
+		It("queues all outstanding packets for retransmission when the path is abandoned", func() {
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			handler.SetHandshakeConfirmed()
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 10}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 11}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 12}))
+			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(3)))
+			Expect(handler.GetLossDetectionTimeout()).ToNot(BeZero())
+			handler.Abandon()
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{10, 11, 12}))
+			Expect(handler.bytesInFlight).To(BeZero())
+			Expect(handler.GetLossDetectionTimeout()).To(BeZero())
+		})
+
 		It("implements exponential backoff", func() {
 			handler.peerAddressValidated = true
 			handler.SetHandshakeConfirmed()
@@ -959,7 +1040,7 @@ var _ = Describe("SentPacketHandler", func() {
 	Context("amplification limit, for the server, with validated address", func() {
 		JustBeforeEach(func() {
 			rttStats := utils.NewRTTStats()
-			handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, true, perspective, nil, utils.DefaultLogger, false)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, true, perspective, nil, utils.DefaultLogger, congestion.NewReno)
 		})
 
 		It("do not limits the window", func() {
@@ -1296,6 +1377,105 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 	})
 
+	Context("ACK frequency", func() {
+		var cong *mocks.MockSendAlgorithmWithDebugInfos
+
+		JustBeforeEach(func() {
+			cong = mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			handler.congestion = cong
+			handler.rttStats.SetMaxAckDelay(25 * time.Millisecond)
+		})
+
+		It("doesn't request an ACK frequency if not enabled", func() {
+			handler.SetHandshakeConfirmed()
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(1000 * 1000)).AnyTimes()
+			Expect(handler.GetAckFrequencyFrame()).To(BeNil())
+		})
+
+		It("doesn't request an ACK frequency before the handshake is confirmed", func() {
+			handler.EnableAckFrequency(time.Millisecond)
+			Expect(handler.GetAckFrequencyFrame()).To(BeNil())
+		})
+
+		It("requests a lower ACK rate when the congestion window grows", func() {
+			handler.EnableAckFrequency(time.Millisecond)
+			handler.SetHandshakeConfirmed()
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(10 * protocol.InitialPacketSizeIPv4))
+			Expect(handler.GetAckFrequencyFrame()).To(BeNil())
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(1000 * protocol.InitialPacketSizeIPv4))
+			f := handler.GetAckFrequencyFrame()
+			Expect(f).ToNot(BeNil())
+			Expect(f.AckElicitingThreshold).To(BeEquivalentTo(maxAckElicitingThreshold))
+			Expect(f.RequestMaxAckDelay).To(Equal(25 * time.Millisecond))
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(1000 * protocol.InitialPacketSizeIPv4))
+			Expect(handler.GetAckFrequencyFrame()).To(BeNil())
+		})
+	})
+
+	Context("statistics", func() {
+		It("counts sent and received packets", func() {
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, Length: 100}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 2, Length: 200}))
+			handler.SentPacket(nonAckElicitingPacket(&Packet{PacketNumber: 3, Length: 50}))
+			handler.ReceivedPacket(protocol.Encryption1RTT)
+			handler.ReceivedBytes(1000)
+			stats := handler.GetStats()
+			Expect(stats.PacketsSent).To(BeEquivalentTo(3))
+			Expect(stats.BytesSent).To(BeEquivalentTo(350))
+			Expect(stats.PacketsReceived).To(BeEquivalentTo(1))
+			Expect(stats.BytesReceived).To(BeEquivalentTo(1000))
+			Expect(stats.BytesInFlight).To(BeEquivalentTo(300))
+			Expect(stats.CongestionWindow).To(Equal(handler.congestion.GetCongestionWindow()))
+			Expect(stats.MaxDatagramSize).To(BeEquivalentTo(protocol.InitialPacketSizeIPv4))
+			handler.SetMaxDatagramSize(1400)
+			Expect(handler.GetStats().MaxDatagramSize).To(BeEquivalentTo(1400))
+		})
+
+		It("counts lost and retransmitted packets", func() {
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i, Length: 100}))
+			}
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 6, Largest: 6}}}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			stats := handler.GetStats()
+			Expect(stats.PacketsLost).To(BeEquivalentTo(3))
+			Expect(stats.BytesLost).To(BeEquivalentTo(300))
+			Expect(stats.PacketsRetransmitted).To(BeEquivalentTo(3))
+			Expect(stats.BytesRetransmitted).To(BeEquivalentTo(300))
+			// probe packets are retransmissions, but the original packet isn't counted as lost
+			Expect(handler.QueueProbePacket(protocol.Encryption1RTT)).To(BeTrue())
+			stats = handler.GetStats()
+			Expect(stats.PacketsLost).To(BeEquivalentTo(3))
+			Expect(stats.PacketsRetransmitted).To(BeEquivalentTo(4))
+			Expect(stats.BytesRetransmitted).To(BeEquivalentTo(400))
+		})
+
+		It("doesn't count lost Path MTU probe packets", func() {
+			now := time.Now()
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, SendTime: now.Add(-time.Hour), IsPathMTUProbePacket: true}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 2, SendTime: now}))
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1}))
+			Expect(handler.GetStats().PacketsLost).To(BeZero())
+		})
+
+		It("counts PTOs, even after the PTO count was reset", func() {
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			handler.SetHandshakeConfirmed()
+			now := time.Now()
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, SendTime: now.Add(-time.Minute)}))
+			Expect(handler.OnLossDetectionTimeout()).To(Succeed())
+			Expect(handler.GetStats().PTOCount).To(BeEquivalentTo(1))
+			Expect(handler.OnLossDetectionTimeout()).To(Succeed())
+			Expect(handler.GetStats().PTOCount).To(BeEquivalentTo(2))
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.ptoCount).To(BeZero())
+			Expect(handler.GetStats().PTOCount).To(BeEquivalentTo(2))
+		})
+	})
+
 	Context("peeking and popping packet number", func() {
 		It("peeks and pops the initial packet number", func() {
 			pn, _ := handler.PeekPacketNumber(protocol.EncryptionInitial)
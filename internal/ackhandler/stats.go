@@ -0,0 +1,38 @@
+package ackhandler
+
+import "github.com/lucas-clemente/quic-go/internal/protocol"
+
+// Stats are the statistics collected by a SentPacketHandler.
+type Stats struct {
+	PacketsSent     uint64
+	BytesSent       protocol.ByteCount
+	PacketsReceived uint64
+	BytesReceived   protocol.ByteCount
+	// Path MTU probe packets are not counted as lost, since they don't indicate congestion.
+	PacketsLost uint64
+	BytesLost   protocol.ByteCount
+	// The frames of a packet are retransmitted when the packet is declared lost,
+	// when it is retransmitted in a probe packet, and when the path it was sent on is abandoned.
+	PacketsRetransmitted uint64
+	BytesRetransmitted   protocol.ByteCount
+	// PTOCount is the number of times the PTO timer fired.
+	PTOCount uint64
+
+	BytesInFlight    protocol.ByteCount
+	CongestionWindow protocol.ByteCount
+	MaxDatagramSize  protocol.ByteCount
+}
+
+// Add adds the packet and byte counters of other to s.
+// It is used to sum up the statistics of multiple paths.
+func (s *Stats) Add(other Stats) {
+	s.PacketsSent += other.PacketsSent
+	s.BytesSent += other.BytesSent
+	s.PacketsReceived += other.PacketsReceived
+	s.BytesReceived += other.BytesReceived
+	s.PacketsLost += other.PacketsLost
+	s.BytesLost += other.BytesLost
+	s.PacketsRetransmitted += other.PacketsRetransmitted
+	s.BytesRetransmitted += other.BytesRetransmitted
+	s.PTOCount += other.PTOCount
+}
@@ -154,9 +154,10 @@ func (s *BandwidthSampler) OnPacketAcknowledgedInner(ackTime time.Time,
 		ackTime.Sub(sentPacketState.lastAckedPacketAckTime))
 
 	sam := BandwidthSample{}
-	sam.Bandwidth = protocol.ByteCount(sendRate)
-	if sam.Bandwidth > protocol.ByteCount(ackRate) {
-		sam.Bandwidth = protocol.ByteCount(ackRate)
+	// Compare the rates before converting them, an infinite send rate doesn't fit into a protocol.ByteCount.
+	sam.Bandwidth = protocol.ByteCount(ackRate)
+	if sendRate < ackRate {
+		sam.Bandwidth = protocol.ByteCount(sendRate)
 	}
 	// Note: this sample does not account for delayed acknowledgement time.  This
 	// means that the RTT measurements here can be artificially high, especially
@@ -0,0 +1,671 @@
+package congestion
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
+)
+
+// This is an implementation of BBRv2, following the description in
+// https://datatracker.ietf.org/doc/html/draft-cardwell-iccrg-bbr-congestion-control-02
+// and the BBRv2 implementations in Linux and Chromium.
+// Compared to BBRv1 (see bbr_sender.go), it bounds the amount of data in flight based on loss and ECN signals,
+// making it a lot less aggressive on shallow-buffered and lossy links, and fairer towards Reno / Cubic flows.
+
+type bbr2Mode uint8
+
+const (
+	bbr2ModeStartup bbr2Mode = iota
+	bbr2ModeDrain
+	bbr2ModeProbeBW
+	bbr2ModeProbeRTT
+)
+
+// The sub-states of the ProbeBW mode.
+type bbr2ProbeBWPhase uint8
+
+const (
+	// pace below the bandwidth estimate, to drain the queue created while probing
+	bbr2ProbeBWDown bbr2ProbeBWPhase = iota
+	// pace at the bandwidth estimate, leaving some headroom for other flows
+	bbr2ProbeBWCruise
+	// pace at the bandwidth estimate for one round trip, to fill the pipe before probing
+	bbr2ProbeBWRefill
+	// pace above the bandwidth estimate, to probe for more bandwidth
+	bbr2ProbeBWUp
+)
+
+const (
+	// 2/ln(2), the minimum gain that allows doubling the sending rate every round trip
+	bbr2StartupPacingGain = 2.885
+	bbr2StartupCwndGain   = 2.0
+	bbr2DrainPacingGain   = 1 / bbr2StartupPacingGain
+	bbr2CwndGain          = 2.0
+
+	bbr2ProbeUpPacingGain   = 1.25
+	bbr2ProbeDownPacingGain = 0.75
+	bbr2ProbeRTTCwndGain    = 0.5
+
+	// The multiplicative decrease applied to the lower bounds when congestion is detected.
+	bbr2Beta = 0.7
+	// The maximum tolerated loss rate per round trip, before considering the amount of data in flight too high.
+	bbr2LossThreshold = 0.02
+	// The fraction of inflight_hi that is left unused while cruising, to leave room for other flows.
+	bbr2InflightHeadroom = 0.15
+
+	// Startup is exited if the bandwidth estimate didn't grow by 25% for 3 round trips ...
+	bbr2FullBandwidthGrowth = 1.25
+	bbr2FullBandwidthRounds = 3
+	// ... or if the loss rate was too high in a round trip with at least this many loss events.
+	bbr2StartupFullLossCount = 8
+
+	// The RTT used to calculate the initial pacing rate, before the RTT has been measured.
+	bbr2InitialRTT = 100 * time.Millisecond
+
+	bbr2ProbeRTTInterval = 5 * time.Second
+	bbr2MinRTTWindow     = 10 * time.Second
+	bbr2ProbeRTTDuration = 200 * time.Millisecond
+
+	// The time between two bandwidth probes is randomized between 2 and 3 seconds...
+	bbr2ProbeBWMinWait  = 2 * time.Second
+	bbr2ProbeBWRandWait = time.Second
+	// ... but bandwidth is probed at least every 63 round trips, to coexist with Reno / Cubic flows.
+	bbr2ProbeBWMaxRounds = 63
+
+	bbr2MinCongestionWindowPackets = 4
+	bbr2MaxCongestionWindowPackets = protocol.MaxCongestionWindowPackets
+	// Extra congestion window to account for delayed and aggregated ACKs.
+	bbr2QuantaPackets = 3
+)
+
+type bbr2Sender struct {
+	clock    Clock
+	rttStats *utils.RTTStats
+	sampler  BandwidthSampler
+	pacer    *pacer
+
+	mode         bbr2Mode
+	probeBWPhase bbr2ProbeBWPhase
+	pacingGain   float64
+	cwndGain     float64
+	pacingRate   Bandwidth
+
+	maxDatagramSize         protocol.ByteCount
+	initialCongestionWindow protocol.ByteCount
+	congestionWindow        protocol.ByteCount
+
+	// The model of the network path.
+	// maxBandwidth is the maximum bandwidth sample of the last two ProbeBW cycles.
+	maxBandwidth    *windowFilter
+	minRTT          time.Duration
+	minRTTTimestamp time.Time
+	// The upper bound for the amount of data in flight, lowered when loss or ECN signals that it is too high.
+	// protocol.MaxByteCount if not set.
+	inflightHi protocol.ByteCount
+	// The lower bounds, lowered when congestion is detected in a round trip, and reset when bandwidth is probed.
+	// protocol.MaxByteCount and infBandwidth if not set.
+	inflightLo  protocol.ByteCount
+	bandwidthLo Bandwidth
+	// The maximum bandwidth sample and the amount of data delivered in the last round trip.
+	bandwidthLatest Bandwidth
+	inflightLatest  protocol.ByteCount
+
+	// Round trip counting.
+	lastSentPacket      protocol.PacketNumber
+	currentRoundTripEnd protocol.PacketNumber
+	roundCount          uint64
+	// The congestion signals in the current round trip.
+	bandwidthInRound     Bandwidth
+	bytesAckedInRound    protocol.ByteCount
+	bytesLostInRound     protocol.ByteCount
+	lossEventsInRound    int
+	ecnInRound           bool
+	cwndLimitedInRound   bool
+	lastSampleAppLimited bool
+
+	// Startup
+	fullBandwidthReached bool
+	fullBandwidth        Bandwidth
+	fullBandwidthCount   int
+
+	// ProbeBW
+	cycleCount          int64
+	cycleStart          time.Time
+	roundsSinceProbe    int
+	probeWait           time.Duration
+	probeUpRounds       uint
+	respondedToProbeHit bool
+
+	// ProbeRTT
+	probeRTTDoneTime  time.Time
+	probeRTTRoundDone bool
+	priorCwnd         protocol.ByteCount
+
+	tracer    logging.ConnectionTracer
+	lastState logging.CongestionState
+}
+
+var (
+	_ SendAlgorithm               = &bbr2Sender{}
+	_ SendAlgorithmWithDebugInfos = &bbr2Sender{}
+)
+
+// NewBBRv2Sender makes a new BBRv2 sender
+func NewBBRv2Sender(
+	clock Clock,
+	rttStats *utils.RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	tracer logging.ConnectionTracer,
+) *bbr2Sender {
+	b := &bbr2Sender{
+		clock:                   clock,
+		rttStats:                rttStats,
+		maxDatagramSize:         initialMaxDatagramSize,
+		initialCongestionWindow: initialCongestionWindow * initialMaxDatagramSize,
+		congestionWindow:        initialCongestionWindow * initialMaxDatagramSize,
+		// The window filter is indexed by the ProbeBW cycle count,
+		// such that it contains the samples of the current and the last cycle.
+		maxBandwidth:        &windowFilter{MaxOrMinFilter: true, windowLength: 1},
+		inflightHi:          protocol.MaxByteCount,
+		inflightLo:          protocol.MaxByteCount,
+		bandwidthLo:         infBandwidth,
+		lastSentPacket:      protocol.InvalidPacketNumber,
+		currentRoundTripEnd: protocol.InvalidPacketNumber,
+		tracer:              tracer,
+	}
+	b.pacer = newPacer(b.pacingRateForPacer)
+	b.pacer.SetMaxDatagramSize(initialMaxDatagramSize)
+	b.enterStartup()
+	b.updatePacingRate()
+	if b.tracer != nil {
+		b.lastState = logging.CongestionStateSlowStart
+		b.tracer.UpdatedCongestionState(logging.CongestionStateSlowStart)
+	}
+	return b
+}
+
+func (b *bbr2Sender) TimeUntilSend(_ protocol.ByteCount) time.Time {
+	return b.pacer.TimeUntilSend()
+}
+
+func (b *bbr2Sender) HasPacingBudget() bool {
+	return b.pacer.Budget(b.clock.Now()) >= b.maxDatagramSize
+}
+
+func (b *bbr2Sender) OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool) {
+	b.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	b.lastSentPacket = packetNumber
+	if bytesInFlight >= b.GetCongestionWindow() {
+		b.cwndLimitedInRound = true
+	}
+	// bytesInFlight already includes this packet.
+	// The sampler expects the bytes in flight before this packet was sent.
+	b.sampler.OnPacketSent(sentTime, packetNumber, bytes, bytesInFlight-bytes, isRetransmittable)
+}
+
+func (b *bbr2Sender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return bytesInFlight < b.GetCongestionWindow()
+}
+
+func (b *bbr2Sender) MaybeExitSlowStart() {}
+
+func (b *bbr2Sender) OnPacketAcked(number protocol.PacketNumber, ackedBytes protocol.ByteCount, priorInFlight protocol.ByteCount, eventTime time.Time) {
+	sample := b.sampler.OnPacketAcknowledged(eventTime, number)
+	b.bytesAckedInRound += ackedBytes
+	b.updateModel(sample, eventTime)
+
+	isRoundStart := b.updateRoundTripCounter(number)
+	if isRoundStart {
+		b.onRoundEnd()
+	}
+	// the state machine decides based on the bytes in flight after this packet was acknowledged
+	b.updateStateMachine(eventTime, priorInFlight-ackedBytes, isRoundStart)
+	b.updateCongestionWindow(ackedBytes)
+	b.updatePacingRate()
+	b.maybeTraceStateChange()
+}
+
+func (b *bbr2Sender) OnPacketLost(number protocol.PacketNumber, lostBytes protocol.ByteCount, priorInFlight protocol.ByteCount) {
+	b.sampler.OnPacketLost(number)
+	b.bytesLostInRound += lostBytes
+	b.lossEventsInRound++
+	if b.isInflightTooHigh() {
+		b.handleInflightTooHigh(priorInFlight)
+	}
+	b.maybeTraceStateChange()
+}
+
+// OnECNCongestionEvent is called when the peer reports that packets were marked with ECN-CE.
+// quic-go uses ECN as defined in RFC 3168, so a CE mark is treated as a signal equivalent to a packet loss:
+// When probing for bandwidth, it ends the probe, and outside of probing, it lowers the lower bounds.
+func (b *bbr2Sender) OnECNCongestionEvent(_ protocol.PacketNumber, priorInFlight protocol.ByteCount) {
+	b.ecnInRound = true
+	b.handleInflightTooHigh(priorInFlight)
+	b.maybeTraceStateChange()
+}
+
+// OnRetransmissionTimeout is called on a retransmission timeout.
+// BBR doesn't use PTOs as a congestion signal, lost packets are reported by OnPacketLost.
+func (b *bbr2Sender) OnRetransmissionTimeout(bool) {}
+
+func (b *bbr2Sender) SetMaxDatagramSize(s protocol.ByteCount) {
+	if s < b.maxDatagramSize {
+		panic(fmt.Sprintf("congestion BUG: decreased max datagram size from %d to %d", b.maxDatagramSize, s))
+	}
+	b.maxDatagramSize = s
+	b.pacer.SetMaxDatagramSize(s)
+}
+
+func (b *bbr2Sender) InSlowStart() bool {
+	return b.mode == bbr2ModeStartup
+}
+
+// InRecovery says if the lower bounds are limiting the sending rate,
+// i.e. if congestion was detected since the last bandwidth probe.
+func (b *bbr2Sender) InRecovery() bool {
+	return b.inflightLo != protocol.MaxByteCount || b.bandwidthLo != infBandwidth
+}
+
+func (b *bbr2Sender) GetCongestionWindow() protocol.ByteCount {
+	cwnd := b.congestionWindow
+	if b.mode == bbr2ModeProbeRTT {
+		cwnd = utils.Min(cwnd, b.probeRTTCongestionWindow())
+	}
+	return utils.Max(utils.Min(cwnd, b.inflightBound()), b.minCongestionWindow())
+}
+
+// BandwidthEstimate returns the current bandwidth estimate.
+// This is the maximum bandwidth measured in the last two ProbeBW cycles, limited by the lower bound,
+// which is lowered if congestion is detected.
+func (b *bbr2Sender) BandwidthEstimate() Bandwidth {
+	if bw := b.bandwidthEstimate(); bw > 0 {
+		return bw
+	}
+	// If we haven't measured the bandwidth yet, derive an estimate from the congestion window.
+	srtt := b.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		return infBandwidth
+	}
+	return BandwidthFromDelta(b.GetCongestionWindow(), srtt)
+}
+
+func (b *bbr2Sender) bandwidthEstimate() Bandwidth {
+	return utils.Min(Bandwidth(b.maxBandwidth.GetBest()), b.bandwidthLo)
+}
+
+// The pacer sends 25% faster than the rate it is given, which is appropriate for window-based congestion controllers.
+// BBR controls the sending rate using the pacing gain, so this needs to be compensated for.
+func (b *bbr2Sender) pacingRateForPacer() Bandwidth {
+	return b.pacingRate * 4 / 5
+}
+
+func (b *bbr2Sender) minCongestionWindow() protocol.ByteCount {
+	return bbr2MinCongestionWindowPackets * b.maxDatagramSize
+}
+
+func (b *bbr2Sender) maxCongestionWindow() protocol.ByteCount {
+	return bbr2MaxCongestionWindowPackets * b.maxDatagramSize
+}
+
+// bdp calculates gain * the bandwidth-delay product
+func (b *bbr2Sender) bdp(gain float64) protocol.ByteCount {
+	bw := b.bandwidthEstimate()
+	if bw == 0 || b.minRTT == 0 {
+		return protocol.ByteCount(gain * float64(b.initialCongestionWindow))
+	}
+	return protocol.ByteCount(gain * float64(bw/BytesPerSecond) * b.minRTT.Seconds())
+}
+
+func (b *bbr2Sender) updateModel(sample BandwidthSample, eventTime time.Time) {
+	if sample.RTT <= 0 {
+		// not a valid sample
+		return
+	}
+	b.lastSampleAppLimited = sample.IsAppLimited
+	bw := Bandwidth(sample.Bandwidth)
+	b.bandwidthInRound = utils.Max(b.bandwidthInRound, bw)
+	if !sample.IsAppLimited || bw > Bandwidth(b.maxBandwidth.GetBest()) {
+		b.maxBandwidth.Update(int64(bw), time.Unix(0, b.cycleCount))
+	}
+	if b.minRTT == 0 || sample.RTT < b.minRTT || eventTime.Sub(b.minRTTTimestamp) > bbr2MinRTTWindow {
+		b.minRTT = sample.RTT
+		b.minRTTTimestamp = eventTime
+	}
+}
+
+func (b *bbr2Sender) updateRoundTripCounter(lastAckedPacket protocol.PacketNumber) bool {
+	if b.currentRoundTripEnd != protocol.InvalidPacketNumber && lastAckedPacket <= b.currentRoundTripEnd {
+		return false
+	}
+	b.roundCount++
+	b.currentRoundTripEnd = b.lastSentPacket
+	return true
+}
+
+// onRoundEnd is called when a round trip ends.
+// It evaluates the congestion signals collected during that round trip.
+func (b *bbr2Sender) onRoundEnd() {
+	b.bandwidthLatest = b.bandwidthInRound
+	b.inflightLatest = b.bytesAckedInRound
+
+	if b.mode == bbr2ModeStartup {
+		b.checkStartupFullBandwidth()
+		b.checkStartupTooMuchLoss()
+	}
+	b.adaptLowerBounds()
+	if b.mode == bbr2ModeProbeBW && b.probeBWPhase == bbr2ProbeBWUp && b.cwndLimitedInRound && b.inflightHi != protocol.MaxByteCount {
+		// Probe for a higher inflight_hi, growing the increment exponentially every round trip.
+		b.inflightHi += b.maxDatagramSize << utils.Min(b.probeUpRounds, 30)
+		b.probeUpRounds++
+	}
+
+	b.roundsSinceProbe++
+	b.bandwidthInRound = 0
+	b.bytesAckedInRound = 0
+	b.bytesLostInRound = 0
+	b.lossEventsInRound = 0
+	b.ecnInRound = false
+	b.cwndLimitedInRound = false
+}
+
+func (b *bbr2Sender) isProbingBandwidth() bool {
+	return b.mode == bbr2ModeStartup ||
+		(b.mode == bbr2ModeProbeBW && (b.probeBWPhase == bbr2ProbeBWRefill || b.probeBWPhase == bbr2ProbeBWUp))
+}
+
+func (b *bbr2Sender) lossTooHigh() bool {
+	lost := float64(b.bytesLostInRound)
+	return lost > 0 && lost > bbr2LossThreshold*float64(b.bytesLostInRound+b.bytesAckedInRound)
+}
+
+func (b *bbr2Sender) isInflightTooHigh() bool {
+	return b.ecnInRound || b.lossTooHigh()
+}
+
+// handleInflightTooHigh is called when loss or ECN signal that the amount of data in flight is too high.
+// If bandwidth is being probed, the probe is stopped, and inflight_hi is lowered.
+func (b *bbr2Sender) handleInflightTooHigh(inflight protocol.ByteCount) {
+	if !b.isProbingBandwidth() || b.mode == bbr2ModeStartup || b.respondedToProbeHit {
+		// Startup handles this at the end of the round, see checkStartupTooMuchLoss.
+		return
+	}
+	// Only react once per probe.
+	b.respondedToProbeHit = true
+	if !b.lastSampleAppLimited {
+		b.inflightHi = utils.Max(inflight, protocol.ByteCount(bbr2Beta*float64(b.bdp(1))))
+	}
+	if b.probeBWPhase == bbr2ProbeBWUp {
+		b.startProbeDown(b.clock.Now())
+	}
+}
+
+func (b *bbr2Sender) checkStartupFullBandwidth() {
+	if b.fullBandwidthReached || b.lastSampleAppLimited {
+		return
+	}
+	bw := Bandwidth(b.maxBandwidth.GetBest())
+	if float64(bw) >= float64(b.fullBandwidth)*bbr2FullBandwidthGrowth {
+		b.fullBandwidth = bw
+		b.fullBandwidthCount = 0
+		return
+	}
+	b.fullBandwidthCount++
+	if b.fullBandwidthCount >= bbr2FullBandwidthRounds {
+		b.fullBandwidthReached = true
+	}
+}
+
+func (b *bbr2Sender) checkStartupTooMuchLoss() {
+	if b.fullBandwidthReached {
+		return
+	}
+	if b.ecnInRound || (b.lossEventsInRound >= bbr2StartupFullLossCount && b.lossTooHigh()) {
+		b.fullBandwidthReached = true
+		b.inflightHi = utils.Max(b.bdp(1), b.inflightLatest)
+	}
+}
+
+// adaptLowerBounds lowers the lower bounds, if congestion was detected in the last round trip.
+// The lower bounds are not used while bandwidth is being probed.
+func (b *bbr2Sender) adaptLowerBounds() {
+	if b.isProbingBandwidth() || (b.bytesLostInRound == 0 && !b.ecnInRound) {
+		return
+	}
+	if b.bandwidthLo == infBandwidth {
+		b.bandwidthLo = Bandwidth(b.maxBandwidth.GetBest())
+	}
+	if b.inflightLo == protocol.MaxByteCount {
+		b.inflightLo = b.congestionWindow
+	}
+	b.bandwidthLo = utils.Max(b.bandwidthLatest, Bandwidth(bbr2Beta*float64(b.bandwidthLo)))
+	b.inflightLo = utils.Max(b.inflightLatest, protocol.ByteCount(bbr2Beta*float64(b.inflightLo)))
+}
+
+func (b *bbr2Sender) resetLowerBounds() {
+	b.bandwidthLo = infBandwidth
+	b.inflightLo = protocol.MaxByteCount
+}
+
+// inflightBound is the upper bound for the congestion window, as given by inflight_hi and inflight_lo.
+func (b *bbr2Sender) inflightBound() protocol.ByteCount {
+	hi := b.inflightHi
+	if b.mode == bbr2ModeProbeBW && (b.probeBWPhase == bbr2ProbeBWDown || b.probeBWPhase == bbr2ProbeBWCruise) {
+		hi = b.inflightWithHeadroom()
+	}
+	return utils.Min(hi, b.inflightLo)
+}
+
+// inflightWithHeadroom leaves some headroom below inflight_hi, to leave room for other flows.
+func (b *bbr2Sender) inflightWithHeadroom() protocol.ByteCount {
+	if b.inflightHi == protocol.MaxByteCount {
+		return protocol.MaxByteCount
+	}
+	headroom := utils.Max(b.maxDatagramSize, protocol.ByteCount(bbr2InflightHeadroom*float64(b.inflightHi)))
+	if b.inflightHi < headroom+b.minCongestionWindow() {
+		return b.minCongestionWindow()
+	}
+	return b.inflightHi - headroom
+}
+
+func (b *bbr2Sender) probeRTTCongestionWindow() protocol.ByteCount {
+	return utils.Max(b.bdp(bbr2ProbeRTTCwndGain), b.minCongestionWindow())
+}
+
+func (b *bbr2Sender) updateStateMachine(now time.Time, inflight protocol.ByteCount, isRoundStart bool) {
+	switch b.mode {
+	case bbr2ModeStartup:
+		if b.fullBandwidthReached {
+			b.enterDrain()
+		}
+	case bbr2ModeDrain:
+		if inflight <= b.bdp(1) {
+			b.enterProbeBW(now)
+		}
+	case bbr2ModeProbeBW:
+		b.updateProbeBWPhase(now, inflight, isRoundStart)
+	}
+	b.maybeEnterOrExitProbeRTT(now, inflight, isRoundStart)
+}
+
+func (b *bbr2Sender) enterStartup() {
+	b.mode = bbr2ModeStartup
+	b.pacingGain = bbr2StartupPacingGain
+	b.cwndGain = bbr2StartupCwndGain
+}
+
+func (b *bbr2Sender) enterDrain() {
+	b.mode = bbr2ModeDrain
+	b.pacingGain = bbr2DrainPacingGain
+	b.cwndGain = bbr2StartupCwndGain
+}
+
+func (b *bbr2Sender) enterProbeBW(now time.Time) {
+	b.mode = bbr2ModeProbeBW
+	b.cwndGain = bbr2CwndGain
+	b.startProbeDown(now)
+}
+
+func (b *bbr2Sender) startProbeDown(now time.Time) {
+	b.probeBWPhase = bbr2ProbeBWDown
+	b.pacingGain = bbr2ProbeDownPacingGain
+	b.cycleStart = now
+	b.roundsSinceProbe = 0
+	b.probeWait = bbr2ProbeBWMinWait + time.Duration(rand.Int63n(int64(bbr2ProbeBWRandWait)))
+	// Start a new cycle. The bandwidth filter keeps the samples of the last two cycles.
+	b.cycleCount++
+}
+
+func (b *bbr2Sender) startProbeCruise() {
+	b.probeBWPhase = bbr2ProbeBWCruise
+	b.pacingGain = 1
+}
+
+func (b *bbr2Sender) startProbeRefill() {
+	b.probeBWPhase = bbr2ProbeBWRefill
+	b.pacingGain = 1
+	b.probeUpRounds = 0
+	b.respondedToProbeHit = false
+	// The lower bounds are only valid until bandwidth is probed again.
+	b.resetLowerBounds()
+	// Start a new round trip, so that the refill lasts for a full round trip.
+	b.currentRoundTripEnd = b.lastSentPacket
+}
+
+func (b *bbr2Sender) startProbeUp(now time.Time) {
+	b.probeBWPhase = bbr2ProbeBWUp
+	b.pacingGain = bbr2ProbeUpPacingGain
+	b.cycleStart = now
+}
+
+func (b *bbr2Sender) isTimeToProbe(now time.Time) bool {
+	if now.Sub(b.cycleStart) >= b.probeWait {
+		return true
+	}
+	// Probe at least as frequently as a Reno flow with the same BDP would.
+	rounds := utils.Min(int(b.bdp(1)/b.maxDatagramSize), bbr2ProbeBWMaxRounds)
+	return b.roundsSinceProbe >= rounds
+}
+
+func (b *bbr2Sender) updateProbeBWPhase(now time.Time, inflight protocol.ByteCount, isRoundStart bool) {
+	switch b.probeBWPhase {
+	case bbr2ProbeBWDown:
+		if b.isTimeToProbe(now) {
+			b.startProbeRefill()
+			return
+		}
+		if inflight <= b.inflightWithHeadroom() && inflight <= b.bdp(1) {
+			b.startProbeCruise()
+		}
+	case bbr2ProbeBWCruise:
+		if b.isTimeToProbe(now) {
+			b.startProbeRefill()
+		}
+	case bbr2ProbeBWRefill:
+		// Refill the pipe for one round trip, before starting to probe.
+		if isRoundStart {
+			b.startProbeUp(now)
+		}
+	case bbr2ProbeBWUp:
+		// Probe for at least one min RTT, and until the pipe is filled to 1.25 * BDP.
+		if now.Sub(b.cycleStart) > b.minRTT && inflight > b.bdp(bbr2ProbeUpPacingGain) {
+			b.startProbeDown(now)
+		}
+	}
+}
+
+func (b *bbr2Sender) maybeEnterOrExitProbeRTT(now time.Time, inflight protocol.ByteCount, isRoundStart bool) {
+	if b.mode != bbr2ModeProbeRTT && !b.minRTTTimestamp.IsZero() && now.Sub(b.minRTTTimestamp) > bbr2ProbeRTTInterval {
+		b.mode = bbr2ModeProbeRTT
+		b.pacingGain = 1
+		b.priorCwnd = b.congestionWindow
+		// Don't decide on the time to exit ProbeRTT until the bytes in flight have been reduced.
+		b.probeRTTDoneTime = time.Time{}
+	}
+	if b.mode != bbr2ModeProbeRTT {
+		return
+	}
+	b.sampler.OnAppLimited()
+	if b.probeRTTDoneTime.IsZero() {
+		if inflight <= b.probeRTTCongestionWindow() {
+			b.probeRTTDoneTime = now.Add(bbr2ProbeRTTDuration)
+			b.probeRTTRoundDone = false
+		}
+		return
+	}
+	if isRoundStart {
+		b.probeRTTRoundDone = true
+	}
+	if b.probeRTTRoundDone && !now.Before(b.probeRTTDoneTime) {
+		b.minRTTTimestamp = now
+		b.congestionWindow = utils.Max(b.congestionWindow, b.priorCwnd)
+		b.resetLowerBounds()
+		if b.fullBandwidthReached {
+			b.mode = bbr2ModeProbeBW
+			b.cwndGain = bbr2CwndGain
+			b.startProbeDown(now)
+			b.startProbeCruise()
+		} else {
+			b.enterStartup()
+		}
+	}
+}
+
+func (b *bbr2Sender) updateCongestionWindow(ackedBytes protocol.ByteCount) {
+	if b.mode == bbr2ModeProbeRTT {
+		return
+	}
+	target := b.bdp(b.cwndGain) + bbr2QuantaPackets*b.maxDatagramSize
+	if b.fullBandwidthReached {
+		// Grow the congestion window towards the target, but reduce it immediately.
+		b.congestionWindow = utils.Min(b.congestionWindow+ackedBytes, target)
+	} else if b.congestionWindow < target || b.sampler.TotalBytesAcked() < b.initialCongestionWindow {
+		// Don't decrease the congestion window while in startup.
+		b.congestionWindow += ackedBytes
+	}
+	b.congestionWindow = utils.Max(b.congestionWindow, b.minCongestionWindow())
+	b.congestionWindow = utils.Min(b.congestionWindow, b.maxCongestionWindow())
+}
+
+func (b *bbr2Sender) updatePacingRate() {
+	bw := b.bandwidthEstimate()
+	if bw == 0 {
+		// Pace at the rate of initial_window / RTT until the bandwidth has been measured.
+		srtt := b.rttStats.SmoothedRTT()
+		if srtt == 0 {
+			srtt = bbr2InitialRTT
+		}
+		bw = BandwidthFromDelta(b.initialCongestionWindow, srtt)
+	}
+	rate := Bandwidth(b.pacingGain * float64(bw))
+	// Don't decrease the pacing rate during startup.
+	if b.fullBandwidthReached || rate > b.pacingRate {
+		b.pacingRate = rate
+	}
+}
+
+func (b *bbr2Sender) maybeTraceStateChange() {
+	if b.tracer == nil {
+		return
+	}
+	var state logging.CongestionState
+	switch {
+	case b.InSlowStart():
+		state = logging.CongestionStateSlowStart
+	case b.InRecovery():
+		state = logging.CongestionStateRecovery
+	default:
+		state = logging.CongestionStateCongestionAvoidance
+	}
+	if state == b.lastState {
+		return
+	}
+	b.tracer.UpdatedCongestionState(state)
+	b.lastState = state
+}
@@ -0,0 +1,213 @@
+package congestion
+
+import (
+	"time"
+
+	mocklogging "github.com/lucas-clemente/quic-go/internal/mocks/logging"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BBRv2 Sender", func() {
+	const (
+		linkBandwidth = 10_000_000 * BitsPerSecond
+		linkRTT       = 50 * time.Millisecond
+	)
+
+	var (
+		sender        *bbr2Sender
+		clock         mockClock
+		rttStats      *utils.RTTStats
+		bytesInFlight protocol.ByteCount
+		packetNumber  protocol.PacketNumber
+		// the time when the bottleneck link is free to transmit the next packet
+		linkFree time.Time
+		// decides if a packet is dropped, or marked with ECN-CE
+		drop, markCE func(protocol.PacketNumber) bool
+	)
+
+	type sentPacket struct {
+		pn      protocol.PacketNumber
+		arrival time.Time
+	}
+	var inFlight []sentPacket
+
+	BeforeEach(func() {
+		clock = mockClock(time.Now())
+		rttStats = utils.NewRTTStats()
+		sender = NewBBRv2Sender(&clock, rttStats, maxDatagramSize, nil)
+		bytesInFlight = 0
+		packetNumber = 1
+		linkFree = time.Time{}
+		inFlight = nil
+		drop = func(protocol.PacketNumber) bool { return false }
+		markCE = func(protocol.PacketNumber) bool { return false }
+	})
+
+	// send sends as many packets as the congestion window and the pacer allow.
+	// The bottleneck link serializes packets at linkBandwidth, so a queue builds up if the sender sends too fast.
+	send := func() {
+		for sender.CanSend(bytesInFlight) && sender.HasPacingBudget() {
+			now := clock.Now()
+			bytesInFlight += maxDatagramSize
+			sender.OnPacketSent(now, bytesInFlight, packetNumber, maxDatagramSize, true)
+			linkFree = utils.MaxTime(linkFree, now).Add(time.Duration(maxDatagramSize) * time.Second / time.Duration(linkBandwidth/BytesPerSecond))
+			inFlight = append(inFlight, sentPacket{pn: packetNumber, arrival: linkFree.Add(linkRTT)})
+			packetNumber++
+		}
+	}
+
+	// advance advances the clock to the next event: either the pacer allows sending of the next packet,
+	// or the next ACK arrives, which is then processed.
+	advance := func() {
+		p := inFlight[0]
+		if sender.CanSend(bytesInFlight) {
+			if t := sender.TimeUntilSend(bytesInFlight); t.Before(p.arrival) {
+				Expect(t.After(clock.Now())).To(BeTrue())
+				clock = mockClock(t)
+				return
+			}
+		}
+		inFlight = inFlight[1:]
+		if p.arrival.After(clock.Now()) {
+			clock = mockClock(p.arrival)
+		}
+		if drop(p.pn) {
+			sender.OnPacketLost(p.pn, maxDatagramSize, bytesInFlight)
+			bytesInFlight -= maxDatagramSize
+			return
+		}
+		rttStats.UpdateRTT(linkRTT, 0, clock.Now())
+		if markCE(p.pn) {
+			sender.OnECNCongestionEvent(p.pn, bytesInFlight)
+		}
+		sender.OnPacketAcked(p.pn, maxDatagramSize, bytesInFlight, clock.Now())
+		bytesInFlight -= maxDatagramSize
+	}
+
+	simulate := func(d time.Duration) {
+		end := clock.Now().Add(d)
+		for clock.Now().Before(end) {
+			send()
+			advance()
+		}
+	}
+
+	simulateUntil := func(cond func() bool, maxDuration time.Duration) {
+		end := clock.Now().Add(maxDuration)
+		for !cond() {
+			Expect(clock.Now().Before(end)).To(BeTrue(), "condition not met in time")
+			send()
+			advance()
+		}
+	}
+
+	It("starts in startup", func() {
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * maxDatagramSize))
+		Expect(sender.HasPacingBudget()).To(BeTrue())
+		Expect(sender.TimeUntilSend(0)).To(BeZero())
+		Expect(sender.BandwidthEstimate()).To(Equal(infBandwidth))
+	})
+
+	It("traces the initial congestion state", func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+		tracer.EXPECT().UpdatedCongestionState(logging.CongestionStateSlowStart)
+		NewBBRv2Sender(&clock, rttStats, maxDatagramSize, tracer)
+	})
+
+	It("estimates the bandwidth and exits startup", func() {
+		simulateUntil(func() bool { return sender.mode == bbr2ModeProbeBW }, 5*time.Second)
+		Expect(sender.fullBandwidthReached).To(BeTrue())
+		Expect(sender.minRTT).To(BeNumerically("~", linkRTT, 5*time.Millisecond))
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", linkBandwidth, linkBandwidth/5))
+		// no losses were detected, so the inflight bounds are not set
+		Expect(sender.inflightHi).To(Equal(protocol.MaxByteCount))
+		Expect(sender.InRecovery()).To(BeFalse())
+	})
+
+	It("cycles through the ProbeBW phases", func() {
+		simulateUntil(func() bool { return sender.mode == bbr2ModeProbeBW }, 5*time.Second)
+		phases := map[bbr2ProbeBWPhase]bool{}
+		simulateUntil(func() bool {
+			if sender.mode == bbr2ModeProbeBW {
+				phases[sender.probeBWPhase] = true
+			}
+			return len(phases) == 4
+		}, 10*time.Second)
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", linkBandwidth, linkBandwidth/5))
+	})
+
+	It("exits startup when the loss rate is too high", func() {
+		drop = func(pn protocol.PacketNumber) bool { return pn > 100 && pn%10 == 0 }
+		simulateUntil(func() bool { return sender.fullBandwidthReached }, 5*time.Second)
+		Expect(sender.inflightHi).ToNot(Equal(protocol.MaxByteCount))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<=", sender.inflightHi))
+	})
+
+	It("stops probing when too many packets are lost", func() {
+		// packets sent during REFILL are acknowledged during UP
+		simulateUntil(func() bool { return sender.mode == bbr2ModeProbeBW && sender.probeBWPhase == bbr2ProbeBWRefill }, 10*time.Second)
+		Expect(sender.inflightHi).To(Equal(protocol.MaxByteCount))
+		lossStart := packetNumber
+		drop = func(pn protocol.PacketNumber) bool { return pn > lossStart && pn%5 == 0 }
+		simulateUntil(func() bool { return sender.probeBWPhase == bbr2ProbeBWDown }, time.Second)
+		Expect(sender.inflightHi).ToNot(Equal(protocol.MaxByteCount))
+		Expect(sender.inflightHi).To(BeNumerically(">=", protocol.ByteCount(bbr2Beta*float64(sender.bdp(1)))))
+	})
+
+	It("stops probing when packets are marked with ECN-CE", func() {
+		// packets sent during REFILL are acknowledged during UP
+		simulateUntil(func() bool { return sender.mode == bbr2ModeProbeBW && sender.probeBWPhase == bbr2ProbeBWRefill }, 10*time.Second)
+		markedPN := packetNumber
+		markCE = func(pn protocol.PacketNumber) bool { return pn == markedPN }
+		simulateUntil(func() bool { return sender.probeBWPhase == bbr2ProbeBWDown }, time.Second)
+		Expect(sender.inflightHi).ToNot(Equal(protocol.MaxByteCount))
+	})
+
+	It("lowers the lower bounds when congestion is detected while cruising, and resets them when probing", func() {
+		simulateUntil(func() bool { return sender.mode == bbr2ModeProbeBW && sender.probeBWPhase == bbr2ProbeBWCruise }, 10*time.Second)
+		markedPN := packetNumber
+		markCE = func(pn protocol.PacketNumber) bool { return pn == markedPN }
+		simulateUntil(func() bool { return sender.InRecovery() }, time.Second)
+		Expect(sender.probeBWPhase).To(Equal(bbr2ProbeBWCruise))
+		Expect(sender.bandwidthLo).To(BeNumerically("<", infBandwidth))
+		Expect(sender.inflightLo).To(BeNumerically("<", protocol.MaxByteCount))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<=", sender.inflightLo))
+		simulateUntil(func() bool { return sender.probeBWPhase == bbr2ProbeBWRefill }, 5*time.Second)
+		Expect(sender.InRecovery()).To(BeFalse())
+	})
+
+	It("enters ProbeRTT if the min RTT wasn't updated for a while", func() {
+		simulateUntil(func() bool { return sender.mode == bbr2ModeProbeRTT }, 10*time.Second)
+		Expect(clock.Now().Sub(sender.minRTTTimestamp)).To(BeNumerically(">", bbr2ProbeRTTInterval))
+		Expect(sender.GetCongestionWindow()).To(Equal(sender.probeRTTCongestionWindow()))
+		start := clock.Now()
+		simulateUntil(func() bool { return sender.mode != bbr2ModeProbeRTT }, time.Second)
+		Expect(clock.Now().Sub(start)).To(BeNumerically(">=", bbr2ProbeRTTDuration))
+		Expect(sender.mode).To(Equal(bbr2ModeProbeBW))
+		Expect(sender.minRTTTimestamp).To(Equal(clock.Now()))
+	})
+
+	It("keeps sending at the link rate", func() {
+		simulate(5 * time.Second)
+		start := clock.Now()
+		startPN := packetNumber
+		simulate(10 * time.Second)
+		sent := protocol.ByteCount(packetNumber-startPN) * maxDatagramSize
+		Expect(BandwidthFromDelta(sent, clock.Now().Sub(start))).To(BeNumerically("~", linkBandwidth, linkBandwidth/10))
+	})
+
+	It("panics when the max datagram size is decreased", func() {
+		sender.SetMaxDatagramSize(maxDatagramSize + 1)
+		Expect(func() { sender.SetMaxDatagramSize(maxDatagramSize) }).To(Panic())
+	})
+})
@@ -299,6 +299,14 @@ func (b *bbrSender) OnPacketLost(number protocol.PacketNumber, lostBytes protoco
 	b.onCongestionEvent(number, math.MaxInt64, lostBytes, priorInFlight, time.Now(), false, false)
 }
 
+// OnECNCongestionEvent is called when the peer reports that packets were marked with ECN-CE.
+// BBR doesn't use CE marks to update its model of the path,
+// but it enters recovery, just like it would after a packet loss.
+func (b *bbrSender) OnECNCongestionEvent(largestAcked protocol.PacketNumber, priorInFlight protocol.ByteCount) {
+	b.updateRecoveryState(largestAcked, true, false)
+	b.calculateRecoveryWindow(0, 0, priorInFlight)
+}
+
 func (b *bbrSender) onCongestionEvent(number protocol.PacketNumber,
 	ackedBytes protocol.ByteCount,
 	lostBytes protocol.ByteCount,
@@ -194,6 +194,20 @@ func (c *cubicSender) OnPacketLost(packetNumber protocol.PacketNumber, lostBytes
 	if packetNumber <= c.largestSentAtLastCutback {
 		return
 	}
+	c.reduceCongestionWindow()
+}
+
+// OnECNCongestionEvent is called when the peer reports that packets were marked with ECN-CE.
+// We respond the same way as to a packet loss. See section 7.1 of RFC 9002.
+func (c *cubicSender) OnECNCongestionEvent(largestAcked protocol.PacketNumber, _ protocol.ByteCount) {
+	// Only reduce the congestion window once per round trip.
+	if largestAcked <= c.largestSentAtLastCutback {
+		return
+	}
+	c.reduceCongestionWindow()
+}
+
+func (c *cubicSender) reduceCongestionWindow() {
 	c.lastCutbackExitedSlowstart = c.InSlowStart()
 	c.maybeTraceStateChange(logging.CongestionStateRecovery)
 
@@ -371,6 +371,23 @@ var _ = Describe("Cubic Sender", func() {
 		Expect(postLossWindow).To(BeNumerically(">", sender.GetCongestionWindow()))
 	})
 
+	It("reduces the window once per round trip on ECN congestion events", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		initialWindow := sender.GetCongestionWindow()
+		sender.OnECNCongestionEvent(ackedPacketNumber, bytesInFlight)
+		postCongestionWindow := sender.GetCongestionWindow()
+		Expect(postCongestionWindow).To(Equal(protocol.ByteCount(float64(initialWindow) * renoBeta)))
+		Expect(sender.InRecovery()).To(BeTrue())
+		// CE marks on packets sent before the reduction don't reduce the window again
+		sender.OnECNCongestionEvent(packetNumber-1, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(postCongestionWindow))
+		// CE marks on packets sent after the reduction do
+		SendAvailableSendWindow()
+		sender.OnECNCongestionEvent(packetNumber-1, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", postCongestionWindow))
+	})
+
 	It("1 connection congestion avoidance at end of recovery", func() {
 		// Ack 10 packets in 5 acks to raise the CWND to 20.
 		const numberOfAcks = 5
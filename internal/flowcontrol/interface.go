@@ -16,7 +16,13 @@ type flowController interface {
 // A StreamFlowController is a flow controller for a QUIC stream.
 type StreamFlowController interface {
 	flowController
+	// for sending
+	// SendWindowSizes returns the send window of the stream and of the connection.
+	// SendWindowSize returns the smaller of the two.
+	SendWindowSizes() (stream, connection protocol.ByteCount)
 	// for receiving
+	// ReceiveWindow returns the highest offset the peer is currently allowed to send on this stream.
+	ReceiveWindow() protocol.ByteCount
 	// UpdateHighestReceived should be called when a new highest offset is received
 	// final has to be to true if this is the final offset of the stream,
 	// as contained in a STREAM frame with FIN bit, and the RESET_STREAM frame
@@ -126,6 +126,16 @@ func (c *streamFlowController) SendWindowSize() protocol.ByteCount {
 	return utils.Min(c.baseFlowController.sendWindowSize(), c.connection.SendWindowSize())
 }
 
+func (c *streamFlowController) SendWindowSizes() (stream, connection protocol.ByteCount) {
+	return c.baseFlowController.sendWindowSize(), c.connection.SendWindowSize()
+}
+
+func (c *streamFlowController) ReceiveWindow() protocol.ByteCount {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.receiveWindow
+}
+
 func (c *streamFlowController) shouldQueueWindowUpdate() bool {
 	return !c.receivedFinalOffset && c.hasWindowUpdate()
 }
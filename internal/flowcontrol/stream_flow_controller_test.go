@@ -161,6 +161,12 @@ var _ = Describe("Stream Flow controller", func() {
 			})
 		})
 
+		It("gets the receive window", func() {
+			Expect(controller.ReceiveWindow()).To(Equal(controller.receiveWindow))
+			controller.receiveWindow = 1337
+			Expect(controller.ReceiveWindow()).To(Equal(protocol.ByteCount(1337)))
+		})
+
 		It("saves when data is read", func() {
 			controller.AddBytesRead(200)
 			Expect(controller.bytesRead).To(Equal(protocol.ByteCount(200)))
@@ -260,6 +266,15 @@ var _ = Describe("Stream Flow controller", func() {
 			Expect(controller.SendWindowSize()).To(Equal(protocol.ByteCount(2)))
 		})
 
+		It("gets the send windows of the stream and the connection", func() {
+			controller.connection.UpdateSendWindow(12)
+			controller.UpdateSendWindow(20)
+			controller.AddBytesSent(10)
+			stream, connection := controller.SendWindowSizes()
+			Expect(stream).To(Equal(protocol.ByteCount(10)))
+			Expect(connection).To(Equal(protocol.ByteCount(2)))
+		})
+
 		It("doesn't say that it's blocked, if only the connection is blocked", func() {
 			controller.connection.UpdateSendWindow(50)
 			controller.UpdateSendWindow(100)
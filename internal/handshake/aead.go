@@ -21,6 +21,28 @@ func createAEAD(suite *qtls.CipherSuiteTLS13, trafficSecret []byte, v protocol.V
 	return suite.AEAD(key, iv)
 }
 
+// createPathAEAD creates the AEAD used on an additional path of a multipath connection.
+// The nonce is constructed from the 32 bit path ID and the 64 bit packet number,
+// see section 9.1 of draft-ietf-quic-multipath-04.
+// Since the AEAD only XORs the packet number into the last 8 bytes of the IV,
+// the path ID is XORed into the first 4 bytes of the IV here.
+func createPathAEAD(suite *qtls.CipherSuiteTLS13, trafficSecret []byte, pathID uint64, v protocol.VersionNumber) cipher.AEAD {
+	keyLabel := hkdfLabelKeyV1
+	ivLabel := hkdfLabelIVV1
+	if v == protocol.Version2 {
+		keyLabel = hkdfLabelKeyV2
+		ivLabel = hkdfLabelIVV2
+	}
+	key := hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, keyLabel, suite.KeyLen)
+	iv := hkdfExpandLabel(suite.Hash, trafficSecret, []byte{}, ivLabel, suite.IVLen())
+	var pathIDBytes [4]byte
+	binary.BigEndian.PutUint32(pathIDBytes[:], uint32(pathID))
+	for i := range pathIDBytes {
+		iv[len(iv)-12+i] ^= pathIDBytes[i]
+	}
+	return suite.AEAD(key, iv)
+}
+
 type longHeaderSealer struct {
 	aead            cipher.AEAD
 	headerProtector headerProtector
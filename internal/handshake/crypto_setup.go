@@ -2,6 +2,7 @@ package handshake
 
 import (
 	"bytes"
+	"crypto/rand"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -93,6 +94,11 @@ type cryptoSetup struct {
 	extraConf *qtls.ExtraConfig
 	conn      *qtls.Conn
 
+	extHandler tlsExtensionHandler
+
+	// the version that the connection was started with
+	origVersion protocol.VersionNumber
+	// the version currently in use, which can change during compatible version negotiation
 	version protocol.VersionNumber
 
 	messageChan               chan []byte
@@ -116,6 +122,12 @@ type cryptoSetup struct {
 	clientHelloWrittenChan chan struct{} // is closed as soon as the ClientHello is written
 	zeroRTTParametersChan  chan<- *wire.TransportParameters
 
+	// only set for the server
+	antiReplay           ZeroRTTAntiReplay
+	allow0RTT            func(net.Addr, []byte) bool
+	getSessionTicketData func(net.Addr) []byte
+	remoteAddr           net.Addr
+
 	rttStats *utils.RTTStats
 
 	tracer logging.ConnectionTracer
@@ -134,8 +146,12 @@ type cryptoSetup struct {
 	zeroRTTSealer LongHeaderSealer // only set for the client
 
 	initialStream io.Writer
+	initialConnID protocol.ConnectionID
 	initialOpener LongHeaderOpener
 	initialSealer LongHeaderSealer
+	// origInitialOpener opens Initial packets using the original version.
+	// Only set for the server, after switching to a compatible version.
+	origInitialOpener LongHeaderOpener
 
 	handshakeStream io.Writer
 	handshakeOpener LongHeaderOpener
@@ -196,6 +212,9 @@ func NewCryptoSetupServer(
 	runner handshakeRunner,
 	tlsConf *tls.Config,
 	enable0RTT bool,
+	antiReplay ZeroRTTAntiReplay,
+	allow0RTT func(net.Addr, []byte) bool,
+	getSessionTicketData func(net.Addr) []byte,
 	rttStats *utils.RTTStats,
 	tracer logging.ConnectionTracer,
 	logger utils.Logger,
@@ -215,6 +234,10 @@ func NewCryptoSetupServer(
 		protocol.PerspectiveServer,
 		version,
 	)
+	cs.antiReplay = antiReplay
+	cs.allow0RTT = allow0RTT
+	cs.getSessionTicketData = getSessionTicketData
+	cs.remoteAddr = remoteAddr
 	cs.conn = qtls.Server(newConn(localAddr, remoteAddr, version), cs.tlsConf, cs.extraConf)
 	return cs
 }
@@ -242,7 +265,9 @@ func newCryptoSetup(
 	zeroRTTParametersChan := make(chan *wire.TransportParameters, 1)
 	cs := &cryptoSetup{
 		tlsConf:                   tlsConf,
+		extHandler:                extHandler,
 		initialStream:             initialStream,
+		initialConnID:             connID,
 		initialSealer:             initialSealer,
 		initialOpener:             initialOpener,
 		handshakeStream:           handshakeStream,
@@ -263,6 +288,7 @@ func newCryptoSetup(
 		messageChan:               make(chan []byte, 100),
 		isReadingHandshakeMessage: make(chan struct{}),
 		closeChan:                 make(chan struct{}),
+		origVersion:               version,
 		version:                   version,
 	}
 	var maxEarlyData uint32
@@ -286,6 +312,7 @@ func newCryptoSetup(
 
 func (h *cryptoSetup) ChangeConnectionID(id protocol.ConnectionID) {
 	initialSealer, initialOpener := NewInitialAEAD(id, h.perspective, h.version)
+	h.initialConnID = id
 	h.initialSealer = initialSealer
 	h.initialOpener = initialOpener
 	if h.tracer != nil {
@@ -294,6 +321,31 @@ func (h *cryptoSetup) ChangeConnectionID(id protocol.ConnectionID) {
 	}
 }
 
+// ChangeVersion switches to a compatible QUIC version during the handshake (RFC 9368).
+// The server calls it when handling the client's transport parameters,
+// the client calls it when receiving the server's first Initial packet.
+func (h *cryptoSetup) ChangeVersion(v protocol.VersionNumber) {
+	h.mutex.Lock()
+	// The client keeps sending Initial packets using the original version until it receives our first Initial.
+	if h.perspective == protocol.PerspectiveServer && h.origInitialOpener == nil {
+		h.origInitialOpener = h.initialOpener
+	}
+	h.version = v
+	h.aead.version = v
+	h.initialSealer, h.initialOpener = NewInitialAEAD(h.initialConnID, h.perspective, v)
+	h.mutex.Unlock()
+
+	if h.tracer != nil {
+		h.tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveClient)
+		h.tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveServer)
+	}
+	// The server hasn't sent its transport parameters yet.
+	if h.perspective == protocol.PerspectiveServer && h.ourParams.VersionInformation != nil {
+		h.ourParams.VersionInformation.ChosenVersion = v
+		h.extHandler.SetTransportParameters(h.ourParams.Marshal(h.perspective))
+	}
+}
+
 func (h *cryptoSetup) SetLargest1RTTAcked(pn protocol.PacketNumber) error {
 	return h.aead.SetLargestAcked(pn)
 }
@@ -379,6 +431,7 @@ readLoop:
 			} else {
 				h.handleTransportParameters(data)
 			}
+			h.extHandler.TransportParametersHandled()
 		case <-h.isReadingHandshakeMessage:
 			break readLoop
 		case <-h.handshakeDone:
@@ -473,10 +526,18 @@ func (h *cryptoSetup) GetSessionTicket() ([]byte, error) {
 	var appData []byte
 	// Save transport parameters to the session ticket if we're allowing 0-RTT.
 	if h.extraConf.MaxEarlyData > 0 {
-		appData = (&sessionTicket{
+		t := &sessionTicket{
 			Parameters: h.ourParams,
 			RTT:        h.rttStats.SmoothedRTT(),
-		}).Marshal()
+			IssuedAt:   time.Now(),
+		}
+		if h.getSessionTicketData != nil {
+			t.AppData = h.getSessionTicketData(h.remoteAddr)
+		}
+		if _, err := rand.Read(t.ID[:]); err != nil {
+			return nil, err
+		}
+		appData = t.Marshal()
 	}
 	return h.conn.GetSessionTicket(appData)
 }
@@ -484,19 +545,44 @@ func (h *cryptoSetup) GetSessionTicket() ([]byte, error) {
 // accept0RTT is called for the server when receiving the client's session ticket.
 // It decides whether to accept 0-RTT.
 func (h *cryptoSetup) accept0RTT(sessionTicketData []byte) bool {
+	// The client sent 0-RTT packets using the original version.
+	if h.version != h.origVersion {
+		h.logger.Debugf("Switched QUIC version from %s to %s. Rejecting 0-RTT.", h.origVersion, h.version)
+		if h.tracer != nil {
+			h.tracer.Rejected0RTT(logging.ZeroRTTRejectedVersionChanged)
+		}
+		return false
+	}
 	var t sessionTicket
 	if err := t.Unmarshal(sessionTicketData); err != nil {
 		h.logger.Debugf("Unmarshalling transport parameters from session ticket failed: %s", err.Error())
 		return false
 	}
-	valid := h.ourParams.ValidFor0RTT(t.Parameters)
-	if valid {
-		h.logger.Debugf("Accepting 0-RTT. Restoring RTT from session ticket: %s", t.RTT)
-		h.rttStats.SetInitialRTT(t.RTT)
-	} else {
+	if !h.ourParams.ValidFor0RTT(t.Parameters) {
 		h.logger.Debugf("Transport parameters changed. Rejecting 0-RTT.")
+		if h.tracer != nil {
+			h.tracer.Rejected0RTT(logging.ZeroRTTRejectedTransportParameters)
+		}
+		return false
 	}
-	return valid
+	if h.allow0RTT != nil && !h.allow0RTT(h.remoteAddr, t.AppData) {
+		h.logger.Debugf("Application rejected 0-RTT.")
+		if h.tracer != nil {
+			h.tracer.Rejected0RTT(logging.ZeroRTTRejectedByApplication)
+		}
+		return false
+	}
+	// Check for replays last, such that tickets are only recorded if they would otherwise be accepted.
+	if h.antiReplay != nil && !h.antiReplay.Accept(t.ID[:], t.IssuedAt) {
+		h.logger.Debugf("Session ticket was already used or is too old (issued at %s). Rejecting 0-RTT.", t.IssuedAt)
+		if h.tracer != nil {
+			h.tracer.Rejected0RTT(logging.ZeroRTTRejectedReplay)
+		}
+		return false
+	}
+	h.logger.Debugf("Accepting 0-RTT. Restoring RTT from session ticket: %s", t.RTT)
+	h.rttStats.SetInitialRTT(t.RTT)
+	return true
 }
 
 // rejected0RTT is called for the client when the server rejects 0-RTT.
@@ -692,6 +778,7 @@ func (h *cryptoSetup) dropInitialKeys() {
 	h.mutex.Lock()
 	h.initialOpener = nil
 	h.initialSealer = nil
+	h.origInitialOpener = nil
 	h.mutex.Unlock()
 	h.runner.DropKeys(protocol.EncryptionInitial)
 	h.logger.Debugf("Dropping Initial keys.")
@@ -757,13 +844,16 @@ func (h *cryptoSetup) Get1RTTSealer() (ShortHeaderSealer, error) {
 	return h.aead, nil
 }
 
-func (h *cryptoSetup) GetInitialOpener() (LongHeaderOpener, error) {
+func (h *cryptoSetup) GetInitialOpener(v protocol.VersionNumber) (LongHeaderOpener, error) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
 	if h.initialOpener == nil {
 		return nil, ErrKeysDropped
 	}
+	if v != h.version && v == h.origVersion && h.origInitialOpener != nil {
+		return h.origInitialOpener, nil
+	}
 	return h.initialOpener, nil
 }
 
@@ -816,3 +906,9 @@ func (h *cryptoSetup) Get1RTTOpener() (ShortHeaderOpener, error) {
 func (h *cryptoSetup) ConnectionState() ConnectionState {
 	return qtls.GetConnectionState(h.conn)
 }
+
+// KeyPhase returns the current key phase of the 1-RTT keys, i.e. the number of key updates.
+// It must only be called from the connection's run loop.
+func (h *cryptoSetup) KeyPhase() protocol.KeyPhase {
+	return h.aead.keyPhase
+}
@@ -8,6 +8,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"math/big"
+	"net"
 	"time"
 
 	mocktls "github.com/lucas-clemente/quic-go/internal/mocks/tls"
@@ -58,6 +59,12 @@ func (s *stream) Write(b []byte) (int, error) {
 	return len(b), nil
 }
 
+type zeroRTTAntiReplayFunc func(ticketID []byte, issuedAt time.Time) bool
+
+func (f zeroRTTAntiReplayFunc) Accept(ticketID []byte, issuedAt time.Time) bool {
+	return f(ticketID, issuedAt)
+}
+
 var _ = Describe("Crypto Setup TLS", func() {
 	var clientConf, serverConf *tls.Config
 
@@ -96,6 +103,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 			runner,
 			testdata.GetTLSConfig(),
 			false,
+			nil,
+			nil,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -178,6 +188,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 			runner,
 			testdata.GetTLSConfig(),
 			false,
+			nil,
+			nil,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -219,6 +232,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 			runner,
 			serverConf,
 			false,
+			nil,
+			nil,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -254,6 +270,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 			NewMockHandshakeRunner(mockCtrl),
 			serverConf,
 			false,
+			nil,
+			nil,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -271,6 +290,18 @@ var _ = Describe("Crypto Setup TLS", func() {
 	})
 
 	Context("doing the handshake", func() {
+		var (
+			serverAntiReplay           ZeroRTTAntiReplay
+			serverAllow0RTT            func(net.Addr, []byte) bool
+			serverGetSessionTicketData func(net.Addr) []byte
+		)
+
+		BeforeEach(func() {
+			serverAntiReplay = nil
+			serverAllow0RTT = nil
+			serverGetSessionTicketData = nil
+		})
+
 		generateCert := func() tls.Certificate {
 			priv, err := rsa.GenerateKey(rand.Reader, 2048)
 			Expect(err).ToNot(HaveOccurred())
@@ -399,6 +430,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 				sRunner,
 				serverConf,
 				enable0RTT,
+				serverAntiReplay,
+				serverAllow0RTT,
+				serverGetSessionTicketData,
 				serverRTTStats,
 				nil,
 				utils.DefaultLogger.WithPrefix("server"),
@@ -537,6 +571,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 				sRunner,
 				serverConf,
 				false,
+				nil,
+				nil,
+				nil,
 				&utils.RTTStats{},
 				nil,
 				utils.DefaultLogger.WithPrefix("server"),
@@ -592,6 +629,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 					sRunner,
 					serverConf,
 					false,
+					nil,
+					nil,
+					nil,
 					&utils.RTTStats{},
 					nil,
 					utils.DefaultLogger.WithPrefix("server"),
@@ -651,6 +691,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 					sRunner,
 					serverConf,
 					false,
+					nil,
+					nil,
+					nil,
 					&utils.RTTStats{},
 					nil,
 					utils.DefaultLogger.WithPrefix("server"),
@@ -859,6 +902,111 @@ var _ = Describe("Crypto Setup TLS", func() {
 				Expect(server.ConnectionState().Used0RTT).To(BeFalse())
 				Expect(client.ConnectionState().Used0RTT).To(BeFalse())
 			})
+
+			It("rejects 0-RTT, when the application rejects it", func() {
+				csc := mocktls.NewMockClientSessionCache(mockCtrl)
+				var state *tls.ClientSessionState
+				receivedSessionTicket := make(chan struct{})
+				csc.EXPECT().Get(gomock.Any())
+				csc.EXPECT().Put(gomock.Any(), gomock.Any()).Do(func(_ string, css *tls.ClientSessionState) {
+					state = css
+					close(receivedSessionTicket)
+				})
+				clientConf.ClientSessionCache = csc
+				serverGetSessionTicketData = func(net.Addr) []byte { return []byte("foobar") }
+				sessionData := make(chan []byte, 1)
+				serverAllow0RTT = func(_ net.Addr, data []byte) bool {
+					sessionData <- data
+					return false
+				}
+				_, client, clientErr, server, serverErr := handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{}, &wire.TransportParameters{},
+					true,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Eventually(receivedSessionTicket).Should(BeClosed())
+				Expect(server.ConnectionState().DidResume).To(BeFalse())
+				Expect(client.ConnectionState().DidResume).To(BeFalse())
+				Expect(sessionData).ToNot(Receive())
+
+				csc.EXPECT().Get(gomock.Any()).Return(state, true)
+				csc.EXPECT().Put(gomock.Any(), nil)
+				csc.EXPECT().Put(gomock.Any(), gomock.Any()).MaxTimes(1)
+				_, client, clientErr, server, serverErr = handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{}, &wire.TransportParameters{},
+					true,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Expect(sessionData).To(Receive(Equal([]byte("foobar"))))
+				Expect(server.ConnectionState().DidResume).To(BeTrue())
+				Expect(client.ConnectionState().DidResume).To(BeTrue())
+				Expect(server.ConnectionState().Used0RTT).To(BeFalse())
+				Expect(client.ConnectionState().Used0RTT).To(BeFalse())
+			})
+
+			It("rejects 0-RTT, when the session ticket is replayed", func() {
+				csc := mocktls.NewMockClientSessionCache(mockCtrl)
+				var state *tls.ClientSessionState
+				receivedSessionTicket := make(chan struct{})
+				csc.EXPECT().Get(gomock.Any())
+				csc.EXPECT().Put(gomock.Any(), gomock.Any()).Do(func(_ string, css *tls.ClientSessionState) {
+					state = css
+					close(receivedSessionTicket)
+				})
+				clientConf.ClientSessionCache = csc
+				var ticketIDs [][]byte
+				var issueTimes []time.Time
+				serverAntiReplay = zeroRTTAntiReplayFunc(func(ticketID []byte, issuedAt time.Time) bool {
+					ticketIDs = append(ticketIDs, ticketID)
+					issueTimes = append(issueTimes, issuedAt)
+					return len(ticketIDs) == 1
+				})
+				start := time.Now()
+				_, client, clientErr, server, serverErr := handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{}, &wire.TransportParameters{},
+					true,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Eventually(receivedSessionTicket).Should(BeClosed())
+				Expect(server.ConnectionState().DidResume).To(BeFalse())
+				Expect(client.ConnectionState().DidResume).To(BeFalse())
+				Expect(ticketIDs).To(BeEmpty())
+
+				// use the same session ticket twice
+				for i := 0; i < 2; i++ {
+					// qtls modifies the session state when using it
+					s := *state
+					csc.EXPECT().Get(gomock.Any()).Return(&s, true)
+					csc.EXPECT().Put(gomock.Any(), gomock.Any()).AnyTimes()
+					_, client, clientErr, server, serverErr = handshakeWithTLSConf(
+						clientConf, serverConf,
+						&utils.RTTStats{}, &utils.RTTStats{},
+						&wire.TransportParameters{}, &wire.TransportParameters{},
+						true,
+					)
+					Expect(clientErr).ToNot(HaveOccurred())
+					Expect(serverErr).ToNot(HaveOccurred())
+					Expect(server.ConnectionState().DidResume).To(BeTrue())
+					Expect(client.ConnectionState().DidResume).To(BeTrue())
+					Expect(server.ConnectionState().Used0RTT).To(Equal(i == 0))
+					Expect(client.ConnectionState().Used0RTT).To(Equal(i == 0))
+				}
+				Expect(ticketIDs).To(HaveLen(2))
+				Expect(ticketIDs[0]).To(HaveLen(sessionTicketIDLen))
+				Expect(ticketIDs[1]).To(Equal(ticketIDs[0]))
+				Expect(issueTimes[0]).To(BeTemporally(">=", start))
+				Expect(issueTimes[0]).To(BeTemporally("<=", time.Now()))
+				Expect(issueTimes[1]).To(Equal(issueTimes[0]))
+			})
 		})
 	})
 })
@@ -42,6 +42,8 @@ type ShortHeaderOpener interface {
 	headerDecryptor
 	DecodePacketNumber(wirePN protocol.PacketNumber, wirePNLen protocol.PacketNumberLen) protocol.PacketNumber
 	Open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, associatedData []byte) ([]byte, error)
+	// OpenOnPath opens a packet received on an additional path of a multipath connection.
+	OpenOnPath(dst, src []byte, pathID uint64, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, associatedData []byte) ([]byte, error)
 }
 
 // LongHeaderSealer seals a long header packet
@@ -55,6 +57,8 @@ type LongHeaderSealer interface {
 type ShortHeaderSealer interface {
 	LongHeaderSealer
 	KeyPhase() protocol.KeyPhaseBit
+	// SealOnPath seals a packet sent on an additional path of a multipath connection.
+	SealOnPath(dst, src []byte, pathID uint64, packetNumber protocol.PacketNumber, associatedData []byte) []byte
 }
 
 // A tlsExtensionHandler sends and received the QUIC TLS extension.
@@ -62,6 +66,17 @@ type tlsExtensionHandler interface {
 	GetExtensions(msgType uint8) []qtls.Extension
 	ReceivedExtensions(msgType uint8, exts []qtls.Extension)
 	TransportParameters() <-chan []byte
+	// TransportParametersHandled must be called after the transport parameters received
+	// from the channel returned by TransportParameters have been handled.
+	TransportParametersHandled()
+	// SetTransportParameters sets the transport parameters sent to the peer.
+	// It must be called before the extension is sent.
+	SetTransportParameters([]byte)
+}
+
+// A ZeroRTTAntiReplay is used by the server to detect replayed 0-RTT session tickets.
+type ZeroRTTAntiReplay interface {
+	Accept(ticketID []byte, issuedAt time.Time) bool
 }
 
 type handshakeRunner interface {
@@ -76,14 +91,18 @@ type CryptoSetup interface {
 	RunHandshake()
 	io.Closer
 	ChangeConnectionID(protocol.ConnectionID)
+	ChangeVersion(protocol.VersionNumber)
 	GetSessionTicket() ([]byte, error)
 
 	HandleMessage([]byte, protocol.EncryptionLevel) bool
 	SetLargest1RTTAcked(protocol.PacketNumber) error
 	SetHandshakeConfirmed()
 	ConnectionState() ConnectionState
+	KeyPhase() protocol.KeyPhase
 
-	GetInitialOpener() (LongHeaderOpener, error)
+	// GetInitialOpener returns the opener for Initial packets using version v.
+	// After switching to a compatible version, the server still accepts Initial packets using the client's original version.
+	GetInitialOpener(v protocol.VersionNumber) (LongHeaderOpener, error)
 	GetHandshakeOpener() (LongHeaderOpener, error)
 	Get0RTTOpener() (LongHeaderOpener, error)
 	Get1RTTOpener() (ShortHeaderOpener, error)
@@ -4,23 +4,36 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/lucas-clemente/quic-go/internal/wire"
 	"github.com/lucas-clemente/quic-go/quicvarint"
 )
 
-const sessionTicketRevision = 2
+const sessionTicketRevision = 4
+
+const sessionTicketIDLen = 16
 
 type sessionTicket struct {
 	Parameters *wire.TransportParameters
 	RTT        time.Duration // to be encoded in mus
+	// ID uniquely identifies the session ticket, and IssuedAt is the time the ticket was issued.
+	// They are used for 0-RTT anti-replay protection.
+	ID       [sessionTicketIDLen]byte
+	IssuedAt time.Time // to be encoded in mus since the Unix epoch
+	// AppData is the data provided by the application when the ticket was issued.
+	AppData []byte
 }
 
 func (t *sessionTicket) Marshal() []byte {
 	b := make([]byte, 0, 256)
 	b = quicvarint.Append(b, sessionTicketRevision)
 	b = quicvarint.Append(b, uint64(t.RTT.Microseconds()))
+	b = append(b, t.ID[:]...)
+	b = quicvarint.Append(b, uint64(t.IssuedAt.UnixMicro()))
+	b = quicvarint.Append(b, uint64(len(t.AppData)))
+	b = append(b, t.AppData...)
 	return t.Parameters.MarshalForSessionTicket(b)
 }
 
@@ -37,11 +50,29 @@ func (t *sessionTicket) Unmarshal(b []byte) error {
 	if err != nil {
 		return errors.New("failed to read RTT")
 	}
+	if _, err := io.ReadFull(r, t.ID[:]); err != nil {
+		return errors.New("failed to read ID")
+	}
+	issuedAt, err := quicvarint.Read(r)
+	if err != nil {
+		return errors.New("failed to read issue time")
+	}
+	appDataLen, err := quicvarint.Read(r)
+	if err != nil || appDataLen > uint64(r.Len()) {
+		return errors.New("failed to read application data")
+	}
+	var appData []byte
+	if appDataLen > 0 {
+		appData = make([]byte, appDataLen)
+		r.Read(appData)
+	}
 	var tp wire.TransportParameters
 	if err := tp.UnmarshalFromSessionTicket(r); err != nil {
 		return fmt.Errorf("unmarshaling transport parameters from session ticket failed: %s", err.Error())
 	}
 	t.Parameters = &tp
 	t.RTT = time.Duration(rtt) * time.Microsecond
+	t.IssuedAt = time.UnixMicro(int64(issuedAt))
+	t.AppData = appData
 	return nil
 }
@@ -18,13 +18,19 @@ var _ = Describe("Session Ticket", func() {
 				InitialMaxStreamDataBidiLocal:  1,
 				InitialMaxStreamDataBidiRemote: 2,
 			},
-			RTT: 1337 * time.Microsecond,
+			RTT:      1337 * time.Microsecond,
+			ID:       [sessionTicketIDLen]byte{1, 2, 3, 4},
+			IssuedAt: time.UnixMicro(1234567890),
+			AppData:  []byte("foobar"),
 		}
 		var t sessionTicket
 		Expect(t.Unmarshal(ticket.Marshal())).To(Succeed())
 		Expect(t.Parameters.InitialMaxStreamDataBidiLocal).To(BeEquivalentTo(1))
 		Expect(t.Parameters.InitialMaxStreamDataBidiRemote).To(BeEquivalentTo(2))
 		Expect(t.RTT).To(Equal(1337 * time.Microsecond))
+		Expect(t.ID).To(Equal(ticket.ID))
+		Expect(t.IssuedAt).To(Equal(ticket.IssuedAt))
+		Expect(t.AppData).To(Equal([]byte("foobar")))
 	})
 
 	It("refuses to unmarshal if the ticket is too short for the revision", func() {
@@ -43,9 +49,40 @@ var _ = Describe("Session Ticket", func() {
 		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read RTT"))
 	})
 
+	It("refuses to unmarshal if the ID cannot be read", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, sessionTicketRevision)
+		quicvarint.Write(b, 1337)
+		b.Write([]byte{1, 2, 3})
+		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read ID"))
+	})
+
+	It("refuses to unmarshal if the issue time cannot be read", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, sessionTicketRevision)
+		quicvarint.Write(b, 1337)
+		b.Write(make([]byte, sessionTicketIDLen))
+		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read issue time"))
+	})
+
+	It("refuses to unmarshal if the application data cannot be read", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, sessionTicketRevision)
+		quicvarint.Write(b, 1337)
+		b.Write(make([]byte, sessionTicketIDLen))
+		quicvarint.Write(b, 42)
+		quicvarint.Write(b, 10)
+		b.Write([]byte("foobar"))
+		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read application data"))
+	})
+
 	It("refuses to unmarshal if unmarshaling the transport parameters fails", func() {
 		b := &bytes.Buffer{}
 		quicvarint.Write(b, sessionTicketRevision)
+		quicvarint.Write(b, 1337)
+		b.Write(make([]byte, sessionTicketIDLen))
+		quicvarint.Write(b, 42)
+		quicvarint.Write(b, 0)
 		b.Write([]byte("foobar"))
 		err := (&sessionTicket{}).Unmarshal(b.Bytes())
 		Expect(err).To(HaveOccurred())
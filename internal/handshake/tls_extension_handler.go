@@ -11,8 +11,9 @@ const (
 )
 
 type extensionHandler struct {
-	ourParams  []byte
-	paramsChan chan []byte
+	ourParams         []byte
+	paramsChan        chan []byte
+	paramsHandledChan chan struct{}
 
 	extensionType uint16
 
@@ -24,14 +25,15 @@ var _ tlsExtensionHandler = &extensionHandler{}
 // newExtensionHandler creates a new extension handler
 func newExtensionHandler(params []byte, pers protocol.Perspective, v protocol.VersionNumber) tlsExtensionHandler {
 	et := uint16(quicTLSExtensionType)
-	if v != protocol.Version1 {
+	if v == protocol.VersionDraft29 {
 		et = quicTLSExtensionTypeOldDrafts
 	}
 	return &extensionHandler{
-		ourParams:     params,
-		paramsChan:    make(chan []byte),
-		perspective:   pers,
-		extensionType: et,
+		ourParams:         params,
+		paramsChan:        make(chan []byte),
+		paramsHandledChan: make(chan struct{}, 1),
+		perspective:       pers,
+		extensionType:     et,
 	}
 }
 
@@ -61,8 +63,19 @@ func (h *extensionHandler) ReceivedExtensions(msgType uint8, exts []qtls.Extensi
 	}
 
 	h.paramsChan <- data
+	// Block the handshake until the transport parameters have been handled.
+	// Handling the transport parameters can change the QUIC version and our own transport parameters.
+	<-h.paramsHandledChan
 }
 
 func (h *extensionHandler) TransportParameters() <-chan []byte {
 	return h.paramsChan
 }
+
+func (h *extensionHandler) TransportParametersHandled() {
+	h.paramsHandledChan <- struct{}{}
+}
+
+func (h *extensionHandler) SetTransportParameters(params []byte) {
+	h.ourParams = params
+}
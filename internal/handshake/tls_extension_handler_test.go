@@ -35,7 +35,7 @@ var _ = Describe("TLS Extension Handler, for the server", func() {
 	})
 
 	Context("for the server", func() {
-		for _, ver := range []protocol.VersionNumber{protocol.VersionDraft29, protocol.Version1} {
+		for _, ver := range []protocol.VersionNumber{protocol.VersionDraft29, protocol.Version1, protocol.Version2} {
 			v := ver
 
 			Context(fmt.Sprintf("sending, for version %s", v), func() {
@@ -62,6 +62,13 @@ var _ = Describe("TLS Extension Handler, for the server", func() {
 					Expect(exts[0].Type).To(BeEquivalentTo(extensionType))
 					Expect(exts[0].Data).To(Equal([]byte("foobar")))
 				})
+
+				It("uses updated TransportParameters", func() {
+					handlerServer.SetTransportParameters([]byte("foobaz"))
+					exts := handlerServer.GetExtensions(uint8(typeEncryptedExtensions))
+					Expect(exts).To(HaveLen(1))
+					Expect(exts[0].Data).To(Equal([]byte("foobaz")))
+				})
 			})
 		}
 
@@ -84,6 +91,20 @@ var _ = Describe("TLS Extension Handler, for the server", func() {
 				Expect(data).To(Equal([]byte("raboof")))
 			})
 
+			It("blocks until the transport parameters have been handled", func() {
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					handlerServer.ReceivedExtensions(uint8(typeClientHello), chExts)
+					close(done)
+				}()
+
+				Eventually(handlerServer.TransportParameters()).Should(Receive())
+				Consistently(done).ShouldNot(BeClosed())
+				handlerServer.TransportParametersHandled()
+				Eventually(done).Should(BeClosed())
+			})
+
 			It("sends nil on the channel if the extension is missing", func() {
 				go func() {
 					defer GinkgoRecover()
@@ -122,7 +143,7 @@ var _ = Describe("TLS Extension Handler, for the server", func() {
 	})
 
 	Context("for the client", func() {
-		for _, ver := range []protocol.VersionNumber{protocol.VersionDraft29, protocol.Version1} {
+		for _, ver := range []protocol.VersionNumber{protocol.VersionDraft29, protocol.Version1, protocol.Version2} {
 			v := ver
 
 			Context(fmt.Sprintf("sending, for version %s", v), func() {
@@ -3,6 +3,7 @@ package handshake
 import (
 	"bytes"
 	"encoding/asn1"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -45,9 +46,11 @@ type TokenGenerator struct {
 	tokenProtector tokenProtector
 }
 
-// NewTokenGenerator initializes a new TookenGenerator
-func NewTokenGenerator(rand io.Reader) (*TokenGenerator, error) {
-	tokenProtector, err := newTokenProtector(rand)
+// NewTokenGenerator initializes a new TookenGenerator.
+// The first key is used to protect new tokens, the other keys are only used to decode tokens.
+// If no keys are given, a random key is used.
+func NewTokenGenerator(rand io.Reader, keys []TokenProtectorKey) (*TokenGenerator, error) {
+	tokenProtector, err := newTokenProtector(rand, keys)
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +59,15 @@ func NewTokenGenerator(rand io.Reader) (*TokenGenerator, error) {
 	}, nil
 }
 
+// SetKeys replaces the keys used to protect and decode tokens.
+func (g *TokenGenerator) SetKeys(keys []TokenProtectorKey) error {
+	if len(keys) == 0 {
+		return errors.New("no token keys")
+	}
+	g.tokenProtector.SetKeys(keys)
+	return nil
+}
+
 // NewRetryToken generates a new token for a Retry for a given source address
 func (g *TokenGenerator) NewRetryToken(
 	raddr net.Addr,
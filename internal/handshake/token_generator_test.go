@@ -17,7 +17,7 @@ var _ = Describe("Token Generator", func() {
 
 	BeforeEach(func() {
 		var err error
-		tokenGen, err = NewTokenGenerator(rand.Reader)
+		tokenGen, err = NewTokenGenerator(rand.Reader, nil)
 		Expect(err).ToNot(HaveOccurred())
 	})
 
@@ -47,6 +47,27 @@ var _ = Describe("Token Generator", func() {
 		Expect(token.RetrySrcConnectionID.Len()).To(BeZero())
 	})
 
+	It("accepts tokens issued by a token generator using the same key", func() {
+		key := TokenProtectorKey{1, 2, 3, 4}
+		tokenGen1, err := NewTokenGenerator(rand.Reader, []TokenProtectorKey{key})
+		Expect(err).ToNot(HaveOccurred())
+		tokenGen2, err := NewTokenGenerator(rand.Reader, []TokenProtectorKey{{5, 6, 7, 8}})
+		Expect(err).ToNot(HaveOccurred())
+		addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+		tokenEnc, err := tokenGen1.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = tokenGen2.DecodeToken(tokenEnc)
+		Expect(err).To(HaveOccurred())
+		Expect(tokenGen2.SetKeys([]TokenProtectorKey{{5, 6, 7, 8}, key})).To(Succeed())
+		token, err := tokenGen2.DecodeToken(tokenEnc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.ValidateRemoteAddr(addr)).To(BeTrue())
+	})
+
+	It("refuses to set an empty list of keys", func() {
+		Expect(tokenGen.SetKeys(nil)).To(MatchError("no token keys"))
+	})
+
 	It("saves the connection ID", func() {
 		connID1 := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef})
 		connID2 := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
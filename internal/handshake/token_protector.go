@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"sync"
 
 	"golang.org/x/crypto/hkdf"
 )
@@ -16,6 +17,8 @@ type tokenProtector interface {
 	NewToken([]byte) ([]byte, error)
 	// DecodeToken decodes a token
 	DecodeToken([]byte) ([]byte, error)
+	// SetKeys replaces the keys
+	SetKeys([]TokenProtectorKey)
 }
 
 const (
@@ -23,31 +26,53 @@ const (
 	tokenNonceSize  = 32
 )
 
+// A TokenProtectorKey is a key used to protect tokens.
+type TokenProtectorKey [tokenSecretSize]byte
+
 // tokenProtector is used to create and verify a token
 type tokenProtectorImpl struct {
-	rand   io.Reader
-	secret []byte
+	rand io.Reader
+
+	mutex sync.RWMutex
+	// The first key is used for new tokens.
+	// All keys are tried when decoding a token.
+	keys []TokenProtectorKey
 }
 
-// newTokenProtector creates a source for source address tokens
-func newTokenProtector(rand io.Reader) (tokenProtector, error) {
-	secret := make([]byte, tokenSecretSize)
-	if _, err := rand.Read(secret); err != nil {
-		return nil, err
+// newTokenProtector creates a source for source address tokens.
+// If no keys are given, a random key is generated.
+func newTokenProtector(rand io.Reader, keys []TokenProtectorKey) (tokenProtector, error) {
+	if len(keys) == 0 {
+		var key TokenProtectorKey
+		if _, err := rand.Read(key[:]); err != nil {
+			return nil, err
+		}
+		keys = []TokenProtectorKey{key}
 	}
 	return &tokenProtectorImpl{
-		rand:   rand,
-		secret: secret,
+		rand: rand,
+		keys: keys,
 	}, nil
 }
 
+// SetKeys replaces the keys.
+// Tokens protected with a key that is not contained in keys can't be decoded any more.
+func (s *tokenProtectorImpl) SetKeys(keys []TokenProtectorKey) {
+	s.mutex.Lock()
+	s.keys = keys
+	s.mutex.Unlock()
+}
+
 // NewToken encodes data into a new token.
 func (s *tokenProtectorImpl) NewToken(data []byte) ([]byte, error) {
 	nonce := make([]byte, tokenNonceSize)
 	if _, err := s.rand.Read(nonce); err != nil {
 		return nil, err
 	}
-	aead, aeadNonce, err := s.createAEAD(nonce)
+	s.mutex.RLock()
+	key := s.keys[0]
+	s.mutex.RUnlock()
+	aead, aeadNonce, err := s.createAEAD(key, nonce)
 	if err != nil {
 		return nil, err
 	}
@@ -60,24 +85,37 @@ func (s *tokenProtectorImpl) DecodeToken(p []byte) ([]byte, error) {
 		return nil, fmt.Errorf("token too short: %d", len(p))
 	}
 	nonce := p[:tokenNonceSize]
-	aead, aeadNonce, err := s.createAEAD(nonce)
-	if err != nil {
-		return nil, err
+	s.mutex.RLock()
+	keys := s.keys
+	s.mutex.RUnlock()
+	var err error
+	for _, key := range keys {
+		var aead cipher.AEAD
+		var aeadNonce []byte
+		aead, aeadNonce, err = s.createAEAD(key, nonce)
+		if err != nil {
+			return nil, err
+		}
+		var data []byte
+		data, err = aead.Open(nil, aeadNonce, p[tokenNonceSize:], nil)
+		if err == nil {
+			return data, nil
+		}
 	}
-	return aead.Open(nil, aeadNonce, p[tokenNonceSize:], nil)
+	return nil, err
 }
 
-func (s *tokenProtectorImpl) createAEAD(nonce []byte) (cipher.AEAD, []byte, error) {
-	h := hkdf.New(sha256.New, s.secret, nonce, []byte("quic-go token source"))
-	key := make([]byte, 32) // use a 32 byte key, in order to select AES-256
-	if _, err := io.ReadFull(h, key); err != nil {
+func (s *tokenProtectorImpl) createAEAD(key TokenProtectorKey, nonce []byte) (cipher.AEAD, []byte, error) {
+	h := hkdf.New(sha256.New, key[:], nonce, []byte("quic-go token source"))
+	aeadKey := make([]byte, 32) // use a 32 byte key, in order to select AES-256
+	if _, err := io.ReadFull(h, aeadKey); err != nil {
 		return nil, nil, err
 	}
 	aeadNonce := make([]byte, 12)
 	if _, err := io.ReadFull(h, aeadNonce); err != nil {
 		return nil, nil, err
 	}
-	c, err := aes.NewCipher(key)
+	c, err := aes.NewCipher(aeadKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -21,21 +21,21 @@ var _ = Describe("Token Protector", func() {
 
 	BeforeEach(func() {
 		var err error
-		tp, err = newTokenProtector(rand.Reader)
+		tp, err = newTokenProtector(rand.Reader, nil)
 		Expect(err).ToNot(HaveOccurred())
 	})
 
 	It("uses the random source", func() {
-		tp1, err := newTokenProtector(&zeroReader{})
+		tp1, err := newTokenProtector(&zeroReader{}, nil)
 		Expect(err).ToNot(HaveOccurred())
-		tp2, err := newTokenProtector(&zeroReader{})
+		tp2, err := newTokenProtector(&zeroReader{}, nil)
 		Expect(err).ToNot(HaveOccurred())
 		t1, err := tp1.NewToken([]byte("foo"))
 		Expect(err).ToNot(HaveOccurred())
 		t2, err := tp2.NewToken([]byte("foo"))
 		Expect(err).ToNot(HaveOccurred())
 		Expect(t1).To(Equal(t2))
-		tp3, err := newTokenProtector(rand.Reader)
+		tp3, err := newTokenProtector(rand.Reader, nil)
 		Expect(err).ToNot(HaveOccurred())
 		t3, err := tp3.NewToken([]byte("foo"))
 		Expect(err).ToNot(HaveOccurred())
@@ -60,6 +60,44 @@ var _ = Describe("Token Protector", func() {
 		Expect(err.Error()).To(ContainSubstring("message authentication failed"))
 	})
 
+	It("uses the first key for new tokens, and all keys for decoding", func() {
+		key1 := TokenProtectorKey{1, 2, 3}
+		key2 := TokenProtectorKey{4, 5, 6}
+		tp1, err := newTokenProtector(rand.Reader, []TokenProtectorKey{key1})
+		Expect(err).ToNot(HaveOccurred())
+		tp2, err := newTokenProtector(rand.Reader, []TokenProtectorKey{key2, key1})
+		Expect(err).ToNot(HaveOccurred())
+		// tokens issued using the previous key can be decoded
+		token, err := tp1.NewToken([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		decoded, err := tp2.DecodeToken(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(Equal([]byte("foobar")))
+		// new tokens are issued using the current key
+		token, err = tp2.NewToken([]byte("raboof"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = tp1.DecodeToken(token)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("replaces the keys", func() {
+		key1 := TokenProtectorKey{1, 2, 3}
+		key2 := TokenProtectorKey{4, 5, 6}
+		tp, err := newTokenProtector(rand.Reader, []TokenProtectorKey{key1})
+		Expect(err).ToNot(HaveOccurred())
+		token1, err := tp.NewToken([]byte("foo"))
+		Expect(err).ToNot(HaveOccurred())
+		tp.SetKeys([]TokenProtectorKey{key2, key1})
+		token2, err := tp.NewToken([]byte("bar"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tp.DecodeToken(token1)).To(Equal([]byte("foo")))
+		Expect(tp.DecodeToken(token2)).To(Equal([]byte("bar")))
+		tp.SetKeys([]TokenProtectorKey{key2})
+		_, err = tp.DecodeToken(token1)
+		Expect(err).To(HaveOccurred())
+		Expect(tp.DecodeToken(token2)).To(Equal([]byte("bar")))
+	})
+
 	It("errors when decoding too short tokens", func() {
 		_, err := tp.DecodeToken([]byte("foobar"))
 		Expect(err).To(MatchError("token too short: 6"))
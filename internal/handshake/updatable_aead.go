@@ -61,6 +61,14 @@ type updatableAEAD struct {
 
 	// use a single slice to avoid allocations
 	nonceBuf []byte
+
+	// The traffic secrets of the current key phase (and the previous key phase, for receiving),
+	// and the AEADs derived from them for additional paths of a multipath connection.
+	rcvTrafficSecret     []byte
+	prevRcvTrafficSecret []byte
+	sendTrafficSecret    []byte
+	pathRcvAEADs         map[uint64]cipher.AEAD
+	pathSendAEADs        map[uint64]cipher.AEAD
 }
 
 var (
@@ -99,6 +107,11 @@ func (a *updatableAEAD) rollKeys() {
 	a.prevRcvAEAD = a.rcvAEAD
 	a.rcvAEAD = a.nextRcvAEAD
 	a.sendAEAD = a.nextSendAEAD
+	a.prevRcvTrafficSecret = a.rcvTrafficSecret
+	a.rcvTrafficSecret = a.nextRcvTrafficSecret
+	a.sendTrafficSecret = a.nextSendTrafficSecret
+	a.pathRcvAEADs = nil
+	a.pathSendAEADs = nil
 
 	a.nextRcvTrafficSecret = a.getNextTrafficSecret(a.suite.Hash, a.nextRcvTrafficSecret)
 	a.nextSendTrafficSecret = a.getNextTrafficSecret(a.suite.Hash, a.nextSendTrafficSecret)
@@ -120,6 +133,7 @@ func (a *updatableAEAD) getNextTrafficSecret(hash crypto.Hash, ts []byte) []byte
 // For the server, this function is called after SetWriteKey.
 func (a *updatableAEAD) SetReadKey(suite *qtls.CipherSuiteTLS13, trafficSecret []byte) {
 	a.rcvAEAD = createAEAD(suite, trafficSecret, a.version)
+	a.rcvTrafficSecret = trafficSecret
 	a.headerDecrypter = newHeaderProtector(suite, trafficSecret, false, a.version)
 	if a.suite == nil {
 		a.setAEADParameters(a.rcvAEAD, suite)
@@ -133,6 +147,7 @@ func (a *updatableAEAD) SetReadKey(suite *qtls.CipherSuiteTLS13, trafficSecret [
 // For the server, this function is called before SetWriteKey.
 func (a *updatableAEAD) SetWriteKey(suite *qtls.CipherSuiteTLS13, trafficSecret []byte) {
 	a.sendAEAD = createAEAD(suite, trafficSecret, a.version)
+	a.sendTrafficSecret = trafficSecret
 	a.headerEncrypter = newHeaderProtector(suite, trafficSecret, false, a.version)
 	if a.suite == nil {
 		a.setAEADParameters(a.sendAEAD, suite)
@@ -252,6 +267,54 @@ func (a *updatableAEAD) Seal(dst, src []byte, pn protocol.PacketNumber, ad []byt
 	return a.sendAEAD.Seal(dst, a.nonceBuf, src, ad)
 }
 
+// OpenOnPath opens a packet received on an additional path of a multipath connection.
+// The path ID is the sequence number of the connection ID that the packet was sent to.
+// Key updates are only initiated and detected on the initial path.
+// On additional paths, packets are only opened with the current or the previous key phase.
+func (a *updatableAEAD) OpenOnPath(dst, src []byte, pathID uint64, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	var aead cipher.AEAD
+	if kp == a.keyPhase.Bit() {
+		aead = a.pathRcvAEADs[pathID]
+		if aead == nil {
+			aead = createPathAEAD(a.suite, a.rcvTrafficSecret, pathID, a.version)
+			if a.pathRcvAEADs == nil {
+				a.pathRcvAEADs = make(map[uint64]cipher.AEAD)
+			}
+			a.pathRcvAEADs[pathID] = aead
+		}
+	} else {
+		if a.prevRcvAEAD == nil {
+			return nil, ErrKeysDropped
+		}
+		aead = createPathAEAD(a.suite, a.prevRcvTrafficSecret, pathID, a.version)
+	}
+	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
+	dec, err := aead.Open(dst, a.nonceBuf, src, ad)
+	if err != nil {
+		a.invalidPacketCount++
+		if a.invalidPacketCount >= a.invalidPacketLimit {
+			return nil, &qerr.TransportError{ErrorCode: qerr.AEADLimitReached}
+		}
+		return nil, ErrDecryptionFailed
+	}
+	return dec, nil
+}
+
+// SealOnPath seals a packet sent on an additional path of a multipath connection.
+func (a *updatableAEAD) SealOnPath(dst, src []byte, pathID uint64, pn protocol.PacketNumber, ad []byte) []byte {
+	aead := a.pathSendAEADs[pathID]
+	if aead == nil {
+		aead = createPathAEAD(a.suite, a.sendTrafficSecret, pathID, a.version)
+		if a.pathSendAEADs == nil {
+			a.pathSendAEADs = make(map[uint64]cipher.AEAD)
+		}
+		a.pathSendAEADs[pathID] = aead
+	}
+	a.numSentWithCurrentKey++
+	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
+	return aead.Seal(dst, a.nonceBuf, src, ad)
+}
+
 func (a *updatableAEAD) SetLargestAcked(pn protocol.PacketNumber) error {
 	if a.firstSentWithCurrentKey != protocol.InvalidPacketNumber &&
 		pn >= a.firstSentWithCurrentKey && a.numRcvdWithCurrentKey == 0 {
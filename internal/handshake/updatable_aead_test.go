@@ -146,6 +146,26 @@ var _ = Describe("Updatable AEAD", func() {
 							Expect(client.DecodePacketNumber(0x38, protocol.PacketNumberLen1)).To(BeEquivalentTo(0x38))
 						})
 
+						It("encrypts and decrypts a message sent on an additional path", func() {
+							encrypted := server.SealOnPath(nil, msg, 3, 0x1337, ad)
+							opened, err := client.OpenOnPath(nil, encrypted, 3, 0x1337, protocol.KeyPhaseZero, ad)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(opened).To(Equal(msg))
+						})
+
+						It("uses the path ID for the nonce", func() {
+							encrypted := server.SealOnPath(nil, msg, 3, 0x1337, ad)
+							_, err := client.OpenOnPath(nil, encrypted, 4, 0x1337, protocol.KeyPhaseZero, ad)
+							Expect(err).To(MatchError(ErrDecryptionFailed))
+							_, err = client.Open(nil, encrypted, time.Now(), 0x1337, protocol.KeyPhaseZero, ad)
+							Expect(err).To(MatchError(ErrDecryptionFailed))
+							// packets on the initial path are not affected
+							encrypted = server.Seal(nil, msg, 0x1337, ad)
+							opened, err := client.Open(nil, encrypted, time.Now(), 0x1337, protocol.KeyPhaseZero, ad)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(opened).To(Equal(msg))
+						})
+
 						It("returns an AEAD_LIMIT_REACHED error when reaching the AEAD limit", func() {
 							client.invalidPacketLimit = 10
 							for i := 0; i < 9; i++ {
@@ -15,6 +15,11 @@ func ConvertFrame(frame wire.Frame) logging.Frame {
 		// We use a pool for ACK frames.
 		// Implementations of the tracer interface may hold on to frames, so we need to make a copy here.
 		return ConvertAckFrame(f)
+	case *wire.AckMPFrame:
+		return &logging.AckMPFrame{
+			PathIdentifier: f.PathIdentifier,
+			AckFrame:       ConvertAckFrame(f.AckFrame),
+		}
 	case *wire.CryptoFrame:
 		return &logging.CryptoFrame{
 			Offset: f.Offset,
@@ -41,6 +41,17 @@ var _ = Describe("CRYPTO frame", func() {
 		Expect(df.Length).To(Equal(logging.ByteCount(6)))
 	})
 
+	It("converts ACK_MP frames", func() {
+		ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 10}}}
+		f := ConvertFrame(&wire.AckMPFrame{PathIdentifier: 3, AckFrame: ack})
+		Expect(f).To(BeAssignableToTypeOf(&logging.AckMPFrame{}))
+		af := f.(*logging.AckMPFrame)
+		Expect(af.PathIdentifier).To(BeEquivalentTo(3))
+		Expect(af.AckRanges).To(Equal(ack.AckRanges))
+		// ACK frames are pooled, so the ACK frame needs to be copied
+		Expect(af.AckFrame).ToNot(BeIdenticalTo(ack))
+	})
+
 	It("converts other frames", func() {
 		f := ConvertFrame(&wire.MaxDataFrame{MaximumData: 1234})
 		Expect(f).To(BeAssignableToTypeOf(&logging.MaxDataFrame{}))
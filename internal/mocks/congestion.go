@@ -36,6 +36,20 @@ func (m *MockSendAlgorithmWithDebugInfos) EXPECT() *MockSendAlgorithmWithDebugIn
 	return m.recorder
 }
 
+// BandwidthEstimate mocks base method.
+func (m *MockSendAlgorithmWithDebugInfos) BandwidthEstimate() congestion.Bandwidth {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BandwidthEstimate")
+	ret0, _ := ret[0].(congestion.Bandwidth)
+	return ret0
+}
+
+// BandwidthEstimate indicates an expected call of BandwidthEstimate.
+func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) BandwidthEstimate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BandwidthEstimate", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).BandwidthEstimate))
+}
+
 // CanSend mocks base method.
 func (m *MockSendAlgorithmWithDebugInfos) CanSend(arg0 protocol.ByteCount) bool {
 	m.ctrl.T.Helper()
@@ -78,18 +92,6 @@ func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) HasPacingBudget() *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPacingBudget", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).HasPacingBudget))
 }
 
-// BandwidthEstimate mocks base method.
-func (m *MockSendAlgorithmWithDebugInfos) BandwidthEstimate() congestion.Bandwidth {
-	ret := m.ctrl.Call(m, "BandwidthEstimate")
-	ret0, _ := ret[0].(congestion.Bandwidth)
-	return ret0
-}
-
-// BandwidthEstimate indicates an expected call of BandwidthEstimate.
-func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) BandwidthEstimate() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BandwidthEstimate", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).BandwidthEstimate))
-}
-
 // InRecovery mocks base method.
 func (m *MockSendAlgorithmWithDebugInfos) InRecovery() bool {
 	m.ctrl.T.Helper()
@@ -130,6 +132,18 @@ func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) MaybeExitSlowStart() *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaybeExitSlowStart", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).MaybeExitSlowStart))
 }
 
+// OnECNCongestionEvent mocks base method.
+func (m *MockSendAlgorithmWithDebugInfos) OnECNCongestionEvent(arg0 protocol.PacketNumber, arg1 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnECNCongestionEvent", arg0, arg1)
+}
+
+// OnECNCongestionEvent indicates an expected call of OnECNCongestionEvent.
+func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnECNCongestionEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnECNCongestionEvent", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnECNCongestionEvent), arg0, arg1)
+}
+
 // OnPacketAcked mocks base method.
 func (m *MockSendAlgorithmWithDebugInfos) OnPacketAcked(arg0 protocol.PacketNumber, arg1, arg2 protocol.ByteCount, arg3 time.Time) {
 	m.ctrl.T.Helper()
@@ -243,6 +243,18 @@ func (mr *MockConnectionTracerMockRecorder) ReceivedVersionNegotiationPacket(arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedVersionNegotiationPacket", reflect.TypeOf((*MockConnectionTracer)(nil).ReceivedVersionNegotiationPacket), arg0, arg1, arg2)
 }
 
+// Rejected0RTT mocks base method.
+func (m *MockConnectionTracer) Rejected0RTT(arg0 logging.ZeroRTTRejectionReason) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Rejected0RTT", arg0)
+}
+
+// Rejected0RTT indicates an expected call of Rejected0RTT.
+func (mr *MockConnectionTracerMockRecorder) Rejected0RTT(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rejected0RTT", reflect.TypeOf((*MockConnectionTracer)(nil).Rejected0RTT), arg0)
+}
+
 // RestoredTransportParameters mocks base method.
 func (m *MockConnectionTracer) RestoredTransportParameters(arg0 *wire.TransportParameters) {
 	m.ctrl.T.Helper()
@@ -351,6 +363,18 @@ func (mr *MockConnectionTracerMockRecorder) UpdatedMetrics(arg0, arg1, arg2, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatedMetrics", reflect.TypeOf((*MockConnectionTracer)(nil).UpdatedMetrics), arg0, arg1, arg2, arg3)
 }
 
+// UpdatedMigrationState mocks base method.
+func (m *MockConnectionTracer) UpdatedMigrationState(arg0 logging.MigrationState, arg1, arg2 net.Addr) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatedMigrationState", arg0, arg1, arg2)
+}
+
+// UpdatedMigrationState indicates an expected call of UpdatedMigrationState.
+func (mr *MockConnectionTracerMockRecorder) UpdatedMigrationState(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatedMigrationState", reflect.TypeOf((*MockConnectionTracer)(nil).UpdatedMigrationState), arg0, arg1, arg2)
+}
+
 // UpdatedPTOCount mocks base method.
 func (m *MockConnectionTracer) UpdatedPTOCount(arg0 uint32) {
 	m.ctrl.T.Helper()
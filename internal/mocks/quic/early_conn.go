@@ -68,8 +68,24 @@ func (mr *MockEarlyConnectionMockRecorder) AcceptUniStream(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptUniStream", reflect.TypeOf((*MockEarlyConnection)(nil).AcceptUniStream), arg0)
 }
 
+// AddPath mocks base method.
+func (m *MockEarlyConnection) AddPath(arg0 net.PacketConn) (quic.Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPath", arg0)
+	ret0, _ := ret[0].(quic.Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPath indicates an expected call of AddPath.
+func (mr *MockEarlyConnectionMockRecorder) AddPath(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockEarlyConnection)(nil).AddPath), arg0)
+}
+
 // BandwidthEstimate mocks base method.
 func (m *MockEarlyConnection) BandwidthEstimate() congestion.Bandwidth {
+	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "BandwidthEstimate")
 	ret0, _ := ret[0].(congestion.Bandwidth)
 	return ret0
@@ -77,6 +93,7 @@ func (m *MockEarlyConnection) BandwidthEstimate() congestion.Bandwidth {
 
 // BandwidthEstimate indicates an expected call of BandwidthEstimate.
 func (mr *MockEarlyConnectionMockRecorder) BandwidthEstimate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BandwidthEstimate", reflect.TypeOf((*MockEarlyConnection)(nil).BandwidthEstimate))
 }
 
@@ -150,6 +167,34 @@ func (mr *MockEarlyConnectionMockRecorder) LocalAddr() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalAddr", reflect.TypeOf((*MockEarlyConnection)(nil).LocalAddr))
 }
 
+// MaxDatagramSize mocks base method.
+func (m *MockEarlyConnection) MaxDatagramSize() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxDatagramSize")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// MaxDatagramSize indicates an expected call of MaxDatagramSize.
+func (mr *MockEarlyConnectionMockRecorder) MaxDatagramSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxDatagramSize", reflect.TypeOf((*MockEarlyConnection)(nil).MaxDatagramSize))
+}
+
+// MigrateTo mocks base method.
+func (m *MockEarlyConnection) MigrateTo(arg0 net.PacketConn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateTo", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateTo indicates an expected call of MigrateTo.
+func (mr *MockEarlyConnectionMockRecorder) MigrateTo(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateTo", reflect.TypeOf((*MockEarlyConnection)(nil).MigrateTo), arg0)
+}
+
 // NextConnection mocks base method.
 func (m *MockEarlyConnection) NextConnection() quic.Connection {
 	m.ctrl.T.Helper()
@@ -239,6 +284,21 @@ func (mr *MockEarlyConnectionMockRecorder) ReceiveMessage() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveMessage", reflect.TypeOf((*MockEarlyConnection)(nil).ReceiveMessage))
 }
 
+// ReceiveMessageContext mocks base method.
+func (m *MockEarlyConnection) ReceiveMessageContext(arg0 context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveMessageContext", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveMessageContext indicates an expected call of ReceiveMessageContext.
+func (mr *MockEarlyConnectionMockRecorder) ReceiveMessageContext(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveMessageContext", reflect.TypeOf((*MockEarlyConnection)(nil).ReceiveMessageContext), arg0)
+}
+
 // RemoteAddr mocks base method.
 func (m *MockEarlyConnection) RemoteAddr() net.Addr {
 	m.ctrl.T.Helper()
@@ -266,3 +326,31 @@ func (mr *MockEarlyConnectionMockRecorder) SendMessage(arg0 interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockEarlyConnection)(nil).SendMessage), arg0)
 }
+
+// SendMessageWithCallbacks mocks base method.
+func (m *MockEarlyConnection) SendMessageWithCallbacks(arg0 []byte, arg1, arg2 func()) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessageWithCallbacks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessageWithCallbacks indicates an expected call of SendMessageWithCallbacks.
+func (mr *MockEarlyConnectionMockRecorder) SendMessageWithCallbacks(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageWithCallbacks", reflect.TypeOf((*MockEarlyConnection)(nil).SendMessageWithCallbacks), arg0, arg1, arg2)
+}
+
+// Stats mocks base method.
+func (m *MockEarlyConnection) Stats() quic.ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(quic.ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockEarlyConnectionMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockEarlyConnection)(nil).Stats))
+}
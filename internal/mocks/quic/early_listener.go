@@ -78,3 +78,45 @@ func (mr *MockEarlyListenerMockRecorder) Close() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockEarlyListener)(nil).Close))
 }
+
+// SetStatelessResetKeys mocks base method.
+func (m *MockEarlyListener) SetStatelessResetKeys(arg0 []quic.StatelessResetKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatelessResetKeys", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStatelessResetKeys indicates an expected call of SetStatelessResetKeys.
+func (mr *MockEarlyListenerMockRecorder) SetStatelessResetKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatelessResetKeys", reflect.TypeOf((*MockEarlyListener)(nil).SetStatelessResetKeys), arg0)
+}
+
+// SetTokenKeys mocks base method.
+func (m *MockEarlyListener) SetTokenKeys(arg0 []quic.TokenKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTokenKeys", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTokenKeys indicates an expected call of SetTokenKeys.
+func (mr *MockEarlyListenerMockRecorder) SetTokenKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTokenKeys", reflect.TypeOf((*MockEarlyListener)(nil).SetTokenKeys), arg0)
+}
+
+// Shutdown mocks base method.
+func (m *MockEarlyListener) Shutdown(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockEarlyListenerMockRecorder) Shutdown(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockEarlyListener)(nil).Shutdown), arg0)
+}
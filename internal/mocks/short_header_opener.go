@@ -75,3 +75,18 @@ func (mr *MockShortHeaderOpenerMockRecorder) Open(arg0, arg1, arg2, arg3, arg4,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockShortHeaderOpener)(nil).Open), arg0, arg1, arg2, arg3, arg4, arg5)
 }
+
+// OpenOnPath mocks base method.
+func (m *MockShortHeaderOpener) OpenOnPath(arg0, arg1 []byte, arg2 uint64, arg3 protocol.PacketNumber, arg4 protocol.KeyPhaseBit, arg5 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenOnPath", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenOnPath indicates an expected call of OpenOnPath.
+func (mr *MockShortHeaderOpenerMockRecorder) OpenOnPath(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenOnPath", reflect.TypeOf((*MockShortHeaderOpener)(nil).OpenOnPath), arg0, arg1, arg2, arg3, arg4, arg5)
+}
@@ -87,3 +87,17 @@ func (mr *MockShortHeaderSealerMockRecorder) Seal(arg0, arg1, arg2, arg3 interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seal", reflect.TypeOf((*MockShortHeaderSealer)(nil).Seal), arg0, arg1, arg2, arg3)
 }
+
+// SealOnPath mocks base method.
+func (m *MockShortHeaderSealer) SealOnPath(arg0, arg1 []byte, arg2 uint64, arg3 protocol.PacketNumber, arg4 []byte) []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SealOnPath", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// SealOnPath indicates an expected call of SealOnPath.
+func (mr *MockShortHeaderSealerMockRecorder) SealOnPath(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SealOnPath", reflect.TypeOf((*MockShortHeaderSealer)(nil).SealOnPath), arg0, arg1, arg2, arg3, arg4)
+}
@@ -177,6 +177,10 @@ const MaxAckDelay = 25 * time.Millisecond
 // This is the value that should be advertised to the peer.
 const MaxAckDelayInclGranularity = MaxAckDelay + TimerGranularity
 
+// MinAckDelay is the min_ack_delay advertised to the peer, if the ACK frequency extension is enabled.
+// We don't delay ACKs by less than the timer granularity.
+const MinAckDelay = TimerGranularity
+
 // KeyUpdateInterval is the maximum number of packets we send or receive before initiating a key update.
 const KeyUpdateInterval = 100 * 1000
 
@@ -191,3 +195,15 @@ const Max0RTTQueues = 32
 // To avoid blocking, this value has to be smaller than MaxConnUnprocessedPackets.
 // To avoid packets being dropped as undecryptable by the connection, this value has to be smaller than MaxUndecryptablePackets.
 const Max0RTTQueueLen = 31
+
+// MinPathValidationPTO is the minimum PTO used when validating a new path.
+// The RTT of the new path is not known yet, so the PTO is derived from the initial RTT of 333ms,
+// see section 8.2.4 of RFC 9000.
+const MinPathValidationPTO = time.Second
+
+// MaxPathChallenges is the maximum number of PATH_CHALLENGE frames sent when validating a new path.
+const MaxPathChallenges = 3
+
+// AmplificationFactor is the anti-amplification limit.
+// Before validating the peer's address, an endpoint won't send more than 3x bytes than it received from that address.
+const AmplificationFactor = 3
@@ -61,6 +61,10 @@ type StatelessResetToken [16]byte
 // Ethernet's max packet size is 1500 bytes,  1500 - 48 = 1452.
 const MaxPacketBufferSize ByteCount = 1452
 
+// MaxLargePacketBufferSize is the size of the buffers used when sending multiple packets
+// in a single sendmsg call, using Generic Segmentation Offload (GSO).
+const MaxLargePacketBufferSize = 20 * MaxPacketBufferSize
+
 // MinInitialPacketSize is the minimum size an Initial packet is required to have.
 const MinInitialPacketSize = 1200
 
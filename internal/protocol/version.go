@@ -94,6 +94,33 @@ func ChooseSupportedVersion(ours, theirs []VersionNumber) (VersionNumber, bool)
 	return 0, false
 }
 
+// IsCompatibleVersion says if a connection that was started using the original version
+// can be switched to the negotiated version during the handshake,
+// as defined for compatible version negotiation (RFC 9368).
+// QUIC v1 and QUIC v2 are compatible with each other (RFC 9369).
+func IsCompatibleVersion(original, negotiated VersionNumber) bool {
+	if original == negotiated {
+		return true
+	}
+	return (original == Version1 || original == Version2) && (negotiated == Version1 || negotiated == Version2)
+}
+
+// ChooseCompatibleVersion chooses the version used for a connection that was started using the original version.
+// ours is a slice of versions that we support, sorted by our preference (descending).
+// theirs is a slice of versions offered by the peer. The order does not matter.
+// If no better version is found, the original version is returned.
+func ChooseCompatibleVersion(original VersionNumber, ours, theirs []VersionNumber) VersionNumber {
+	for _, ourVer := range ours {
+		if !IsCompatibleVersion(original, ourVer) {
+			continue
+		}
+		if ourVer == original || IsSupportedVersion(theirs, ourVer) {
+			return ourVer
+		}
+	}
+	return original
+}
+
 // generateReservedVersion generates a reserved version number (v & 0x0f0f0f0f == 0x0a0a0a0a)
 func generateReservedVersion() VersionNumber {
 	b := make([]byte, 4)
@@ -76,6 +76,38 @@ var _ = Describe("Version", func() {
 		})
 	})
 
+	Context("compatible versions", func() {
+		It("says which versions are compatible", func() {
+			Expect(IsCompatibleVersion(Version1, Version1)).To(BeTrue())
+			Expect(IsCompatibleVersion(Version1, Version2)).To(BeTrue())
+			Expect(IsCompatibleVersion(Version2, Version1)).To(BeTrue())
+			Expect(IsCompatibleVersion(VersionDraft29, VersionDraft29)).To(BeTrue())
+			Expect(IsCompatibleVersion(Version1, VersionDraft29)).To(BeFalse())
+			Expect(IsCompatibleVersion(VersionDraft29, Version2)).To(BeFalse())
+		})
+
+		It("upgrades to a preferred compatible version", func() {
+			ours := []VersionNumber{Version2, Version1}
+			Expect(ChooseCompatibleVersion(Version1, ours, []VersionNumber{Version1, Version2})).To(Equal(Version2))
+		})
+
+		It("keeps the original version if it is preferred", func() {
+			ours := []VersionNumber{Version1, Version2}
+			Expect(ChooseCompatibleVersion(Version1, ours, []VersionNumber{Version1, Version2})).To(Equal(Version1))
+		})
+
+		It("keeps the original version if the peer doesn't support the preferred version", func() {
+			ours := []VersionNumber{Version2, Version1}
+			Expect(ChooseCompatibleVersion(Version1, ours, []VersionNumber{Version1})).To(Equal(Version1))
+			Expect(ChooseCompatibleVersion(Version1, ours, nil)).To(Equal(Version1))
+		})
+
+		It("doesn't switch to incompatible versions", func() {
+			ours := []VersionNumber{VersionDraft29, Version1}
+			Expect(ChooseCompatibleVersion(Version1, ours, []VersionNumber{Version1, VersionDraft29})).To(Equal(Version1))
+		})
+	})
+
 	Context("reserved versions", func() {
 		It("adds a greased version if passed an empty slice", func() {
 			greased := GetGreasedVersions([]VersionNumber{})
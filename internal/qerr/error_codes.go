@@ -28,6 +28,11 @@ const (
 	KeyUpdateError            TransportErrorCode = 0xe
 	AEADLimitReached          TransportErrorCode = 0xf
 	NoViablePathError         TransportErrorCode = 0x10
+
+	// VersionNegotiationErrorErrorCode is the VERSION_NEGOTIATION_ERROR defined in RFC 9368.
+	// Like ApplicationErrorErrorCode, its name carries the ErrorCode suffix,
+	// since VersionNegotiationError is already the error returned when the peers don't share a version.
+	VersionNegotiationErrorErrorCode TransportErrorCode = 0x11
 )
 
 func (e TransportErrorCode) IsCryptoError() bool {
@@ -79,6 +84,8 @@ func (e TransportErrorCode) String() string {
 		return "AEAD_LIMIT_REACHED"
 	case NoViablePathError:
 		return "NO_VIABLE_PATH"
+	case VersionNegotiationErrorErrorCode:
+		return "VERSION_NEGOTIATION_ERROR"
 	default:
 		if e.IsCryptoError() {
 			return fmt.Sprintf("CRYPTO_ERROR %#x", uint16(e))
@@ -27,8 +27,11 @@ func parseAckFrame(r *bytes.Reader, ackDelayExponent uint8, _ protocol.VersionNu
 	if err != nil {
 		return nil, err
 	}
-	ecn := typeByte&0x1 > 0
+	return parseAckFrameBody(r, typeByte&0x1 > 0, ackDelayExponent)
+}
 
+// parseAckFrameBody reads the contents of an ACK frame, after the frame type
+func parseAckFrameBody(r *bytes.Reader, ecn bool, ackDelayExponent uint8) (*AckFrame, error) {
 	frame := GetAckFrame()
 
 	la, err := quicvarint.Read(r)
@@ -94,13 +97,23 @@ func parseAckFrame(r *bytes.Reader, ackDelayExponent uint8, _ protocol.VersionNu
 		return nil, errInvalidAckRanges
 	}
 
-	// parse (and skip) the ECN section
+	// parse the ECN section
 	if ecn {
-		for i := 0; i < 3; i++ {
-			if _, err := quicvarint.Read(r); err != nil {
-				return nil, err
-			}
+		ect0, err := quicvarint.Read(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.ECT0 = ect0
+		ect1, err := quicvarint.Read(r)
+		if err != nil {
+			return nil, err
 		}
+		frame.ECT1 = ect1
+		ecnce, err := quicvarint.Read(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.ECNCE = ecnce
 	}
 
 	return frame, nil
@@ -108,12 +121,16 @@ func parseAckFrame(r *bytes.Reader, ackDelayExponent uint8, _ protocol.VersionNu
 
 // Append appends an ACK frame.
 func (f *AckFrame) Append(b []byte, _ protocol.VersionNumber) ([]byte, error) {
-	hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
-	if hasECN {
+	if f.hasECN() {
 		b = append(b, 0b11)
 	} else {
 		b = append(b, 0b10)
 	}
+	return f.appendBody(b), nil
+}
+
+// appendBody appends the contents of an ACK frame, after the frame type
+func (f *AckFrame) appendBody(b []byte) []byte {
 	b = quicvarint.Append(b, uint64(f.LargestAcked()))
 	b = quicvarint.Append(b, encodeAckDelay(f.DelayTime))
 
@@ -131,20 +148,25 @@ func (f *AckFrame) Append(b []byte, _ protocol.VersionNumber) ([]byte, error) {
 		b = quicvarint.Append(b, len)
 	}
 
-	if hasECN {
+	if f.hasECN() {
 		b = quicvarint.Append(b, f.ECT0)
 		b = quicvarint.Append(b, f.ECT1)
 		b = quicvarint.Append(b, f.ECNCE)
 	}
-	return b, nil
+	return b
 }
 
 // Length of a written frame
 func (f *AckFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return 1 + f.bodyLength()
+}
+
+// bodyLength is the length of the contents of an ACK frame, after the frame type
+func (f *AckFrame) bodyLength() protocol.ByteCount {
 	largestAcked := f.AckRanges[0].Largest
 	numRanges := f.numEncodableAckRanges()
 
-	length := 1 + quicvarint.Len(uint64(largestAcked)) + quicvarint.Len(encodeAckDelay(f.DelayTime))
+	length := quicvarint.Len(uint64(largestAcked)) + quicvarint.Len(encodeAckDelay(f.DelayTime))
 
 	length += quicvarint.Len(uint64(numRanges - 1))
 	lowestInFirstRange := f.AckRanges[0].Smallest
@@ -155,7 +177,7 @@ func (f *AckFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
 		length += quicvarint.Len(gap)
 		length += quicvarint.Len(len)
 	}
-	if f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0 {
+	if f.hasECN() {
 		length += quicvarint.Len(f.ECT0)
 		length += quicvarint.Len(f.ECT1)
 		length += quicvarint.Len(f.ECNCE)
@@ -163,6 +185,10 @@ func (f *AckFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
 	return length
 }
 
+func (f *AckFrame) hasECN() bool {
+	return f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
+}
+
 // gets the number of ACK ranges that can be encoded
 // such that the resulting frame is smaller than the maximum ACK frame size
 func (f *AckFrame) numEncodableAckRanges() int {
@@ -0,0 +1,72 @@
+package wire
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const ackFrequencyFrameType = 0xaf
+
+// An AckFrequencyFrame is an ACK_FREQUENCY frame, as defined in draft-ietf-quic-ack-frequency.
+type AckFrequencyFrame struct {
+	SequenceNumber uint64
+	// AckElicitingThreshold is the number of ack-eliciting packets the peer may receive
+	// without immediately sending an ACK.
+	AckElicitingThreshold uint64
+	// RequestMaxAckDelay is the maximum amount of time the peer may delay sending an ACK.
+	RequestMaxAckDelay time.Duration
+	// ReorderingThreshold is the number of out-of-order packets that trigger an immediate ACK.
+	// A value of 0 disables immediate ACKs on reordering.
+	ReorderingThreshold uint64
+}
+
+func parseAckFrequencyFrame(r *bytes.Reader, _ protocol.VersionNumber) (*AckFrequencyFrame, error) {
+	if _, err := quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+
+	f := &AckFrequencyFrame{}
+	seq, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	f.SequenceNumber = seq
+	threshold, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	f.AckElicitingThreshold = threshold
+	delay, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	// prevent overflows when converting to a time.Duration
+	if delay > uint64(protocol.MaxMaxAckDelay/time.Microsecond) {
+		delay = uint64(protocol.MaxMaxAckDelay / time.Microsecond)
+	}
+	f.RequestMaxAckDelay = time.Duration(delay) * time.Microsecond
+	reordering, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	f.ReorderingThreshold = reordering
+	return f, nil
+}
+
+func (f *AckFrequencyFrame) Append(b []byte, _ protocol.VersionNumber) ([]byte, error) {
+	b = quicvarint.Append(b, ackFrequencyFrameType)
+	b = quicvarint.Append(b, f.SequenceNumber)
+	b = quicvarint.Append(b, f.AckElicitingThreshold)
+	b = quicvarint.Append(b, uint64(f.RequestMaxAckDelay/time.Microsecond))
+	b = quicvarint.Append(b, f.ReorderingThreshold)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *AckFrequencyFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return quicvarint.Len(ackFrequencyFrameType) + quicvarint.Len(f.SequenceNumber) + quicvarint.Len(f.AckElicitingThreshold) +
+		quicvarint.Len(uint64(f.RequestMaxAckDelay/time.Microsecond)) + quicvarint.Len(f.ReorderingThreshold)
+}
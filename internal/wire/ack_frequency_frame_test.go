@@ -0,0 +1,88 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK_FREQUENCY frame", func() {
+	Context("when parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(ackFrequencyFrameType)
+			data = append(data, encodeVarInt(0x1337)...) // sequence number
+			data = append(data, encodeVarInt(10)...)     // ack-eliciting threshold
+			data = append(data, encodeVarInt(5000)...)   // request max ack delay
+			data = append(data, encodeVarInt(3)...)      // reordering threshold
+			b := bytes.NewReader(data)
+			frame, err := parseAckFrequencyFrame(b, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.SequenceNumber).To(Equal(uint64(0x1337)))
+			Expect(frame.AckElicitingThreshold).To(Equal(uint64(10)))
+			Expect(frame.RequestMaxAckDelay).To(Equal(5 * time.Millisecond))
+			Expect(frame.ReorderingThreshold).To(Equal(uint64(3)))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("limits the request max ack delay", func() {
+			data := encodeVarInt(ackFrequencyFrameType)
+			data = append(data, encodeVarInt(1)...)       // sequence number
+			data = append(data, encodeVarInt(2)...)       // ack-eliciting threshold
+			data = append(data, encodeVarInt(1<<62-1)...) // request max ack delay
+			data = append(data, encodeVarInt(1)...)       // reordering threshold
+			frame, err := parseAckFrequencyFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.RequestMaxAckDelay).To(Equal(protocol.MaxMaxAckDelay))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(ackFrequencyFrameType)
+			data = append(data, encodeVarInt(0x1337)...) // sequence number
+			data = append(data, encodeVarInt(10)...)     // ack-eliciting threshold
+			data = append(data, encodeVarInt(5000)...)   // request max ack delay
+			data = append(data, encodeVarInt(3)...)      // reordering threshold
+			_, err := parseAckFrequencyFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseAckFrequencyFrame(bytes.NewReader(data[0:i]), protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			frame := &AckFrequencyFrame{
+				SequenceNumber:        0x1337,
+				AckElicitingThreshold: 10,
+				RequestMaxAckDelay:    5 * time.Millisecond,
+				ReorderingThreshold:   3,
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(ackFrequencyFrameType)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(10)...)
+			expected = append(expected, encodeVarInt(5000)...)
+			expected = append(expected, encodeVarInt(3)...)
+			Expect(b).To(Equal(expected))
+		})
+
+		It("has the correct length", func() {
+			frame := &AckFrequencyFrame{
+				SequenceNumber:        0xdecafbad,
+				AckElicitingThreshold: 0x42,
+				RequestMaxAckDelay:    time.Second,
+				ReorderingThreshold:   1,
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(HaveLen(int(frame.Length(protocol.Version1))))
+		})
+	})
+})
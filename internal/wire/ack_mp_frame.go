@@ -0,0 +1,54 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const (
+	ackMPFrameType    = 0x15228c00
+	ackMPECNFrameType = 0x15228c01
+)
+
+// An AckMPFrame is an ACK_MP frame, as defined in draft-ietf-quic-multipath.
+// It acknowledges packets received in the packet number space of a single path.
+type AckMPFrame struct {
+	// PathIdentifier is the sequence number of the destination connection ID
+	// used by the packets that are acknowledged.
+	PathIdentifier uint64
+	*AckFrame
+}
+
+func parseAckMPFrame(r *bytes.Reader, ackDelayExponent uint8, _ protocol.VersionNumber) (*AckMPFrame, error) {
+	typ, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	pathID, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	ack, err := parseAckFrameBody(r, typ&0x1 > 0, ackDelayExponent)
+	if err != nil {
+		return nil, err
+	}
+	return &AckMPFrame{PathIdentifier: pathID, AckFrame: ack}, nil
+}
+
+// Append appends an ACK_MP frame.
+func (f *AckMPFrame) Append(b []byte, _ protocol.VersionNumber) ([]byte, error) {
+	if f.hasECN() {
+		b = quicvarint.Append(b, ackMPECNFrameType)
+	} else {
+		b = quicvarint.Append(b, ackMPFrameType)
+	}
+	b = quicvarint.Append(b, f.PathIdentifier)
+	return f.appendBody(b), nil
+}
+
+// Length of a written frame
+func (f *AckMPFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return quicvarint.Len(ackMPFrameType) + quicvarint.Len(f.PathIdentifier) + f.bodyLength()
+}
@@ -0,0 +1,103 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK_MP frame", func() {
+	Context("when parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(ackMPFrameType)
+			data = append(data, encodeVarInt(3)...)   // path identifier
+			data = append(data, encodeVarInt(100)...) // largest acked
+			data = append(data, encodeVarInt(0)...)   // delay
+			data = append(data, encodeVarInt(0)...)   // num blocks
+			data = append(data, encodeVarInt(10)...)  // first ack block
+			b := bytes.NewReader(data)
+			frame, err := parseAckMPFrame(b, protocol.AckDelayExponent, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.PathIdentifier).To(Equal(uint64(3)))
+			Expect(frame.LargestAcked()).To(Equal(protocol.PacketNumber(100)))
+			Expect(frame.LowestAcked()).To(Equal(protocol.PacketNumber(90)))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("parses the ECN section", func() {
+			data := encodeVarInt(ackMPECNFrameType)
+			data = append(data, encodeVarInt(3)...)   // path identifier
+			data = append(data, encodeVarInt(100)...) // largest acked
+			data = append(data, encodeVarInt(0)...)   // delay
+			data = append(data, encodeVarInt(0)...)   // num blocks
+			data = append(data, encodeVarInt(10)...)  // first ack block
+			data = append(data, encodeVarInt(1)...)   // ECT(0)
+			data = append(data, encodeVarInt(2)...)   // ECT(1)
+			data = append(data, encodeVarInt(3)...)   // ECN-CE
+			b := bytes.NewReader(data)
+			_, err := parseAckMPFrame(b, protocol.AckDelayExponent, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(ackMPFrameType)
+			data = append(data, encodeVarInt(3)...)   // path identifier
+			data = append(data, encodeVarInt(100)...) // largest acked
+			data = append(data, encodeVarInt(0)...)   // delay
+			data = append(data, encodeVarInt(0)...)   // num blocks
+			data = append(data, encodeVarInt(10)...)  // first ack block
+			_, err := parseAckMPFrame(bytes.NewReader(data), protocol.AckDelayExponent, protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseAckMPFrame(bytes.NewReader(data[0:i]), protocol.AckDelayExponent, protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			f := &AckMPFrame{
+				PathIdentifier: 0x1337,
+				AckFrame: &AckFrame{
+					AckRanges: []AckRange{{Smallest: 90, Largest: 100}},
+					DelayTime: 10 * time.Millisecond,
+				},
+			}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(HaveLen(int(f.Length(protocol.Version1))))
+			frame, err := parseAckMPFrame(bytes.NewReader(b), protocol.AckDelayExponent, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.PathIdentifier).To(Equal(uint64(0x1337)))
+			Expect(frame.AckRanges).To(Equal(f.AckRanges))
+			Expect(frame.DelayTime).To(Equal(f.DelayTime))
+		})
+
+		It("writes a frame with ECN counts", func() {
+			f := &AckMPFrame{
+				PathIdentifier: 1,
+				AckFrame: &AckFrame{
+					AckRanges: []AckRange{{Smallest: 1, Largest: 10}},
+					ECT0:      1,
+					ECT1:      2,
+					ECNCE:     3,
+				},
+			}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b[:4]).To(Equal(encodeVarInt(ackMPECNFrameType)))
+			Expect(b).To(HaveLen(int(f.Length(protocol.Version1))))
+			r := bytes.NewReader(b)
+			_, err = parseAckMPFrame(r, protocol.AckDelayExponent, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.Len()).To(BeZero())
+		})
+	})
+})
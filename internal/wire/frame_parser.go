@@ -4,28 +4,38 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
+	"github.com/lucas-clemente/quic-go/quicvarint"
 )
 
+var errUnknownFrameType = errors.New("unknown frame type")
+
 type frameParser struct {
 	r bytes.Reader // cached bytes.Reader, so we don't have to repeatedly allocate them
 
 	ackDelayExponent uint8
 
-	supportsDatagrams bool
+	supportsDatagrams     bool
+	supportsMultipath     bool
+	supportsResetStreamAt bool
+	supportsAckFrequency  bool
 
 	version protocol.VersionNumber
 }
 
 // NewFrameParser creates a new frame parser.
-func NewFrameParser(supportsDatagrams bool, v protocol.VersionNumber) FrameParser {
+func NewFrameParser(supportsDatagrams, supportsMultipath, supportsResetStreamAt, supportsAckFrequency bool, v protocol.VersionNumber) FrameParser {
 	return &frameParser{
-		r:                 *bytes.NewReader(nil),
-		supportsDatagrams: supportsDatagrams,
-		version:           v,
+		r:                     *bytes.NewReader(nil),
+		supportsDatagrams:     supportsDatagrams,
+		supportsMultipath:     supportsMultipath,
+		supportsResetStreamAt: supportsResetStreamAt,
+		supportsAckFrequency:  supportsAckFrequency,
+		version:               v,
 	}
 }
 
@@ -42,16 +52,26 @@ func (p *frameParser) ParseNext(data []byte, encLevel protocol.EncryptionLevel)
 
 func (p *frameParser) parseNext(r *bytes.Reader, encLevel protocol.EncryptionLevel) (Frame, error) {
 	for r.Len() != 0 {
-		typeByte, _ := p.r.ReadByte()
-		if typeByte == 0x0 { // PADDING frame
+		// Frame types are encoded as variable-length integers.
+		// All frame types defined in RFC 9000 fit into a single byte,
+		// but extension frames (e.g. the multipath frames) use longer encodings.
+		startLen := r.Len()
+		typ, err := quicvarint.Read(r)
+		if err != nil {
+			return nil, &qerr.TransportError{
+				ErrorCode:    qerr.FrameEncodingError,
+				ErrorMessage: err.Error(),
+			}
+		}
+		if typ == 0x0 { // PADDING frame
 			continue
 		}
-		r.UnreadByte()
+		r.Seek(int64(r.Len()-startLen), io.SeekCurrent)
 
-		f, err := p.parseFrame(r, typeByte, encLevel)
+		f, err := p.parseFrame(r, typ, encLevel)
 		if err != nil {
 			return nil, &qerr.TransportError{
-				FrameType:    uint64(typeByte),
+				FrameType:    typ,
 				ErrorCode:    qerr.FrameEncodingError,
 				ErrorMessage: err.Error(),
 			}
@@ -61,13 +81,13 @@ func (p *frameParser) parseNext(r *bytes.Reader, encLevel protocol.EncryptionLev
 	return nil, nil
 }
 
-func (p *frameParser) parseFrame(r *bytes.Reader, typeByte byte, encLevel protocol.EncryptionLevel) (Frame, error) {
+func (p *frameParser) parseFrame(r *bytes.Reader, typ uint64, encLevel protocol.EncryptionLevel) (Frame, error) {
 	var frame Frame
 	var err error
-	if typeByte&0xf8 == 0x8 {
+	if typ&0xf8 == 0x8 {
 		frame, err = parseStreamFrame(r, p.version)
 	} else {
-		switch typeByte {
+		switch typ {
 		case 0x1:
 			frame, err = parsePingFrame(r, p.version)
 		case 0x2, 0x3:
@@ -76,7 +96,7 @@ func (p *frameParser) parseFrame(r *bytes.Reader, typeByte byte, encLevel protoc
 				ackDelayExponent = protocol.DefaultAckDelayExponent
 			}
 			frame, err = parseAckFrame(r, ackDelayExponent, p.version)
-		case 0x4:
+		case resetStreamFrameType:
 			frame, err = parseResetStreamFrame(r, p.version)
 		case 0x5:
 			frame, err = parseStopSendingFrame(r, p.version)
@@ -108,6 +128,28 @@ func (p *frameParser) parseFrame(r *bytes.Reader, typeByte byte, encLevel protoc
 			frame, err = parseConnectionCloseFrame(r, p.version)
 		case 0x1e:
 			frame, err = parseHandshakeDoneFrame(r, p.version)
+		case immediateAckFrameType, ackFrequencyFrameType:
+			if !p.supportsAckFrequency {
+				err = errUnknownFrameType
+				break
+			}
+			if typ == immediateAckFrameType {
+				frame, err = parseImmediateAckFrame(r, p.version)
+			} else {
+				frame, err = parseAckFrequencyFrame(r, p.version)
+			}
+		case ackMPFrameType, ackMPECNFrameType, pathAbandonFrameType, pathStatusFrameType:
+			if !p.supportsMultipath {
+				err = errUnknownFrameType
+				break
+			}
+			frame, err = p.parseMultipathFrame(r, typ)
+		case resetStreamAtFrameType:
+			if !p.supportsResetStreamAt {
+				err = errUnknownFrameType
+				break
+			}
+			frame, err = parseResetStreamFrame(r, p.version)
 		case 0x30, 0x31:
 			if p.supportsDatagrams {
 				frame, err = parseDatagramFrame(r, p.version)
@@ -115,7 +157,7 @@ func (p *frameParser) parseFrame(r *bytes.Reader, typeByte byte, encLevel protoc
 			}
 			fallthrough
 		default:
-			err = errors.New("unknown frame type")
+			err = errUnknownFrameType
 		}
 	}
 	if err != nil {
@@ -127,6 +169,19 @@ func (p *frameParser) parseFrame(r *bytes.Reader, typeByte byte, encLevel protoc
 	return frame, nil
 }
 
+func (p *frameParser) parseMultipathFrame(r *bytes.Reader, typ uint64) (Frame, error) {
+	switch typ {
+	case ackMPFrameType, ackMPECNFrameType:
+		return parseAckMPFrame(r, p.ackDelayExponent, p.version)
+	case pathAbandonFrameType:
+		return parsePathAbandonFrame(r, p.version)
+	case pathStatusFrameType:
+		return parsePathStatusFrame(r, p.version)
+	default:
+		return nil, errUnknownFrameType
+	}
+}
+
 func (p *frameParser) isAllowedAtEncLevel(f Frame, encLevel protocol.EncryptionLevel) bool {
 	switch encLevel {
 	case protocol.EncryptionInitial, protocol.EncryptionHandshake:
@@ -138,7 +193,7 @@ func (p *frameParser) isAllowedAtEncLevel(f Frame, encLevel protocol.EncryptionL
 		}
 	case protocol.Encryption0RTT:
 		switch f.(type) {
-		case *CryptoFrame, *AckFrame, *ConnectionCloseFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame:
+		case *CryptoFrame, *AckFrame, *AckMPFrame, *ConnectionCloseFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame:
 			return false
 		default:
 			return true
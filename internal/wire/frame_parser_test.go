@@ -13,7 +13,7 @@ var _ = Describe("Frame parsing", func() {
 	var parser FrameParser
 
 	BeforeEach(func() {
-		parser = NewFrameParser(true, protocol.Version1)
+		parser = NewFrameParser(true, true, true, true, protocol.Version1)
 	})
 
 	It("returns nil if there's nothing more to read", func() {
@@ -107,6 +107,34 @@ var _ = Describe("Frame parsing", func() {
 		Expect(l).To(Equal(len(b)))
 	})
 
+	It("unpacks RESET_STREAM_AT frames", func() {
+		f := &ResetStreamFrame{
+			StreamID:     0xdeadbeef,
+			FinalSize:    0xdecafbad1234,
+			ReliableSize: 0x1234,
+			ErrorCode:    0x1337,
+		}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors when RESET_STREAM_AT frames are not supported", func() {
+		parser = NewFrameParser(false, false, false, false, protocol.Version1)
+		f := &ResetStreamFrame{StreamID: 4, FinalSize: 100, ReliableSize: 10}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0x24,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
 	It("unpacks STOP_SENDING frames", func() {
 		f := &StopSendingFrame{StreamID: 0x42}
 		b, err := f.Append(nil, protocol.Version1)
@@ -314,7 +342,7 @@ var _ = Describe("Frame parsing", func() {
 	})
 
 	It("errors when DATAGRAM frames are not supported", func() {
-		parser = NewFrameParser(false, protocol.Version1)
+		parser = NewFrameParser(false, false, false, false, protocol.Version1)
 		f := &DatagramFrame{Data: []byte("foobar")}
 		b, err := f.Append(nil, protocol.Version1)
 		Expect(err).ToNot(HaveOccurred())
@@ -326,15 +354,125 @@ var _ = Describe("Frame parsing", func() {
 		}))
 	})
 
+	It("unpacks ACK_MP frames", func() {
+		f := &AckMPFrame{
+			PathIdentifier: 7,
+			AckFrame:       &AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 0x13}}},
+		}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(BeAssignableToTypeOf(&AckMPFrame{}))
+		Expect(frame.(*AckMPFrame).PathIdentifier).To(Equal(uint64(7)))
+		Expect(frame.(*AckMPFrame).LargestAcked()).To(Equal(protocol.PacketNumber(0x13)))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("unpacks PATH_ABANDON frames", func() {
+		f := &PathAbandonFrame{PathIdentifier: 1, ErrorCode: 2, ReasonPhrase: "foobar"}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("unpacks PATH_STATUS frames", func() {
+		f := &PathStatusFrame{PathIdentifier: 1, SequenceNumber: 2, Status: PathStatusStandby}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("skips PADDING before a multipath frame", func() {
+		f := &PathStatusFrame{PathIdentifier: 1, SequenceNumber: 2, Status: PathStatusStandby}
+		b, err := f.Append([]byte{0, 0, 0}, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors when multipath frames are not supported", func() {
+		parser = NewFrameParser(false, false, false, false, protocol.Version1)
+		f := &PathStatusFrame{PathIdentifier: 1, SequenceNumber: 2, Status: PathStatusStandby}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    pathStatusFrameType,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
+	It("unpacks ACK_FREQUENCY frames", func() {
+		f := &AckFrequencyFrame{
+			SequenceNumber:        1,
+			AckElicitingThreshold: 9,
+			RequestMaxAckDelay:    10 * time.Millisecond,
+			ReorderingThreshold:   2,
+		}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("unpacks IMMEDIATE_ACK frames", func() {
+		f := &ImmediateAckFrame{}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors when ACK frequency frames are not supported", func() {
+		parser = NewFrameParser(false, false, false, false, protocol.Version1)
+		for _, f := range []Frame{&AckFrequencyFrame{}, &ImmediateAckFrame{}} {
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, err = parser.ParseNext(b, protocol.Encryption1RTT)
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.FrameEncodingError))
+			Expect(err.(*qerr.TransportError).ErrorMessage).To(Equal("unknown frame type"))
+		}
+	})
+
 	It("errors on invalid type", func() {
-		_, _, err := parser.ParseNext([]byte{0x42}, protocol.Encryption1RTT)
+		_, _, err := parser.ParseNext([]byte{0x2a}, protocol.Encryption1RTT)
 		Expect(err).To(MatchError(&qerr.TransportError{
 			ErrorCode:    qerr.FrameEncodingError,
-			FrameType:    0x42,
+			FrameType:    0x2a,
 			ErrorMessage: "unknown frame type",
 		}))
 	})
 
+	It("errors on invalid multi-byte types", func() {
+		_, _, err := parser.ParseNext(encodeVarInt(0x1337), protocol.Encryption1RTT)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0x1337,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
+	It("errors on truncated frame types", func() {
+		_, _, err := parser.ParseNext(encodeVarInt(pathStatusFrameType)[:2], protocol.Encryption1RTT)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.FrameEncodingError))
+	})
+
 	It("errors on invalid frames", func() {
 		f := &MaxStreamDataFrame{
 			StreamID:          0x1337,
@@ -352,6 +490,7 @@ var _ = Describe("Frame parsing", func() {
 			&PingFrame{},
 			&AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 42}}},
 			&ResetStreamFrame{},
+			&ResetStreamFrame{FinalSize: 10, ReliableSize: 5},
 			&StopSendingFrame{},
 			&CryptoFrame{},
 			&NewTokenFrame{Token: []byte("lorem ipsum")},
@@ -369,6 +508,11 @@ var _ = Describe("Frame parsing", func() {
 			&ConnectionCloseFrame{},
 			&HandshakeDoneFrame{},
 			&DatagramFrame{},
+			&AckMPFrame{AckFrame: &AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 42}}}},
+			&PathAbandonFrame{},
+			&PathStatusFrame{Status: PathStatusAvailable},
+			&AckFrequencyFrame{AckElicitingThreshold: 1},
+			&ImmediateAckFrame{},
 		}
 
 		var framesSerialized [][]byte
@@ -414,7 +558,7 @@ var _ = Describe("Frame parsing", func() {
 			for i, b := range framesSerialized {
 				_, _, err := parser.ParseNext(b, protocol.Encryption0RTT)
 				switch frames[i].(type) {
-				case *AckFrame, *ConnectionCloseFrame, *CryptoFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame:
+				case *AckFrame, *AckMPFrame, *ConnectionCloseFrame, *CryptoFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame:
 					Expect(err).To(BeAssignableToTypeOf(&qerr.TransportError{}))
 					Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.FrameEncodingError))
 					Expect(err.(*qerr.TransportError).ErrorMessage).To(ContainSubstring("not allowed at encryption level 0-RTT"))
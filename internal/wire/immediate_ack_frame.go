@@ -0,0 +1,29 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const immediateAckFrameType = 0x1f
+
+// An ImmediateAckFrame is an IMMEDIATE_ACK frame, as defined in draft-ietf-quic-ack-frequency.
+type ImmediateAckFrame struct{}
+
+func parseImmediateAckFrame(r *bytes.Reader, _ protocol.VersionNumber) (*ImmediateAckFrame, error) {
+	if _, err := quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+	return &ImmediateAckFrame{}, nil
+}
+
+func (f *ImmediateAckFrame) Append(b []byte, _ protocol.VersionNumber) ([]byte, error) {
+	return quicvarint.Append(b, immediateAckFrameType), nil
+}
+
+// Length of a written frame
+func (f *ImmediateAckFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return quicvarint.Len(immediateAckFrameType)
+}
@@ -24,22 +24,15 @@ func LogFrame(logger utils.Logger, frame Frame, sent bool) {
 	case *StreamFrame:
 		logger.Debugf("\t%s &wire.StreamFrame{StreamID: %d, Fin: %t, Offset: %d, Data length: %d, Offset + Data length: %d}", dir, f.StreamID, f.Fin, f.Offset, f.DataLen(), f.Offset+f.DataLen())
 	case *ResetStreamFrame:
-		logger.Debugf("\t%s &wire.ResetStreamFrame{StreamID: %d, ErrorCode: %#x, FinalSize: %d}", dir, f.StreamID, f.ErrorCode, f.FinalSize)
-	case *AckFrame:
-		hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
-		var ecn string
-		if hasECN {
-			ecn = fmt.Sprintf(", ECT0: %d, ECT1: %d, CE: %d", f.ECT0, f.ECT1, f.ECNCE)
-		}
-		if len(f.AckRanges) > 1 {
-			ackRanges := make([]string, len(f.AckRanges))
-			for i, r := range f.AckRanges {
-				ackRanges[i] = fmt.Sprintf("{Largest: %d, Smallest: %d}", r.Largest, r.Smallest)
-			}
-			logger.Debugf("\t%s &wire.AckFrame{LargestAcked: %d, LowestAcked: %d, AckRanges: {%s}, DelayTime: %s%s}", dir, f.LargestAcked(), f.LowestAcked(), strings.Join(ackRanges, ", "), f.DelayTime.String(), ecn)
+		if f.ReliableSize > 0 {
+			logger.Debugf("\t%s &wire.ResetStreamFrame{StreamID: %d, ErrorCode: %#x, FinalSize: %d, ReliableSize: %d}", dir, f.StreamID, f.ErrorCode, f.FinalSize, f.ReliableSize)
 		} else {
-			logger.Debugf("\t%s &wire.AckFrame{LargestAcked: %d, LowestAcked: %d, DelayTime: %s%s}", dir, f.LargestAcked(), f.LowestAcked(), f.DelayTime.String(), ecn)
+			logger.Debugf("\t%s &wire.ResetStreamFrame{StreamID: %d, ErrorCode: %#x, FinalSize: %d}", dir, f.StreamID, f.ErrorCode, f.FinalSize)
 		}
+	case *AckFrame:
+		logger.Debugf("\t%s &wire.AckFrame{%s}", dir, formatAckFrame(f))
+	case *AckMPFrame:
+		logger.Debugf("\t%s &wire.AckMPFrame{PathIdentifier: %d, %s}", dir, f.PathIdentifier, formatAckFrame(f.AckFrame))
 	case *MaxDataFrame:
 		logger.Debugf("\t%s &wire.MaxDataFrame{MaximumData: %d}", dir, f.MaximumData)
 	case *MaxStreamDataFrame:
@@ -66,7 +59,26 @@ func LogFrame(logger utils.Logger, frame Frame, sent bool) {
 		logger.Debugf("\t%s &wire.NewConnectionIDFrame{SequenceNumber: %d, ConnectionID: %s, StatelessResetToken: %#x}", dir, f.SequenceNumber, f.ConnectionID, f.StatelessResetToken)
 	case *NewTokenFrame:
 		logger.Debugf("\t%s &wire.NewTokenFrame{Token: %#x}", dir, f.Token)
+	case *PathAbandonFrame:
+		logger.Debugf("\t%s &wire.PathAbandonFrame{PathIdentifier: %d, ErrorCode: %#x, ReasonPhrase: %q}", dir, f.PathIdentifier, f.ErrorCode, f.ReasonPhrase)
+	case *AckFrequencyFrame:
+		logger.Debugf("\t%s &wire.AckFrequencyFrame{SequenceNumber: %d, AckElicitingThreshold: %d, RequestMaxAckDelay: %s, ReorderingThreshold: %d}", dir, f.SequenceNumber, f.AckElicitingThreshold, f.RequestMaxAckDelay, f.ReorderingThreshold)
 	default:
 		logger.Debugf("\t%s %#v", dir, frame)
 	}
 }
+
+func formatAckFrame(f *AckFrame) string {
+	var ecn string
+	if f.hasECN() {
+		ecn = fmt.Sprintf(", ECT0: %d, ECT1: %d, CE: %d", f.ECT0, f.ECT1, f.ECNCE)
+	}
+	if len(f.AckRanges) > 1 {
+		ackRanges := make([]string, len(f.AckRanges))
+		for i, r := range f.AckRanges {
+			ackRanges[i] = fmt.Sprintf("{Largest: %d, Smallest: %d}", r.Largest, r.Smallest)
+		}
+		return fmt.Sprintf("LargestAcked: %d, LowestAcked: %d, AckRanges: {%s}, DelayTime: %s%s", f.LargestAcked(), f.LowestAcked(), strings.Join(ackRanges, ", "), f.DelayTime.String(), ecn)
+	}
+	return fmt.Sprintf("LargestAcked: %d, LowestAcked: %d, DelayTime: %s%s", f.LargestAcked(), f.LowestAcked(), f.DelayTime.String(), ecn)
+}
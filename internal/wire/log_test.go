@@ -46,6 +46,11 @@ var _ = Describe("Frame logging", func() {
 		Expect(buf.String()).To(ContainSubstring("\t<- &wire.ResetStreamFrame{StreamID: 0, ErrorCode: 0x0, FinalSize: 0}\n"))
 	})
 
+	It("logs RESET_STREAM_AT frames", func() {
+		LogFrame(logger, &ResetStreamFrame{StreamID: 4, FinalSize: 100, ReliableSize: 10}, false)
+		Expect(buf.String()).To(ContainSubstring("\t<- &wire.ResetStreamFrame{StreamID: 4, ErrorCode: 0x0, FinalSize: 100, ReliableSize: 10}\n"))
+	})
+
 	It("logs CRYPTO frames", func() {
 		frame := &CryptoFrame{
 			Offset: 42,
@@ -98,6 +103,30 @@ var _ = Describe("Frame logging", func() {
 		Expect(buf.String()).To(ContainSubstring("\t<- &wire.AckFrame{LargestAcked: 8, LowestAcked: 2, AckRanges: {{Largest: 8, Smallest: 5}, {Largest: 3, Smallest: 2}}, DelayTime: 12ms}\n"))
 	})
 
+	It("logs ACK_MP frames", func() {
+		frame := &AckMPFrame{
+			PathIdentifier: 3,
+			AckFrame: &AckFrame{
+				AckRanges: []AckRange{{Smallest: 42, Largest: 1337}},
+				DelayTime: 1 * time.Millisecond,
+			},
+		}
+		LogFrame(logger, frame, false)
+		Expect(buf.String()).To(ContainSubstring("\t<- &wire.AckMPFrame{PathIdentifier: 3, LargestAcked: 1337, LowestAcked: 42, DelayTime: 1ms}\n"))
+	})
+
+	It("logs PATH_ABANDON frames", func() {
+		frame := &PathAbandonFrame{PathIdentifier: 3, ErrorCode: 0x42, ReasonPhrase: "foobar"}
+		LogFrame(logger, frame, true)
+		Expect(buf.String()).To(ContainSubstring("\t-> &wire.PathAbandonFrame{PathIdentifier: 3, ErrorCode: 0x42, ReasonPhrase: \"foobar\"}\n"))
+	})
+
+	It("logs ACK_FREQUENCY frames", func() {
+		frame := &AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 9, RequestMaxAckDelay: 5 * time.Millisecond, ReorderingThreshold: 2}
+		LogFrame(logger, frame, true)
+		Expect(buf.String()).To(ContainSubstring("\t-> &wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 9, RequestMaxAckDelay: 5ms, ReorderingThreshold: 2}\n"))
+	})
+
 	It("logs MAX_STREAMS frames", func() {
 		frame := &MaxStreamsFrame{
 			Type:         protocol.StreamTypeBidi,
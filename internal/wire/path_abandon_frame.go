@@ -0,0 +1,68 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const pathAbandonFrameType = 0x15228c05
+
+// A PathAbandonFrame is a PATH_ABANDON frame, as defined in draft-ietf-quic-multipath.
+type PathAbandonFrame struct {
+	// PathIdentifier is the sequence number of the destination connection ID
+	// used by the sender of the frame on the path that is abandoned.
+	PathIdentifier uint64
+	ErrorCode      uint64
+	ReasonPhrase   string
+}
+
+func parsePathAbandonFrame(r *bytes.Reader, _ protocol.VersionNumber) (*PathAbandonFrame, error) {
+	if _, err := quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+
+	f := &PathAbandonFrame{}
+	pathID, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	f.PathIdentifier = pathID
+	ec, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	f.ErrorCode = ec
+	reasonPhraseLen, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	// shortcut to prevent the unnecessary allocation of reasonPhraseLen bytes
+	if int(reasonPhraseLen) > r.Len() {
+		return nil, io.EOF
+	}
+	reasonPhrase := make([]byte, reasonPhraseLen)
+	if _, err := io.ReadFull(r, reasonPhrase); err != nil {
+		// this should never happen, since we already checked the reasonPhraseLen earlier
+		return nil, err
+	}
+	f.ReasonPhrase = string(reasonPhrase)
+	return f, nil
+}
+
+func (f *PathAbandonFrame) Append(b []byte, _ protocol.VersionNumber) ([]byte, error) {
+	b = quicvarint.Append(b, pathAbandonFrameType)
+	b = quicvarint.Append(b, f.PathIdentifier)
+	b = quicvarint.Append(b, f.ErrorCode)
+	b = quicvarint.Append(b, uint64(len(f.ReasonPhrase)))
+	b = append(b, []byte(f.ReasonPhrase)...)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *PathAbandonFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	length := quicvarint.Len(pathAbandonFrameType) + quicvarint.Len(f.PathIdentifier) + quicvarint.Len(f.ErrorCode)
+	return length + quicvarint.Len(uint64(len(f.ReasonPhrase))) + protocol.ByteCount(len(f.ReasonPhrase))
+}
@@ -0,0 +1,82 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PATH_ABANDON frame", func() {
+	Context("when parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(pathAbandonFrameType)
+			data = append(data, encodeVarInt(0x42)...)   // path identifier
+			data = append(data, encodeVarInt(0x1337)...) // error code
+			data = append(data, encodeVarInt(6)...)      // reason phrase length
+			data = append(data, []byte("foobar")...)
+			b := bytes.NewReader(data)
+			frame, err := parsePathAbandonFrame(b, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.PathIdentifier).To(Equal(uint64(0x42)))
+			Expect(frame.ErrorCode).To(Equal(uint64(0x1337)))
+			Expect(frame.ReasonPhrase).To(Equal("foobar"))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("rejects long reason phrases", func() {
+			data := encodeVarInt(pathAbandonFrameType)
+			data = append(data, encodeVarInt(0x42)...)   // path identifier
+			data = append(data, encodeVarInt(0x1337)...) // error code
+			data = append(data, encodeVarInt(0xffff)...) // reason phrase length
+			_, err := parsePathAbandonFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).To(MatchError(io.EOF))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(pathAbandonFrameType)
+			data = append(data, encodeVarInt(0x42)...)   // path identifier
+			data = append(data, encodeVarInt(0x1337)...) // error code
+			data = append(data, encodeVarInt(6)...)      // reason phrase length
+			data = append(data, []byte("foobar")...)
+			_, err := parsePathAbandonFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parsePathAbandonFrame(bytes.NewReader(data[0:i]), protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			frame := &PathAbandonFrame{
+				PathIdentifier: 0x42,
+				ErrorCode:      0xdead,
+				ReasonPhrase:   "foobar",
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(pathAbandonFrameType)
+			expected = append(expected, encodeVarInt(0x42)...)
+			expected = append(expected, encodeVarInt(0xdead)...)
+			expected = append(expected, encodeVarInt(6)...)
+			expected = append(expected, []byte("foobar")...)
+			Expect(b).To(Equal(expected))
+		})
+
+		It("has the correct length", func() {
+			frame := &PathAbandonFrame{
+				PathIdentifier: 0xdecafbad,
+				ErrorCode:      0xcafe,
+				ReasonPhrase:   "lorem ipsum",
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(HaveLen(int(frame.Length(protocol.Version1))))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package wire
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const pathStatusFrameType = 0x15228c06
+
+const (
+	// PathStatusStandby means that the path should only be used if no other path is available.
+	PathStatusStandby uint64 = 1
+	// PathStatusAvailable means that the path can be used for sending.
+	PathStatusAvailable uint64 = 2
+)
+
+// A PathStatusFrame is a PATH_STATUS frame, as defined in draft-ietf-quic-multipath.
+type PathStatusFrame struct {
+	// PathIdentifier is the sequence number of the destination connection ID
+	// used by the sender of the frame on the path.
+	PathIdentifier uint64
+	// SequenceNumber orders PATH_STATUS frames sent for the same path.
+	SequenceNumber uint64
+	Status         uint64
+}
+
+func parsePathStatusFrame(r *bytes.Reader, _ protocol.VersionNumber) (*PathStatusFrame, error) {
+	if _, err := quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+
+	f := &PathStatusFrame{}
+	pathID, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	f.PathIdentifier = pathID
+	seq, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	f.SequenceNumber = seq
+	status, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if status != PathStatusStandby && status != PathStatusAvailable {
+		return nil, fmt.Errorf("invalid path status: %d", status)
+	}
+	f.Status = status
+	return f, nil
+}
+
+func (f *PathStatusFrame) Append(b []byte, _ protocol.VersionNumber) ([]byte, error) {
+	b = quicvarint.Append(b, pathStatusFrameType)
+	b = quicvarint.Append(b, f.PathIdentifier)
+	b = quicvarint.Append(b, f.SequenceNumber)
+	b = quicvarint.Append(b, f.Status)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *PathStatusFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return quicvarint.Len(pathStatusFrameType) + quicvarint.Len(f.PathIdentifier) + quicvarint.Len(f.SequenceNumber) + quicvarint.Len(f.Status)
+}
@@ -0,0 +1,79 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PATH_STATUS frame", func() {
+	Context("when parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(pathStatusFrameType)
+			data = append(data, encodeVarInt(0x42)...)                // path identifier
+			data = append(data, encodeVarInt(0x1337)...)              // sequence number
+			data = append(data, encodeVarInt(PathStatusAvailable)...) // status
+			b := bytes.NewReader(data)
+			frame, err := parsePathStatusFrame(b, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.PathIdentifier).To(Equal(uint64(0x42)))
+			Expect(frame.SequenceNumber).To(Equal(uint64(0x1337)))
+			Expect(frame.Status).To(Equal(PathStatusAvailable))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("rejects invalid path status values", func() {
+			data := encodeVarInt(pathStatusFrameType)
+			data = append(data, encodeVarInt(0x42)...)   // path identifier
+			data = append(data, encodeVarInt(0x1337)...) // sequence number
+			data = append(data, encodeVarInt(3)...)      // status
+			_, err := parsePathStatusFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).To(MatchError("invalid path status: 3"))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(pathStatusFrameType)
+			data = append(data, encodeVarInt(0x42)...)              // path identifier
+			data = append(data, encodeVarInt(0x1337)...)            // sequence number
+			data = append(data, encodeVarInt(PathStatusStandby)...) // status
+			_, err := parsePathStatusFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parsePathStatusFrame(bytes.NewReader(data[0:i]), protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			frame := &PathStatusFrame{
+				PathIdentifier: 0x42,
+				SequenceNumber: 0x1337,
+				Status:         PathStatusStandby,
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(pathStatusFrameType)
+			expected = append(expected, encodeVarInt(0x42)...)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(PathStatusStandby)...)
+			Expect(b).To(Equal(expected))
+		})
+
+		It("has the correct length", func() {
+			frame := &PathStatusFrame{
+				PathIdentifier: 0xdecafbad,
+				SequenceNumber: 0xcafe,
+				Status:         PathStatusAvailable,
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(HaveLen(int(frame.Length(protocol.Version1))))
+		})
+	})
+})
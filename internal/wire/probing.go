@@ -0,0 +1,12 @@
+package wire
+
+// IsProbingFrame returns true if the frame is a probing frame, see section 9.1 of RFC 9000.
+// PADDING frames are also probing frames, but they are never returned by the frame parser.
+func IsProbingFrame(f Frame) bool {
+	switch f.(type) {
+	case *PathChallengeFrame, *PathResponseFrame, *NewConnectionIDFrame:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,28 @@
+package wire
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("probing frames", func() {
+	for fl, p := range map[Frame]bool{
+		&PathChallengeFrame{}:   true,
+		&PathResponseFrame{}:    true,
+		&NewConnectionIDFrame{}: true,
+		&PingFrame{}:            false,
+		&AckFrame{}:             false,
+		&StreamFrame{}:          false,
+		&MaxDataFrame{}:         false,
+	} {
+		f := fl
+		probing := p
+		fName := reflect.ValueOf(f).Elem().Type().Name()
+
+		It("works for "+fName, func() {
+			Expect(IsProbingFrame(f)).To(Equal(probing))
+		})
+	}
+})
@@ -26,6 +26,32 @@ var _ = Describe("RESET_STREAM frame", func() {
 			Expect(frame.ErrorCode).To(Equal(qerr.StreamErrorCode(0x1337)))
 		})
 
+		It("accepts a RESET_STREAM_AT frame", func() {
+			data := []byte{0x24}
+			data = append(data, encodeVarInt(0xdeadbeef)...)  // stream ID
+			data = append(data, encodeVarInt(0x1337)...)      // error code
+			data = append(data, encodeVarInt(0x987654321)...) // byte offset
+			data = append(data, encodeVarInt(0x42)...)        // reliable size
+			b := bytes.NewReader(data)
+			frame, err := parseResetStreamFrame(b, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.StreamID).To(Equal(protocol.StreamID(0xdeadbeef)))
+			Expect(frame.FinalSize).To(Equal(protocol.ByteCount(0x987654321)))
+			Expect(frame.ReliableSize).To(Equal(protocol.ByteCount(0x42)))
+			Expect(frame.ErrorCode).To(Equal(qerr.StreamErrorCode(0x1337)))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("errors when the reliable size is larger than the final size", func() {
+			data := []byte{0x24}
+			data = append(data, encodeVarInt(0xdeadbeef)...) // stream ID
+			data = append(data, encodeVarInt(0x1337)...)     // error code
+			data = append(data, encodeVarInt(0x42)...)       // byte offset
+			data = append(data, encodeVarInt(0x43)...)       // reliable size
+			_, err := parseResetStreamFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).To(MatchError("RESET_STREAM_AT frame: reliable size larger than final size"))
+		})
+
 		It("errors on EOFs", func() {
 			data := []byte{0x4}
 			data = append(data, encodeVarInt(0xdeadbeef)...)  // stream ID
@@ -56,6 +82,24 @@ var _ = Describe("RESET_STREAM frame", func() {
 			Expect(b).To(Equal(expected))
 		})
 
+		It("writes a RESET_STREAM_AT frame", func() {
+			frame := ResetStreamFrame{
+				StreamID:     0x1337,
+				FinalSize:    0x11223344decafbad,
+				ReliableSize: 0x1234,
+				ErrorCode:    0xcafe,
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := []byte{0x24}
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(0xcafe)...)
+			expected = append(expected, encodeVarInt(0x11223344decafbad)...)
+			expected = append(expected, encodeVarInt(0x1234)...)
+			Expect(b).To(Equal(expected))
+			Expect(frame.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+		})
+
 		It("has the correct length", func() {
 			rst := ResetStreamFrame{
 				StreamID:  0x1337,
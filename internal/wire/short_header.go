@@ -9,14 +9,17 @@ import (
 	"github.com/lucas-clemente/quic-go/internal/utils"
 )
 
-func ParseShortHeader(data []byte, connIDLen int) (length int, _ protocol.PacketNumber, _ protocol.PacketNumberLen, _ protocol.KeyPhaseBit, _ error) {
+// ParseShortHeader parses a Short Header packet.
+// Packets with the QUIC bit (0x40) set to 0 are only accepted if acceptGreasedQUICBit is set.
+// Endpoints may only set it if they advertised the grease_quic_bit transport parameter (RFC 9287).
+func ParseShortHeader(data []byte, connIDLen int, acceptGreasedQUICBit bool) (length int, _ protocol.PacketNumber, _ protocol.PacketNumberLen, _ protocol.KeyPhaseBit, _ error) {
 	if len(data) == 0 {
 		return 0, 0, 0, 0, io.EOF
 	}
 	if data[0]&0x80 > 0 {
 		return 0, 0, 0, 0, errors.New("not a short header packet")
 	}
-	if data[0]&0x40 == 0 {
+	if !acceptGreasedQUICBit && data[0]&0x40 == 0 {
 		return 0, 0, 0, 0, errors.New("not a QUIC packet")
 	}
 	pnLen := protocol.PacketNumberLen(data[0]&0b11) + 1
@@ -21,7 +21,7 @@ var _ = Describe("Short Header", func() {
 				0xde, 0xad, 0xbe, 0xef,
 				0x13, 0x37, 0x99,
 			}
-			l, pn, pnLen, kp, err := ParseShortHeader(data, 4)
+			l, pn, pnLen, kp, err := ParseShortHeader(data, 4, false)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(l).To(Equal(len(data)))
 			Expect(kp).To(Equal(protocol.KeyPhaseOne))
@@ -35,23 +35,36 @@ var _ = Describe("Short Header", func() {
 				0xde, 0xad, 0xbe, 0xef,
 				0x13, 0x37,
 			}
-			_, _, _, _, err := ParseShortHeader(data, 4)
+			_, _, _, _, err := ParseShortHeader(data, 4, false)
 			Expect(err).To(MatchError("not a QUIC packet"))
 		})
 
+		It("parses packets with a greased QUIC bit, if requested", func() {
+			data := []byte{
+				0b00000101,
+				0xde, 0xad, 0xbe, 0xef,
+				0x13, 0x37,
+			}
+			l, pn, pnLen, _, err := ParseShortHeader(data, 4, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(data)))
+			Expect(pn).To(Equal(protocol.PacketNumber(0x1337)))
+			Expect(pnLen).To(Equal(protocol.PacketNumberLen2))
+		})
+
 		It("errors, but returns the header, when the reserved bits are set", func() {
 			data := []byte{
 				0b01010101,
 				0xde, 0xad, 0xbe, 0xef,
 				0x13, 0x37,
 			}
-			_, pn, _, _, err := ParseShortHeader(data, 4)
+			_, pn, _, _, err := ParseShortHeader(data, 4, false)
 			Expect(err).To(MatchError(ErrInvalidReservedBits))
 			Expect(pn).To(Equal(protocol.PacketNumber(0x1337)))
 		})
 
 		It("errors when passed a long header packet", func() {
-			_, _, _, _, err := ParseShortHeader([]byte{0x80}, 4)
+			_, _, _, _, err := ParseShortHeader([]byte{0x80}, 4, false)
 			Expect(err).To(MatchError("not a short header packet"))
 		})
 
@@ -61,10 +74,10 @@ var _ = Describe("Short Header", func() {
 				0xde, 0xad, 0xbe, 0xef,
 				0x13, 0x37, 0x99,
 			}
-			_, _, _, _, err := ParseShortHeader(data, 4)
+			_, _, _, _, err := ParseShortHeader(data, 4, false)
 			Expect(err).ToNot(HaveOccurred())
 			for i := range data {
-				_, _, _, _, err := ParseShortHeader(data[:i], 4)
+				_, _, _, _, err := ParseShortHeader(data[:i], 4, false)
 				Expect(err).To(MatchError(io.EOF))
 			}
 		})
@@ -60,6 +60,15 @@ var _ = Describe("Transport Parameters", func() {
 			MaxDatagramFrameSize:            876,
 		}
 		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876}"))
+		p.MinAckDelay = time.Millisecond
+		Expect(p.String()).To(HaveSuffix(", MaxDatagramFrameSize: 876, MinAckDelay: 1ms}"))
+		p.GreaseQUICBit = true
+		Expect(p.String()).To(HaveSuffix(", MaxDatagramFrameSize: 876, GreaseQUICBit: true, MinAckDelay: 1ms}"))
+		p.VersionInformation = &VersionInformation{
+			ChosenVersion:     protocol.Version1,
+			AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.Version2},
+		}
+		Expect(p.String()).To(HaveSuffix(", MinAckDelay: 1ms, VersionInformation: {ChosenVersion: v1, AvailableVersions: [v1 v2]}}"))
 	})
 
 	It("has a string representation, if there's no stateless reset token, no Retry source connection id and no datagram support", func() {
@@ -102,6 +111,14 @@ var _ = Describe("Transport Parameters", func() {
 			MaxAckDelay:                     42 * time.Millisecond,
 			ActiveConnectionIDLimit:         getRandomValue(),
 			MaxDatagramFrameSize:            protocol.ByteCount(getRandomValue()),
+			GreaseQUICBit:                   true,
+			EnableMultipath:                 true,
+			EnableResetStreamAt:             true,
+			MinAckDelay:                     1500 * time.Microsecond,
+			VersionInformation: &VersionInformation{
+				ChosenVersion:     protocol.Version2,
+				AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.Version2, 0x1337},
+			},
 		}
 		data := params.Marshal(protocol.PerspectiveServer)
 
@@ -123,6 +140,67 @@ var _ = Describe("Transport Parameters", func() {
 		Expect(p.MaxAckDelay).To(Equal(42 * time.Millisecond))
 		Expect(p.ActiveConnectionIDLimit).To(Equal(params.ActiveConnectionIDLimit))
 		Expect(p.MaxDatagramFrameSize).To(Equal(params.MaxDatagramFrameSize))
+		Expect(p.GreaseQUICBit).To(BeTrue())
+		Expect(p.EnableMultipath).To(BeTrue())
+		Expect(p.EnableResetStreamAt).To(BeTrue())
+		Expect(p.MinAckDelay).To(Equal(1500 * time.Microsecond))
+		Expect(p.VersionInformation).To(Equal(params.VersionInformation))
+	})
+
+	It("doesn't marshal enable_multipath, if multipath is disabled", func() {
+		data := (&TransportParameters{
+			StatelessResetToken: &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+		Expect(p.EnableMultipath).To(BeFalse())
+	})
+
+	It("doesn't marshal grease_quic_bit, if not set", func() {
+		data := (&TransportParameters{
+			StatelessResetToken: &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+		Expect(p.GreaseQUICBit).To(BeFalse())
+	})
+
+	It("doesn't marshal reset_stream_at, if reliable stream resets are disabled", func() {
+		data := (&TransportParameters{
+			StatelessResetToken: &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+		Expect(p.EnableResetStreamAt).To(BeFalse())
+	})
+
+	It("doesn't marshal min_ack_delay, if the ACK frequency extension is disabled", func() {
+		data := (&TransportParameters{
+			StatelessResetToken: &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+		Expect(p.MinAckDelay).To(BeZero())
+	})
+
+	It("doesn't marshal the version_information, if not set", func() {
+		data := (&TransportParameters{
+			StatelessResetToken: &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+		Expect(p.VersionInformation).To(BeNil())
+	})
+
+	It("marshals a version_information without available versions", func() {
+		data := (&TransportParameters{
+			VersionInformation: &VersionInformation{ChosenVersion: protocol.Version1},
+		}).Marshal(protocol.PerspectiveClient)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(Succeed())
+		Expect(p.VersionInformation).ToNot(BeNil())
+		Expect(p.VersionInformation.ChosenVersion).To(Equal(protocol.Version1))
+		Expect(p.VersionInformation.AvailableVersions).To(BeEmpty())
 	})
 
 	It("doesn't marshal a retry_source_connection_id, if no Retry was performed", func() {
@@ -179,6 +257,73 @@ var _ = Describe("Transport Parameters", func() {
 		}))
 	})
 
+	It("errors when enable_multipath has content", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, uint64(enableMultipathParameterID))
+		quicvarint.Write(b, 6)
+		b.Write([]byte("foobar"))
+		Expect((&TransportParameters{}).Unmarshal(b.Bytes(), protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "wrong length for enable_multipath: 6 (expected empty)",
+		}))
+	})
+
+	It("errors when grease_quic_bit has content", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, uint64(greaseQUICBitParameterID))
+		quicvarint.Write(b, 6)
+		b.Write([]byte("foobar"))
+		Expect((&TransportParameters{}).Unmarshal(b.Bytes(), protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "wrong length for grease_quic_bit: 6 (expected empty)",
+		}))
+	})
+
+	It("errors when reset_stream_at has content", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, uint64(resetStreamAtParameterID))
+		quicvarint.Write(b, 6)
+		b.Write([]byte("foobar"))
+		Expect((&TransportParameters{}).Unmarshal(b.Bytes(), protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "wrong length for reset_stream_at: 6 (expected empty)",
+		}))
+	})
+
+	It("errors when the version_information has the wrong length", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, uint64(versionInformationParameterID))
+		quicvarint.Write(b, 6)
+		b.Write([]byte{0, 0, 0, 1, 0, 0})
+		Expect((&TransportParameters{}).Unmarshal(b.Bytes(), protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "invalid length for version_information: 6",
+		}))
+	})
+
+	It("errors when the version_information is empty", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, uint64(versionInformationParameterID))
+		quicvarint.Write(b, 0)
+		Expect((&TransportParameters{}).Unmarshal(b.Bytes(), protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "invalid length for version_information: 0",
+		}))
+	})
+
+	It("errors when the version_information contains version 0", func() {
+		data := (&TransportParameters{
+			VersionInformation: &VersionInformation{
+				ChosenVersion:     protocol.Version1,
+				AvailableVersions: []protocol.VersionNumber{protocol.Version1, 0},
+			},
+		}).Marshal(protocol.PerspectiveClient)
+		Expect((&TransportParameters{}).Unmarshal(data, protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "version_information contains version 0",
+		}))
+	})
+
 	It("errors when the server doesn't set the original_destination_connection_id", func() {
 		b := &bytes.Buffer{}
 		quicvarint.Write(b, uint64(statelessResetTokenParameterID))
@@ -210,6 +355,30 @@ var _ = Describe("Transport Parameters", func() {
 		}))
 	})
 
+	It("errors when the min_ack_delay is larger than the max_ack_delay", func() {
+		data := (&TransportParameters{
+			MaxAckDelay:         10 * time.Millisecond,
+			MinAckDelay:         11 * time.Millisecond,
+			StatelessResetToken: &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "min_ack_delay (11ms) larger than max_ack_delay (10ms)",
+		}))
+	})
+
+	It("errors when the min_ack_delay is 0", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, uint64(minAckDelayParameterID))
+		quicvarint.Write(b, 1)
+		quicvarint.Write(b, 0)
+		Expect((&TransportParameters{}).Unmarshal(b.Bytes(), protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "invalid value for min_ack_delay: 0us",
+		}))
+	})
+
 	It("doesn't send the max_ack_delay, if it has the default value", func() {
 		const num = 1000
 		var defaultLen, dataLen int
@@ -484,6 +653,7 @@ var _ = Describe("Transport Parameters", func() {
 				MaxBidiStreamNum:               protocol.StreamNum(getRandomValueUpTo(int64(protocol.MaxStreamCount))),
 				MaxUniStreamNum:                protocol.StreamNum(getRandomValueUpTo(int64(protocol.MaxStreamCount))),
 				ActiveConnectionIDLimit:        getRandomValue(),
+				MaxDatagramFrameSize:           protocol.ByteCount(getRandomValue()),
 			}
 			Expect(params.ValidFor0RTT(params)).To(BeTrue())
 			b := params.MarshalForSessionTicket(nil)
@@ -496,6 +666,18 @@ var _ = Describe("Transport Parameters", func() {
 			Expect(tp.MaxBidiStreamNum).To(Equal(params.MaxBidiStreamNum))
 			Expect(tp.MaxUniStreamNum).To(Equal(params.MaxUniStreamNum))
 			Expect(tp.ActiveConnectionIDLimit).To(Equal(params.ActiveConnectionIDLimit))
+			Expect(tp.MaxDatagramFrameSize).To(Equal(params.MaxDatagramFrameSize))
+		})
+
+		It("saves parameters without datagram support", func() {
+			params := &TransportParameters{
+				ActiveConnectionIDLimit: 2,
+				MaxDatagramFrameSize:    protocol.InvalidByteCount,
+			}
+			b := params.MarshalForSessionTicket(nil)
+			var tp TransportParameters
+			Expect(tp.UnmarshalFromSessionTicket(bytes.NewReader(b))).To(Succeed())
+			Expect(tp.MaxDatagramFrameSize).To(Equal(protocol.InvalidByteCount))
 		})
 
 		It("rejects the parameters if it can't parse them", func() {
@@ -522,6 +704,7 @@ var _ = Describe("Transport Parameters", func() {
 				MaxBidiStreamNum:               5,
 				MaxUniStreamNum:                6,
 				ActiveConnectionIDLimit:        7,
+				MaxDatagramFrameSize:           1000,
 			}
 
 			BeforeEach(func() {
@@ -593,6 +776,21 @@ var _ = Describe("Transport Parameters", func() {
 				p.ActiveConnectionIDLimit = 0
 				Expect(p.ValidFor0RTT(saved)).To(BeFalse())
 			})
+
+			It("rejects the parameters if the MaxDatagramFrameSize was reduced", func() {
+				p.MaxDatagramFrameSize = saved.MaxDatagramFrameSize - 1
+				Expect(p.ValidFor0RTT(saved)).To(BeFalse())
+			})
+
+			It("rejects the parameters if datagram support was disabled", func() {
+				p.MaxDatagramFrameSize = protocol.InvalidByteCount
+				Expect(p.ValidFor0RTT(saved)).To(BeFalse())
+			})
+
+			It("accepts the parameters if the MaxDatagramFrameSize was increased", func() {
+				p.MaxDatagramFrameSize = saved.MaxDatagramFrameSize + 1
+				Expect(p.ValidFor0RTT(saved)).To(BeTrue())
+			})
 		})
 	})
 })
@@ -43,8 +43,18 @@ const (
 	activeConnectionIDLimitParameterID         transportParameterID = 0xe
 	initialSourceConnectionIDParameterID       transportParameterID = 0xf
 	retrySourceConnectionIDParameterID         transportParameterID = 0x10
+	// RFC 9368
+	versionInformationParameterID transportParameterID = 0x11
 	// RFC 9221
 	maxDatagramFrameSizeParameterID transportParameterID = 0x20
+	// RFC 9287
+	greaseQUICBitParameterID transportParameterID = 0x2ab2
+	// draft-ietf-quic-multipath-04
+	enableMultipathParameterID transportParameterID = 0x0f739bbc1b666d04
+	// draft-ietf-quic-reliable-stream-reset
+	resetStreamAtParameterID transportParameterID = 0x17f7586d2cb571
+	// draft-ietf-quic-ack-frequency
+	minAckDelayParameterID transportParameterID = 0xff04de1b
 )
 
 // PreferredAddress is the value encoding in the preferred_address transport parameter
@@ -57,6 +67,12 @@ type PreferredAddress struct {
 	StatelessResetToken protocol.StatelessResetToken
 }
 
+// VersionInformation is the value encoded in the version_information transport parameter
+type VersionInformation struct {
+	ChosenVersion     protocol.VersionNumber
+	AvailableVersions []protocol.VersionNumber
+}
+
 // TransportParameters are parameters sent to the peer during the handshake
 type TransportParameters struct {
 	InitialMaxStreamDataBidiLocal  protocol.ByteCount
@@ -86,6 +102,21 @@ type TransportParameters struct {
 	ActiveConnectionIDLimit uint64
 
 	MaxDatagramFrameSize protocol.ByteCount
+
+	// GreaseQUICBit is set if the endpoint accepts packets with the fixed bit (the QUIC bit) set to 0.
+	GreaseQUICBit bool
+
+	EnableMultipath bool
+
+	EnableResetStreamAt bool
+
+	// MinAckDelay is the minimum amount of time the endpoint delays sending ACKs.
+	// It is 0 if the endpoint doesn't support the ACK frequency extension.
+	MinAckDelay time.Duration
+
+	// VersionInformation is used for compatible version negotiation.
+	// It is nil if the endpoint didn't send the version_information transport parameter.
+	VersionInformation *VersionInformation
 }
 
 // Unmarshal the transport parameters
@@ -138,6 +169,7 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 			maxAckDelayParameterID,
 			activeConnectionIDLimitParameterID,
 			maxDatagramFrameSizeParameterID,
+			minAckDelayParameterID,
 			ackDelayExponentParameterID:
 			if err := p.readNumericTransportParameter(r, paramID, int(paramLen)); err != nil {
 				return err
@@ -154,6 +186,21 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 				return fmt.Errorf("wrong length for disable_active_migration: %d (expected empty)", paramLen)
 			}
 			p.DisableActiveMigration = true
+		case greaseQUICBitParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for grease_quic_bit: %d (expected empty)", paramLen)
+			}
+			p.GreaseQUICBit = true
+		case enableMultipathParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for enable_multipath: %d (expected empty)", paramLen)
+			}
+			p.EnableMultipath = true
+		case resetStreamAtParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for reset_stream_at: %d (expected empty)", paramLen)
+			}
+			p.EnableResetStreamAt = true
 		case statelessResetTokenParameterID:
 			if sentBy == protocol.PerspectiveClient {
 				return errors.New("client sent a stateless_reset_token")
@@ -179,6 +226,10 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 			}
 			connID, _ := protocol.ReadConnectionID(r, int(paramLen))
 			p.RetrySourceConnectionID = &connID
+		case versionInformationParameterID:
+			if err := p.readVersionInformation(r, int(paramLen)); err != nil {
+				return err
+			}
 		default:
 			r.Seek(int64(paramLen), io.SeekCurrent)
 		}
@@ -196,6 +247,10 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 		}
 	}
 
+	if p.MinAckDelay > p.MaxAckDelay {
+		return fmt.Errorf("min_ack_delay (%s) larger than max_ack_delay (%s)", p.MinAckDelay, p.MaxAckDelay)
+	}
+
 	// check that every transport parameter was sent at most once
 	sort.Slice(parameterIDs, func(i, j int) bool { return parameterIDs[i] < parameterIDs[j] })
 	for i := 0; i < len(parameterIDs)-1; i++ {
@@ -252,6 +307,30 @@ func (p *TransportParameters) readPreferredAddress(r *bytes.Reader, expectedLen
 	return nil
 }
 
+func (p *TransportParameters) readVersionInformation(r *bytes.Reader, l int) error {
+	if l < 4 || l%4 != 0 {
+		return fmt.Errorf("invalid length for version_information: %d", l)
+	}
+	vi := &VersionInformation{AvailableVersions: make([]protocol.VersionNumber, 0, l/4-1)}
+	b := make([]byte, 4)
+	for i := 0; i < l/4; i++ {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return err
+		}
+		v := protocol.VersionNumber(binary.BigEndian.Uint32(b))
+		if v == 0 {
+			return errors.New("version_information contains version 0")
+		}
+		if i == 0 {
+			vi.ChosenVersion = v
+		} else {
+			vi.AvailableVersions = append(vi.AvailableVersions, v)
+		}
+	}
+	p.VersionInformation = vi
+	return nil
+}
+
 func (p *TransportParameters) readNumericTransportParameter(
 	r *bytes.Reader,
 	paramID transportParameterID,
@@ -306,6 +385,11 @@ func (p *TransportParameters) readNumericTransportParameter(
 		p.ActiveConnectionIDLimit = val
 	case maxDatagramFrameSizeParameterID:
 		p.MaxDatagramFrameSize = protocol.ByteCount(val)
+	case minAckDelayParameterID:
+		if val == 0 || val > uint64(protocol.MaxMaxAckDelay/time.Microsecond) {
+			return fmt.Errorf("invalid value for min_ack_delay: %dus", val)
+		}
+		p.MinAckDelay = time.Duration(val) * time.Microsecond
 	default:
 		return fmt.Errorf("TransportParameter BUG: transport parameter %d not found", paramID)
 	}
@@ -399,6 +483,36 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	if p.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		b = p.marshalVarintParam(b, maxDatagramFrameSizeParameterID, uint64(p.MaxDatagramFrameSize))
 	}
+	// grease_quic_bit
+	if p.GreaseQUICBit {
+		b = quicvarint.Append(b, uint64(greaseQUICBitParameterID))
+		b = quicvarint.Append(b, 0)
+	}
+	// enable_multipath
+	if p.EnableMultipath {
+		b = quicvarint.Append(b, uint64(enableMultipathParameterID))
+		b = quicvarint.Append(b, 0)
+	}
+	// reset_stream_at
+	if p.EnableResetStreamAt {
+		b = quicvarint.Append(b, uint64(resetStreamAtParameterID))
+		b = quicvarint.Append(b, 0)
+	}
+	// min_ack_delay
+	if p.MinAckDelay > 0 {
+		b = p.marshalVarintParam(b, minAckDelayParameterID, uint64(p.MinAckDelay/time.Microsecond))
+	}
+	// version_information
+	if p.VersionInformation != nil {
+		b = quicvarint.Append(b, uint64(versionInformationParameterID))
+		b = quicvarint.Append(b, uint64(4*(1+len(p.VersionInformation.AvailableVersions))))
+		b = append(b, make([]byte, 4)...)
+		binary.BigEndian.PutUint32(b[len(b)-4:], uint32(p.VersionInformation.ChosenVersion))
+		for _, v := range p.VersionInformation.AvailableVersions {
+			b = append(b, make([]byte, 4)...)
+			binary.BigEndian.PutUint32(b[len(b)-4:], uint32(v))
+		}
+	}
 	return b
 }
 
@@ -432,7 +546,12 @@ func (p *TransportParameters) MarshalForSessionTicket(b []byte) []byte {
 	// initial_max_uni_streams
 	b = p.marshalVarintParam(b, initialMaxStreamsUniParameterID, uint64(p.MaxUniStreamNum))
 	// active_connection_id_limit
-	return p.marshalVarintParam(b, activeConnectionIDLimitParameterID, p.ActiveConnectionIDLimit)
+	b = p.marshalVarintParam(b, activeConnectionIDLimitParameterID, p.ActiveConnectionIDLimit)
+	// max_datagram_frame_size
+	if p.MaxDatagramFrameSize != protocol.InvalidByteCount {
+		b = p.marshalVarintParam(b, maxDatagramFrameSizeParameterID, uint64(p.MaxDatagramFrameSize))
+	}
+	return b
 }
 
 // UnmarshalFromSessionTicket unmarshals transport parameters from a session ticket.
@@ -455,7 +574,8 @@ func (p *TransportParameters) ValidFor0RTT(saved *TransportParameters) bool {
 		p.InitialMaxData >= saved.InitialMaxData &&
 		p.MaxBidiStreamNum >= saved.MaxBidiStreamNum &&
 		p.MaxUniStreamNum >= saved.MaxUniStreamNum &&
-		p.ActiveConnectionIDLimit == saved.ActiveConnectionIDLimit
+		p.ActiveConnectionIDLimit == saved.ActiveConnectionIDLimit &&
+		p.MaxDatagramFrameSize >= saved.MaxDatagramFrameSize
 }
 
 // String returns a string representation, intended for logging.
@@ -476,6 +596,23 @@ func (p *TransportParameters) String() string {
 		logString += ", MaxDatagramFrameSize: %d"
 		logParams = append(logParams, p.MaxDatagramFrameSize)
 	}
+	if p.GreaseQUICBit {
+		logString += ", GreaseQUICBit: true"
+	}
+	if p.EnableMultipath {
+		logString += ", EnableMultipath: true"
+	}
+	if p.EnableResetStreamAt {
+		logString += ", EnableResetStreamAt: true"
+	}
+	if p.MinAckDelay > 0 {
+		logString += ", MinAckDelay: %s"
+		logParams = append(logParams, p.MinAckDelay)
+	}
+	if p.VersionInformation != nil {
+		logString += ", VersionInformation: {ChosenVersion: %s, AvailableVersions: %s}"
+		logParams = append(logParams, p.VersionInformation.ChosenVersion, p.VersionInformation.AvailableVersions)
+	}
 	logString += "}"
 	return fmt.Sprintf(logString, logParams...)
 }
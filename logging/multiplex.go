@@ -104,6 +104,12 @@ func (m *connTracerMultiplexer) RestoredTransportParameters(tp *TransportParamet
 	}
 }
 
+func (m *connTracerMultiplexer) Rejected0RTT(reason ZeroRTTRejectionReason) {
+	for _, t := range m.tracers {
+		t.Rejected0RTT(reason)
+	}
+}
+
 func (m *connTracerMultiplexer) SentPacket(hdr *ExtendedHeader, size ByteCount, ack *AckFrame, frames []Frame) {
 	for _, t := range m.tracers {
 		t.SentPacket(hdr, size, ack, frames)
@@ -176,6 +182,12 @@ func (m *connTracerMultiplexer) UpdatedPTOCount(value uint32) {
 	}
 }
 
+func (m *connTracerMultiplexer) UpdatedMigrationState(state MigrationState, local, remote net.Addr) {
+	for _, t := range m.tracers {
+		t.UpdatedMigrationState(state, local, remote)
+	}
+}
+
 func (m *connTracerMultiplexer) UpdatedKeyFromTLS(encLevel EncryptionLevel, perspective Perspective) {
 	for _, t := range m.tracers {
 		t.UpdatedKeyFromTLS(encLevel, perspective)
@@ -157,6 +157,12 @@ var _ = Describe("Tracing", func() {
 			tracer.RestoredTransportParameters(tp)
 		})
 
+		It("traces the Rejected0RTT event", func() {
+			tr1.EXPECT().Rejected0RTT(ZeroRTTRejectedReplay)
+			tr2.EXPECT().Rejected0RTT(ZeroRTTRejectedReplay)
+			tracer.Rejected0RTT(ZeroRTTRejectedReplay)
+		})
+
 		It("traces the SentPacket event", func() {
 			hdr := &ExtendedHeader{Header: Header{DestConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3})}}
 			ack := &AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 10}}}
@@ -241,6 +247,14 @@ var _ = Describe("Tracing", func() {
 			tracer.UpdatedPTOCount(88)
 		})
 
+		It("traces the UpdatedMigrationState event", func() {
+			local := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+			remote := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1), Port: 4321}
+			tr1.EXPECT().UpdatedMigrationState(MigrationStateProbingStarted, local, remote)
+			tr2.EXPECT().UpdatedMigrationState(MigrationStateProbingStarted, local, remote)
+			tracer.UpdatedMigrationState(MigrationStateProbingStarted, local, remote)
+		})
+
 		It("traces the UpdatedKeyFromTLS event", func() {
 			tr1.EXPECT().UpdatedKeyFromTLS(EncryptionHandshake, PerspectiveClient)
 			tr2.EXPECT().UpdatedKeyFromTLS(EncryptionHandshake, PerspectiveClient)
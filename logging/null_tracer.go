@@ -35,6 +35,7 @@ func (n NullConnectionTracer) ClosedConnection(err error)
 func (n NullConnectionTracer) SentTransportParameters(*TransportParameters)              {}
 func (n NullConnectionTracer) ReceivedTransportParameters(*TransportParameters)          {}
 func (n NullConnectionTracer) RestoredTransportParameters(*TransportParameters)          {}
+func (n NullConnectionTracer) Rejected0RTT(ZeroRTTRejectionReason)                       {}
 func (n NullConnectionTracer) SentPacket(*ExtendedHeader, ByteCount, *AckFrame, []Frame) {}
 func (n NullConnectionTracer) ReceivedVersionNegotiationPacket(dest, src ArbitraryLenConnectionID, _ []VersionNumber) {
 }
@@ -50,6 +51,7 @@ func (n NullConnectionTracer) AcknowledgedPacket(EncryptionLevel, PacketNumber)
 func (n NullConnectionTracer) LostPacket(EncryptionLevel, PacketNumber, PacketLossReason)  {}
 func (n NullConnectionTracer) UpdatedCongestionState(CongestionState)                      {}
 func (n NullConnectionTracer) UpdatedPTOCount(uint32)                                      {}
+func (n NullConnectionTracer) UpdatedMigrationState(MigrationState, net.Addr, net.Addr)    {}
 func (n NullConnectionTracer) UpdatedKeyFromTLS(EncryptionLevel, Perspective)              {}
 func (n NullConnectionTracer) UpdatedKey(keyPhase KeyPhase, remote bool)                   {}
 func (n NullConnectionTracer) DroppedEncryptionLevel(EncryptionLevel)                      {}
@@ -92,3 +92,31 @@ const (
 	// CongestionStateApplicationLimited means that the congestion controller is application limited
 	CongestionStateApplicationLimited
 )
+
+// MigrationState is the state of a connection migration
+type MigrationState uint8
+
+const (
+	// MigrationStateProbingStarted means that path validation for a new path was started
+	MigrationStateProbingStarted MigrationState = iota
+	// MigrationStateProbingAbandoned means that path validation failed
+	MigrationStateProbingAbandoned
+	// MigrationStateProbingSuccessful means that the new path was validated
+	MigrationStateProbingSuccessful
+	// MigrationStateMigrationComplete means that the connection is now using the new path
+	MigrationStateMigrationComplete
+)
+
+// ZeroRTTRejectionReason is the reason why the server rejected 0-RTT
+type ZeroRTTRejectionReason uint8
+
+const (
+	// ZeroRTTRejectedVersionChanged means that the QUIC version was changed during the handshake
+	ZeroRTTRejectedVersionChanged ZeroRTTRejectionReason = iota
+	// ZeroRTTRejectedTransportParameters means that the transport parameters changed since the session ticket was issued
+	ZeroRTTRejectedTransportParameters
+	// ZeroRTTRejectedReplay means that the session ticket was already used, or that it is too old to detect replays
+	ZeroRTTRejectedReplay
+	// ZeroRTTRejectedByApplication means that the application rejected 0-RTT (see Config.Allow0RTT)
+	ZeroRTTRejectedByApplication
+)
@@ -173,3 +173,15 @@ func (mr *MockPacketHandlerManagerMockRecorder) SetServer(arg0 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetServer", reflect.TypeOf((*MockPacketHandlerManager)(nil).SetServer), arg0)
 }
+
+// SetStatelessResetKeys mocks base method.
+func (m *MockPacketHandlerManager) SetStatelessResetKeys(arg0 []StatelessResetKey) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetStatelessResetKeys", arg0)
+}
+
+// SetStatelessResetKeys indicates an expected call of SetStatelessResetKeys.
+func (mr *MockPacketHandlerManagerMockRecorder) SetStatelessResetKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatelessResetKeys", reflect.TypeOf((*MockPacketHandlerManager)(nil).SetStatelessResetKeys), arg0)
+}
@@ -10,7 +10,6 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
-	congestion "github.com/lucas-clemente/quic-go/internal/congestion"
 	protocol "github.com/lucas-clemente/quic-go/internal/protocol"
 )
 
@@ -67,15 +66,32 @@ func (mr *MockQuicConnMockRecorder) AcceptUniStream(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptUniStream", reflect.TypeOf((*MockQuicConn)(nil).AcceptUniStream), arg0)
 }
 
+// AddPath mocks base method.
+func (m *MockQuicConn) AddPath(arg0 net.PacketConn) (Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPath", arg0)
+	ret0, _ := ret[0].(Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPath indicates an expected call of AddPath.
+func (mr *MockQuicConnMockRecorder) AddPath(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockQuicConn)(nil).AddPath), arg0)
+}
+
 // BandwidthEstimate mocks base method.
-func (m *MockQuicConn) BandwidthEstimate() congestion.Bandwidth {
+func (m *MockQuicConn) BandwidthEstimate() Bandwidth {
+	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "BandwidthEstimate")
-	ret0, _ := ret[0].(congestion.Bandwidth)
+	ret0, _ := ret[0].(Bandwidth)
 	return ret0
 }
 
 // BandwidthEstimate indicates an expected call of BandwidthEstimate.
 func (mr *MockQuicConnMockRecorder) BandwidthEstimate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BandwidthEstimate", reflect.TypeOf((*MockQuicConn)(nil).BandwidthEstimate))
 }
 
@@ -163,6 +179,34 @@ func (mr *MockQuicConnMockRecorder) LocalAddr() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalAddr", reflect.TypeOf((*MockQuicConn)(nil).LocalAddr))
 }
 
+// MaxDatagramSize mocks base method.
+func (m *MockQuicConn) MaxDatagramSize() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxDatagramSize")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// MaxDatagramSize indicates an expected call of MaxDatagramSize.
+func (mr *MockQuicConnMockRecorder) MaxDatagramSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxDatagramSize", reflect.TypeOf((*MockQuicConn)(nil).MaxDatagramSize))
+}
+
+// MigrateTo mocks base method.
+func (m *MockQuicConn) MigrateTo(arg0 net.PacketConn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateTo", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateTo indicates an expected call of MigrateTo.
+func (mr *MockQuicConnMockRecorder) MigrateTo(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateTo", reflect.TypeOf((*MockQuicConn)(nil).MigrateTo), arg0)
+}
+
 // NextConnection mocks base method.
 func (m *MockQuicConn) NextConnection() Connection {
 	m.ctrl.T.Helper()
@@ -252,6 +296,21 @@ func (mr *MockQuicConnMockRecorder) ReceiveMessage() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveMessage", reflect.TypeOf((*MockQuicConn)(nil).ReceiveMessage))
 }
 
+// ReceiveMessageContext mocks base method.
+func (m *MockQuicConn) ReceiveMessageContext(arg0 context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveMessageContext", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveMessageContext indicates an expected call of ReceiveMessageContext.
+func (mr *MockQuicConnMockRecorder) ReceiveMessageContext(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveMessageContext", reflect.TypeOf((*MockQuicConn)(nil).ReceiveMessageContext), arg0)
+}
+
 // RemoteAddr mocks base method.
 func (m *MockQuicConn) RemoteAddr() net.Addr {
 	m.ctrl.T.Helper()
@@ -280,6 +339,34 @@ func (mr *MockQuicConnMockRecorder) SendMessage(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockQuicConn)(nil).SendMessage), arg0)
 }
 
+// SendMessageWithCallbacks mocks base method.
+func (m *MockQuicConn) SendMessageWithCallbacks(msg []byte, onAcked, onLost func()) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessageWithCallbacks", msg, onAcked, onLost)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessageWithCallbacks indicates an expected call of SendMessageWithCallbacks.
+func (mr *MockQuicConnMockRecorder) SendMessageWithCallbacks(msg, onAcked, onLost interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageWithCallbacks", reflect.TypeOf((*MockQuicConn)(nil).SendMessageWithCallbacks), msg, onAcked, onLost)
+}
+
+// Stats mocks base method.
+func (m *MockQuicConn) Stats() ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockQuicConnMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockQuicConn)(nil).Stats))
+}
+
 // destroy mocks base method.
 func (m *MockQuicConn) destroy(arg0 error) {
 	m.ctrl.T.Helper()
@@ -68,3 +68,21 @@ func (mr *MockUnpackerMockRecorder) UnpackShortHeader(rcvTime, data interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpackShortHeader", reflect.TypeOf((*MockUnpacker)(nil).UnpackShortHeader), rcvTime, data)
 }
+
+// UnpackShortHeaderOnPath mocks base method.
+func (m *MockUnpacker) UnpackShortHeaderOnPath(data []byte, pathID uint64, largestRcvd protocol.PacketNumber) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpackShortHeaderOnPath", data, pathID, largestRcvd)
+	ret0, _ := ret[0].(protocol.PacketNumber)
+	ret1, _ := ret[1].(protocol.PacketNumberLen)
+	ret2, _ := ret[2].(protocol.KeyPhaseBit)
+	ret3, _ := ret[3].([]byte)
+	ret4, _ := ret[4].(error)
+	return ret0, ret1, ret2, ret3, ret4
+}
+
+// UnpackShortHeaderOnPath indicates an expected call of UnpackShortHeaderOnPath.
+func (mr *MockUnpackerMockRecorder) UnpackShortHeaderOnPath(data, pathID, largestRcvd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpackShortHeaderOnPath", reflect.TypeOf((*MockUnpacker)(nil).UnpackShortHeaderOnPath), data, pathID, largestRcvd)
+}
@@ -12,6 +12,8 @@ import (
 type mtuDiscoverer interface {
 	ShouldSendProbe(now time.Time) bool
 	GetPing() (ping ackhandler.Frame, datagramSize protocol.ByteCount)
+	// CurrentSize returns the largest datagram size that was verified to work on the path.
+	CurrentSize() protocol.ByteCount
 }
 
 const (
@@ -55,6 +57,10 @@ func (f *mtuFinder) ShouldSendProbe(now time.Time) bool {
 	return !now.Before(f.lastProbeTime.Add(mtuProbeDelay * f.rttStats.SmoothedRTT()))
 }
 
+func (f *mtuFinder) CurrentSize() protocol.ByteCount {
+	return f.current
+}
+
 func (f *mtuFinder) GetPing() (ackhandler.Frame, protocol.ByteCount) {
 	size := (f.max + f.current) / 2
 	f.lastProbeTime = time.Now()
@@ -59,8 +59,10 @@ var _ = Describe("MTU Discoverer", func() {
 	It("tries a higher size and calls the callback when a probe is acknowledged", func() {
 		ping, size := d.GetPing()
 		Expect(size).To(Equal(protocol.ByteCount(1500)))
+		Expect(d.CurrentSize()).To(Equal(startMTU))
 		ping.OnAcked(ping.Frame)
 		Expect(discoveredMTU).To(Equal(protocol.ByteCount(1500)))
+		Expect(d.CurrentSize()).To(Equal(protocol.ByteCount(1500)))
 		_, size = d.GetPing()
 		Expect(size).To(Equal(protocol.ByteCount(1750)))
 	})
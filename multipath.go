@@ -0,0 +1,222 @@
+package quic
+
+import (
+	"errors"
+	"net"
+
+	"github.com/lucas-clemente/quic-go/internal/ackhandler"
+	"github.com/lucas-clemente/quic-go/internal/handshake"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// A path is a path of a multipath connection, see draft-ietf-quic-multipath.
+// Every path has its own packet number space, congestion controller and RTT estimate.
+// The initial path uses the connection's sent and received packet handlers.
+// All other paths are created when the client calls AddPath, or when the server
+// receives a packet using a new connection ID.
+type path struct {
+	initial bool
+
+	// sendID is the sequence number of the connection ID used for packets sent on this path,
+	// rcvID is the sequence number of the connection ID used by the peer.
+	// The client only learns rcvID when it receives the first packet on this path.
+	sendID     uint64
+	rcvID      uint64
+	rcvIDKnown bool
+
+	connID  protocol.ConnectionID
+	conn    sendConn
+	runner  connRunner // receives packets sent on this path, only set if the client added this path
+	rcvConn rawConn    // only set if the peer added this path
+
+	rttStats              *utils.RTTStats
+	sentPacketHandler     ackhandler.SentPacketHandler
+	receivedPacketHandler ackhandler.ReceivedPacketHandler
+	packer                packer
+	largestRcvdPN         protocol.PacketNumber
+
+	// The path is validated using a pathProbe.
+	// Once validated, the probe is kept around to send PATH_RESPONSE frames.
+	probe *pathProbe
+
+	status             PathStatus
+	statusSeq          uint64
+	peerStatus         PathStatus
+	peerStatusSeq      uint64
+	receivedPeerStatus bool
+	abandoned          bool
+
+	requests *pathQueue
+}
+
+var _ Path = &path{}
+
+func (p *path) LocalAddr() net.Addr  { return p.conn.LocalAddr() }
+func (p *path) RemoteAddr() net.Addr { return p.conn.RemoteAddr() }
+
+func (p *path) SetStatus(status PathStatus) error {
+	return p.requests.Do(&pathRequest{action: pathActionSetStatus, path: p, status: status})
+}
+
+func (p *path) Close() error {
+	return p.requests.Do(&pathRequest{action: pathActionClose, path: p})
+}
+
+// IsUsable says if the path was validated, and not abandoned.
+func (p *path) IsUsable() bool {
+	return !p.abandoned && (p.initial || p.probe.validated)
+}
+
+// IsStandby says if either endpoint marked this path as standby.
+func (p *path) IsStandby() bool {
+	return p.status == PathStatusStandby || p.peerStatus == PathStatusStandby
+}
+
+func (p *path) effectiveStatus() PathStatus {
+	if p.IsStandby() {
+		return PathStatusStandby
+	}
+	return PathStatusAvailable
+}
+
+func (p *path) Info() PathInfo {
+	return PathInfo{
+		LocalAddr:         p.conn.LocalAddr(),
+		RemoteAddr:        p.conn.RemoteAddr(),
+		Status:            p.effectiveStatus(),
+		SmoothedRTT:       p.rttStats.SmoothedRTT(),
+		BandwidthEstimate: p.sentPacketHandler.GetBandwidthEstimate(),
+	}
+}
+
+// pathSealingManager is the sealingManager used for additional paths.
+// Only 1-RTT packets are sent on additional paths,
+// and the path ID is used to compute the AEAD nonce.
+type pathSealingManager struct {
+	sealingManager sealingManager
+	pathID         uint64
+}
+
+var _ sealingManager = &pathSealingManager{}
+
+func (m *pathSealingManager) GetInitialSealer() (handshake.LongHeaderSealer, error) {
+	return nil, handshake.ErrKeysDropped
+}
+
+func (m *pathSealingManager) GetHandshakeSealer() (handshake.LongHeaderSealer, error) {
+	return nil, handshake.ErrKeysDropped
+}
+
+func (m *pathSealingManager) Get0RTTSealer() (handshake.LongHeaderSealer, error) {
+	return nil, handshake.ErrKeysDropped
+}
+
+func (m *pathSealingManager) Get1RTTSealer() (handshake.ShortHeaderSealer, error) {
+	sealer, err := m.sealingManager.Get1RTTSealer()
+	if err != nil {
+		return nil, err
+	}
+	return &pathSealer{ShortHeaderSealer: sealer, pathID: m.pathID}, nil
+}
+
+type pathSealer struct {
+	handshake.ShortHeaderSealer
+	pathID uint64
+}
+
+func (s *pathSealer) Seal(dst, src []byte, pn protocol.PacketNumber, ad []byte) []byte {
+	return s.SealOnPath(dst, src, s.pathID, pn, ad)
+}
+
+// The minRTTPathScheduler is the default PathScheduler.
+// It sends on the path with the lowest smoothed RTT.
+// Paths that don't have an RTT estimate yet are only used if no other path is available.
+type minRTTPathScheduler struct{}
+
+var _ PathScheduler = &minRTTPathScheduler{}
+
+func (s *minRTTPathScheduler) SelectPath(paths []PathInfo) int {
+	selected := -1
+	for i, p := range paths {
+		if selected == -1 {
+			selected = i
+			continue
+		}
+		rtt, minRTT := p.SmoothedRTT, paths[selected].SmoothedRTT
+		if rtt != 0 && (minRTT == 0 || rtt < minRTT) {
+			selected = i
+		}
+	}
+	return selected
+}
+
+type pathAction uint8
+
+const (
+	pathActionAdd pathAction = iota
+	pathActionSetStatus
+	pathActionClose
+)
+
+// A pathRequest is a request by the application to add a path, or to change or close an existing path.
+type pathRequest struct {
+	action pathAction
+	path   *path
+	status PathStatus
+	result chan error
+}
+
+// The pathQueue passes requests from the application to the connection's run loop.
+type pathQueue struct {
+	queue chan *pathRequest
+
+	closeErr error
+	closed   chan struct{}
+
+	hasData func()
+}
+
+func newPathQueue(hasData func()) *pathQueue {
+	return &pathQueue{
+		queue:   make(chan *pathRequest, 4),
+		closed:  make(chan struct{}),
+		hasData: hasData,
+	}
+}
+
+// Do queues a request, and blocks until it was handled.
+// When adding a path, it blocks until the path was either validated or path validation failed.
+func (q *pathQueue) Do(r *pathRequest) error {
+	r.result = make(chan error, 1)
+	select {
+	case q.queue <- r:
+		q.hasData()
+	case <-q.closed:
+		return q.closeErr
+	}
+
+	select {
+	case err := <-r.result:
+		return err
+	case <-q.closed:
+		return q.closeErr
+	}
+}
+
+// Dequeue gets the next request, if any.
+func (q *pathQueue) Dequeue() *pathRequest {
+	select {
+	case r := <-q.queue:
+		return r
+	default:
+		return nil
+	}
+}
+
+func (q *pathQueue) CloseWithError(e error) {
+	q.closeErr = e
+	close(q.closed)
+}
+
+var errPathClosed = errors.New("path closed")
@@ -0,0 +1,148 @@
+package quic
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lucas-clemente/quic-go/internal/handshake"
+	"github.com/lucas-clemente/quic-go/internal/mocks"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multipath", func() {
+	Context("min RTT path scheduler", func() {
+		var scheduler *minRTTPathScheduler
+
+		BeforeEach(func() {
+			scheduler = &minRTTPathScheduler{}
+		})
+
+		It("returns -1 if there are no paths", func() {
+			Expect(scheduler.SelectPath(nil)).To(Equal(-1))
+		})
+
+		It("selects the path with the lowest RTT", func() {
+			Expect(scheduler.SelectPath([]PathInfo{
+				{SmoothedRTT: 30 * time.Millisecond},
+				{SmoothedRTT: 10 * time.Millisecond},
+				{SmoothedRTT: 20 * time.Millisecond},
+			})).To(Equal(1))
+		})
+
+		It("only selects paths without an RTT estimate if there are no other paths", func() {
+			Expect(scheduler.SelectPath([]PathInfo{{}, {}})).To(BeZero())
+			Expect(scheduler.SelectPath([]PathInfo{{}, {SmoothedRTT: time.Second}})).To(Equal(1))
+			Expect(scheduler.SelectPath([]PathInfo{{SmoothedRTT: time.Second}, {}})).To(BeZero())
+		})
+	})
+
+	Context("path queue", func() {
+		var (
+			queue  *pathQueue
+			queued chan struct{}
+		)
+
+		BeforeEach(func() {
+			queued = make(chan struct{}, 100)
+			queue = newPathQueue(func() { queued <- struct{}{} })
+		})
+
+		It("returns nil when there's no request", func() {
+			Expect(queue.Dequeue()).To(BeNil())
+		})
+
+		It("queues a request and waits for the result", func() {
+			req := &pathRequest{action: pathActionClose}
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				Expect(queue.Do(req)).To(MatchError("request failed"))
+			}()
+
+			Eventually(queued).Should(HaveLen(1))
+			Expect(queue.Dequeue()).To(Equal(req))
+			Expect(queue.Dequeue()).To(BeNil())
+			Consistently(done).ShouldNot(BeClosed())
+			req.result <- errors.New("request failed")
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("returns the close error when the connection is closed", func() {
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				Expect(queue.Do(&pathRequest{action: pathActionAdd})).To(MatchError("test error"))
+			}()
+
+			Eventually(queued).Should(HaveLen(1))
+			Consistently(done).ShouldNot(BeClosed())
+			queue.CloseWithError(errors.New("test error"))
+			Eventually(done).Should(BeClosed())
+			Expect(queue.Do(&pathRequest{action: pathActionClose})).To(MatchError("test error"))
+		})
+	})
+
+	Context("path sealing manager", func() {
+		var (
+			sealingManager *MockSealingManager
+			m              *pathSealingManager
+		)
+
+		BeforeEach(func() {
+			sealingManager = NewMockSealingManager(mockCtrl)
+			m = &pathSealingManager{sealingManager: sealingManager, pathID: 3}
+		})
+
+		It("doesn't return long header sealers", func() {
+			_, err := m.GetInitialSealer()
+			Expect(err).To(MatchError(handshake.ErrKeysDropped))
+			_, err = m.GetHandshakeSealer()
+			Expect(err).To(MatchError(handshake.ErrKeysDropped))
+			_, err = m.Get0RTTSealer()
+			Expect(err).To(MatchError(handshake.ErrKeysDropped))
+		})
+
+		It("seals 1-RTT packets using the path ID", func() {
+			sealer := mocks.NewMockShortHeaderSealer(mockCtrl)
+			sealingManager.EXPECT().Get1RTTSealer().Return(sealer, nil)
+			s, err := m.Get1RTTSealer()
+			Expect(err).ToNot(HaveOccurred())
+			sealer.EXPECT().SealOnPath(gomock.Any(), []byte("foobar"), uint64(3), protocol.PacketNumber(42), []byte("ad")).Return([]byte("sealed"))
+			Expect(s.Seal(nil, []byte("foobar"), 42, []byte("ad"))).To(Equal([]byte("sealed")))
+		})
+
+		It("returns the error when getting the 1-RTT sealer fails", func() {
+			sealingManager.EXPECT().Get1RTTSealer().Return(nil, handshake.ErrKeysNotYetAvailable)
+			_, err := m.Get1RTTSealer()
+			Expect(err).To(MatchError(handshake.ErrKeysNotYetAvailable))
+		})
+	})
+
+	Context("path status", func() {
+		It("is standby if either endpoint marked it as standby", func() {
+			p := &path{initial: true}
+			Expect(p.IsStandby()).To(BeFalse())
+			Expect(p.effectiveStatus()).To(Equal(PathStatusAvailable))
+			p.peerStatus = PathStatusStandby
+			Expect(p.IsStandby()).To(BeTrue())
+			p.peerStatus = PathStatusAvailable
+			p.status = PathStatusStandby
+			Expect(p.effectiveStatus()).To(Equal(PathStatusStandby))
+		})
+
+		It("is only usable once validated", func() {
+			p := &path{probe: newPathProbe(nil, nil)}
+			Expect(p.IsUsable()).To(BeFalse())
+			p.probe.validated = true
+			Expect(p.IsUsable()).To(BeTrue())
+			p.abandoned = true
+			Expect(p.IsUsable()).To(BeFalse())
+		})
+	})
+})
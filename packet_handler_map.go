@@ -14,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -25,7 +26,11 @@ import (
 // rawConn is a connection that allow reading of a receivedPacket.
 type rawConn interface {
 	ReadPacket() (*receivedPacket, error)
-	WritePacket(b []byte, addr net.Addr, oob []byte) (int, error)
+	// WritePacket writes a packet. ecn must be protocol.ECNNon, unless the connection is ECN capable.
+	// If gsoSize is non-zero, b contains multiple packets of gsoSize bytes (the last one might be shorter),
+	// which are sent using GSO. This is only allowed if the connection is GSO capable.
+	WritePacket(b []byte, addr net.Addr, oob []byte, gsoSize uint16, ecn protocol.ECN) (int, error)
+	capabilities() connCapabilities
 	LocalAddr() net.Addr
 	io.Closer
 }
@@ -59,9 +64,14 @@ type packetHandlerMap struct {
 	deleteRetiredConnsAfter time.Duration
 	zeroRTTQueueDuration    time.Duration
 
-	statelessResetEnabled bool
+	// Set to 1 if stateless resets are enabled, i.e. if statelessResetHashers is not empty.
+	// Accessed atomically, so that the mutex doesn't need to be acquired if stateless resets are disabled.
+	statelessResetEnabled int32
 	statelessResetMutex   sync.Mutex
-	statelessResetHasher  hash.Hash
+	// The first hasher is used to generate new stateless reset tokens.
+	// When sending a stateless reset, one packet is sent for every hasher.
+	// Stateless resets are disabled if no hashers are set.
+	statelessResetHashers []hash.Hash
 
 	tracer logging.Tracer
 	logger utils.Logger
@@ -132,12 +142,12 @@ func newPacketHandlerMap(
 		deleteRetiredConnsAfter: protocol.RetiredConnectionIDDeleteTimeout,
 		zeroRTTQueueDuration:    protocol.Max0RTTQueueingDuration,
 		closeQueue:              make(chan closePacket, 4),
-		statelessResetEnabled:   statelessResetKey != nil,
 		tracer:                  tracer,
 		logger:                  logger,
 	}
-	if m.statelessResetEnabled {
-		m.statelessResetHasher = hmac.New(sha256.New, statelessResetKey[:])
+	if statelessResetKey != nil {
+		m.statelessResetHashers = []hash.Hash{hmac.New(sha256.New, statelessResetKey[:])}
+		m.statelessResetEnabled = 1
 	}
 	go m.listen()
 	go m.runCloseQueue()
@@ -278,7 +288,7 @@ func (h *packetHandlerMap) runCloseQueue() {
 		case <-h.listening:
 			return
 		case p := <-h.closeQueue:
-			h.conn.WritePacket(p.payload, p.addr, p.info.OOB())
+			h.conn.WritePacket(p.payload, p.addr, p.info.OOB(), 0, protocol.ECNNon)
 		}
 	}
 }
@@ -301,12 +311,11 @@ func (h *packetHandlerMap) SetServer(s unknownPacketHandler) {
 	h.mutex.Unlock()
 }
 
+// CloseServer closes all server connections.
+// This is also used for packet conns that don't have a server set,
+// e.g. for the packet conn that receives packets sent to the server's preferred address.
 func (h *packetHandlerMap) CloseServer() {
 	h.mutex.Lock()
-	if h.server == nil {
-		h.mutex.Unlock()
-		return
-	}
 	h.server = nil
 	var wg sync.WaitGroup
 	for _, handler := range h.handlers {
@@ -374,6 +383,7 @@ func (h *packetHandlerMap) listen() {
 			h.close(err)
 			return
 		}
+		p.rcvConn = h.conn
 		h.handlePacket(p)
 	}
 }
@@ -467,39 +477,84 @@ func (h *packetHandlerMap) maybeHandleStatelessReset(data []byte) bool {
 }
 
 func (h *packetHandlerMap) GetStatelessResetToken(connID protocol.ConnectionID) protocol.StatelessResetToken {
+	if atomic.LoadInt32(&h.statelessResetEnabled) == 1 {
+		h.statelessResetMutex.Lock()
+		defer h.statelessResetMutex.Unlock()
+		// The keys might have been removed in the meantime.
+		if len(h.statelessResetHashers) > 0 {
+			return getStatelessResetToken(h.statelessResetHashers[0], connID)
+		}
+	}
+	// Return a random stateless reset token.
+	// This token will be sent in the server's transport parameters.
+	// By using a random token, an off-path attacker won't be able to disrupt the connection.
+	var token protocol.StatelessResetToken
+	rand.Read(token[:])
+	return token
+}
+
+// must be called with the statelessResetMutex held
+func getStatelessResetToken(hasher hash.Hash, connID protocol.ConnectionID) protocol.StatelessResetToken {
 	var token protocol.StatelessResetToken
-	if !h.statelessResetEnabled {
-		// Return a random stateless reset token.
-		// This token will be sent in the server's transport parameters.
-		// By using a random token, an off-path attacker won't be able to disrupt the connection.
-		rand.Read(token[:])
-		return token
+	hasher.Write(connID.Bytes())
+	copy(token[:], hasher.Sum(nil))
+	hasher.Reset()
+	return token
+}
+
+// maxStatelessResetKeys is the maximum number of stateless reset keys: the current and the previous key.
+// A stateless reset is sent for every key, so this also limits the number of packets sent in response to a single packet.
+const maxStatelessResetKeys = 2
+
+// SetStatelessResetKeys replaces the keys used to derive stateless reset tokens.
+// At most maxStatelessResetKeys keys may be passed.
+func (h *packetHandlerMap) SetStatelessResetKeys(keys []StatelessResetKey) {
+	hashers := make([]hash.Hash, 0, len(keys))
+	for _, key := range keys {
+		hashers = append(hashers, hmac.New(sha256.New, key[:]))
+	}
+	var enabled int32
+	if len(hashers) > 0 {
+		enabled = 1
 	}
 	h.statelessResetMutex.Lock()
-	h.statelessResetHasher.Write(connID.Bytes())
-	copy(token[:], h.statelessResetHasher.Sum(nil))
-	h.statelessResetHasher.Reset()
+	h.statelessResetHashers = hashers
+	atomic.StoreInt32(&h.statelessResetEnabled, enabled)
 	h.statelessResetMutex.Unlock()
-	return token
 }
 
 func (h *packetHandlerMap) maybeSendStatelessReset(p *receivedPacket, connID protocol.ConnectionID) {
 	defer p.buffer.Release()
-	if !h.statelessResetEnabled {
-		return
-	}
 	// Don't send a stateless reset in response to very small packets.
 	// This includes packets that could be stateless resets.
 	if len(p.data) <= protocol.MinStatelessResetSize {
 		return
 	}
-	token := h.GetStatelessResetToken(connID)
-	h.logger.Debugf("Sending stateless reset to %s (connection ID: %s). Token: %#x", p.remoteAddr, connID, token)
-	data := make([]byte, protocol.MinStatelessResetSize-16, protocol.MinStatelessResetSize)
-	rand.Read(data)
-	data[0] = (data[0] & 0x7f) | 0x40
-	data = append(data, token[:]...)
-	if _, err := h.conn.WritePacket(data, p.remoteAddr, p.info.OOB()); err != nil {
-		h.logger.Debugf("Error sending Stateless Reset: %s", err)
+	if atomic.LoadInt32(&h.statelessResetEnabled) == 0 {
+		return
+	}
+	h.statelessResetMutex.Lock()
+	tokens := make([]protocol.StatelessResetToken, 0, len(h.statelessResetHashers))
+	for _, hasher := range h.statelessResetHashers {
+		tokens = append(tokens, getStatelessResetToken(hasher, connID))
+	}
+	h.statelessResetMutex.Unlock()
+	// If the keys were rotated, we don't know which key was used for this connection ID.
+	// Send one stateless reset for every key, the peer will ignore the ones with an invalid token.
+	// To avoid amplification, the stateless resets must be smaller in total than the packet that triggered them.
+	if n := (len(p.data) - 1) / protocol.MinStatelessResetSize; len(tokens) > n {
+		tokens = tokens[:n]
+	}
+	for _, token := range tokens {
+		h.logger.Debugf("Sending stateless reset to %s (connection ID: %s). Token: %#x", p.remoteAddr, connID, token)
+		data := make([]byte, protocol.MinStatelessResetSize-16, protocol.MinStatelessResetSize)
+		rand.Read(data)
+		// Always set the QUIC bit, even though most peers advertise grease_quic_bit (RFC 9287):
+		// The connection state is gone, so we don't know if this peer did.
+		data[0] = (data[0] & 0x7f) | 0x40
+		data = append(data, token[:]...)
+		if _, err := h.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNNon); err != nil {
+			h.logger.Debugf("Error sending Stateless Reset: %s", err)
+		}
 	}
 }
@@ -263,6 +263,14 @@ var _ = Describe("Packet Handler Map", func() {
 				handler.CloseServer()
 			})
 
+			It("closes all server connections, if no server is set", func() {
+				serverConn := NewMockPacketHandler(mockCtrl)
+				serverConn.EXPECT().getPerspective().Return(protocol.PerspectiveServer)
+				serverConn.EXPECT().shutdown()
+				handler.Add(protocol.ParseConnectionID([]byte{2, 2, 2, 2}), serverConn)
+				handler.CloseServer()
+			})
+
 			It("stops handling packets with unknown connection IDs after the server is closed", func() {
 				connID := protocol.ParseConnectionID([]byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88})
 				p := getPacket(connID)
@@ -468,6 +476,69 @@ var _ = Describe("Packet Handler Map", func() {
 					Eventually(done).Should(BeClosed())
 				})
 
+				It("uses the new key after rotating the keys", func() {
+					connID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef})
+					token := handler.GetStatelessResetToken(connID)
+					Expect(handler.GetStatelessResetToken(connID)).To(Equal(token))
+					handler.SetStatelessResetKeys([]StatelessResetKey{{1, 2, 3}, *statelessResetKey})
+					Expect(handler.GetStatelessResetToken(connID)).ToNot(Equal(token))
+				})
+
+				It("uses random tokens after removing all keys", func() {
+					connID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef})
+					token := handler.GetStatelessResetToken(connID)
+					Expect(handler.GetStatelessResetToken(connID)).To(Equal(token))
+					handler.SetStatelessResetKeys(nil)
+					token1 := handler.GetStatelessResetToken(connID)
+					Expect(token1).ToNot(Equal(token))
+					Expect(handler.GetStatelessResetToken(connID)).ToNot(Equal(token1))
+				})
+
+				It("sends a stateless reset for every key", func() {
+					connID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef, 0x42})
+					oldToken := handler.GetStatelessResetToken(connID)
+					handler.SetStatelessResetKeys([]StatelessResetKey{{1, 2, 3}, *statelessResetKey})
+					newToken := handler.GetStatelessResetToken(connID)
+					addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+					p := append([]byte{0x40}, connID.Bytes()...)
+					p = append(p, make([]byte, 100)...)
+					tokens := make(chan protocol.StatelessResetToken, 2)
+					conn.EXPECT().WriteTo(gomock.Any(), addr).Do(func(b []byte, _ net.Addr) {
+						var token protocol.StatelessResetToken
+						copy(token[:], b[len(b)-16:])
+						tokens <- token
+					}).Times(2)
+					handler.handlePacket(&receivedPacket{
+						buffer:     getPacketBuffer(),
+						remoteAddr: addr,
+						data:       p,
+					})
+					Eventually(tokens).Should(Receive(Equal(newToken)))
+					Eventually(tokens).Should(Receive(Equal(oldToken)))
+				})
+
+				It("only sends as many stateless resets as are smaller in total than the packet that triggered them", func() {
+					connID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef, 0x42})
+					handler.SetStatelessResetKeys([]StatelessResetKey{{1, 2, 3}, *statelessResetKey})
+					newToken := handler.GetStatelessResetToken(connID)
+					addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+					p := append([]byte{0x40}, connID.Bytes()...)
+					p = append(p, make([]byte, 2*protocol.MinStatelessResetSize-len(p))...)
+					done := make(chan struct{})
+					conn.EXPECT().WriteTo(gomock.Any(), addr).Do(func(b []byte, _ net.Addr) {
+						defer close(done)
+						Expect(b[len(b)-16:]).To(Equal(newToken[:]))
+					})
+					handler.handlePacket(&receivedPacket{
+						buffer:     getPacketBuffer(),
+						remoteAddr: addr,
+						data:       p,
+					})
+					Eventually(done).Should(BeClosed())
+					// make sure there are no further Write calls on the packet conn
+					time.Sleep(50 * time.Millisecond)
+				})
+
 				It("doesn't send stateless resets for small packets", func() {
 					addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
 					p := append([]byte{40}, make([]byte, protocol.MinStatelessResetSize-2)...)
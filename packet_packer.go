@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	mrand "math/rand"
 	"net"
 	"time"
 
@@ -18,15 +19,18 @@ import (
 type packer interface {
 	PackCoalescedPacket(onlyAck bool) (*coalescedPacket, error)
 	PackPacket(onlyAck bool) (*packedPacket, error)
+	AppendPacket(buf *packetBuffer, maxPacketSize protocol.ByteCount, onlyAck bool) (*packetContents, error)
 	MaybePackProbePacket(protocol.EncryptionLevel) (*packedPacket, error)
 	PackConnectionClose(*qerr.TransportError) (*coalescedPacket, error)
 	PackApplicationClose(*qerr.ApplicationError) (*coalescedPacket, error)
 
 	SetMaxPacketSize(protocol.ByteCount)
 	PackMTUProbePacket(ping ackhandler.Frame, size protocol.ByteCount) (*packedPacket, error)
+	PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount) (*packedPacket, error)
 
 	HandleTransportParameters(*wire.TransportParameters)
 	SetToken([]byte)
+	SetVersion(protocol.VersionNumber)
 }
 
 type sealer interface {
@@ -168,6 +172,16 @@ type packetPacker struct {
 
 	maxPacketSize          protocol.ByteCount
 	numNonAckElicitingAcks int
+
+	// Set if the peer sent the grease_quic_bit transport parameter.
+	// The fixed bit is then set randomly on every 1-RTT packet.
+	greaseQUICBit bool
+	// Only used for greasing. It doesn't need to be cryptographically secure.
+	rand *mrand.Rand
+
+	// Only set for packers of additional paths of a multipath connection.
+	// Acknowledgements are then sent in ACK_MP frames, using this path identifier.
+	ackMPIdentifier *uint64
 }
 
 var _ packer = &packetPacker{}
@@ -201,6 +215,7 @@ func newPacketPacker(
 		acks:                acks,
 		pnManager:           packetNumberManager,
 		maxPacketSize:       getMaxPacketSize(remoteAddr),
+		rand:                mrand.New(mrand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -438,17 +453,10 @@ func (p *packetPacker) PackCoalescedPacket(onlyAck bool) (*coalescedPacket, erro
 // PackPacket packs a packet in the application data packet number space.
 // It should be called after the handshake is confirmed.
 func (p *packetPacker) PackPacket(onlyAck bool) (*packedPacket, error) {
-	sealer, err := p.cryptoSetup.Get1RTTSealer()
-	if err != nil {
-		return nil, err
-	}
-	hdr, payload := p.maybeGetShortHeaderPacket(sealer, p.maxPacketSize, onlyAck, true)
-	if payload == nil {
-		return nil, nil
-	}
 	buffer := getPacketBuffer()
-	cont, err := p.appendPacket(buffer, hdr, payload, 0, protocol.Encryption1RTT, sealer, false)
-	if err != nil {
+	cont, err := p.AppendPacket(buffer, p.maxPacketSize, onlyAck)
+	if err != nil || cont == nil {
+		buffer.Release()
 		return nil, err
 	}
 	return &packedPacket{
@@ -457,12 +465,28 @@ func (p *packetPacker) PackPacket(onlyAck bool) (*packedPacket, error) {
 	}, nil
 }
 
+// AppendPacket packs a packet in the application data packet number space, and appends it to buf.
+// The packet is at most maxPacketSize bytes large (but never larger than the maximum packet size of the connection).
+// It returns nil if there's nothing to send.
+// It should be called after the handshake is confirmed.
+func (p *packetPacker) AppendPacket(buf *packetBuffer, maxPacketSize protocol.ByteCount, onlyAck bool) (*packetContents, error) {
+	sealer, err := p.cryptoSetup.Get1RTTSealer()
+	if err != nil {
+		return nil, err
+	}
+	hdr, payload := p.maybeGetShortHeaderPacket(sealer, utils.Min(maxPacketSize, p.maxPacketSize), onlyAck, true)
+	if payload == nil {
+		return nil, nil
+	}
+	return p.appendPacket(buf, hdr, payload, 0, protocol.Encryption1RTT, sealer, false)
+}
+
 func (p *packetPacker) maybeGetCryptoPacket(maxPacketSize protocol.ByteCount, encLevel protocol.EncryptionLevel, onlyAck, ackAllowed bool) (*wire.ExtendedHeader, *payload) {
 	if onlyAck {
 		if ack := p.acks.GetAckFrame(encLevel, true); ack != nil {
 			var payload payload
 			payload.ack = ack
-			payload.length = ack.Length(p.version)
+			payload.length = p.ackLength(ack)
 			return p.getLongHeader(encLevel), &payload
 		}
 		return nil, nil
@@ -493,7 +517,7 @@ func (p *packetPacker) maybeGetCryptoPacket(maxPacketSize protocol.ByteCount, en
 	var payload payload
 	if ack != nil {
 		payload.ack = ack
-		payload.length = ack.Length(p.version)
+		payload.length = p.ackLength(ack)
 		maxPacketSize -= payload.length
 	}
 	hdr := p.getLongHeader(encLevel)
@@ -571,7 +595,7 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 		if ack := p.acks.GetAckFrame(protocol.Encryption1RTT, true); ack != nil {
 			payload := &payload{}
 			payload.ack = ack
-			payload.length += ack.Length(p.version)
+			payload.length += p.ackLength(ack)
 			return payload
 		}
 		return &payload{}
@@ -586,7 +610,7 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 	if ackAllowed {
 		if ack := p.acks.GetAckFrame(protocol.Encryption1RTT, !hasRetransmission && !hasData); ack != nil {
 			payload.ack = ack
-			payload.length += ack.Length(p.version)
+			payload.length += p.ackLength(ack)
 			hasAck = true
 		}
 	}
@@ -595,13 +619,8 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 		if f := p.datagramQueue.Peek(); f != nil {
 			size := f.Length(p.version)
 			if size <= maxFrameSize-payload.length {
-				payload.frames = append(payload.frames, ackhandler.Frame{
-					Frame: f,
-					// set it to a no-op. Then we won't set the default callback, which would retransmit the frame.
-					OnLost: func(wire.Frame) {},
-				})
+				payload.frames = append(payload.frames, p.datagramQueue.Pop())
 				payload.length += size
-				p.datagramQueue.Pop()
 			}
 		}
 	}
@@ -709,6 +728,36 @@ func (p *packetPacker) PackMTUProbePacket(ping ackhandler.Frame, size protocol.B
 	}, nil
 }
 
+// PackPathProbePacket packs a packet containing PATH_CHALLENGE and / or PATH_RESPONSE frames, which is sent on a new path.
+// It uses the connection ID that was chosen for this path, and is padded to size.
+// Usually, this is the minimum size that a path is required to support (see section 8.2.1 of RFC 9000),
+// but it might be smaller if sending is limited by the anti-amplification limit.
+func (p *packetPacker) PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount) (*packedPacket, error) {
+	payload := &payload{frames: frames}
+	for _, f := range frames {
+		payload.length += f.Length(p.version)
+	}
+	buffer := getPacketBuffer()
+	sealer, err := p.cryptoSetup.Get1RTTSealer()
+	if err != nil {
+		return nil, err
+	}
+	hdr := p.getShortHeader(sealer.KeyPhase())
+	hdr.DestConnectionID = connID
+	var padding protocol.ByteCount
+	if length := p.packetLength(hdr, payload) + protocol.ByteCount(sealer.Overhead()); length < size {
+		padding = size - length
+	}
+	contents, err := p.appendPacket(buffer, hdr, payload, padding, protocol.Encryption1RTT, sealer, false)
+	if err != nil {
+		return nil, err
+	}
+	return &packedPacket{
+		buffer:         buffer,
+		packetContents: contents,
+	}, nil
+}
+
 func (p *packetPacker) getShortHeader(kp protocol.KeyPhaseBit) *wire.ExtendedHeader {
 	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
 	hdr := &wire.ExtendedHeader{}
@@ -761,10 +810,13 @@ func (p *packetPacker) appendPacket(buffer *packetBuffer, header *wire.ExtendedH
 	}
 	payloadOffset := buf.Len()
 	raw := buffer.Data[:payloadOffset]
+	if p.greaseQUICBit && !header.IsLongHeader && p.rand.Intn(2) == 0 {
+		raw[hdrOffset] &^= 0x40
+	}
 
 	if payload.ack != nil {
 		var err error
-		raw, err = payload.ack.Append(raw, p.version)
+		raw, err = p.appendAck(raw, payload.ack)
 		if err != nil {
 			return nil, err
 		}
@@ -784,7 +836,7 @@ func (p *packetPacker) appendPacket(buffer *packetBuffer, header *wire.ExtendedH
 		return nil, fmt.Errorf("PacketPacker BUG: payload size inconsistent (expected %d, got %d bytes)", payload.length, payloadSize)
 	}
 	if !isMTUProbePacket {
-		if size := protocol.ByteCount(len(raw) + sealer.Overhead()); size > p.maxPacketSize {
+		if size := protocol.ByteCount(len(raw)+sealer.Overhead()) - hdrOffset; size > p.maxPacketSize {
 			return nil, fmt.Errorf("PacketPacker BUG: packet too large (%d bytes, allowed %d bytes)", size, p.maxPacketSize)
 		}
 	}
@@ -809,10 +861,29 @@ func (p *packetPacker) appendPacket(buffer *packetBuffer, header *wire.ExtendedH
 	}, nil
 }
 
+func (p *packetPacker) ackLength(ack *wire.AckFrame) protocol.ByteCount {
+	if p.ackMPIdentifier != nil {
+		return (&wire.AckMPFrame{PathIdentifier: *p.ackMPIdentifier, AckFrame: ack}).Length(p.version)
+	}
+	return ack.Length(p.version)
+}
+
+func (p *packetPacker) appendAck(b []byte, ack *wire.AckFrame) ([]byte, error) {
+	if p.ackMPIdentifier != nil {
+		return (&wire.AckMPFrame{PathIdentifier: *p.ackMPIdentifier, AckFrame: ack}).Append(b, p.version)
+	}
+	return ack.Append(b, p.version)
+}
+
 func (p *packetPacker) SetToken(token []byte) {
 	p.token = token
 }
 
+// SetVersion is used when switching to a compatible version during the handshake.
+func (p *packetPacker) SetVersion(v protocol.VersionNumber) {
+	p.version = v
+}
+
 // When a higher MTU is discovered, use it.
 func (p *packetPacker) SetMaxPacketSize(s protocol.ByteCount) {
 	p.maxPacketSize = s
@@ -824,4 +895,5 @@ func (p *packetPacker) HandleTransportParameters(params *wire.TransportParameter
 	if params.MaxUDPPayloadSize != 0 {
 		p.maxPacketSize = utils.Min(p.maxPacketSize, params.MaxUDPPayloadSize)
 	}
+	p.greaseQUICBit = params.GreaseQUICBit
 }
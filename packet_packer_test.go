@@ -90,7 +90,7 @@ var _ = Describe("Packet packer", func() {
 		ackFramer = NewMockAckFrameSource(mockCtrl)
 		sealingManager = NewMockSealingManager(mockCtrl)
 		pnManager = mockackhandler.NewMockSentPacketHandler(mockCtrl)
-		datagramQueue = newDatagramQueue(func() {}, utils.DefaultLogger)
+		datagramQueue = newDatagramQueue(func() {}, 0, DatagramDropOldest, utils.DefaultLogger)
 
 		packer = newPacketPacker(
 			protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
@@ -298,6 +298,24 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.frames).To(BeEmpty())
 				parsePacket(p.buffer.Data)
 			})
+
+			It("packs ACK_MP frames, for additional paths", func() {
+				pathID := uint64(3)
+				packer.ackMPIdentifier = &pathID
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 10}}}
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(ack)
+				p, err := packer.PackPacket(true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(p).ToNot(BeNil())
+				Expect(p.ack).To(Equal(ack))
+				parsePacket(p.buffer.Data)
+				b, err := (&wire.AckMPFrame{PathIdentifier: 3, AckFrame: ack}).Append(nil, version)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(bytes.Contains(p.buffer.Data, b)).To(BeTrue())
+			})
 		})
 
 		Context("packing 0-RTT packets", func() {
@@ -532,6 +550,30 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.buffer.Data).To(ContainSubstring(string(b)))
 			})
 
+			It("appends packets to a buffer, respecting the maximum packet size", func() {
+				buf := getLargePacketBuffer()
+				// the buffer already contains a full-size packet
+				buf.Data = append(buf.Data, bytes.Repeat([]byte{'f'}, int(maxPacketSize))...)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				framer.EXPECT().HasData().Return(true)
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+				expectAppendControlFrames()
+				f := &wire.StreamFrame{StreamID: 5, Data: make([]byte, 2000), DataLenPresent: true}
+				framer.EXPECT().AppendStreamFrames(gomock.Any(), gomock.Any()).DoAndReturn(func(fs []ackhandler.Frame, maxSize protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount) {
+					sf, split := f.MaybeSplitOffFrame(maxSize, packer.version)
+					Expect(split).To(BeTrue())
+					return append(fs, ackhandler.Frame{Frame: sf}), sf.Length(packer.version)
+				})
+				p, err := packer.AppendPacket(buf, 1000, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p).ToNot(BeNil())
+				Expect(p.length).To(BeEquivalentTo(1000))
+				Expect(buf.Data[:maxPacketSize]).To(Equal(bytes.Repeat([]byte{'f'}, int(maxPacketSize))))
+				Expect(buf.Len()).To(BeEquivalentTo(maxPacketSize + 1000))
+			})
+
 			It("stores the encryption level a packet was sealed with", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
@@ -593,7 +635,7 @@ var _ = Describe("Packet packer", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					datagramQueue.AddAndWait(f)
+					datagramQueue.AddAndWait(f, nil, nil)
 				}()
 				// make sure the DATAGRAM has actually been queued
 				time.Sleep(scaleDuration(20 * time.Millisecond))
@@ -621,7 +663,7 @@ var _ = Describe("Packet packer", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					datagramQueue.AddAndWait(f)
+					datagramQueue.AddAndWait(f, nil, nil)
 				}()
 				// make sure the DATAGRAM has actually been queued
 				time.Sleep(scaleDuration(20 * time.Millisecond))
@@ -689,7 +731,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(secondPayloadByte).To(Equal(byte(0)))
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false, packer.version)
+				frameParser := wire.NewFrameParser(false, false, false, false, packer.version)
 				l, frame, err := frameParser.ParseNext(data[len(data)-r.Len():], protocol.Encryption1RTT)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -727,7 +769,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(firstPayloadByte).To(Equal(byte(0)))
 				// ... followed by the STREAM frame
-				frameParser := wire.NewFrameParser(true, packer.version)
+				frameParser := wire.NewFrameParser(true, false, false, false, packer.version)
 				l, frame, err := frameParser.ParseNext(packet.buffer.Data[len(data)-r.Len():], protocol.Encryption1RTT)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.StreamFrame{}))
@@ -925,6 +967,34 @@ var _ = Describe("Packet packer", func() {
 					_, err = packer.PackPacket(false)
 					Expect(err).ToNot(HaveOccurred())
 				})
+
+				It("greases the QUIC bit, if the peer supports it", func() {
+					const num = 100
+					pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2).Times(2 * num)
+					pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42)).Times(2 * num)
+					sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil).Times(2 * num)
+					framer.EXPECT().HasData().Return(true).Times(2 * num)
+					ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false).Times(2 * num)
+					framer.EXPECT().AppendControlFrames(gomock.Any(), gomock.Any()).Return([]ackhandler.Frame{{Frame: &wire.PingFrame{}}}, protocol.ByteCount(1)).Times(2 * num)
+					framer.EXPECT().AppendStreamFrames(gomock.Any(), gomock.Any()).DoAndReturn(func(fs []ackhandler.Frame, _ protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount) {
+						return fs, 0
+					}).Times(2 * num)
+					for i := 0; i < num; i++ {
+						p, err := packer.PackPacket(false)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(p.buffer.Data[0] & 0x40).ToNot(BeZero())
+					}
+					packer.HandleTransportParameters(&wire.TransportParameters{GreaseQUICBit: true})
+					var numCleared int
+					for i := 0; i < num; i++ {
+						p, err := packer.PackPacket(false)
+						Expect(err).ToNot(HaveOccurred())
+						if p.buffer.Data[0]&0x40 == 0 {
+							numCleared++
+						}
+					}
+					Expect(numCleared).To(And(BeNumerically(">", num/10), BeNumerically("<", num*9/10)))
+				})
 			})
 
 			Context("max packet size", func() {
@@ -1026,6 +1096,27 @@ var _ = Describe("Packet packer", func() {
 				parsePacket(p.buffer.Data)
 			})
 
+			It("doesn't grease the QUIC bit of Handshake packets", func() {
+				packer.HandleTransportParameters(&wire.TransportParameters{GreaseQUICBit: true})
+				const num = 50
+				pnManager.EXPECT().PeekPacketNumber(protocol.EncryptionHandshake).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2).Times(num)
+				pnManager.EXPECT().PopPacketNumber(protocol.EncryptionHandshake).Return(protocol.PacketNumber(0x42)).Times(num)
+				sealingManager.EXPECT().GetInitialSealer().Return(getSealer(), nil).Times(num)
+				sealingManager.EXPECT().GetHandshakeSealer().Return(getSealer(), nil).Times(num)
+				sealingManager.EXPECT().Get1RTTSealer().Return(nil, handshake.ErrKeysNotYetAvailable).Times(num)
+				ackFramer.EXPECT().GetAckFrame(protocol.EncryptionInitial, true).Times(num)
+				ackFramer.EXPECT().GetAckFrame(protocol.EncryptionHandshake, false).Times(num)
+				initialStream.EXPECT().HasData().Times(num)
+				handshakeStream.EXPECT().HasData().Return(true).Times(2 * num)
+				handshakeStream.EXPECT().PopCryptoFrame(gomock.Any()).Return(&wire.CryptoFrame{Data: []byte("foobar")}).Times(num)
+				for i := 0; i < num; i++ {
+					p, err := packer.PackCoalescedPacket(false)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(p.packets).To(HaveLen(1))
+					Expect(p.buffer.Data[0] & 0x40).ToNot(BeZero())
+				}
+			})
+
 			It("packs a coalesced packet with Initial / Handshake, and pads it", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.EncryptionInitial).Return(protocol.PacketNumber(0x24), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.EncryptionInitial).Return(protocol.PacketNumber(0x24))
@@ -1281,7 +1372,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(secondPayloadByte).To(Equal(byte(0)))
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false, packer.version)
+				frameParser := wire.NewFrameParser(false, false, false, false, packer.version)
 				l, frame, err := frameParser.ParseNext(data[len(data)-r.Len():], protocol.Encryption1RTT)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -1554,6 +1645,38 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.buffer.Data).To(HaveLen(int(probePacketSize)))
 				Expect(p.packetContents.isMTUProbePacket).To(BeTrue())
 			})
+
+			It("packs a path probe packet", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad})
+				frames := []ackhandler.Frame{
+					{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}},
+					{Frame: &wire.PathResponseFrame{Data: [8]byte{8, 7, 6, 5, 4, 3, 2, 1}}},
+				}
+				p, err := packer.PackPathProbePacket(connID, frames, protocol.MinInitialPacketSize)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.header.IsLongHeader).To(BeFalse())
+				Expect(p.header.DestConnectionID).To(Equal(connID))
+				Expect(p.header.PacketNumber).To(Equal(protocol.PacketNumber(0x43)))
+				Expect(p.EncryptionLevel()).To(Equal(protocol.Encryption1RTT))
+				Expect(p.frames).To(Equal(frames))
+				Expect(p.buffer.Data).To(HaveLen(protocol.MinInitialPacketSize))
+				Expect(p.packetContents.isMTUProbePacket).To(BeFalse())
+			})
+
+			It("packs a smaller path probe packet", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad})
+				challenge := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+				p, err := packer.PackPathProbePacket(connID, []ackhandler.Frame{challenge}, 100)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.buffer.Data).To(HaveLen(100))
+				Expect(p.frames).To(Equal([]ackhandler.Frame{challenge}))
+			})
 		})
 	})
 })
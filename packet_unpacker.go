@@ -39,15 +39,19 @@ type packetUnpacker struct {
 
 	shortHdrConnIDLen int
 	version           protocol.VersionNumber
+	// Set if we advertised the grease_quic_bit transport parameter.
+	// 1-RTT packets with the QUIC bit set to 0 are then accepted.
+	acceptGreasedQUICBit bool
 }
 
 var _ unpacker = &packetUnpacker{}
 
-func newPacketUnpacker(cs handshake.CryptoSetup, shortHdrConnIDLen int, version protocol.VersionNumber) unpacker {
+func newPacketUnpacker(cs handshake.CryptoSetup, shortHdrConnIDLen int, version protocol.VersionNumber, acceptGreasedQUICBit bool) unpacker {
 	return &packetUnpacker{
-		cs:                cs,
-		shortHdrConnIDLen: shortHdrConnIDLen,
-		version:           version,
+		cs:                   cs,
+		shortHdrConnIDLen:    shortHdrConnIDLen,
+		version:              version,
+		acceptGreasedQUICBit: acceptGreasedQUICBit,
 	}
 }
 
@@ -63,7 +67,7 @@ func (u *packetUnpacker) UnpackLongHeader(hdr *wire.Header, rcvTime time.Time, d
 	switch hdr.Type {
 	case protocol.PacketTypeInitial:
 		encLevel = protocol.EncryptionInitial
-		opener, err := u.cs.GetInitialOpener()
+		opener, err := u.cs.GetInitialOpener(hdr.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -127,6 +131,38 @@ func (u *packetUnpacker) UnpackShortHeader(rcvTime time.Time, data []byte) (prot
 	return pn, pnLen, kp, decrypted, nil
 }
 
+// UnpackShortHeaderOnPath unpacks a short header packet received on an additional path of a multipath connection.
+// Every path uses its own packet number space.
+// The packet number is decoded based on the largest packet number received on this path.
+func (u *packetUnpacker) UnpackShortHeaderOnPath(data []byte, pathID uint64, largestRcvd protocol.PacketNumber) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
+	opener, err := u.cs.Get1RTTOpener()
+	if err != nil {
+		return 0, 0, 0, nil, err
+	}
+	l, pn, pnLen, kp, parseErr := u.unpackShortHeader(opener, data)
+	// If the reserved bits are set incorrectly, we still need to continue unpacking.
+	// This avoids a timing side-channel, which otherwise might allow an attacker
+	// to gain information about the header encryption.
+	if parseErr != nil && parseErr != wire.ErrInvalidReservedBits {
+		return 0, 0, 0, nil, &headerParseError{parseErr}
+	}
+	pn = protocol.DecodePacketNumber(pnLen, largestRcvd, pn)
+	decrypted, err := opener.OpenOnPath(data[l:l], data[l:], pathID, pn, kp, data[:l])
+	if err != nil {
+		return 0, 0, 0, nil, err
+	}
+	if parseErr != nil {
+		return 0, 0, 0, nil, parseErr
+	}
+	if len(decrypted) == 0 {
+		return 0, 0, 0, nil, &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: "empty packet",
+		}
+	}
+	return pn, pnLen, kp, decrypted, nil
+}
+
 func (u *packetUnpacker) unpackLongHeaderPacket(opener handshake.LongHeaderOpener, hdr *wire.Header, data []byte) (*wire.ExtendedHeader, []byte, error) {
 	extHdr, parseErr := u.unpackLongHeader(opener, hdr, data)
 	// If the reserved bits are set incorrectly, we still need to continue unpacking.
@@ -177,7 +213,7 @@ func (u *packetUnpacker) unpackShortHeader(hd headerDecryptor, data []byte) (int
 		data[hdrLen:hdrLen+4],
 	)
 	// 3. parse the header (and learn the actual length of the packet number)
-	l, pn, pnLen, kp, parseErr := wire.ParseShortHeader(data, u.shortHdrConnIDLen)
+	l, pn, pnLen, kp, parseErr := wire.ParseShortHeader(data, u.shortHdrConnIDLen, u.acceptGreasedQUICBit)
 	if parseErr != nil && parseErr != wire.ErrInvalidReservedBits {
 		return l, pn, pnLen, kp, parseErr
 	}
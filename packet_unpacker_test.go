@@ -41,7 +41,7 @@ var _ = Describe("Packet Unpacker", func() {
 
 	BeforeEach(func() {
 		cs = mocks.NewMockCryptoSetup(mockCtrl)
-		unpacker = newPacketUnpacker(cs, 4, version).(*packetUnpacker)
+		unpacker = newPacketUnpacker(cs, 4, version, true).(*packetUnpacker)
 	})
 
 	It("errors when the packet is too small to obtain the header decryption sample, for long headers", func() {
@@ -95,7 +95,7 @@ var _ = Describe("Packet Unpacker", func() {
 		hdr, hdrRaw := getHeader(extHdr)
 		opener := mocks.NewMockLongHeaderOpener(mockCtrl)
 		gomock.InOrder(
-			cs.EXPECT().GetInitialOpener().Return(opener, nil),
+			cs.EXPECT().GetInitialOpener(hdr.Version).Return(opener, nil),
 			opener.EXPECT().DecryptHeader(gomock.Any(), gomock.Any(), gomock.Any()),
 			opener.EXPECT().DecodePacketNumber(protocol.PacketNumber(2), protocol.PacketNumberLen3).Return(protocol.PacketNumber(1234)),
 			opener.EXPECT().Open(gomock.Any(), payload, protocol.PacketNumber(1234), hdrRaw).Return([]byte("decrypted"), nil),
@@ -156,6 +156,67 @@ var _ = Describe("Packet Unpacker", func() {
 		Expect(data).To(Equal([]byte("decrypted")))
 	})
 
+	It("opens short header packets with a greased QUIC bit", func() {
+		extHdr := &wire.ExtendedHeader{
+			Header:          wire.Header{DestConnectionID: connID},
+			PacketNumber:    99,
+			PacketNumberLen: protocol.PacketNumberLen4,
+		}
+		_, hdrRaw := getHeader(extHdr)
+		hdrRaw[0] &^= 0x40
+		opener := mocks.NewMockShortHeaderOpener(mockCtrl)
+		now := time.Now()
+		gomock.InOrder(
+			cs.EXPECT().Get1RTTOpener().Return(opener, nil),
+			opener.EXPECT().DecryptHeader(gomock.Any(), gomock.Any(), gomock.Any()),
+			opener.EXPECT().DecodePacketNumber(protocol.PacketNumber(99), protocol.PacketNumberLen4).Return(protocol.PacketNumber(321)),
+			opener.EXPECT().Open(gomock.Any(), payload, now, protocol.PacketNumber(321), protocol.KeyPhaseZero, hdrRaw).Return([]byte("decrypted"), nil),
+		)
+		pn, _, _, data, err := unpacker.UnpackShortHeader(now, append(hdrRaw, payload...))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pn).To(Equal(protocol.PacketNumber(321)))
+		Expect(data).To(Equal([]byte("decrypted")))
+	})
+
+	It("rejects short header packets with a greased QUIC bit, if we didn't advertise grease_quic_bit", func() {
+		unpacker = newPacketUnpacker(cs, 4, version, false).(*packetUnpacker)
+		extHdr := &wire.ExtendedHeader{
+			Header:          wire.Header{DestConnectionID: connID},
+			PacketNumber:    99,
+			PacketNumberLen: protocol.PacketNumberLen4,
+		}
+		_, hdrRaw := getHeader(extHdr)
+		hdrRaw[0] &^= 0x40
+		opener := mocks.NewMockShortHeaderOpener(mockCtrl)
+		cs.EXPECT().Get1RTTOpener().Return(opener, nil)
+		opener.EXPECT().DecryptHeader(gomock.Any(), gomock.Any(), gomock.Any())
+		_, _, _, _, err := unpacker.UnpackShortHeader(time.Now(), append(hdrRaw, payload...))
+		Expect(err).To(BeAssignableToTypeOf(&headerParseError{}))
+		Expect(err).To(MatchError(ContainSubstring("not a QUIC packet")))
+	})
+
+	It("opens short header packets sent on additional paths", func() {
+		extHdr := &wire.ExtendedHeader{
+			Header:          wire.Header{DestConnectionID: connID},
+			KeyPhase:        protocol.KeyPhaseZero,
+			PacketNumber:    0x1337,
+			PacketNumberLen: protocol.PacketNumberLen2,
+		}
+		_, hdrRaw := getHeader(extHdr)
+		opener := mocks.NewMockShortHeaderOpener(mockCtrl)
+		gomock.InOrder(
+			cs.EXPECT().Get1RTTOpener().Return(opener, nil),
+			opener.EXPECT().DecryptHeader(gomock.Any(), gomock.Any(), gomock.Any()),
+			opener.EXPECT().OpenOnPath(gomock.Any(), payload, uint64(2), protocol.PacketNumber(0x1337), protocol.KeyPhaseZero, hdrRaw).Return([]byte("decrypted"), nil),
+		)
+		pn, pnLen, kp, data, err := unpacker.UnpackShortHeaderOnPath(append(hdrRaw, payload...), 2, 0x1300)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pn).To(Equal(protocol.PacketNumber(0x1337)))
+		Expect(pnLen).To(Equal(protocol.PacketNumberLen2))
+		Expect(kp).To(Equal(protocol.KeyPhaseZero))
+		Expect(data).To(Equal([]byte("decrypted")))
+	})
+
 	It("returns the error when getting the opener fails", func() {
 		extHdr := &wire.ExtendedHeader{
 			Header:          wire.Header{DestConnectionID: connID},
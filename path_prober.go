@@ -0,0 +1,191 @@
+package quic
+
+import (
+	"net"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// maxUnpaddedPathProbeSize is the maximum size of a packet sent on a path that is being validated, before padding it:
+// a short header packet containing a PATH_CHALLENGE and a PATH_RESPONSE frame, plus the AEAD overhead.
+const maxUnpaddedPathProbeSize = 1 + protocol.MaxConnIDLen + 4 + 2*9 + 16
+
+// A pathProbe is a new path that is being validated before the connection migrates to it.
+// See section 8.2 and section 9 of RFC 9000.
+type pathProbe struct {
+	conn   sendConn              // used to send packets on the new path
+	runner connRunner            // receives packets sent on the new path, nil if the peer migrated
+	connID protocol.ConnectionID // the connection ID used on the new path
+	// rcvConn is the packet conn that packets on the new path are received on.
+	// Only set if the peer migrated.
+	rcvConn rawConn
+	// pconn is the packet conn passed to MigrateTo.
+	// It is only registered with the multiplexer once the connection is allowed to migrate.
+	pconn net.PacketConn
+
+	// toPreferredAddress is set if the client migrates to the server's preferred address.
+	toPreferredAddress bool
+
+	// peerInitiated is set if the peer's address changed.
+	// Until the path is validated, sending on this path is limited by the anti-amplification limit.
+	peerInitiated bool
+	bytesReceived protocol.ByteCount
+	bytesSent     protocol.ByteCount
+	// receivedNonProbingPacket is set if the highest-numbered non-probing packet was received on this path.
+	// Only then does the connection migrate to this path, see section 9.3 of RFC 9000.
+	receivedNonProbingPacket bool
+
+	// data of the PATH_CHALLENGE frames sent on this path
+	challenges        [][8]byte
+	lastChallengeSent time.Time
+	started           time.Time
+	deadline          time.Time
+	validated         bool
+
+	// data of a PATH_CHALLENGE frame received on this path, that still needs to be responded to
+	response *[8]byte
+
+	result chan error
+}
+
+func newPathProbe(conn sendConn, runner connRunner) *pathProbe {
+	return &pathProbe{
+		conn:   conn,
+		runner: runner,
+		result: make(chan error, 1),
+	}
+}
+
+// newMigrationPathProbe creates a path probe for migrating to a new packet conn.
+// The runner is set when the path probe is started.
+func newMigrationPathProbe(pconn net.PacketConn, remoteAddr net.Addr) *pathProbe {
+	probe := newPathProbe(newSendPconn(pconn, remoteAddr), nil)
+	probe.pconn = pconn
+	return probe
+}
+
+// newPeerPathProbe creates a path probe for a new address of the peer.
+func newPeerPathProbe(conn sendConn, rcvConn rawConn) *pathProbe {
+	return &pathProbe{
+		conn:          conn,
+		rcvConn:       rcvConn,
+		peerInitiated: true,
+		result:        make(chan error, 1),
+	}
+}
+
+// newPreferredAddressPathProbe creates a path probe for the server's preferred address.
+func newPreferredAddressPathProbe(conn sendConn) *pathProbe {
+	return &pathProbe{
+		conn:               conn,
+		toPreferredAddress: true,
+		result:             make(chan error, 1),
+	}
+}
+
+// AmplificationWindow returns the number of bytes that can be sent on this path.
+func (p *pathProbe) AmplificationWindow() protocol.ByteCount {
+	if !p.peerInitiated || p.validated {
+		return protocol.MaxByteCount
+	}
+	if p.bytesSent >= protocol.AmplificationFactor*p.bytesReceived {
+		return 0
+	}
+	return protocol.AmplificationFactor*p.bytesReceived - p.bytesSent
+}
+
+// CanSend says if the anti-amplification limit allows sending a packet on this path.
+func (p *pathProbe) CanSend() bool {
+	return p.AmplificationWindow() >= maxUnpaddedPathProbeSize
+}
+
+// NextChallengeTime returns the time when the next PATH_CHALLENGE is due on this path.
+// It returns the zero value if no more PATH_CHALLENGE frames will be sent on this path.
+func (p *pathProbe) NextChallengeTime(pto time.Duration) time.Time {
+	if p.validated || len(p.challenges) >= protocol.MaxPathChallenges {
+		return time.Time{}
+	}
+	// If no PATH_CHALLENGE was sent yet, lastChallengeSent is the zero value,
+	// and the first PATH_CHALLENGE is sent right away.
+	return p.lastChallengeSent.Add(pto)
+}
+
+// NextTimeout returns the time when a packet needs to be sent on this path,
+// or when path validation times out.
+func (p *pathProbe) NextTimeout(pto time.Duration) time.Time {
+	if !p.CanSend() {
+		// Wait for the peer to send more data on this path.
+		return p.deadline
+	}
+	if p.response != nil {
+		return time.Time{}
+	}
+	if nextChallenge := p.NextChallengeTime(pto); !nextChallenge.IsZero() {
+		return utils.MinTime(nextChallenge, p.deadline)
+	}
+	return p.deadline
+}
+
+// IsResponse says if the data of a PATH_RESPONSE frame matches any of the PATH_CHALLENGE frames sent on this path.
+func (p *pathProbe) IsResponse(data [8]byte) bool {
+	for _, c := range p.challenges {
+		if c == data {
+			return true
+		}
+	}
+	return false
+}
+
+// The pathProber queues requests to migrate the connection to a new path.
+// At any point in time, at most one path is being validated.
+type pathProber struct {
+	queue chan *pathProbe
+
+	closeErr error
+	closed   chan struct{}
+
+	hasData func()
+}
+
+func newPathProber(hasData func()) *pathProber {
+	return &pathProber{
+		queue:   make(chan *pathProbe, 1),
+		closed:  make(chan struct{}),
+		hasData: hasData,
+	}
+}
+
+// AddAndWait queues a new path probe.
+// It blocks until the path was either validated or path validation failed.
+func (p *pathProber) AddAndWait(probe *pathProbe) error {
+	select {
+	case p.queue <- probe:
+		p.hasData()
+	case <-p.closed:
+		return p.closeErr
+	}
+
+	select {
+	case err := <-probe.result:
+		return err
+	case <-p.closed:
+		return p.closeErr
+	}
+}
+
+// Dequeue gets the next path probe, if any.
+func (p *pathProber) Dequeue() *pathProbe {
+	select {
+	case probe := <-p.queue:
+		return probe
+	default:
+		return nil
+	}
+}
+
+func (p *pathProber) CloseWithError(e error) {
+	p.closeErr = e
+	close(p.closed)
+}
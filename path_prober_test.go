@@ -0,0 +1,65 @@
+package quic
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Prober", func() {
+	var (
+		prober *pathProber
+		queued chan struct{}
+	)
+
+	BeforeEach(func() {
+		queued = make(chan struct{}, 100)
+		prober = newPathProber(func() { queued <- struct{}{} })
+	})
+
+	It("returns nil when there's no path to probe", func() {
+		Expect(prober.Dequeue()).To(BeNil())
+	})
+
+	It("queues a path probe and waits for the result", func() {
+		probe := newPathProbe(nil, nil)
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(prober.AddAndWait(probe)).To(MatchError("validation failed"))
+		}()
+
+		Eventually(queued).Should(HaveLen(1))
+		Expect(prober.Dequeue()).To(Equal(probe))
+		Expect(prober.Dequeue()).To(BeNil())
+		Consistently(done).ShouldNot(BeClosed())
+		probe.result <- errors.New("validation failed")
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("matches PATH_RESPONSE data", func() {
+		probe := newPathProbe(nil, nil)
+		probe.challenges = append(probe.challenges, [8]byte{1, 2, 3, 4, 5, 6, 7, 8}, [8]byte{8, 7, 6, 5, 4, 3, 2, 1})
+		Expect(probe.IsResponse([8]byte{1, 2, 3, 4, 5, 6, 7, 8})).To(BeTrue())
+		Expect(probe.IsResponse([8]byte{8, 7, 6, 5, 4, 3, 2, 1})).To(BeTrue())
+		Expect(probe.IsResponse([8]byte{1, 1, 1, 1, 1, 1, 1, 1})).To(BeFalse())
+	})
+
+	It("returns the close error when the connection is closed", func() {
+		probe := newPathProbe(nil, nil)
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(prober.AddAndWait(probe)).To(MatchError("test error"))
+		}()
+
+		Eventually(queued).Should(HaveLen(1))
+		Consistently(done).ShouldNot(BeClosed())
+		prober.CloseWithError(errors.New("test error"))
+		Eventually(done).Should(BeClosed())
+		Expect(prober.AddAndWait(newPathProbe(nil, nil))).To(MatchError("test error"))
+	})
+})
@@ -351,6 +351,18 @@ func (e eventKeyUpdated) MarshalJSONObject(enc *gojay.Encoder) {
 	}
 }
 
+type event0RTTRejected struct {
+	Trigger zeroRTTRejectionReason
+}
+
+func (e event0RTTRejected) Category() category { return categorySecurity }
+func (e event0RTTRejected) Name() string       { return "0rtt_rejected" }
+func (e event0RTTRejected) IsNil() bool        { return false }
+
+func (e event0RTTRejected) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("trigger", e.Trigger.String())
+}
+
 type eventKeyDiscarded struct {
 	KeyType    keyType
 	Generation protocol.KeyPhase
@@ -517,6 +529,26 @@ func (e eventCongestionStateUpdated) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.StringKey("new", e.state.String())
 }
 
+type eventMigrationStateUpdated struct {
+	state  migrationState
+	local  net.Addr
+	remote net.Addr
+}
+
+func (e eventMigrationStateUpdated) Category() category { return categoryConnectivity }
+func (e eventMigrationStateUpdated) Name() string       { return "migration_state_updated" }
+func (e eventMigrationStateUpdated) IsNil() bool        { return false }
+
+func (e eventMigrationStateUpdated) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("new", e.state.String())
+	if e.local != nil {
+		enc.StringKey("path_local", e.local.String())
+	}
+	if e.remote != nil {
+		enc.StringKey("path_remote", e.remote.String())
+	}
+}
+
 type eventGeneric struct {
 	name string
 	msg  string
@@ -23,6 +23,8 @@ func (f frame) MarshalJSONObject(enc *gojay.Encoder) {
 		marshalPingFrame(enc, frame)
 	case *logging.AckFrame:
 		marshalAckFrame(enc, frame)
+	case *logging.AckMPFrame:
+		marshalAckMPFrame(enc, frame)
 	case *logging.ResetStreamFrame:
 		marshalResetStreamFrame(enc, frame)
 	case *logging.StopSendingFrame:
@@ -59,6 +61,14 @@ func (f frame) MarshalJSONObject(enc *gojay.Encoder) {
 		marshalHandshakeDoneFrame(enc, frame)
 	case *logging.DatagramFrame:
 		marshalDatagramFrame(enc, frame)
+	case *logging.PathAbandonFrame:
+		marshalPathAbandonFrame(enc, frame)
+	case *logging.PathStatusFrame:
+		marshalPathStatusFrame(enc, frame)
+	case *logging.AckFrequencyFrame:
+		marshalAckFrequencyFrame(enc, frame)
+	case *logging.ImmediateAckFrame:
+		marshalImmediateAckFrame(enc, frame)
 	default:
 		panic("unknown frame type")
 	}
@@ -102,6 +112,16 @@ func (ar ackRange) IsNil() bool { return false }
 
 func marshalAckFrame(enc *gojay.Encoder, f *logging.AckFrame) {
 	enc.StringKey("frame_type", "ack")
+	marshalAckFrameBody(enc, f)
+}
+
+func marshalAckMPFrame(enc *gojay.Encoder, f *logging.AckMPFrame) {
+	enc.StringKey("frame_type", "ack_mp")
+	enc.Uint64Key("path_identifier", f.PathIdentifier)
+	marshalAckFrameBody(enc, f.AckFrame)
+}
+
+func marshalAckFrameBody(enc *gojay.Encoder, f *logging.AckFrame) {
 	enc.FloatKeyOmitEmpty("ack_delay", milliseconds(f.DelayTime))
 	enc.ArrayKey("acked_ranges", ackRanges(f.AckRanges))
 	if hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0; hasECN {
@@ -112,10 +132,17 @@ func marshalAckFrame(enc *gojay.Encoder, f *logging.AckFrame) {
 }
 
 func marshalResetStreamFrame(enc *gojay.Encoder, f *logging.ResetStreamFrame) {
-	enc.StringKey("frame_type", "reset_stream")
+	if f.ReliableSize > 0 {
+		enc.StringKey("frame_type", "reset_stream_at")
+	} else {
+		enc.StringKey("frame_type", "reset_stream")
+	}
 	enc.Int64Key("stream_id", int64(f.StreamID))
 	enc.Int64Key("error_code", int64(f.ErrorCode))
 	enc.Int64Key("final_size", int64(f.FinalSize))
+	if f.ReliableSize > 0 {
+		enc.Int64Key("reliable_size", int64(f.ReliableSize))
+	}
 }
 
 func marshalStopSendingFrame(enc *gojay.Encoder, f *logging.StopSendingFrame) {
@@ -225,3 +252,29 @@ func marshalDatagramFrame(enc *gojay.Encoder, f *logging.DatagramFrame) {
 	enc.StringKey("frame_type", "datagram")
 	enc.Int64Key("length", int64(f.Length))
 }
+
+func marshalPathAbandonFrame(enc *gojay.Encoder, f *logging.PathAbandonFrame) {
+	enc.StringKey("frame_type", "path_abandon")
+	enc.Uint64Key("path_identifier", f.PathIdentifier)
+	enc.Uint64Key("error_code", f.ErrorCode)
+	enc.StringKeyOmitEmpty("reason", f.ReasonPhrase)
+}
+
+func marshalPathStatusFrame(enc *gojay.Encoder, f *logging.PathStatusFrame) {
+	enc.StringKey("frame_type", "path_status")
+	enc.Uint64Key("path_identifier", f.PathIdentifier)
+	enc.Uint64Key("sequence_number", f.SequenceNumber)
+	enc.Uint64Key("status", f.Status)
+}
+
+func marshalAckFrequencyFrame(enc *gojay.Encoder, f *logging.AckFrequencyFrame) {
+	enc.StringKey("frame_type", "ack_frequency")
+	enc.Uint64Key("sequence_number", f.SequenceNumber)
+	enc.Uint64Key("ack_eliciting_threshold", f.AckElicitingThreshold)
+	enc.Float64Key("request_max_ack_delay", milliseconds(f.RequestMaxAckDelay))
+	enc.Uint64Key("reordering_threshold", f.ReorderingThreshold)
+}
+
+func marshalImmediateAckFrame(enc *gojay.Encoder, _ *logging.ImmediateAckFrame) {
+	enc.StringKey("frame_type", "immediate_ack")
+}
@@ -48,6 +48,24 @@ var _ = Describe("Frames", func() {
 		)
 	})
 
+	It("marshals ACK_MP frames", func() {
+		check(
+			&logging.AckMPFrame{
+				PathIdentifier: 3,
+				AckFrame: &logging.AckFrame{
+					DelayTime: 86 * time.Millisecond,
+					AckRanges: []logging.AckRange{{Smallest: 120, Largest: 120}},
+				},
+			},
+			map[string]interface{}{
+				"frame_type":      "ack_mp",
+				"path_identifier": 3,
+				"ack_delay":       86,
+				"acked_ranges":    [][]float64{{120}},
+			},
+		)
+	})
+
 	It("marshals ACK frames without a delay", func() {
 		check(
 			&logging.AckFrame{
@@ -114,6 +132,24 @@ var _ = Describe("Frames", func() {
 		)
 	})
 
+	It("marshals RESET_STREAM_AT frames", func() {
+		check(
+			&logging.ResetStreamFrame{
+				StreamID:     987,
+				FinalSize:    1234,
+				ReliableSize: 42,
+				ErrorCode:    42,
+			},
+			map[string]interface{}{
+				"frame_type":    "reset_stream_at",
+				"stream_id":     987,
+				"error_code":    42,
+				"final_size":    1234,
+				"reliable_size": 42,
+			},
+		)
+	})
+
 	It("marshals STOP_SENDING frames", func() {
 		check(
 			&logging.StopSendingFrame{
@@ -374,4 +410,63 @@ var _ = Describe("Frames", func() {
 			},
 		)
 	})
+
+	It("marshals PATH_ABANDON frames", func() {
+		check(
+			&logging.PathAbandonFrame{
+				PathIdentifier: 2,
+				ErrorCode:      1,
+				ReasonPhrase:   "foobar",
+			},
+			map[string]interface{}{
+				"frame_type":      "path_abandon",
+				"path_identifier": 2,
+				"error_code":      1,
+				"reason":          "foobar",
+			},
+		)
+	})
+
+	It("marshals PATH_STATUS frames", func() {
+		check(
+			&logging.PathStatusFrame{
+				PathIdentifier: 2,
+				SequenceNumber: 5,
+				Status:         1,
+			},
+			map[string]interface{}{
+				"frame_type":      "path_status",
+				"path_identifier": 2,
+				"sequence_number": 5,
+				"status":          1,
+			},
+		)
+	})
+
+	It("marshals ACK_FREQUENCY frames", func() {
+		check(
+			&logging.AckFrequencyFrame{
+				SequenceNumber:        3,
+				AckElicitingThreshold: 10,
+				RequestMaxAckDelay:    2 * time.Millisecond,
+				ReorderingThreshold:   1,
+			},
+			map[string]interface{}{
+				"frame_type":              "ack_frequency",
+				"sequence_number":         3,
+				"ack_eliciting_threshold": 10,
+				"request_max_ack_delay":   2,
+				"reordering_threshold":    1,
+			},
+		)
+	})
+
+	It("marshals IMMEDIATE_ACK frames", func() {
+		check(
+			&logging.ImmediateAckFrame{},
+			map[string]interface{}{
+				"frame_type": "immediate_ack",
+			},
+		)
+	})
 })
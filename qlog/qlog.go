@@ -227,6 +227,12 @@ func (t *connectionTracer) RestoredTransportParameters(tp *wire.TransportParamet
 	t.mutex.Unlock()
 }
 
+func (t *connectionTracer) Rejected0RTT(reason logging.ZeroRTTRejectionReason) {
+	t.mutex.Lock()
+	t.recordEvent(time.Now(), &event0RTTRejected{Trigger: zeroRTTRejectionReason(reason)})
+	t.mutex.Unlock()
+}
+
 func (t *connectionTracer) recordTransportParameters(sentBy protocol.Perspective, tp *wire.TransportParameters) {
 	ev := t.toTransportParameters(tp)
 	ev.Owner = ownerLocal
@@ -416,6 +422,16 @@ func (t *connectionTracer) UpdatedPTOCount(value uint32) {
 	t.mutex.Unlock()
 }
 
+func (t *connectionTracer) UpdatedMigrationState(state logging.MigrationState, local, remote net.Addr) {
+	t.mutex.Lock()
+	t.recordEvent(time.Now(), &eventMigrationStateUpdated{
+		state:  migrationState(state),
+		local:  local,
+		remote: remote,
+	})
+	t.mutex.Unlock()
+}
+
 func (t *connectionTracer) UpdatedKeyFromTLS(encLevel protocol.EncryptionLevel, pers protocol.Perspective) {
 	t.mutex.Lock()
 	t.recordEvent(time.Now(), &eventKeyUpdated{
@@ -420,6 +420,14 @@ var _ = Describe("Tracing", func() {
 				Expect(ev).To(HaveKeyWithValue("initial_max_stream_data_uni", float64(300)))
 			})
 
+			It("records rejected 0-RTT", func() {
+				tracer.Rejected0RTT(logging.ZeroRTTRejectedReplay)
+				entry := exportAndParseSingle()
+				Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+				Expect(entry.Name).To(Equal("security:0rtt_rejected"))
+				Expect(entry.Event).To(HaveKeyWithValue("trigger", "replay"))
+			})
+
 			It("records a sent packet, without an ACK", func() {
 				tracer.SentPacket(
 					&logging.ExtendedHeader{
@@ -745,6 +753,21 @@ var _ = Describe("Tracing", func() {
 				Expect(entry.Event).To(HaveKeyWithValue("pto_count", float64(42)))
 			})
 
+			It("records migration state updates", func() {
+				tracer.UpdatedMigrationState(
+					logging.MigrationStateProbingStarted,
+					&net.UDPAddr{IP: net.IPv4(192, 168, 13, 37), Port: 42},
+					&net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 24},
+				)
+				entry := exportAndParseSingle()
+				Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+				Expect(entry.Name).To(Equal("connectivity:migration_state_updated"))
+				ev := entry.Event
+				Expect(ev).To(HaveKeyWithValue("new", "probing_started"))
+				Expect(ev).To(HaveKeyWithValue("path_local", "192.168.13.37:42"))
+				Expect(ev).To(HaveKeyWithValue("path_remote", "10.0.0.1:24"))
+			})
+
 			It("records TLS key updates", func() {
 				tracer.UpdatedKeyFromTLS(protocol.EncryptionHandshake, protocol.PerspectiveClient)
 				entry := exportAndParseSingle()
@@ -209,6 +209,8 @@ func (e transportError) String() string {
 		return "aead_limit_reached"
 	case qerr.NoViablePathError:
 		return "no_viable_path"
+	case qerr.VersionNegotiationErrorErrorCode:
+		return "version_negotiation_error"
 	default:
 		return ""
 	}
@@ -312,3 +314,37 @@ func (s congestionState) String() string {
 		return "unknown congestion state"
 	}
 }
+
+type migrationState logging.MigrationState
+
+func (s migrationState) String() string {
+	switch logging.MigrationState(s) {
+	case logging.MigrationStateProbingStarted:
+		return "probing_started"
+	case logging.MigrationStateProbingAbandoned:
+		return "probing_abandoned"
+	case logging.MigrationStateProbingSuccessful:
+		return "probing_successful"
+	case logging.MigrationStateMigrationComplete:
+		return "migration_complete"
+	default:
+		return "unknown migration state"
+	}
+}
+
+type zeroRTTRejectionReason logging.ZeroRTTRejectionReason
+
+func (r zeroRTTRejectionReason) String() string {
+	switch logging.ZeroRTTRejectionReason(r) {
+	case logging.ZeroRTTRejectedVersionChanged:
+		return "version_changed"
+	case logging.ZeroRTTRejectedTransportParameters:
+		return "transport_parameters_changed"
+	case logging.ZeroRTTRejectedReplay:
+		return "replay"
+	case logging.ZeroRTTRejectedByApplication:
+		return "application"
+	default:
+		return "unknown reason"
+	}
+}
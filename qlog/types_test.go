@@ -117,6 +117,7 @@ var _ = Describe("Types", func() {
 			Expect(transportError(qerr.ApplicationErrorErrorCode).String()).To(Equal("application_error"))
 			Expect(transportError(qerr.CryptoBufferExceeded).String()).To(Equal("crypto_buffer_exceeded"))
 			Expect(transportError(qerr.NoViablePathError).String()).To(Equal("no_viable_path"))
+			Expect(transportError(qerr.VersionNegotiationErrorErrorCode).String()).To(Equal("version_negotiation_error"))
 			Expect(transportError(1337).String()).To(BeEmpty())
 		})
 	})
@@ -127,4 +128,18 @@ var _ = Describe("Types", func() {
 		Expect(congestionState(logging.CongestionStateApplicationLimited).String()).To(Equal("application_limited"))
 		Expect(congestionState(logging.CongestionStateRecovery).String()).To(Equal("recovery"))
 	})
+
+	It("has a string representation for migration state updates", func() {
+		Expect(migrationState(logging.MigrationStateProbingStarted).String()).To(Equal("probing_started"))
+		Expect(migrationState(logging.MigrationStateProbingAbandoned).String()).To(Equal("probing_abandoned"))
+		Expect(migrationState(logging.MigrationStateProbingSuccessful).String()).To(Equal("probing_successful"))
+		Expect(migrationState(logging.MigrationStateMigrationComplete).String()).To(Equal("migration_complete"))
+	})
+
+	It("has a string representation for 0-RTT rejection reasons", func() {
+		Expect(zeroRTTRejectionReason(logging.ZeroRTTRejectedVersionChanged).String()).To(Equal("version_changed"))
+		Expect(zeroRTTRejectionReason(logging.ZeroRTTRejectedTransportParameters).String()).To(Equal("transport_parameters_changed"))
+		Expect(zeroRTTRejectionReason(logging.ZeroRTTRejectedReplay).String()).To(Equal("replay"))
+		Expect(zeroRTTRejectionReason(logging.ZeroRTTRejectedByApplication).String()).To(Equal("application"))
+	})
 })
@@ -0,0 +1,111 @@
+package quiclb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+)
+
+// The connIDCipher encrypts and decrypts the server ID and the nonce.
+// If they are 16 bytes long in total, a single AES-ECB pass is used.
+// Otherwise, the four-pass algorithm is used:
+// The plaintext is split into two halves (sharing one nibble if the length is odd),
+// and in every pass, one half is expanded to a 16 byte block, encrypted and XORed onto the other half.
+type connIDCipher struct {
+	block        cipher.Block
+	plaintextLen int
+}
+
+// encrypt encrypts b in place
+func (c *connIDCipher) encrypt(b []byte) {
+	if c.plaintextLen == aes.BlockSize {
+		c.block.Encrypt(b, b)
+		return
+	}
+	left, right := c.split(b)
+	c.xorRight(right, left, 1)
+	c.xorLeft(left, right, 2)
+	c.xorRight(right, left, 3)
+	c.xorLeft(left, right, 4)
+	c.join(b, left, right)
+}
+
+// decrypt decrypts b in place
+func (c *connIDCipher) decrypt(b []byte) {
+	if c.plaintextLen == aes.BlockSize {
+		c.block.Decrypt(b, b)
+		return
+	}
+	left, right := c.split(b)
+	c.xorLeft(left, right, 4)
+	c.xorRight(right, left, 3)
+	c.xorLeft(left, right, 2)
+	c.xorRight(right, left, 1)
+	c.join(b, left, right)
+}
+
+func (c *connIDCipher) halfLen() int {
+	return (c.plaintextLen + 1) / 2
+}
+
+func (c *connIDCipher) isOdd() bool {
+	return c.plaintextLen%2 == 1
+}
+
+// split splits b into two halves.
+// If the length is odd, the left half gets the upper and the right half the lower nibble of the middle byte.
+func (c *connIDCipher) split(b []byte) (left, right []byte) {
+	l := c.halfLen()
+	left = make([]byte, l)
+	right = make([]byte, l)
+	copy(left, b[:l])
+	copy(right, b[c.plaintextLen-l:c.plaintextLen])
+	if c.isOdd() {
+		left[l-1] &= 0xf0
+		right[0] &= 0x0f
+	}
+	return left, right
+}
+
+func (c *connIDCipher) join(b, left, right []byte) {
+	l := c.halfLen()
+	copy(b[:l], left)
+	if c.isOdd() {
+		b[l-1] |= right[0]
+		copy(b[l:], right[1:])
+		return
+	}
+	copy(b[l:], right)
+}
+
+// expand pads one half to a 16 byte block: the half, zeros, the plaintext length and the pass index.
+func (c *connIDCipher) expand(half []byte, pass uint8) [aes.BlockSize]byte {
+	var block [aes.BlockSize]byte
+	copy(block[:], half)
+	block[aes.BlockSize-2] = uint8(c.plaintextLen)
+	block[aes.BlockSize-1] = pass
+	return block
+}
+
+// xorRight XORs the leftmost bytes of the encrypted left half onto the right half.
+func (c *connIDCipher) xorRight(right, left []byte, pass uint8) {
+	block := c.expand(left, pass)
+	c.block.Encrypt(block[:], block[:])
+	for i := range right {
+		right[i] ^= block[i]
+	}
+	if c.isOdd() {
+		right[0] &= 0x0f
+	}
+}
+
+// xorLeft XORs the leftmost bytes of the encrypted right half onto the left half.
+func (c *connIDCipher) xorLeft(left, right []byte, pass uint8) {
+	block := c.expand(right, pass)
+	c.block.Encrypt(block[:], block[:])
+	for i := range left {
+		left[i] ^= block[i]
+	}
+	if c.isOdd() {
+		left[len(left)-1] &= 0xf0
+	}
+}
@@ -0,0 +1,55 @@
+package quiclb
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection ID cipher", func() {
+	getCipher := func(plaintextLen int) *connIDCipher {
+		key := make([]byte, 16)
+		rand.Read(key)
+		block, err := aes.NewCipher(key)
+		Expect(err).ToNot(HaveOccurred())
+		return &connIDCipher{block: block, plaintextLen: plaintextLen}
+	}
+
+	for i := 5; i <= 19; i++ {
+		l := i
+
+		It(fmt.Sprintf("encrypts and decrypts, for %d bytes", l), func() {
+			c := getCipher(l)
+			plaintext := make([]byte, l)
+			rand.Read(plaintext)
+			b := append([]byte{}, plaintext...)
+			c.encrypt(b)
+			Expect(b).ToNot(Equal(plaintext))
+			c.decrypt(b)
+			Expect(b).To(Equal(plaintext))
+		})
+	}
+
+	It("uses a single pass for 16 bytes", func() {
+		c := getCipher(16)
+		plaintext := make([]byte, 16)
+		rand.Read(plaintext)
+		b := append([]byte{}, plaintext...)
+		c.encrypt(b)
+		expected := make([]byte, 16)
+		c.block.Encrypt(expected, plaintext)
+		Expect(b).To(Equal(expected))
+	})
+
+	It("changes the whole ciphertext when changing the nonce", func() {
+		c := getCipher(9) // 3 bytes server ID, 6 bytes nonce
+		b1 := []byte{1, 2, 3, 0, 0, 0, 0, 0, 0}
+		b2 := []byte{1, 2, 3, 0, 0, 0, 0, 0, 1}
+		c.encrypt(b1)
+		c.encrypt(b2)
+		Expect(b1[:3]).ToNot(Equal(b2[:3]))
+	})
+})
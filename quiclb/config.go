@@ -0,0 +1,74 @@
+// Package quiclb implements QUIC-LB compatible connection IDs, as described in draft-ietf-quic-load-balancers.
+// Servers behind a load balancer use a Generator to encode their server ID into every connection ID they issue.
+// The load balancer uses a Decoder to extract the server ID from incoming packets, and routes them accordingly.
+package quiclb
+
+import (
+	"crypto/aes"
+	"errors"
+	"fmt"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// unroutableConfigID is the config ID reserved for connection IDs that can't be routed by the load balancer.
+const unroutableConfigID = 0b111
+
+// A Config is a QUIC-LB configuration.
+// The same configuration needs to be used by the load balancer and by all servers behind it.
+type Config struct {
+	// ConfigID is encoded in the config rotation bits of the first octet of the connection ID.
+	// Changing it allows rotating configurations without breaking existing connections.
+	// It must be between 0 and 6, the value 7 is reserved for unroutable connection IDs.
+	ConfigID uint8
+	// ServerIDLen is the length of the server ID in bytes, between 1 and 15.
+	ServerIDLen int
+	// NonceLen is the length of the nonce in bytes, at least 4.
+	// The connection ID consists of the first octet, the server ID and the nonce,
+	// so ServerIDLen + NonceLen must not exceed 19 bytes.
+	NonceLen int
+	// Key is the AES-128 key used to encrypt the connection IDs.
+	// If ServerIDLen + NonceLen equals 16, connection IDs are encrypted using single-pass encryption,
+	// otherwise four-pass encryption is used.
+	// If nil, the server ID is encoded in plaintext.
+	Key []byte
+	// LengthSelfEncoding encodes the length of the connection ID into the first octet.
+	// If false, the lower 5 bits of the first octet are random.
+	LengthSelfEncoding bool
+}
+
+// ConnectionIDLen returns the length of connection IDs using this configuration.
+func (c *Config) ConnectionIDLen() int {
+	return 1 + c.ServerIDLen + c.NonceLen
+}
+
+func (c *Config) validate() error {
+	if c.ConfigID >= unroutableConfigID {
+		return fmt.Errorf("invalid config ID: %d", c.ConfigID)
+	}
+	if c.ServerIDLen < 1 || c.ServerIDLen > 15 {
+		return fmt.Errorf("invalid server ID length: %d", c.ServerIDLen)
+	}
+	if c.NonceLen < 4 {
+		return fmt.Errorf("nonce too short: %d", c.NonceLen)
+	}
+	if c.ConnectionIDLen() > protocol.MaxConnIDLen {
+		return fmt.Errorf("connection ID too long: %d bytes", c.ConnectionIDLen())
+	}
+	if c.Key != nil && len(c.Key) != aes.BlockSize {
+		return errors.New("key must be 16 bytes long")
+	}
+	return nil
+}
+
+// newCipher returns the cipher used for this configuration, or nil if connection IDs are not encrypted.
+func (c *Config) newCipher() (*connIDCipher, error) {
+	if c.Key == nil {
+		return nil, nil
+	}
+	block, err := aes.NewCipher(c.Key)
+	if err != nil {
+		return nil, err
+	}
+	return &connIDCipher{block: block, plaintextLen: c.ServerIDLen + c.NonceLen}, nil
+}
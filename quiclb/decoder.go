@@ -0,0 +1,98 @@
+package quiclb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// ErrUnroutable is returned if the server ID can't be extracted from a connection ID.
+// This is the case for connection IDs chosen by the client (in its first Initial packets),
+// and for connection IDs using an unknown configuration.
+// The load balancer should then use a fallback algorithm, for example hashing the 4-tuple.
+var ErrUnroutable = errors.New("unroutable connection ID")
+
+type decoderConfig struct {
+	Config
+	cipher *connIDCipher
+}
+
+// A Decoder extracts the server ID from QUIC-LB connection IDs.
+// It is safe for concurrent use.
+type Decoder struct {
+	configs [unroutableConfigID]*decoderConfig
+}
+
+// NewDecoder creates a new Decoder.
+// Every configuration must use a different config ID.
+func NewDecoder(configs ...*Config) (*Decoder, error) {
+	d := &Decoder{}
+	for _, conf := range configs {
+		if err := conf.validate(); err != nil {
+			return nil, err
+		}
+		if d.configs[conf.ConfigID] != nil {
+			return nil, fmt.Errorf("duplicate config ID: %d", conf.ConfigID)
+		}
+		c, err := conf.newCipher()
+		if err != nil {
+			return nil, err
+		}
+		d.configs[conf.ConfigID] = &decoderConfig{Config: *conf, cipher: c}
+	}
+	return d, nil
+}
+
+func (d *Decoder) getConfig(firstOctet byte) *decoderConfig {
+	configID := firstOctet >> 5
+	if configID == unroutableConfigID {
+		return nil
+	}
+	return d.configs[configID]
+}
+
+// ServerID extracts the server ID from a connection ID.
+func (d *Decoder) ServerID(connID []byte) ([]byte, error) {
+	if len(connID) == 0 {
+		return nil, ErrUnroutable
+	}
+	conf := d.getConfig(connID[0])
+	if conf == nil || len(connID) != conf.ConnectionIDLen() {
+		return nil, ErrUnroutable
+	}
+	if conf.LengthSelfEncoding && int(connID[0]&0x1f)+1 != len(connID) {
+		return nil, ErrUnroutable
+	}
+	b := make([]byte, len(connID)-1)
+	copy(b, connID[1:])
+	if conf.cipher != nil {
+		conf.cipher.decrypt(b)
+	}
+	return b[:conf.ServerIDLen], nil
+}
+
+// ServerIDFromPacket extracts the server ID from the Destination Connection ID of a QUIC packet.
+// For short header packets, the length of the connection ID is determined from the configuration.
+func (d *Decoder) ServerIDFromPacket(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, io.EOF
+	}
+	var connIDLen int
+	if !wire.IsLongHeaderPacket(data[0]) {
+		if len(data) < 2 {
+			return nil, io.EOF
+		}
+		conf := d.getConfig(data[1])
+		if conf == nil {
+			return nil, ErrUnroutable
+		}
+		connIDLen = conf.ConnectionIDLen()
+	}
+	connID, err := wire.ParseConnectionID(data, connIDLen)
+	if err != nil {
+		return nil, err
+	}
+	return d.ServerID(connID.Bytes())
+}
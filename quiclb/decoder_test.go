@@ -0,0 +1,177 @@
+package quiclb
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decoder", func() {
+	getKey := func() []byte {
+		key := make([]byte, 16)
+		rand.Read(key)
+		return key
+	}
+
+	for _, c := range []*Config{
+		{ConfigID: 0, ServerIDLen: 3, NonceLen: 4},
+		{ConfigID: 1, ServerIDLen: 4, NonceLen: 12, Key: getKey()},
+		{ConfigID: 2, ServerIDLen: 3, NonceLen: 6, Key: getKey()},
+		{ConfigID: 3, ServerIDLen: 8, NonceLen: 11, Key: getKey(), LengthSelfEncoding: true},
+	} {
+		conf := c
+
+		It(fmt.Sprintf("decodes connection IDs, for %d byte server IDs and %d byte nonces (encrypted: %t)", conf.ServerIDLen, conf.NonceLen, conf.Key != nil), func() {
+			serverID := make([]byte, conf.ServerIDLen)
+			rand.Read(serverID)
+			g, err := NewGenerator(conf, serverID)
+			Expect(err).ToNot(HaveOccurred())
+			d, err := NewDecoder(conf)
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < 10; i++ {
+				connID, err := g.GenerateConnectionID()
+				Expect(err).ToNot(HaveOccurred())
+				sid, err := d.ServerID(connID.Bytes())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sid).To(Equal(serverID))
+			}
+		})
+	}
+
+	for _, v := range testVectors {
+		vector := v
+
+		It(fmt.Sprintf("decodes the connection ID of the test vector, for config ID %d (encrypted: %t)", vector.configID, vector.key != ""), func() {
+			d, err := NewDecoder(vector.config())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(d.ServerID(mustDecodeHex(vector.connID))).To(Equal(mustDecodeHex(vector.serverID)))
+		})
+	}
+
+	It("uses the config ID to select the configuration", func() {
+		conf1 := &Config{ConfigID: 1, ServerIDLen: 2, NonceLen: 6, Key: getKey()}
+		conf2 := &Config{ConfigID: 2, ServerIDLen: 3, NonceLen: 8, Key: getKey()}
+		g1, err := NewGenerator(conf1, []byte{1, 2})
+		Expect(err).ToNot(HaveOccurred())
+		g2, err := NewGenerator(conf2, []byte{3, 4, 5})
+		Expect(err).ToNot(HaveOccurred())
+		d, err := NewDecoder(conf1, conf2)
+		Expect(err).ToNot(HaveOccurred())
+		c1, err := g1.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		c2, err := g2.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.ServerID(c1.Bytes())).To(Equal([]byte{1, 2}))
+		Expect(d.ServerID(c2.Bytes())).To(Equal([]byte{3, 4, 5}))
+	})
+
+	It("rejects duplicate config IDs", func() {
+		_, err := NewDecoder(
+			&Config{ConfigID: 1, ServerIDLen: 2, NonceLen: 6},
+			&Config{ConfigID: 1, ServerIDLen: 3, NonceLen: 6},
+		)
+		Expect(err).To(MatchError("duplicate config ID: 1"))
+	})
+
+	It("returns ErrUnroutable for unknown configurations", func() {
+		d, err := NewDecoder(&Config{ConfigID: 1, ServerIDLen: 2, NonceLen: 6})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = d.ServerID(nil)
+		Expect(err).To(MatchError(ErrUnroutable))
+		_, err = d.ServerID([]byte{2 << 5, 1, 2, 3, 4, 5, 6, 7, 8})
+		Expect(err).To(MatchError(ErrUnroutable))
+		_, err = d.ServerID([]byte{0xff, 1, 2, 3, 4, 5, 6, 7, 8})
+		Expect(err).To(MatchError(ErrUnroutable))
+	})
+
+	It("returns ErrUnroutable if the length doesn't match", func() {
+		d, err := NewDecoder(&Config{ConfigID: 1, ServerIDLen: 2, NonceLen: 6})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = d.ServerID([]byte{1 << 5, 1, 2, 3, 4, 5, 6, 7})
+		Expect(err).To(MatchError(ErrUnroutable))
+		_, err = d.ServerID([]byte{1 << 5, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+		Expect(err).To(MatchError(ErrUnroutable))
+	})
+
+	Context("parsing packets", func() {
+		var (
+			conf     *Config
+			g        *Generator
+			d        *Decoder
+			serverID = []byte{0xde, 0xca, 0xfb, 0xad}
+		)
+
+		BeforeEach(func() {
+			conf = &Config{ConfigID: 4, ServerIDLen: 4, NonceLen: 7, Key: getKey()}
+			var err error
+			g, err = NewGenerator(conf, serverID)
+			Expect(err).ToNot(HaveOccurred())
+			d, err = NewDecoder(conf)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("decodes long header packets", func() {
+			connID, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			b := &bytes.Buffer{}
+			Expect((&wire.ExtendedHeader{
+				Header: wire.Header{
+					IsLongHeader:     true,
+					Type:             protocol.PacketTypeHandshake,
+					DestConnectionID: connID,
+					SrcConnectionID:  protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+					Version:          protocol.Version1,
+					Length:           10,
+				},
+				PacketNumberLen: protocol.PacketNumberLen2,
+			}).Write(b, protocol.Version1)).To(Succeed())
+			b.Write(make([]byte, 8))
+			Expect(d.ServerIDFromPacket(b.Bytes())).To(Equal(serverID))
+		})
+
+		It("decodes short header packets", func() {
+			connID, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			b := &bytes.Buffer{}
+			Expect((&wire.ExtendedHeader{
+				Header:          wire.Header{DestConnectionID: connID},
+				PacketNumberLen: protocol.PacketNumberLen2,
+			}).Write(b, protocol.Version1)).To(Succeed())
+			b.Write(make([]byte, 20))
+			Expect(d.ServerIDFromPacket(b.Bytes())).To(Equal(serverID))
+		})
+
+		It("returns ErrUnroutable for the client's first Initial", func() {
+			b := &bytes.Buffer{}
+			Expect((&wire.ExtendedHeader{
+				Header: wire.Header{
+					IsLongHeader:     true,
+					Type:             protocol.PacketTypeInitial,
+					DestConnectionID: protocol.ParseConnectionID([]byte{0xff, 1, 2, 3, 4, 5, 6, 7}),
+					Version:          protocol.Version1,
+					Length:           10,
+				},
+				PacketNumberLen: protocol.PacketNumberLen2,
+			}).Write(b, protocol.Version1)).To(Succeed())
+			_, err := d.ServerIDFromPacket(b.Bytes())
+			Expect(err).To(MatchError(ErrUnroutable))
+		})
+
+		It("errors on short packets", func() {
+			connID, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			data := append([]byte{0x40}, connID.Bytes()...)
+			_, err = d.ServerIDFromPacket(data[:5])
+			Expect(err).To(MatchError(io.EOF))
+			_, err = d.ServerIDFromPacket(nil)
+			Expect(err).To(MatchError(io.EOF))
+		})
+	})
+})
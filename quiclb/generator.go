@@ -0,0 +1,66 @@
+package quiclb
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// A Generator generates QUIC-LB connection IDs encoding the server ID.
+// It implements the quic.ConnectionIDGenerator interface, and is meant to be used as the Config.ConnectionIDGenerator.
+// It is safe for concurrent use.
+type Generator struct {
+	config   Config
+	serverID []byte
+	cipher   *connIDCipher // nil if connection IDs are not encrypted
+}
+
+// NewGenerator creates a new Generator.
+// The length of the server ID must equal the server ID length of the configuration.
+func NewGenerator(config *Config, serverID []byte) (*Generator, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if len(serverID) != config.ServerIDLen {
+		return nil, fmt.Errorf("server ID has wrong length (expected %d, got %d)", config.ServerIDLen, len(serverID))
+	}
+	c, err := config.newCipher()
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{
+		config:   *config,
+		serverID: append([]byte{}, serverID...),
+		cipher:   c,
+	}, nil
+}
+
+// GenerateConnectionID generates a new connection ID, using a random nonce.
+func (g *Generator) GenerateConnectionID() (protocol.ConnectionID, error) {
+	b := make([]byte, g.config.ConnectionIDLen())
+	if _, err := rand.Read(b); err != nil {
+		return protocol.ConnectionID{}, err
+	}
+	return g.encode(b), nil
+}
+
+// encode encodes the config ID and the server ID into b.
+// The random bytes following the server ID are used as the nonce.
+func (g *Generator) encode(b []byte) protocol.ConnectionID {
+	if g.config.LengthSelfEncoding {
+		b[0] = g.config.ConfigID<<5 | uint8(len(b)-1)
+	} else {
+		b[0] = g.config.ConfigID<<5 | b[0]&0x1f
+	}
+	copy(b[1:], g.serverID)
+	if g.cipher != nil {
+		g.cipher.encrypt(b[1:])
+	}
+	return protocol.ParseConnectionID(b)
+}
+
+// ConnectionIDLen returns the length of the generated connection IDs.
+func (g *Generator) ConnectionIDLen() int {
+	return g.config.ConnectionIDLen()
+}
@@ -0,0 +1,80 @@
+package quiclb
+
+import (
+	"fmt"
+
+	"github.com/lucas-clemente/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generator", func() {
+	var _ quic.ConnectionIDGenerator = &Generator{}
+
+	It("generates plaintext connection IDs", func() {
+		g, err := NewGenerator(&Config{ConfigID: 2, ServerIDLen: 3, NonceLen: 5}, []byte{0xde, 0xca, 0xfb})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.ConnectionIDLen()).To(Equal(9))
+		c1, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c1.Len()).To(Equal(9))
+		Expect(c1.Bytes()[0] >> 5).To(BeEquivalentTo(2))
+		Expect(c1.Bytes()[1:4]).To(Equal([]byte{0xde, 0xca, 0xfb}))
+		c2, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c2.Bytes()[1:4]).To(Equal([]byte{0xde, 0xca, 0xfb}))
+		Expect(c2).ToNot(Equal(c1))
+	})
+
+	It("encodes the length", func() {
+		g, err := NewGenerator(&Config{ConfigID: 6, ServerIDLen: 2, NonceLen: 8, LengthSelfEncoding: true}, []byte{1, 2})
+		Expect(err).ToNot(HaveOccurred())
+		c, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Bytes()[0]).To(Equal(byte(6<<5 | 10)))
+	})
+
+	It("encrypts connection IDs", func() {
+		g, err := NewGenerator(&Config{ConfigID: 1, ServerIDLen: 4, NonceLen: 6, Key: make([]byte, 16)}, []byte{0xde, 0xad, 0xbe, 0xef})
+		Expect(err).ToNot(HaveOccurred())
+		c1, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c1.Len()).To(Equal(11))
+		Expect(c1.Bytes()[0] >> 5).To(BeEquivalentTo(1))
+		c2, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c1.Bytes()[1:5]).ToNot(Equal(c2.Bytes()[1:5]))
+	})
+
+	for _, v := range testVectors {
+		vector := v
+
+		It(fmt.Sprintf("generates the connection ID of the test vector, for config ID %d (encrypted: %t)", vector.configID, vector.key != ""), func() {
+			serverID := mustDecodeHex(vector.serverID)
+			g, err := NewGenerator(vector.config(), serverID)
+			Expect(err).ToNot(HaveOccurred())
+			b := make([]byte, g.ConnectionIDLen())
+			copy(b[1+len(serverID):], mustDecodeHex(vector.nonce))
+			Expect(g.encode(b).Bytes()).To(Equal(mustDecodeHex(vector.connID)))
+		})
+	}
+
+	It("errors if the server ID has the wrong length", func() {
+		_, err := NewGenerator(&Config{ServerIDLen: 3, NonceLen: 5}, []byte{1, 2})
+		Expect(err).To(MatchError("server ID has wrong length (expected 3, got 2)"))
+	})
+
+	It("validates the configuration", func() {
+		_, err := NewGenerator(&Config{ConfigID: 7, ServerIDLen: 1, NonceLen: 5}, []byte{1})
+		Expect(err).To(MatchError("invalid config ID: 7"))
+		_, err = NewGenerator(&Config{ServerIDLen: 0, NonceLen: 5}, nil)
+		Expect(err).To(MatchError("invalid server ID length: 0"))
+		_, err = NewGenerator(&Config{ServerIDLen: 1, NonceLen: 3}, []byte{1})
+		Expect(err).To(MatchError("nonce too short: 3"))
+		_, err = NewGenerator(&Config{ServerIDLen: 10, NonceLen: 10}, make([]byte, 10))
+		Expect(err).To(MatchError("connection ID too long: 21 bytes"))
+		_, err = NewGenerator(&Config{ServerIDLen: 1, NonceLen: 5, Key: make([]byte, 15)}, []byte{1})
+		Expect(err).To(MatchError("key must be 16 bytes long"))
+	})
+})
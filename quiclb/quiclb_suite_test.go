@@ -0,0 +1,55 @@
+package quiclb
+
+import (
+	"encoding/hex"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestQuicLB(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "QUIC-LB Suite")
+}
+
+// A testVector is a connection ID encoding a server ID, using length self-encoding.
+// The test vectors are taken from Appendix B of draft-ietf-quic-load-balancers-20.
+type testVector struct {
+	configID uint8
+	serverID string
+	nonce    string
+	key      string // empty if the connection ID is not encrypted
+	connID   string
+}
+
+var testVectors = []testVector{
+	// unencrypted
+	{configID: 0, serverID: "c4605e", nonce: "4504cc4f", connID: "07c4605e4504cc4f"},
+	// single-pass encryption
+	{configID: 2, serverID: "ed793a51d49b8f5f", nonce: "ee080dbf48c0d1e5", key: "8f95f09245765f80256934e50c66207f", connID: "504dd2d05a7b0de9b2b9907afb5ecf8cc3"},
+	// four-pass encryption
+	{configID: 0, serverID: "ed793a", nonce: "ee080dbf", key: "8f95f09245765f80256934e50c66207f", connID: "0720b1d07b359d3c"},
+	{configID: 1, serverID: "ed793a51d49b8f5fab65", nonce: "ee080dbf48", key: "8f95f09245765f80256934e50c66207f", connID: "2fcc381bc74cb4fbad2823a3d1f8fed2"},
+}
+
+func (v *testVector) config() *Config {
+	conf := &Config{
+		ConfigID:           v.configID,
+		ServerIDLen:        len(v.serverID) / 2,
+		NonceLen:           len(v.nonce) / 2,
+		LengthSelfEncoding: true,
+	}
+	if v.key != "" {
+		conf.Key = mustDecodeHex(v.key)
+	}
+	return conf
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
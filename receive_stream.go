@@ -32,6 +32,9 @@ type receiveStream struct {
 	frameQueue  *frameSorter
 	finalOffset protocol.ByteCount
 
+	highestReceived protocol.ByteCount
+	bytesRead       protocol.ByteCount
+
 	currentFrame       []byte
 	currentFrameDone   func()
 	currentFrameIsLast bool // is the currentFrame the last frame on this stream
@@ -45,6 +48,9 @@ type receiveStream struct {
 	finRead           bool // set once we read a frame with a Fin
 	canceledRead      bool // set when CancelRead() is called
 	resetRemotely     bool // set when HandleResetStreamFrame() is called
+	// set when a RESET_STREAM_AT frame is received, and the application hasn't read up to its reliable size yet
+	resetAtPending bool
+	reliableSize   protocol.ByteCount
 
 	readChan chan struct{}
 	readOnce chan struct{} // cap: 1, to protect against concurrent use of Read
@@ -177,6 +183,7 @@ func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, err
 		m := copy(p[bytesRead:], s.currentFrame[s.readPosInFrame:])
 		s.readPosInFrame += m
 		bytesRead += m
+		s.bytesRead += protocol.ByteCount(m)
 
 		// when a RESET_STREAM was received, the was already informed about the final byteOffset for this stream
 		if !s.resetRemotely {
@@ -184,6 +191,13 @@ func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, err
 		}
 
 		if s.readPosInFrame >= len(s.currentFrame) && s.currentFrameIsLast {
+			if s.resetAtPending {
+				// All data up to the reliable size was read. Now the stream reset can be surfaced.
+				s.resetAtPending = false
+				s.resetRemotely = true
+				s.flowController.Abandon()
+				return true, bytesRead, s.resetRemotelyErr
+			}
 			s.finRead = true
 			return true, bytesRead, io.EOF
 		}
@@ -198,10 +212,18 @@ func (s *receiveStream) dequeueNextFrame() {
 		s.currentFrameDone()
 	}
 	offset, s.currentFrame, s.currentFrameDone = s.frameQueue.Pop()
-	s.currentFrameIsLast = offset+protocol.ByteCount(len(s.currentFrame)) >= s.finalOffset
+	s.currentFrameIsLast = offset+protocol.ByteCount(len(s.currentFrame)) >= s.readLimit()
 	s.readPosInFrame = 0
 }
 
+// readLimit is the offset up to which data is delivered to the application.
+func (s *receiveStream) readLimit() protocol.ByteCount {
+	if s.resetAtPending {
+		return s.reliableSize
+	}
+	return s.finalOffset
+}
+
 func (s *receiveStream) CancelRead(errorCode StreamErrorCode) {
 	s.mutex.Lock()
 	completed := s.cancelReadImpl(errorCode)
@@ -245,6 +267,7 @@ func (s *receiveStream) handleStreamFrameImpl(frame *wire.StreamFrame) (bool /*
 	if err := s.flowController.UpdateHighestReceived(maxOffset, frame.Fin); err != nil {
 		return false, err
 	}
+	s.highestReceived = utils.Max(s.highestReceived, maxOffset)
 	var newlyRcvdFinalOffset bool
 	if frame.Fin {
 		newlyRcvdFinalOffset = s.finalOffset == protocol.MaxByteCount
@@ -279,6 +302,7 @@ func (s *receiveStream) handleResetStreamFrameImpl(frame *wire.ResetStreamFrame)
 	if err := s.flowController.UpdateHighestReceived(frame.FinalSize, true); err != nil {
 		return false, err
 	}
+	s.highestReceived = utils.Max(s.highestReceived, frame.FinalSize)
 	newlyRcvdFinalOffset := s.finalOffset == protocol.MaxByteCount
 	s.finalOffset = frame.FinalSize
 
@@ -286,13 +310,51 @@ func (s *receiveStream) handleResetStreamFrameImpl(frame *wire.ResetStreamFrame)
 	if s.resetRemotely {
 		return false, nil
 	}
-	s.resetRemotely = true
-	s.resetRemotelyErr = &StreamError{
-		StreamID:  s.streamID,
-		ErrorCode: frame.ErrorCode,
+	// A RESET_STREAM_AT frame can only lower the reliable size.
+	if s.resetAtPending && frame.ReliableSize >= s.reliableSize {
+		return false, nil
+	}
+	if !s.resetAtPending {
+		s.resetRemotelyErr = &StreamError{
+			StreamID:  s.streamID,
+			ErrorCode: frame.ErrorCode,
+		}
 	}
 	s.signalRead()
-	return newlyRcvdFinalOffset, nil
+	if frame.ReliableSize > s.bytesRead {
+		s.resetAtPending = true
+		s.reliableSize = frame.ReliableSize
+		s.frameQueue.Truncate(frame.ReliableSize)
+		// The frame that is currently being read might extend beyond the reliable size.
+		if s.currentFrame != nil {
+			frameOffset := s.bytesRead - protocol.ByteCount(s.readPosInFrame)
+			if frameOffset+protocol.ByteCount(len(s.currentFrame)) >= frame.ReliableSize {
+				s.currentFrame = s.currentFrame[:frame.ReliableSize-frameOffset]
+				s.currentFrameIsLast = true
+			}
+		}
+		return false, nil
+	}
+	// The application already read all data up to the reliable size.
+	wasPending := s.resetAtPending
+	s.resetAtPending = false
+	s.resetRemotely = true
+	return newlyRcvdFinalOffset || wasPending, nil
+}
+
+func (s *receiveStream) Stats() StreamStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var receiveWindow protocol.ByteCount
+	if window := s.flowController.ReceiveWindow(); window > s.highestReceived {
+		receiveWindow = window - s.highestReceived
+	}
+	return StreamStats{
+		BytesReceived: uint64(s.highestReceived),
+		BytesRead:     uint64(s.bytesRead),
+		ReceiveWindow: uint64(receiveWindow),
+	}
 }
 
 func (s *receiveStream) CloseRemote(offset protocol.ByteCount) {
@@ -674,6 +674,115 @@ var _ = Describe("Receive Stream", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
+
+		Context("receiving RESET_STREAM_AT frames", func() {
+			rst := &wire.ResetStreamFrame{
+				StreamID:     streamID,
+				FinalSize:    42,
+				ReliableSize: 6,
+				ErrorCode:    1234,
+			}
+
+			It("delivers data up to the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foob")})).To(Succeed())
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 4, Data: []byte("ardata")})).To(Succeed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b := make([]byte, 10)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(MatchError(&StreamError{
+					StreamID:  streamID,
+					ErrorCode: 1234,
+				}))
+				Expect(b[:n]).To(Equal([]byte("foobar")))
+				_, err = strWithTimeout.Read(b)
+				Expect(err).To(MatchError(&StreamError{
+					StreamID:  streamID,
+					ErrorCode: 1234,
+				}))
+			})
+
+			It("waits for data up to the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					b := make([]byte, 10)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).To(HaveOccurred())
+					Expect(b[:n]).To(Equal([]byte("foobar")))
+					close(done)
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarfoob")})).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("truncates the frame that is currently being read", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarfoob")})).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
+				b := make([]byte, 2)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(2))
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b = make([]byte, 10)
+				n, err = strWithTimeout.Read(b)
+				Expect(err).To(HaveOccurred())
+				Expect(b[:n]).To(Equal([]byte("obar")))
+			})
+
+			It("resets the stream immediately if the reliable size was already read", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				b := make([]byte, 6)
+				_, err := strWithTimeout.Read(b)
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				_, err = strWithTimeout.Read(b)
+				Expect(err).To(MatchError(&StreamError{
+					StreamID:  streamID,
+					ErrorCode: 1234,
+				}))
+			})
+
+			It("resets the stream when a RESET_STREAM frame is received after a RESET_STREAM_AT", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true).Times(2)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{
+					StreamID:  streamID,
+					FinalSize: 42,
+					ErrorCode: 1234,
+				})).To(Succeed())
+				_, err := strWithTimeout.Read([]byte{0})
+				Expect(err).To(MatchError(&StreamError{
+					StreamID:  streamID,
+					ErrorCode: 1234,
+				}))
+			})
+		})
 	})
 
 	Context("flow control", func() {
@@ -693,4 +802,35 @@ var _ = Describe("Receive Stream", func() {
 			Expect(str.getWindowUpdate()).To(Equal(protocol.ByteCount(0x100)))
 		})
 	})
+
+	Context("statistics", func() {
+		It("reports the bytes received and read, and the receive window", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 4, Data: []byte("foobar")})).To(Succeed())
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("raboof")[:4]})).To(Succeed())
+			b := make([]byte, 4)
+			n, err := strWithTimeout.Read(b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(4))
+			mockFC.EXPECT().ReceiveWindow().Return(protocol.ByteCount(100))
+			stats := str.Stats()
+			Expect(stats.BytesReceived).To(BeEquivalentTo(10))
+			Expect(stats.BytesRead).To(BeEquivalentTo(4))
+			Expect(stats.ReceiveWindow).To(BeEquivalentTo(90))
+			Expect(stats.BytesWritten).To(BeZero())
+		})
+
+		It("reports the final size of a reset stream as the bytes received", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+			mockFC.EXPECT().Abandon()
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{StreamID: streamID, FinalSize: 42})).To(Succeed())
+			mockFC.EXPECT().ReceiveWindow().Return(protocol.ByteCount(42))
+			stats := str.Stats()
+			Expect(stats.BytesReceived).To(BeEquivalentTo(42))
+			Expect(stats.ReceiveWindow).To(BeZero())
+		})
+	})
 })
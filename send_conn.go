@@ -2,14 +2,25 @@ package quic
 
 import (
 	"net"
+	"sync"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
 )
 
 // A sendConn allows sending using a simple Write() on a non-connected packet conn.
 type sendConn interface {
-	Write([]byte) error
+	// Write writes a packet. ecn must be protocol.ECNNon, unless capabilities() reports ECN support.
+	// If gsoSize is non-zero, b contains multiple packets, which are sent using GSO.
+	// This is only allowed if capabilities() reports GSO support.
+	Write(b []byte, gsoSize uint16, ecn protocol.ECN) error
 	Close() error
 	LocalAddr() net.Addr
 	RemoteAddr() net.Addr
+	// WithRemoteAddr returns a sendConn that uses the same underlying packet conn,
+	// but sends packets to a different remote address.
+	WithRemoteAddr(remote net.Addr, info *packetInfo) sendConn
+
+	capabilities() connCapabilities
 }
 
 type sconn struct {
@@ -31,8 +42,8 @@ func newSendConn(c rawConn, remote net.Addr, info *packetInfo) sendConn {
 	}
 }
 
-func (c *sconn) Write(p []byte) error {
-	_, err := c.WritePacket(p, c.remoteAddr, c.oob)
+func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
+	_, err := c.WritePacket(p, c.remoteAddr, c.oob, gsoSize, ecn)
 	return err
 }
 
@@ -40,6 +51,10 @@ func (c *sconn) RemoteAddr() net.Addr {
 	return c.remoteAddr
 }
 
+func (c *sconn) WithRemoteAddr(remote net.Addr, info *packetInfo) sendConn {
+	return newSendConn(c.rawConn, remote, info)
+}
+
 func (c *sconn) LocalAddr() net.Addr {
 	addr := c.rawConn.LocalAddr()
 	if c.info != nil {
@@ -64,7 +79,7 @@ func newSendPconn(c net.PacketConn, remote net.Addr) sendConn {
 	return &spconn{PacketConn: c, remoteAddr: remote}
 }
 
-func (c *spconn) Write(p []byte) error {
+func (c *spconn) Write(p []byte, _ uint16, _ protocol.ECN) error {
 	_, err := c.WriteTo(p, c.remoteAddr)
 	return err
 }
@@ -72,3 +87,50 @@ func (c *spconn) Write(p []byte) error {
 func (c *spconn) RemoteAddr() net.Addr {
 	return c.remoteAddr
 }
+
+func (c *spconn) WithRemoteAddr(remote net.Addr, _ *packetInfo) sendConn {
+	return newSendPconn(c.PacketConn, remote)
+}
+
+// We don't know if the net.PacketConn allows setting the ECN bits or sending packets using GSO.
+func (c *spconn) capabilities() connCapabilities { return connCapabilities{} }
+
+// A migratableSendConn is a sendConn whose underlying sendConn can be replaced
+// while the connection is running. This is used when a connection is migrated to a new path.
+type migratableSendConn struct {
+	mutex sync.RWMutex
+	conn  sendConn
+}
+
+var _ sendConn = &migratableSendConn{}
+
+func newMigratableSendConn(c sendConn) *migratableSendConn {
+	return &migratableSendConn{conn: c}
+}
+
+func (c *migratableSendConn) get() sendConn {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.conn
+}
+
+// Migrate replaces the underlying sendConn.
+// All subsequent calls are forwarded to the new sendConn.
+func (c *migratableSendConn) Migrate(conn sendConn) {
+	c.mutex.Lock()
+	c.conn = conn
+	c.mutex.Unlock()
+}
+
+func (c *migratableSendConn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
+	return c.get().Write(p, gsoSize, ecn)
+}
+
+func (c *migratableSendConn) Close() error                   { return c.get().Close() }
+func (c *migratableSendConn) LocalAddr() net.Addr            { return c.get().LocalAddr() }
+func (c *migratableSendConn) RemoteAddr() net.Addr           { return c.get().RemoteAddr() }
+func (c *migratableSendConn) capabilities() connCapabilities { return c.get().capabilities() }
+
+func (c *migratableSendConn) WithRemoteAddr(remote net.Addr, info *packetInfo) sendConn {
+	return c.get().WithRemoteAddr(remote, info)
+}
@@ -3,6 +3,8 @@ package quic
 import (
 	"net"
 
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -22,7 +24,11 @@ var _ = Describe("Connection (for sending packets)", func() {
 
 	It("writes", func() {
 		packetConn.EXPECT().WriteTo([]byte("foobar"), addr)
-		Expect(c.Write([]byte("foobar"))).To(Succeed())
+		Expect(c.Write([]byte("foobar"), 0, protocol.ECNNon)).To(Succeed())
+	})
+
+	It("doesn't support ECN", func() {
+		Expect(c.capabilities().ECN).To(BeFalse())
 	})
 
 	It("gets the remote address", func() {
@@ -42,4 +48,31 @@ var _ = Describe("Connection (for sending packets)", func() {
 		packetConn.EXPECT().Close()
 		Expect(c.Close()).To(Succeed())
 	})
+
+	It("sends to a different remote address", func() {
+		newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 201), Port: 4242}
+		c2 := c.WithRemoteAddr(newAddr, nil)
+		Expect(c2.RemoteAddr()).To(Equal(newAddr))
+		packetConn.EXPECT().WriteTo([]byte("foobar"), newAddr)
+		Expect(c2.Write([]byte("foobar"), 0, protocol.ECNNon)).To(Succeed())
+		Expect(c.RemoteAddr()).To(Equal(addr))
+	})
+})
+
+var _ = Describe("Migratable connection", func() {
+	It("forwards calls to the connection it was migrated to", func() {
+		conn1 := NewMockSendConn(mockCtrl)
+		conn2 := NewMockSendConn(mockCtrl)
+		c := newMigratableSendConn(conn1)
+		conn1.EXPECT().Write([]byte("foo"), uint16(0), protocol.ECNNon)
+		Expect(c.Write([]byte("foo"), 0, protocol.ECNNon)).To(Succeed())
+		c.Migrate(conn2)
+		conn2.EXPECT().Write([]byte("bar"), uint16(0), protocol.ECNNon)
+		Expect(c.Write([]byte("bar"), 0, protocol.ECNNon)).To(Succeed())
+		addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1234}
+		conn2.EXPECT().LocalAddr().Return(addr)
+		Expect(c.LocalAddr()).To(Equal(addr))
+		conn2.EXPECT().capabilities().Return(connCapabilities{ECN: true})
+		Expect(c.capabilities().ECN).To(BeTrue())
+	})
 })
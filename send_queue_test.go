@@ -4,6 +4,8 @@ import (
 	"errors"
 
 	"github.com/golang/mock/gomock"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -26,10 +28,10 @@ var _ = Describe("Send Queue", func() {
 
 	It("sends a packet", func() {
 		p := getPacket([]byte("foobar"))
-		q.Send(p)
+		q.Send(p, 3, protocol.ECT0)
 
 		written := make(chan struct{})
-		c.EXPECT().Write([]byte("foobar")).Do(func([]byte) { close(written) })
+		c.EXPECT().Write([]byte("foobar"), uint16(3), protocol.ECT0).Do(func([]byte, uint16, protocol.ECN) { close(written) })
 		done := make(chan struct{})
 		go func() {
 			defer GinkgoRecover()
@@ -45,19 +47,19 @@ var _ = Describe("Send Queue", func() {
 	It("panics when Send() is called although there's no space in the queue", func() {
 		for i := 0; i < sendQueueCapacity; i++ {
 			Expect(q.WouldBlock()).To(BeFalse())
-			q.Send(getPacket([]byte("foobar")))
+			q.Send(getPacket([]byte("foobar")), 0, protocol.ECNNon)
 		}
 		Expect(q.WouldBlock()).To(BeTrue())
-		Expect(func() { q.Send(getPacket([]byte("raboof"))) }).To(Panic())
+		Expect(func() { q.Send(getPacket([]byte("raboof")), 0, protocol.ECNNon) }).To(Panic())
 	})
 
 	It("signals when sending is possible again", func() {
 		Expect(q.WouldBlock()).To(BeFalse())
-		q.Send(getPacket([]byte("foobar1")))
+		q.Send(getPacket([]byte("foobar1")), 0, protocol.ECNNon)
 		Consistently(q.Available()).ShouldNot(Receive())
 
 		// now start sending out packets. This should free up queue space.
-		c.EXPECT().Write(gomock.Any()).MinTimes(1).MaxTimes(2)
+		c.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon).MinTimes(1).MaxTimes(2)
 		done := make(chan struct{})
 		go func() {
 			defer GinkgoRecover()
@@ -67,7 +69,7 @@ var _ = Describe("Send Queue", func() {
 
 		Eventually(q.Available()).Should(Receive())
 		Expect(q.WouldBlock()).To(BeFalse())
-		Expect(func() { q.Send(getPacket([]byte("foobar2"))) }).ToNot(Panic())
+		Expect(func() { q.Send(getPacket([]byte("foobar2")), 0, protocol.ECNNon) }).ToNot(Panic())
 
 		q.Close()
 		Eventually(done).Should(BeClosed())
@@ -77,7 +79,7 @@ var _ = Describe("Send Queue", func() {
 		write := make(chan struct{}, 1)
 		written := make(chan struct{}, 100)
 		// now start sending out packets. This should free up queue space.
-		c.EXPECT().Write(gomock.Any()).DoAndReturn(func(b []byte) error {
+		c.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon).DoAndReturn(func(b []byte, _ uint16, _ protocol.ECN) error {
 			<-write
 			written <- struct{}{}
 			return nil
@@ -92,13 +94,13 @@ var _ = Describe("Send Queue", func() {
 			close(done)
 		}()
 
-		q.Send(getPacket([]byte("foobar")))
+		q.Send(getPacket([]byte("foobar")), 0, protocol.ECNNon)
 		<-written
 
 		// now fill up the send queue
 		for i := 0; i < sendQueueCapacity+1; i++ {
 			Expect(q.WouldBlock()).To(BeFalse())
-			q.Send(getPacket([]byte("foobar")))
+			q.Send(getPacket([]byte("foobar")), 0, protocol.ECNNon)
 		}
 
 		Expect(q.WouldBlock()).To(BeTrue())
@@ -125,15 +127,15 @@ var _ = Describe("Send Queue", func() {
 
 		// the run loop exits if there is a write error
 		testErr := errors.New("test error")
-		c.EXPECT().Write(gomock.Any()).Return(testErr)
-		q.Send(getPacket([]byte("foobar")))
+		c.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon).Return(testErr)
+		q.Send(getPacket([]byte("foobar")), 0, protocol.ECNNon)
 		Eventually(done).Should(BeClosed())
 
 		sent := make(chan struct{})
 		go func() {
 			defer GinkgoRecover()
-			q.Send(getPacket([]byte("raboof")))
-			q.Send(getPacket([]byte("quux")))
+			q.Send(getPacket([]byte("raboof")), 0, protocol.ECNNon)
+			q.Send(getPacket([]byte("quux")), 0, protocol.ECNNon)
 			close(sent)
 		}()
 
@@ -142,7 +144,7 @@ var _ = Describe("Send Queue", func() {
 
 	It("blocks Close() until the packet has been sent out", func() {
 		written := make(chan []byte)
-		c.EXPECT().Write(gomock.Any()).Do(func(p []byte) { written <- p })
+		c.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon).Do(func(p []byte, _ uint16, _ protocol.ECN) { written <- p })
 		done := make(chan struct{})
 		go func() {
 			defer GinkgoRecover()
@@ -150,7 +152,7 @@ var _ = Describe("Send Queue", func() {
 			close(done)
 		}()
 
-		q.Send(getPacket([]byte("foobar")))
+		q.Send(getPacket([]byte("foobar")), 0, protocol.ECNNon)
 
 		closed := make(chan struct{})
 		go func() {
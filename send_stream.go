@@ -2,6 +2,7 @@ package quic
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -37,9 +38,22 @@ type sendStream struct {
 
 	writeOffset protocol.ByteCount
 
+	bytesAcked         protocol.ByteCount
+	bytesRetransmitted protocol.ByteCount
+	// The flow control state is only accessed from the run loop.
+	// These fields save it when popping a STREAM frame, such that it can be reported by Stats.
+	sendWindow          protocol.ByteCount
+	blockedByStream     bool
+	blockedByConnection bool
+
 	cancelWriteErr      error
 	closeForShutdownErr error
 
+	// When the stream is canceled using CancelWrite, reliableSize is 0.
+	// When it is canceled using CancelWriteAt, data up to reliableSize is still delivered reliably.
+	reliableSize protocol.ByteCount
+	finalSize    protocol.ByteCount // the final size sent in the RESET_STREAM(_AT) frame
+
 	closedForShutdown bool // set when CloseForShutdown() is called
 	finishedWriting   bool // set once Close() is called
 	canceledWrite     bool // set when CancelWrite() is called, or a STOP_SENDING frame is received
@@ -126,7 +140,7 @@ func (s *sendStream) Write(p []byte) (int, error) {
 		// This allows us to return Write() when all data but x bytes have been sent out.
 		// When the user now calls Close(), this is much more likely to happen before we popped that last STREAM frame,
 		// allowing us to set the FIN bit on that frame (instead of sending an empty STREAM frame with FIN).
-		if s.canBufferStreamFrame() && len(s.dataForWriting) > 0 {
+		if s.canBufferStreamFrame() && len(s.dataForWriting) > 0 && !s.canceledWrite {
 			if s.nextFrame == nil {
 				f := wire.GetStreamFrame()
 				f.Offset = s.writeOffset
@@ -219,7 +233,7 @@ func (s *sendStream) popStreamFrame(maxBytes protocol.ByteCount) (*ackhandler.Fr
 }
 
 func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCount) (*wire.StreamFrame, bool /* has more data to send */) {
-	if s.canceledWrite || s.closeForShutdownErr != nil {
+	if (s.canceledWrite && s.reliableSize == 0) || s.closeForShutdownErr != nil {
 		return nil, false
 	}
 
@@ -235,6 +249,10 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 		}
 	}
 
+	// After CancelWriteAt, only the data up to the reliable size (which was trimmed to fit into nextFrame) is sent.
+	if s.canceledWrite && s.nextFrame == nil {
+		return nil, false
+	}
 	if len(s.dataForWriting) == 0 && s.nextFrame == nil {
 		if s.finishedWriting && !s.finSent {
 			s.finSent = true
@@ -249,7 +267,11 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 	}
 
 	sendWindow := s.flowController.SendWindowSize()
+	s.sendWindow = sendWindow
 	if sendWindow == 0 {
+		streamWindow, connWindow := s.flowController.SendWindowSizes()
+		s.blockedByStream = streamWindow == 0
+		s.blockedByConnection = connWindow == 0
 		if isBlocked, offset := s.flowController.IsNewlyBlocked(); isBlocked {
 			s.sender.queueControlFrame(&wire.StreamDataBlockedFrame{
 				StreamID:          s.streamID,
@@ -260,10 +282,16 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 		return nil, true
 	}
 
+	s.blockedByStream = false
+	s.blockedByConnection = false
 	f, hasMoreData := s.popNewStreamFrame(maxBytes, sendWindow)
 	if dataLen := f.DataLen(); dataLen > 0 {
 		s.writeOffset += f.DataLen()
 		s.flowController.AddBytesSent(f.DataLen())
+		s.sendWindow -= f.DataLen()
+	}
+	if s.canceledWrite {
+		return f, s.nextFrame != nil
 	}
 	f.Fin = s.finishedWriting && s.dataForWriting == nil && s.nextFrame == nil && !s.finSent
 	if f.Fin {
@@ -351,13 +379,16 @@ func (s *sendStream) getDataForWriting(f *wire.StreamFrame, maxBytes protocol.By
 }
 
 func (s *sendStream) frameAcked(f wire.Frame) {
-	f.(*wire.StreamFrame).PutBack()
+	sf := f.(*wire.StreamFrame)
+	dataLen := sf.DataLen()
+	sf.PutBack()
 
 	s.mutex.Lock()
-	if s.canceledWrite {
+	if s.canceledWrite && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
+	s.bytesAcked += dataLen
 	s.numOutstandingFrames--
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
@@ -371,7 +402,7 @@ func (s *sendStream) frameAcked(f wire.Frame) {
 }
 
 func (s *sendStream) isNewlyCompleted() bool {
-	completed := (s.finSent || s.canceledWrite) && s.numOutstandingFrames == 0 && len(s.retransmissionQueue) == 0
+	completed := (s.finSent || s.canceledWrite) && s.numOutstandingFrames == 0 && len(s.retransmissionQueue) == 0 && s.nextFrame == nil
 	if completed && !s.completed {
 		s.completed = true
 		return true
@@ -383,15 +414,24 @@ func (s *sendStream) queueRetransmission(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
 	sf.DataLenPresent = true
 	s.mutex.Lock()
-	if s.canceledWrite {
+	if s.canceledWrite && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
-	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.numOutstandingFrames--
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
 	}
+	if s.canceledWrite && !s.trimToReliableSize(sf) {
+		newlyCompleted := s.isNewlyCompleted()
+		s.mutex.Unlock()
+		if newlyCompleted {
+			s.sender.onStreamCompleted(s.streamID)
+		}
+		return
+	}
+	s.retransmissionQueue = append(s.retransmissionQueue, sf)
+	s.bytesRetransmitted += sf.DataLen()
 	s.mutex.Unlock()
 
 	s.sender.onHasStreamData(s.streamID)
@@ -416,48 +456,129 @@ func (s *sendStream) Close() error {
 }
 
 func (s *sendStream) CancelWrite(errorCode StreamErrorCode) {
-	s.cancelWriteImpl(errorCode, fmt.Errorf("Write on stream %d canceled with error code %d", s.streamID, errorCode))
+	s.cancelWriteImpl(errorCode, 0, fmt.Errorf("Write on stream %d canceled with error code %d", s.streamID, errorCode))
 }
 
-// must be called after locking the mutex
-func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, writeErr error) {
+func (s *sendStream) CancelWriteAt(errorCode StreamErrorCode, reliableSize uint64) error {
+	if !s.sender.supportsResetStreamAt() {
+		return errors.New("peer doesn't support reliable stream resets")
+	}
 	s.mutex.Lock()
-	if s.canceledWrite {
+	bytesWritten := s.writeOffset
+	if s.nextFrame != nil {
+		bytesWritten += s.nextFrame.DataLen()
+	}
+	canceled := s.canceledWrite
+	s.mutex.Unlock()
+	if !canceled && protocol.ByteCount(reliableSize) > bytesWritten {
+		return fmt.Errorf("reliable size (%d) larger than the number of bytes written (%d)", reliableSize, bytesWritten)
+	}
+	s.cancelWriteImpl(errorCode, protocol.ByteCount(reliableSize), fmt.Errorf("Write on stream %d canceled with error code %d", s.streamID, errorCode))
+	return nil
+}
+
+func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, reliableSize protocol.ByteCount, writeErr error) {
+	s.mutex.Lock()
+	// A stream that was already canceled can only be canceled again with a smaller reliable size.
+	if s.canceledWrite && reliableSize >= s.reliableSize {
 		s.mutex.Unlock()
 		return
 	}
-	s.ctxCancel()
-	s.canceledWrite = true
-	s.cancelWriteErr = writeErr
-	s.numOutstandingFrames = 0
-	s.retransmissionQueue = nil
+	if !s.canceledWrite {
+		s.ctxCancel()
+		s.canceledWrite = true
+		s.cancelWriteErr = writeErr
+		// Data up to the reliable size that wasn't sent yet is still sent out.
+		s.finalSize = utils.Max(s.writeOffset, reliableSize)
+	}
+	s.reliableSize = reliableSize
+	if reliableSize == 0 {
+		s.numOutstandingFrames = 0
+		s.retransmissionQueue = nil
+	}
+	s.discardUnreliableData()
 	newlyCompleted := s.isNewlyCompleted()
 	s.mutex.Unlock()
 
 	s.signalWrite()
 	s.sender.queueControlFrame(&wire.ResetStreamFrame{
-		StreamID:  s.streamID,
-		FinalSize: s.writeOffset,
-		ErrorCode: errorCode,
+		StreamID:     s.streamID,
+		FinalSize:    s.finalSize,
+		ReliableSize: reliableSize,
+		ErrorCode:    errorCode,
 	})
 	if newlyCompleted {
 		s.sender.onStreamCompleted(s.streamID)
 	}
 }
 
+// discardUnreliableData drops all data beyond the reliable size from the nextFrame and the retransmission queue.
+// must be called after locking the mutex
+func (s *sendStream) discardUnreliableData() {
+	if s.nextFrame != nil && !s.trimToReliableSize(s.nextFrame) {
+		s.nextFrame = nil
+	}
+	if len(s.retransmissionQueue) == 0 {
+		return
+	}
+	queue := s.retransmissionQueue[:0]
+	for _, f := range s.retransmissionQueue {
+		if s.trimToReliableSize(f) {
+			queue = append(queue, f)
+		}
+	}
+	s.retransmissionQueue = queue
+}
+
+// trimToReliableSize cuts off the data of a STREAM frame that lies beyond the reliable size.
+// If no data is left, the frame is returned to the pool, and false is returned.
+func (s *sendStream) trimToReliableSize(f *wire.StreamFrame) bool /* keep the frame */ {
+	if f.Offset >= s.reliableSize {
+		f.PutBack()
+		return false
+	}
+	if f.Offset+f.DataLen() > s.reliableSize {
+		f.Data = f.Data[:s.reliableSize-f.Offset]
+		f.Fin = false
+	}
+	return true
+}
+
 func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
 	s.mutex.Lock()
 	hasStreamData := s.dataForWriting != nil || s.nextFrame != nil
 	s.mutex.Unlock()
 
 	s.flowController.UpdateSendWindow(limit)
+	sendWindow := s.flowController.SendWindowSize()
+	s.mutex.Lock()
+	s.sendWindow = sendWindow
+	s.mutex.Unlock()
 	if hasStreamData {
 		s.sender.onHasStreamData(s.streamID)
 	}
 }
 
+func (s *sendStream) Stats() StreamStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	bytesWritten := s.writeOffset
+	if s.nextFrame != nil {
+		bytesWritten += s.nextFrame.DataLen()
+	}
+	return StreamStats{
+		BytesWritten:                   uint64(bytesWritten),
+		BytesAcked:                     uint64(s.bytesAcked),
+		BytesRetransmitted:             uint64(s.bytesRetransmitted),
+		SendWindow:                     uint64(s.sendWindow),
+		BlockedByStreamFlowControl:     s.blockedByStream,
+		BlockedByConnectionFlowControl: s.blockedByConnection,
+	}
+}
+
 func (s *sendStream) handleStopSendingFrame(frame *wire.StopSendingFrame) {
-	s.cancelWriteImpl(frame.ErrorCode, &StreamError{
+	s.cancelWriteImpl(frame.ErrorCode, 0, &StreamError{
 		StreamID:  s.streamID,
 		ErrorCode: frame.ErrorCode,
 	})
@@ -475,6 +596,16 @@ func (s *sendStream) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+func (s *sendStream) SetPriority(urgency uint8, incremental bool) {
+	s.mutex.Lock()
+	completed := s.completed
+	s.mutex.Unlock()
+	if completed {
+		return
+	}
+	s.sender.setStreamPriority(s.streamID, newStreamPriority(urgency, incremental)) // must be called without holding the mutex
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Write unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RST.
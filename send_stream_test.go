@@ -71,6 +71,13 @@ var _ = Describe("Send Stream", func() {
 		Expect(str.StreamID()).To(Equal(protocol.StreamID(1337)))
 	})
 
+	It("sets the priority", func() {
+		mockSender.EXPECT().setStreamPriority(streamID, StreamPriority{Urgency: 1, Incremental: false})
+		str.SetPriority(1, false)
+		mockSender.EXPECT().setStreamPriority(streamID, StreamPriority{Urgency: 7, Incremental: true})
+		str.SetPriority(10, true)
+	})
+
 	Context("writing", func() {
 		It("writes and gets all data at once", func() {
 			done := make(chan struct{})
@@ -309,6 +316,7 @@ var _ = Describe("Send Stream", func() {
 		Context("flow control blocking", func() {
 			It("queues a BLOCKED frame if the stream is flow control blocked", func() {
 				mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(0))
+				mockFC.EXPECT().SendWindowSizes().Return(protocol.ByteCount(0), protocol.ByteCount(100))
 				mockFC.EXPECT().IsNewlyBlocked().Return(true, protocol.ByteCount(12))
 				mockSender.EXPECT().queueControlFrame(&wire.StreamDataBlockedFrame{
 					StreamID:          streamID,
@@ -351,6 +359,7 @@ var _ = Describe("Send Stream", func() {
 
 				// try to pop again, this time noticing that we're blocked
 				mockFC.EXPECT().SendWindowSize()
+				mockFC.EXPECT().SendWindowSizes()
 				// don't use offset 3 here, to make sure the BLOCKED frame contains the number returned by the flow controller
 				mockFC.EXPECT().IsNewlyBlocked().Return(true, protocol.ByteCount(10))
 				mockSender.EXPECT().queueControlFrame(&wire.StreamDataBlockedFrame{
@@ -652,11 +661,14 @@ var _ = Describe("Send Stream", func() {
 	Context("handling MAX_STREAM_DATA frames", func() {
 		It("informs the flow controller", func() {
 			mockFC.EXPECT().UpdateSendWindow(protocol.ByteCount(0x1337))
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(0x1000))
 			str.updateSendWindow(0x1337)
+			Expect(str.Stats().SendWindow).To(BeEquivalentTo(0x1000))
 		})
 
 		It("says when it has data for sending", func() {
 			mockFC.EXPECT().UpdateSendWindow(gomock.Any())
+			mockFC.EXPECT().SendWindowSize()
 			mockSender.EXPECT().onHasStreamData(streamID)
 			done := make(chan struct{})
 			go func() {
@@ -840,6 +852,129 @@ var _ = Describe("Send Stream", func() {
 			})
 		})
 
+		Context("canceling writing with a reliable size", func() {
+			It("errors if the peer doesn't support reliable stream resets", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(false)
+				Expect(str.CancelWriteAt(1234, 0)).To(MatchError("peer doesn't support reliable stream resets"))
+			})
+
+			It("errors if the reliable size is larger than the number of bytes written", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.CancelWriteAt(1234, 101)).To(MatchError("reliable size (101) larger than the number of bytes written (100)"))
+			})
+
+			It("sends data up to the reliable size", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					FinalSize:    40,
+					ReliableSize: 40,
+					ErrorCode:    1234,
+				})
+				Expect(str.CancelWriteAt(1234, 40)).To(Succeed())
+				_, err = strWithTimeout.Write([]byte("foobar"))
+				Expect(err).To(MatchError("Write on stream 1337 canceled with error code 1234"))
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(40))
+				frame, hasMoreData := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).ToNot(BeNil())
+				Expect(hasMoreData).To(BeFalse())
+				f := frame.Frame.(*wire.StreamFrame)
+				Expect(f.Data).To(Equal(getData(40)))
+				Expect(f.Fin).To(BeFalse())
+				next, hasMoreData := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(next).To(BeNil())
+				Expect(hasMoreData).To(BeFalse())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame.OnAcked(f)
+			})
+
+			It("retransmits data up to the reliable size", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(100))
+				frame, _ := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).ToNot(BeNil())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					FinalSize:    100,
+					ReliableSize: 30,
+					ErrorCode:    1234,
+				})
+				Expect(str.CancelWriteAt(1234, 30)).To(Succeed())
+				mockSender.EXPECT().onHasStreamData(streamID)
+				frame.OnLost(frame.Frame)
+				frame, _ = str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).ToNot(BeNil())
+				Expect(frame.Frame.(*wire.StreamFrame).Data).To(Equal(getData(30)))
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame.OnAcked(frame.Frame)
+			})
+
+			It("doesn't retransmit data beyond the reliable size", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).Times(2)
+				mockFC.EXPECT().AddBytesSent(gomock.Any()).Times(2)
+				frame1, _ := str.popStreamFrame(expectedFrameHeaderLen(0) + 50)
+				Expect(frame1).ToNot(BeNil())
+				frame2, _ := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame2).ToNot(BeNil())
+				Expect(frame2.Frame.(*wire.StreamFrame).Offset).To(BeEquivalentTo(50))
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				Expect(str.CancelWriteAt(1234, 50)).To(Succeed())
+				frame2.OnLost(frame2.Frame)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame1.OnAcked(frame1.Frame)
+			})
+
+			It("only allows lowering the reliable size", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true).Times(3)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					FinalSize:    50,
+					ReliableSize: 50,
+					ErrorCode:    1234,
+				})
+				Expect(str.CancelWriteAt(1234, 50)).To(Succeed())
+				Expect(str.CancelWriteAt(1234, 60)).To(Succeed())
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					FinalSize:    50,
+					ReliableSize: 20,
+					ErrorCode:    1234,
+				})
+				Expect(str.CancelWriteAt(1234, 20)).To(Succeed())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(20))
+				frame, _ := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).ToNot(BeNil())
+				Expect(frame.Frame.(*wire.StreamFrame).Data).To(Equal(getData(20)))
+				// CancelWrite resets the stream without any reliable size
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:  streamID,
+					FinalSize: 50,
+					ErrorCode: 1234,
+				})
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				str.CancelWrite(1234)
+			})
+		})
+
 		Context("receiving STOP_SENDING frames", func() {
 			It("queues a RESET_STREAM frames, and copies the error code from the STOP_SENDING frame", func() {
 				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
@@ -892,6 +1027,87 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("statistics", func() {
+		It("reports the bytes written, acknowledged and retransmitted", func() {
+			mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				_, err := str.Write(getData(5000))
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			waitForWrite()
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).Times(2)
+			mockFC.EXPECT().AddBytesSent(gomock.Any()).Times(2)
+			f1, _ := str.popStreamFrame(1000)
+			Expect(f1).ToNot(BeNil())
+			f2, _ := str.popStreamFrame(1000)
+			Expect(f2).ToNot(BeNil())
+			len1 := f1.Frame.(*wire.StreamFrame).DataLen()
+			len2 := f2.Frame.(*wire.StreamFrame).DataLen()
+			Expect(str.Stats().BytesWritten).To(BeNumerically(">=", len1+len2))
+			f1.OnAcked(f1.Frame)
+			f2.OnLost(f2.Frame)
+			stats := str.Stats()
+			Expect(stats.BytesAcked).To(BeEquivalentTo(len1))
+			Expect(stats.BytesRetransmitted).To(BeEquivalentTo(len2))
+			Expect(stats.BlockedByStreamFlowControl).To(BeFalse())
+			Expect(stats.BlockedByConnectionFlowControl).To(BeFalse())
+			str.closeForShutdown(nil)
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("reports the send window and the flow control blocked state", func() {
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := str.Write([]byte("foobar"))
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			waitForWrite()
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(10))
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(3))
+			f, _ := str.popStreamFrame(expectedFrameHeaderLen(0) + 3)
+			Expect(f).ToNot(BeNil())
+			Expect(str.Stats().SendWindow).To(BeEquivalentTo(7))
+
+			// blocked by connection-level flow control
+			mockFC.EXPECT().SendWindowSize()
+			mockFC.EXPECT().SendWindowSizes().Return(protocol.ByteCount(7), protocol.ByteCount(0))
+			mockFC.EXPECT().IsNewlyBlocked()
+			f, _ = str.popStreamFrame(1000)
+			Expect(f).To(BeNil())
+			stats := str.Stats()
+			Expect(stats.SendWindow).To(BeZero())
+			Expect(stats.BlockedByStreamFlowControl).To(BeFalse())
+			Expect(stats.BlockedByConnectionFlowControl).To(BeTrue())
+
+			// blocked by stream-level flow control
+			mockFC.EXPECT().SendWindowSize()
+			mockFC.EXPECT().SendWindowSizes().Return(protocol.ByteCount(0), protocol.ByteCount(100))
+			mockFC.EXPECT().IsNewlyBlocked()
+			f, _ = str.popStreamFrame(1000)
+			Expect(f).To(BeNil())
+			stats = str.Stats()
+			Expect(stats.BlockedByStreamFlowControl).To(BeTrue())
+			Expect(stats.BlockedByConnectionFlowControl).To(BeFalse())
+
+			// unblocked
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(100))
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(3))
+			f, _ = str.popStreamFrame(1000)
+			Expect(f).ToNot(BeNil())
+			stats = str.Stats()
+			Expect(stats.SendWindow).To(BeEquivalentTo(97))
+			Expect(stats.BlockedByStreamFlowControl).To(BeFalse())
+			Expect(stats.BlockedByConnectionFlowControl).To(BeFalse())
+			Eventually(done).Should(BeClosed())
+		})
+	})
+
 	Context("retransmissions", func() {
 		It("queues and retrieves frames", func() {
 			str.numOutstandingFrames = 1
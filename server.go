@@ -42,6 +42,7 @@ type packetHandlerManager interface {
 	connRunner
 	SetServer(unknownPacketHandler)
 	CloseServer()
+	SetStatelessResetKeys([]StatelessResetKey)
 }
 
 type quicConn interface {
@@ -72,6 +73,8 @@ type baseServer struct {
 	tokenGenerator *handshake.TokenGenerator
 
 	connHandler packetHandlerManager
+	// receives packets sent to the preferred address, nil if no preferred address is configured
+	preferredAddressConnHandler packetHandlerManager
 
 	receivedPackets chan *receivedPacket
 
@@ -79,6 +82,7 @@ type baseServer struct {
 	newConn func(
 		sendConn,
 		connRunner,
+		connRunner, /* preferred address runner */
 		protocol.ConnectionID, /* original dest connection ID */
 		*protocol.ConnectionID, /* retry src connection ID */
 		protocol.ConnectionID, /* client dest connection ID */
@@ -104,6 +108,12 @@ type baseServer struct {
 	connQueue    chan quicConn
 	connQueueLen int32 // to be used as an atomic
 
+	// set when Shutdown is called, new connection attempts are refused
+	shuttingDown utils.AtomicBool
+	connsMutex   sync.Mutex
+	conns        map[quicConn]struct{} // connections that haven't been closed yet
+	connClosed   chan struct{}         // signaled every time a connection is removed from conns
+
 	logger utils.Logger
 }
 
@@ -147,6 +157,7 @@ func listenAddr(addr string, tlsConf *tls.Config, config *Config, acceptEarly bo
 	}
 	serv, err := listen(conn, tlsConf, config, acceptEarly)
 	if err != nil {
+		conn.Close()
 		return nil, err
 	}
 	serv.createdPacketConn = true
@@ -195,7 +206,17 @@ func listen(conn net.PacketConn, tlsConf *tls.Config, config *Config, acceptEarl
 	if err != nil {
 		return nil, err
 	}
-	tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader)
+	var preferredAddressConnHandler packetHandlerManager
+	if config.PreferredAddress != nil {
+		preferredAddressConnHandler, err = getMultiplexer().AddConn(config.PreferredAddress.Conn, config.ConnectionIDGenerator.ConnectionIDLen(), config.StatelessResetKey, config.Tracer)
+		if err != nil {
+			return nil, err
+		}
+		if preferredAddressConnHandler == connHandler {
+			return nil, errors.New("quic: the preferred address must use a different packet conn")
+		}
+	}
+	tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader, toTokenProtectorKeys(config.TokenKeys))
 	if err != nil {
 		return nil, err
 	}
@@ -204,18 +225,21 @@ func listen(conn net.PacketConn, tlsConf *tls.Config, config *Config, acceptEarl
 		return nil, err
 	}
 	s := &baseServer{
-		conn:             c,
-		tlsConf:          tlsConf,
-		config:           config,
-		tokenGenerator:   tokenGenerator,
-		connHandler:      connHandler,
-		connQueue:        make(chan quicConn),
-		errorChan:        make(chan struct{}),
-		running:          make(chan struct{}),
-		receivedPackets:  make(chan *receivedPacket, protocol.MaxServerUnprocessedPackets),
-		newConn:          newConnection,
-		logger:           utils.DefaultLogger.WithPrefix("server"),
-		acceptEarlyConns: acceptEarly,
+		conn:                        c,
+		tlsConf:                     tlsConf,
+		config:                      config,
+		tokenGenerator:              tokenGenerator,
+		connHandler:                 connHandler,
+		preferredAddressConnHandler: preferredAddressConnHandler,
+		connQueue:                   make(chan quicConn),
+		conns:                       make(map[quicConn]struct{}),
+		connClosed:                  make(chan struct{}, 1),
+		errorChan:                   make(chan struct{}),
+		running:                     make(chan struct{}),
+		receivedPackets:             make(chan *receivedPacket, protocol.MaxServerUnprocessedPackets),
+		newConn:                     newConnection,
+		logger:                      utils.DefaultLogger.WithPrefix("server"),
+		acceptEarlyConns:            acceptEarly,
 	}
 	go s.run()
 	connHandler.SetServer(s)
@@ -279,12 +303,70 @@ func (s *baseServer) Close() error {
 
 	<-s.running
 	s.connHandler.CloseServer()
+	if s.preferredAddressConnHandler != nil {
+		// Connections that migrated to the preferred address are only registered with this packet handler.
+		s.preferredAddressConnHandler.CloseServer()
+	}
 	if createdPacketConn {
 		return s.connHandler.Destroy()
 	}
 	return nil
 }
 
+// Shutdown gracefully shuts down the server.
+// New connection attempts are refused, while existing connections are allowed to finish.
+// Once all connections have been closed, or when the context expires, the server is closed.
+// Connections that are still open at that point are closed using the Config.ShutdownErrorCode.
+// If the context expires, it returns the context's error.
+func (s *baseServer) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Set(true)
+	s.logger.Debugf("Shutting down. Refusing new connection attempts.")
+
+	var err error
+drain:
+	for {
+		s.connsMutex.Lock()
+		numConns := len(s.conns)
+		s.connsMutex.Unlock()
+		if numConns == 0 {
+			break
+		}
+		select {
+		case <-s.connClosed:
+		case <-ctx.Done():
+			err = ctx.Err()
+			s.closeConns()
+			break drain
+		}
+	}
+	if closeErr := s.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// closeConns closes all connections that haven't been closed yet, using the Config.ShutdownErrorCode.
+func (s *baseServer) closeConns() {
+	s.connsMutex.Lock()
+	conns := make([]quicConn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.connsMutex.Unlock()
+
+	s.logger.Debugf("Closing %d connections.", len(conns))
+	var wg sync.WaitGroup
+	wg.Add(len(conns))
+	for _, conn := range conns {
+		go func(conn quicConn) {
+			defer wg.Done()
+			// blocks until the CONNECTION_CLOSE has been sent and the run-loop has stopped
+			conn.CloseWithError(s.config.ShutdownErrorCode, "server shutting down")
+		}(conn)
+	}
+	wg.Wait()
+}
+
 func (s *baseServer) setCloseError(e error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -301,6 +383,40 @@ func (s *baseServer) Addr() net.Addr {
 	return s.conn.LocalAddr()
 }
 
+// SetTokenKeys replaces the keys used to protect address validation tokens.
+func (s *baseServer) SetTokenKeys(keys []TokenKey) error {
+	if len(keys) == 0 {
+		return errors.New("quic: no token keys")
+	}
+	return s.tokenGenerator.SetKeys(toTokenProtectorKeys(keys))
+}
+
+// SetStatelessResetKeys replaces the keys used to derive stateless reset tokens.
+func (s *baseServer) SetStatelessResetKeys(keys []StatelessResetKey) error {
+	if len(keys) == 0 {
+		return errors.New("quic: no stateless reset keys")
+	}
+	if len(keys) > maxStatelessResetKeys {
+		return fmt.Errorf("quic: too many stateless reset keys (%d), at most %d are supported", len(keys), maxStatelessResetKeys)
+	}
+	s.connHandler.SetStatelessResetKeys(keys)
+	if s.preferredAddressConnHandler != nil {
+		s.preferredAddressConnHandler.SetStatelessResetKeys(keys)
+	}
+	return nil
+}
+
+func toTokenProtectorKeys(keys []TokenKey) []handshake.TokenProtectorKey {
+	if len(keys) == 0 {
+		return nil
+	}
+	tokenKeys := make([]handshake.TokenProtectorKey, 0, len(keys))
+	for _, key := range keys {
+		tokenKeys = append(tokenKeys, handshake.TokenProtectorKey(key))
+	}
+	return tokenKeys
+}
+
 func (s *baseServer) handlePacket(p *receivedPacket) {
 	select {
 	case s.receivedPackets <- p:
@@ -415,6 +531,17 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 		return errors.New("too short connection ID")
 	}
 
+	if s.shuttingDown.Get() {
+		s.logger.Debugf("Rejecting new connection. Server is shutting down.")
+		go func() {
+			defer p.buffer.Release()
+			if err := s.sendConnectionRefused(p.remoteAddr, hdr, p.info); err != nil {
+				s.logger.Debugf("Error rejecting connection: %s", err)
+			}
+		}()
+		return nil
+	}
+
 	var (
 		token          *handshake.Token
 		retrySrcConnID *protocol.ConnectionID
@@ -473,8 +600,40 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 		return nil
 	}
 
+	if ac := s.config.AdmissionController; ac != nil {
+		switch ac.admit(p.remoteAddr, token != nil) {
+		case admissionRefuse:
+			s.logger.Debugf("Rejecting new connection. Too many concurrent handshakes.")
+			go func() {
+				defer p.buffer.Release()
+				if err := s.sendConnectionRefused(p.remoteAddr, hdr, p.info); err != nil {
+					s.logger.Debugf("Error rejecting connection: %s", err)
+				}
+			}()
+			return nil
+		case admissionRetry:
+			go func() {
+				defer p.buffer.Release()
+				if err := s.sendRetry(p.remoteAddr, hdr, p.info); err != nil {
+					s.logger.Debugf("Error sending Retry: %s", err)
+				}
+			}()
+			return nil
+		case admissionDrop:
+			p.buffer.Release()
+			if s.config.Tracer != nil {
+				s.config.Tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention)
+			}
+			s.logger.Debugf("Dropping Initial packet from %s (%d bytes). Connection attempt rate limit exceeded.", p.remoteAddr, p.Size())
+			return nil
+		}
+	}
+
 	connID, err := s.config.ConnectionIDGenerator.GenerateConnectionID()
 	if err != nil {
+		if s.config.AdmissionController != nil {
+			s.config.AdmissionController.handshakeDone()
+		}
 		return err
 	}
 	s.logger.Debugf("Changing connection ID to %s.", connID)
@@ -497,6 +656,7 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 		conn = s.newConn(
 			newSendConn(s.conn, p.remoteAddr, p.info),
 			s.connHandler,
+			s.preferredAddressConnHandler,
 			origDestConnID,
 			retrySrcConnID,
 			hdr.DestConnectionID,
@@ -516,8 +676,14 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 		conn.handlePacket(p)
 		return conn
 	}); !added {
+		if s.config.AdmissionController != nil {
+			s.config.AdmissionController.handshakeDone()
+		}
 		return nil
 	}
+	s.connsMutex.Lock()
+	s.conns[conn] = struct{}{}
+	s.connsMutex.Unlock()
 	go conn.run()
 	go s.handleNewConn(conn)
 	if conn == nil {
@@ -529,29 +695,79 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 
 func (s *baseServer) handleNewConn(conn quicConn) {
 	connCtx := conn.Context()
+	// The connection holds a slot of the AdmissionController until the handshake completes or fails.
+	var handshakeDone func()
+	if ac := s.config.AdmissionController; ac != nil {
+		handshakeDone = ac.handshakeDone
+	}
+	s.queueNewConn(conn, connCtx, &handshakeDone)
+	if handshakeDone != nil {
+		// The connection was accepted before the handshake completed.
+		select {
+		case <-conn.HandshakeComplete().Done():
+		case <-connCtx.Done():
+		}
+		handshakeDone()
+	}
+
+	<-connCtx.Done()
+	s.connsMutex.Lock()
+	delete(s.conns, conn)
+	s.connsMutex.Unlock()
+	select {
+	case s.connClosed <- struct{}{}:
+	default:
+	}
+}
+
+// queueNewConn passes the connection to Accept.
+// If *handshakeDone is set, it is called (and reset) as soon as the handshake completes or fails.
+func (s *baseServer) queueNewConn(conn quicConn, connCtx context.Context, handshakeDone *func()) {
+	callHandshakeDone := func() {
+		if *handshakeDone != nil {
+			(*handshakeDone)()
+			*handshakeDone = nil
+		}
+	}
 	if s.acceptEarlyConns {
 		// wait until the early connection is ready (or the handshake fails)
 		select {
 		case <-conn.earlyConnReady():
 		case <-connCtx.Done():
+			callHandshakeDone()
 			return
 		}
 	} else {
 		// wait until the handshake is complete (or fails)
 		select {
 		case <-conn.HandshakeComplete().Done():
+			callHandshakeDone()
 		case <-connCtx.Done():
+			callHandshakeDone()
 			return
 		}
 	}
 
+	// Only early connections can complete their handshake while waiting to be accepted.
+	var handshakeComplete <-chan struct{}
+	if *handshakeDone != nil {
+		handshakeComplete = conn.HandshakeComplete().Done()
+	}
 	atomic.AddInt32(&s.connQueueLen, 1)
-	select {
-	case s.connQueue <- conn:
-		// blocks until the connection is accepted
-	case <-connCtx.Done():
-		atomic.AddInt32(&s.connQueueLen, -1)
-		// don't pass connections that were already closed to Accept()
+	for {
+		select {
+		case s.connQueue <- conn:
+			// blocks until the connection is accepted
+			return
+		case <-handshakeComplete:
+			callHandshakeDone()
+			handshakeComplete = nil
+		case <-connCtx.Done():
+			atomic.AddInt32(&s.connQueueLen, -1)
+			callHandshakeDone()
+			// don't pass connections that were already closed to Accept()
+			return
+		}
 	}
 }
 
@@ -592,7 +808,7 @@ func (s *baseServer) sendRetry(remoteAddr net.Addr, hdr *wire.Header, info *pack
 	if s.config.Tracer != nil {
 		s.config.Tracer.SentPacket(remoteAddr, &replyHdr.Header, protocol.ByteCount(buf.Len()), nil)
 	}
-	_, err = s.conn.WritePacket(buf.Bytes(), remoteAddr, info.OOB())
+	_, err = s.conn.WritePacket(buf.Bytes(), remoteAddr, info.OOB(), 0, protocol.ECNNon)
 	return err
 }
 
@@ -670,7 +886,7 @@ func (s *baseServer) sendError(remoteAddr net.Addr, hdr *wire.Header, sealer han
 	if s.config.Tracer != nil {
 		s.config.Tracer.SentPacket(remoteAddr, &replyHdr.Header, protocol.ByteCount(len(raw)), []logging.Frame{ccf})
 	}
-	_, err = s.conn.WritePacket(raw, remoteAddr, info.OOB())
+	_, err = s.conn.WritePacket(raw, remoteAddr, info.OOB(), 0, protocol.ECNNon)
 	return err
 }
 
@@ -681,7 +897,7 @@ func (s *baseServer) sendVersionNegotiationPacket(remote net.Addr, src, dest pro
 	if s.config.Tracer != nil {
 		s.config.Tracer.SentVersionNegotiationPacket(remote, src, dest, s.config.Versions)
 	}
-	if _, err := s.conn.WritePacket(data, remote, oob); err != nil {
+	if _, err := s.conn.WritePacket(data, remote, oob, 0, protocol.ECNNon); err != nil {
 		s.logger.Debugf("Error sending Version Negotiation: %s", err)
 	}
 }
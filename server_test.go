@@ -277,6 +277,7 @@ var _ = Describe("Server", func() {
 				serv.newConn = func(
 					_ sendConn,
 					_ connRunner,
+					_ connRunner,
 					origDestConnID protocol.ConnectionID,
 					retrySrcConnID *protocol.ConnectionID,
 					clientDestConnID protocol.ConnectionID,
@@ -480,6 +481,7 @@ var _ = Describe("Server", func() {
 				serv.newConn = func(
 					_ sendConn,
 					_ connRunner,
+					_ connRunner,
 					origDestConnID protocol.ConnectionID,
 					retrySrcConnID *protocol.ConnectionID,
 					clientDestConnID protocol.ConnectionID,
@@ -540,6 +542,7 @@ var _ = Describe("Server", func() {
 				serv.newConn = func(
 					_ sendConn,
 					runner connRunner,
+					_ connRunner,
 					_ protocol.ConnectionID,
 					_ *protocol.ConnectionID,
 					_ protocol.ConnectionID,
@@ -594,6 +597,7 @@ var _ = Describe("Server", func() {
 				serv.newConn = func(
 					_ sendConn,
 					runner connRunner,
+					_ connRunner,
 					_ protocol.ConnectionID,
 					_ *protocol.ConnectionID,
 					_ protocol.ConnectionID,
@@ -624,6 +628,7 @@ var _ = Describe("Server", func() {
 				serv.newConn = func(
 					_ sendConn,
 					runner connRunner,
+					_ connRunner,
 					_ protocol.ConnectionID,
 					_ *protocol.ConnectionID,
 					_ protocol.ConnectionID,
@@ -695,6 +700,7 @@ var _ = Describe("Server", func() {
 				serv.newConn = func(
 					_ sendConn,
 					runner connRunner,
+					_ connRunner,
 					_ protocol.ConnectionID,
 					_ *protocol.ConnectionID,
 					_ protocol.ConnectionID,
@@ -749,6 +755,138 @@ var _ = Describe("Server", func() {
 				Expect(serv.Close()).To(Succeed())
 				Eventually(done).Should(BeClosed())
 			})
+
+			Context("admission control", func() {
+				newConnWithHandshakeContext := func(handshakeCtx context.Context) func(
+					sendConn, connRunner, connRunner, protocol.ConnectionID, *protocol.ConnectionID, protocol.ConnectionID, protocol.ConnectionID, protocol.ConnectionID, protocol.StatelessResetToken, *Config, *tls.Config, *handshake.TokenGenerator, bool, bool, logging.ConnectionTracer, uint64, utils.Logger, protocol.VersionNumber,
+				) quicConn {
+					return func(
+						_ sendConn,
+						_ connRunner,
+						_ connRunner,
+						_ protocol.ConnectionID,
+						_ *protocol.ConnectionID,
+						_ protocol.ConnectionID,
+						_ protocol.ConnectionID,
+						_ protocol.ConnectionID,
+						_ protocol.StatelessResetToken,
+						_ *Config,
+						_ *tls.Config,
+						_ *handshake.TokenGenerator,
+						_ bool,
+						_ bool,
+						_ logging.ConnectionTracer,
+						_ uint64,
+						_ utils.Logger,
+						_ protocol.VersionNumber,
+					) quicConn {
+						conn := NewMockQuicConn(mockCtrl)
+						conn.EXPECT().handlePacket(gomock.Any())
+						conn.EXPECT().run()
+						conn.EXPECT().Context().Return(context.Background()).AnyTimes()
+						conn.EXPECT().HandshakeComplete().Return(handshakeCtx).AnyTimes()
+						ready := make(chan struct{})
+						close(ready)
+						conn.EXPECT().earlyConnReady().Return(ready).AnyTimes()
+						return conn
+					}
+				}
+
+				expectConnCreation := func() {
+					phm.EXPECT().AddWithConnID(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_, _ protocol.ConnectionID, fn func() packetHandler) bool {
+						phm.EXPECT().GetStatelessResetToken(gomock.Any())
+						fn()
+						return true
+					})
+					tracer.EXPECT().TracerForConnection(gomock.Any(), protocol.PerspectiveServer, gomock.Any())
+				}
+
+				It("refuses connection attempts if there are too many concurrent handshakes", func() {
+					ac := NewAdmissionController(&AdmissionControlConfig{MaxConcurrentHandshakes: 1})
+					serv.config.AdmissionController = ac
+					handshakeCtx, handshakeComplete := context.WithCancel(context.Background())
+					serv.newConn = newConnWithHandshakeContext(handshakeCtx)
+					expectConnCreation()
+					serv.handlePacket(getInitialWithRandomDestConnID())
+					Eventually(func() int { return ac.Stats().Handshakes }).Should(Equal(1))
+
+					p := getInitialWithRandomDestConnID()
+					hdr := parseHeader(p.data)
+					tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					done := make(chan struct{})
+					conn.EXPECT().WriteTo(gomock.Any(), p.remoteAddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+						defer close(done)
+						rejectHdr := parseHeader(b)
+						Expect(rejectHdr.Type).To(Equal(protocol.PacketTypeInitial))
+						Expect(rejectHdr.DestConnectionID).To(Equal(hdr.SrcConnectionID))
+						Expect(rejectHdr.SrcConnectionID).To(Equal(hdr.DestConnectionID))
+						return len(b), nil
+					})
+					serv.handlePacket(p)
+					Eventually(done).Should(BeClosed())
+					Expect(ac.Stats().Refused).To(BeEquivalentTo(1))
+
+					// once the handshake completes, new connection attempts are accepted again
+					handshakeComplete()
+					Eventually(func() int { return ac.Stats().Handshakes }).Should(BeZero())
+					serv.newConn = newConnWithHandshakeContext(context.Background())
+					expectConnCreation()
+					serv.handlePacket(getInitialWithRandomDestConnID())
+					Eventually(func() uint64 { return ac.Stats().Accepted }).Should(BeEquivalentTo(2))
+				})
+
+				It("counts early connections as handshakes until the handshake completes", func() {
+					serv.acceptEarlyConns = true
+					ac := NewAdmissionController(&AdmissionControlConfig{MaxConcurrentHandshakes: 1})
+					serv.config.AdmissionController = ac
+					handshakeCtx, handshakeComplete := context.WithCancel(context.Background())
+					serv.newConn = newConnWithHandshakeContext(handshakeCtx)
+					expectConnCreation()
+					serv.handlePacket(getInitialWithRandomDestConnID())
+					Eventually(func() int { return ac.Stats().Handshakes }).Should(Equal(1))
+					Consistently(func() int { return ac.Stats().Handshakes }).Should(Equal(1))
+					handshakeComplete()
+					Eventually(func() int { return ac.Stats().Handshakes }).Should(BeZero())
+				})
+
+				It("sends a Retry when overloaded", func() {
+					ac := NewAdmissionController(&AdmissionControlConfig{RetryThreshold: 1})
+					serv.config.AdmissionController = ac
+					serv.newConn = newConnWithHandshakeContext(context.Background())
+					expectConnCreation()
+					serv.handlePacket(getInitialWithRandomDestConnID())
+					Eventually(func() int { return ac.Stats().Handshakes }).Should(Equal(1))
+
+					p := getInitialWithRandomDestConnID()
+					tracer.EXPECT().SentPacket(p.remoteAddr, gomock.Any(), gomock.Any(), nil).Do(func(_ net.Addr, replyHdr *logging.Header, _ logging.ByteCount, _ []logging.Frame) {
+						Expect(replyHdr.Type).To(Equal(protocol.PacketTypeRetry))
+					})
+					done := make(chan struct{})
+					conn.EXPECT().WriteTo(gomock.Any(), p.remoteAddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+						defer close(done)
+						Expect(parseHeader(b).Type).To(Equal(protocol.PacketTypeRetry))
+						return len(b), nil
+					})
+					serv.handlePacket(p)
+					Eventually(done).Should(BeClosed())
+					Expect(ac.Stats().Retried).To(BeEquivalentTo(1))
+				})
+
+				It("drops connection attempts that exceed the rate limit", func() {
+					ac := NewAdmissionController(&AdmissionControlConfig{PerIPRate: 0.001})
+					serv.config.AdmissionController = ac
+					serv.newConn = newConnWithHandshakeContext(context.Background())
+					expectConnCreation()
+					serv.handlePacket(getInitialWithRandomDestConnID())
+					Eventually(func() uint64 { return ac.Stats().Accepted }).Should(BeEquivalentTo(1))
+
+					p := getInitialWithRandomDestConnID()
+					tracer.EXPECT().DroppedPacket(p.remoteAddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention)
+					// dropping a packet is not an error
+					Expect(serv.handleInitialImpl(p, parseHeader(p.data))).To(Succeed())
+					Expect(ac.Stats().RateLimitedIP).To(BeEquivalentTo(1))
+				})
+			})
 		})
 
 		Context("token validation", func() {
@@ -762,7 +900,7 @@ var _ = Describe("Server", func() {
 				Expect(err).ToNot(HaveOccurred())
 				data, err := opener.Open(nil, b[extHdr.ParsedLen():], extHdr.PacketNumber, b[:extHdr.ParsedLen()])
 				Expect(err).ToNot(HaveOccurred())
-				_, f, err := wire.NewFrameParser(false, origHdr.Version).ParseNext(data, protocol.EncryptionInitial)
+				_, f, err := wire.NewFrameParser(false, false, false, false, origHdr.Version).ParseNext(data, protocol.EncryptionInitial)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(f).To(BeAssignableToTypeOf(&wire.ConnectionCloseFrame{}))
 				ccf := f.(*wire.ConnectionCloseFrame)
@@ -945,6 +1083,155 @@ var _ = Describe("Server", func() {
 			})
 		})
 
+		Context("key rotation", func() {
+			It("uses the token keys from the config", func() {
+				key := TokenKey{1, 2, 3}
+				ln, err := ListenAddr("localhost:0", tlsConf, &Config{TokenKeys: []TokenKey{key}})
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+				raddr := &net.UDPAddr{IP: net.IPv4(192, 168, 13, 37), Port: 1337}
+				token, err := ln.(*baseServer).tokenGenerator.NewToken(raddr)
+				Expect(err).ToNot(HaveOccurred())
+				// the token can't be decoded by a server using a different key ...
+				_, err = serv.tokenGenerator.DecodeToken(token)
+				Expect(err).To(HaveOccurred())
+				// ... until this server learns the key
+				Expect(serv.SetTokenKeys([]TokenKey{{4, 5, 6}, key})).To(Succeed())
+				t, err := serv.tokenGenerator.DecodeToken(token)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(t.ValidateRemoteAddr(raddr)).To(BeTrue())
+			})
+
+			It("refuses to set an empty list of token keys", func() {
+				Expect(serv.SetTokenKeys(nil)).To(MatchError("quic: no token keys"))
+			})
+
+			It("sets the stateless reset keys", func() {
+				keys := []StatelessResetKey{{1, 2, 3}, {4, 5, 6}}
+				phm.EXPECT().SetStatelessResetKeys(keys)
+				Expect(serv.SetStatelessResetKeys(keys)).To(Succeed())
+			})
+
+			It("refuses to set an empty list of stateless reset keys", func() {
+				Expect(serv.SetStatelessResetKeys(nil)).To(MatchError("quic: no stateless reset keys"))
+			})
+
+			It("refuses to set more than two stateless reset keys", func() {
+				Expect(serv.SetStatelessResetKeys([]StatelessResetKey{{1}, {2}, {3}})).To(MatchError("quic: too many stateless reset keys (3), at most 2 are supported"))
+			})
+		})
+
+		It("closes the connections on the packet conn of the preferred address", func() {
+			preferredAddrPhm := NewMockPacketHandlerManager(mockCtrl)
+			serv.preferredAddressConnHandler = preferredAddrPhm
+			phm.EXPECT().CloseServer()
+			preferredAddrPhm.EXPECT().CloseServer()
+			Expect(serv.Close()).To(Succeed())
+		})
+
+		Context("shutting down", func() {
+			// newConnWithContext creates a connection that is closed when the context is canceled
+			newConnWithContext := func(connCtx context.Context, created chan<- *MockQuicConn) func(
+				sendConn, connRunner, connRunner, protocol.ConnectionID, *protocol.ConnectionID, protocol.ConnectionID, protocol.ConnectionID, protocol.ConnectionID, protocol.StatelessResetToken, *Config, *tls.Config, *handshake.TokenGenerator, bool, bool, logging.ConnectionTracer, uint64, utils.Logger, protocol.VersionNumber,
+			) quicConn {
+				return func(
+					_ sendConn,
+					_ connRunner,
+					_ connRunner,
+					_ protocol.ConnectionID,
+					_ *protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.StatelessResetToken,
+					_ *Config,
+					_ *tls.Config,
+					_ *handshake.TokenGenerator,
+					_ bool,
+					_ bool,
+					_ logging.ConnectionTracer,
+					_ uint64,
+					_ utils.Logger,
+					_ protocol.VersionNumber,
+				) quicConn {
+					conn := NewMockQuicConn(mockCtrl)
+					conn.EXPECT().handlePacket(gomock.Any())
+					conn.EXPECT().run()
+					conn.EXPECT().Context().Return(connCtx)
+					conn.EXPECT().HandshakeComplete().Return(connCtx)
+					created <- conn
+					return conn
+				}
+			}
+
+			openConn := func(connCtx context.Context) *MockQuicConn {
+				created := make(chan *MockQuicConn, 1)
+				serv.newConn = newConnWithContext(connCtx, created)
+				phm.EXPECT().AddWithConnID(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_, _ protocol.ConnectionID, fn func() packetHandler) bool {
+					phm.EXPECT().GetStatelessResetToken(gomock.Any())
+					fn()
+					return true
+				})
+				tracer.EXPECT().TracerForConnection(gomock.Any(), protocol.PerspectiveServer, gomock.Any())
+				serv.handlePacket(getInitialWithRandomDestConnID())
+				var conn *MockQuicConn
+				Eventually(created).Should(Receive(&conn))
+				return conn
+			}
+
+			It("closes the server right away if there are no connections", func() {
+				phm.EXPECT().CloseServer()
+				Expect(serv.Shutdown(context.Background())).To(Succeed())
+				_, err := serv.Accept(context.Background())
+				Expect(err).To(MatchError(ErrServerClosed))
+			})
+
+			It("refuses new connection attempts and waits for existing connections to close", func() {
+				connCtx, closeConn := context.WithCancel(context.Background())
+				openConn(connCtx)
+
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					Expect(serv.Shutdown(context.Background())).To(Succeed())
+				}()
+				Eventually(serv.shuttingDown.Get).Should(BeTrue())
+
+				p := getInitialWithRandomDestConnID()
+				hdr := parseHeader(p.data)
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				written := make(chan struct{})
+				conn.EXPECT().WriteTo(gomock.Any(), p.remoteAddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(written)
+					rejectHdr := parseHeader(b)
+					Expect(rejectHdr.Type).To(Equal(protocol.PacketTypeInitial))
+					Expect(rejectHdr.DestConnectionID).To(Equal(hdr.SrcConnectionID))
+					Expect(rejectHdr.SrcConnectionID).To(Equal(hdr.DestConnectionID))
+					return len(b), nil
+				})
+				serv.handlePacket(p)
+				Eventually(written).Should(BeClosed())
+				Consistently(done).ShouldNot(BeClosed())
+
+				phm.EXPECT().CloseServer()
+				closeConn()
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("closes the remaining connections when the context expires", func() {
+				serv.config.ShutdownErrorCode = 0x1337
+				c := openConn(context.Background())
+				ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(50*time.Millisecond))
+				defer cancel()
+				gomock.InOrder(
+					c.EXPECT().CloseWithError(ApplicationErrorCode(0x1337), gomock.Any()),
+					phm.EXPECT().CloseServer(),
+				)
+				Expect(serv.Shutdown(ctx)).To(MatchError(context.DeadlineExceeded))
+			})
+		})
+
 		Context("accepting connections", func() {
 			It("returns Accept when an error occurs", func() {
 				testErr := errors.New("test err")
@@ -1001,6 +1288,7 @@ var _ = Describe("Server", func() {
 				serv.newConn = func(
 					_ sendConn,
 					runner connRunner,
+					_ connRunner,
 					_ protocol.ConnectionID,
 					_ *protocol.ConnectionID,
 					_ protocol.ConnectionID,
@@ -1075,6 +1363,7 @@ var _ = Describe("Server", func() {
 			serv.newConn = func(
 				_ sendConn,
 				runner connRunner,
+				_ connRunner,
 				_ protocol.ConnectionID,
 				_ *protocol.ConnectionID,
 				_ protocol.ConnectionID,
@@ -1118,6 +1407,7 @@ var _ = Describe("Server", func() {
 			serv.newConn = func(
 				_ sendConn,
 				runner connRunner,
+				_ connRunner,
 				_ protocol.ConnectionID,
 				_ *protocol.ConnectionID,
 				_ protocol.ConnectionID,
@@ -1181,6 +1471,7 @@ var _ = Describe("Server", func() {
 			serv.newConn = func(
 				_ sendConn,
 				runner connRunner,
+				_ connRunner,
 				_ protocol.ConnectionID,
 				_ *protocol.ConnectionID,
 				_ protocol.ConnectionID,
@@ -25,6 +25,9 @@ var errDeadline net.Error = &deadlineError{}
 type streamSender interface {
 	queueControlFrame(wire.Frame)
 	onHasStreamData(protocol.StreamID)
+	setStreamPriority(protocol.StreamID, StreamPriority)
+	// supportsResetStreamAt says if RESET_STREAM_AT frames can be sent
+	supportsResetStreamAt() bool
 	// must be called without holding the mutex that is acquired by closeForShutdown
 	onStreamCompleted(protocol.StreamID)
 }
@@ -44,6 +47,14 @@ func (s *uniStreamSender) onHasStreamData(id protocol.StreamID) {
 	s.streamSender.onHasStreamData(id)
 }
 
+func (s *uniStreamSender) setStreamPriority(id protocol.StreamID, prio StreamPriority) {
+	s.streamSender.setStreamPriority(id, prio)
+}
+
+func (s *uniStreamSender) supportsResetStreamAt() bool {
+	return s.streamSender.supportsResetStreamAt()
+}
+
 func (s *uniStreamSender) onStreamCompleted(protocol.StreamID) {
 	s.onStreamCompletedImpl()
 }
@@ -122,6 +133,16 @@ func (s *stream) StreamID() protocol.StreamID {
 	return s.sendStream.StreamID()
 }
 
+// need to define Stats() here, since both receiveStream and sendStream have a Stats()
+func (s *stream) Stats() StreamStats {
+	stats := s.sendStream.Stats()
+	rcvStats := s.receiveStream.Stats()
+	stats.BytesReceived = rcvStats.BytesReceived
+	stats.BytesRead = rcvStats.BytesRead
+	stats.ReceiveWindow = rcvStats.ReceiveWindow
+	return stats
+}
+
 func (s *stream) Close() error {
 	return s.sendStream.Close()
 }
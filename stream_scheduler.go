@@ -0,0 +1,201 @@
+package quic
+
+import (
+	"container/heap"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	list "github.com/lucas-clemente/quic-go/internal/utils/linkedlist"
+)
+
+const maxStreamUrgency = 7
+
+var defaultStreamPriority = StreamPriority{Urgency: 3, Incremental: true}
+
+func newStreamPriority(urgency uint8, incremental bool) StreamPriority {
+	if urgency > maxStreamUrgency {
+		urgency = maxStreamUrgency
+	}
+	return StreamPriority{Urgency: urgency, Incremental: incremental}
+}
+
+// The roundRobinScheduler ignores priorities, and serves all streams in turn.
+type roundRobinScheduler struct {
+	queue []protocol.StreamID
+}
+
+var _ StreamScheduler = &roundRobinScheduler{}
+
+// NewRoundRobinStreamScheduler creates a StreamScheduler that ignores stream priorities, and lets all streams take turns.
+// This is the default StreamScheduler.
+func NewRoundRobinStreamScheduler() StreamScheduler {
+	return &roundRobinScheduler{}
+}
+
+func (s *roundRobinScheduler) Add(id protocol.StreamID, _ StreamPriority) {
+	s.queue = append(s.queue, id)
+}
+
+func (s *roundRobinScheduler) Requeue(id protocol.StreamID, _ StreamPriority, _ int64) {
+	s.queue = append(s.queue, id)
+}
+
+func (s *roundRobinScheduler) Pop() protocol.StreamID {
+	id := s.queue[0]
+	s.queue = s.queue[1:]
+	return id
+}
+
+func (s *roundRobinScheduler) SetPriority(protocol.StreamID, StreamPriority) {}
+func (s *roundRobinScheduler) Len() int                                      { return len(s.queue) }
+func (s *roundRobinScheduler) Reset()                                        { s.queue = s.queue[:0] }
+
+type prioritizedStream struct {
+	id   protocol.StreamID
+	prio StreamPriority
+}
+
+// The strictPriorityScheduler serves the streams with the lowest urgency first.
+// It keeps one queue per urgency.
+// Incremental streams are requeued at the end, so they take turns with the other streams of the same urgency.
+// Non-incremental streams are requeued at the front, so they keep sending until they run out of data.
+type strictPriorityScheduler struct {
+	queues  [maxStreamUrgency + 1]list.List[prioritizedStream]
+	streams map[protocol.StreamID]*list.Element[prioritizedStream]
+}
+
+var _ StreamScheduler = &strictPriorityScheduler{}
+
+// NewStrictPriorityStreamScheduler creates a StreamScheduler that always sends data of the streams with the lowest urgency first.
+// Streams with the same urgency are served as described in RFC 9218:
+// Incremental streams take turns, while non-incremental streams are sent one after the other.
+func NewStrictPriorityStreamScheduler() StreamScheduler {
+	return &strictPriorityScheduler{streams: make(map[protocol.StreamID]*list.Element[prioritizedStream])}
+}
+
+func (s *strictPriorityScheduler) Add(id protocol.StreamID, prio StreamPriority) {
+	s.streams[id] = s.queues[prio.Urgency].PushBack(prioritizedStream{id: id, prio: prio})
+}
+
+func (s *strictPriorityScheduler) Requeue(id protocol.StreamID, prio StreamPriority, _ int64) {
+	if prio.Incremental {
+		s.Add(id, prio)
+		return
+	}
+	s.streams[id] = s.queues[prio.Urgency].PushFront(prioritizedStream{id: id, prio: prio})
+}
+
+func (s *strictPriorityScheduler) Pop() protocol.StreamID {
+	for i := range s.queues {
+		if e := s.queues[i].Front(); e != nil {
+			str := s.queues[i].Remove(e)
+			delete(s.streams, str.id)
+			return str.id
+		}
+	}
+	panic("no stream to pop")
+}
+
+func (s *strictPriorityScheduler) SetPriority(id protocol.StreamID, prio StreamPriority) {
+	e, ok := s.streams[id]
+	if !ok {
+		return
+	}
+	if e.Value.prio.Urgency == prio.Urgency {
+		e.Value.prio = prio
+		return
+	}
+	s.queues[e.Value.prio.Urgency].Remove(e)
+	s.Add(id, prio)
+}
+
+func (s *strictPriorityScheduler) Len() int { return len(s.streams) }
+
+func (s *strictPriorityScheduler) Reset() {
+	for i := range s.queues {
+		s.queues[i].Init()
+	}
+	s.streams = make(map[protocol.StreamID]*list.Element[prioritizedStream])
+}
+
+type weightedFairStream struct {
+	id                protocol.StreamID
+	virtualFinishTime uint64
+	// seq breaks ties between streams with the same virtual finish time, in the order they were queued
+	seq uint64
+}
+
+// weightedFairQueue is a min-heap of streams, ordered by their virtual finish time.
+// It implements heap.Interface.
+type weightedFairQueue []weightedFairStream
+
+func (q weightedFairQueue) Len() int { return len(q) }
+
+func (q weightedFairQueue) Less(i, j int) bool {
+	if q[i].virtualFinishTime != q[j].virtualFinishTime {
+		return q[i].virtualFinishTime < q[j].virtualFinishTime
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q weightedFairQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *weightedFairQueue) Push(x any)   { *q = append(*q, x.(weightedFairStream)) }
+
+func (q *weightedFairQueue) Pop() any {
+	old := *q
+	n := len(old)
+	str := old[n-1]
+	*q = old[:n-1]
+	return str
+}
+
+// The weightedFairScheduler implements weighted fair queueing.
+// Every stream is assigned a weight of 8-urgency.
+// The stream with the earliest virtual finish time is served next.
+// Every time a stream sends n bytes, its virtual finish time is advanced by n/weight.
+type weightedFairScheduler struct {
+	queue       weightedFairQueue
+	virtualTime uint64
+	seq         uint64
+}
+
+var _ StreamScheduler = &weightedFairScheduler{}
+
+// NewWeightedFairStreamScheduler creates a StreamScheduler that shares the available bandwidth
+// between all streams that have data to send.
+// A stream with urgency u receives a share proportional to 8-u. The incremental flag is not used.
+func NewWeightedFairStreamScheduler() StreamScheduler {
+	return &weightedFairScheduler{}
+}
+
+func (s *weightedFairScheduler) push(id protocol.StreamID, virtualFinishTime uint64) {
+	heap.Push(&s.queue, weightedFairStream{id: id, virtualFinishTime: virtualFinishTime, seq: s.seq})
+	s.seq++
+}
+
+func (s *weightedFairScheduler) Add(id protocol.StreamID, _ StreamPriority) {
+	// Streams that just became active start at the current virtual time.
+	// This prevents them from claiming the bandwidth they didn't use while they were idle.
+	s.push(id, s.virtualTime)
+}
+
+func (s *weightedFairScheduler) Requeue(id protocol.StreamID, prio StreamPriority, n int64) {
+	weight := uint64(maxStreamUrgency + 1 - prio.Urgency)
+	s.push(id, s.virtualTime+uint64(n)*(maxStreamUrgency+1)/weight)
+}
+
+func (s *weightedFairScheduler) Pop() protocol.StreamID {
+	str := heap.Pop(&s.queue).(weightedFairStream)
+	s.virtualTime = str.virtualFinishTime
+	return str.id
+}
+
+// SetPriority doesn't need to do anything:
+// The weight of a stream is only used to calculate its virtual finish time when it is requeued.
+func (s *weightedFairScheduler) SetPriority(protocol.StreamID, StreamPriority) {}
+
+func (s *weightedFairScheduler) Len() int { return len(s.queue) }
+
+func (s *weightedFairScheduler) Reset() {
+	s.queue = s.queue[:0]
+	s.virtualTime = 0
+}
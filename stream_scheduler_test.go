@@ -0,0 +1,145 @@
+package quic
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stream Scheduler", func() {
+	popAll := func(s StreamScheduler) []protocol.StreamID {
+		var ids []protocol.StreamID
+		for s.Len() > 0 {
+			ids = append(ids, s.Pop())
+		}
+		return ids
+	}
+
+	It("limits the urgency", func() {
+		Expect(newStreamPriority(42, true)).To(Equal(StreamPriority{Urgency: 7, Incremental: true}))
+		Expect(newStreamPriority(2, false)).To(Equal(StreamPriority{Urgency: 2, Incremental: false}))
+	})
+
+	Context("round-robin", func() {
+		It("serves streams in the order they were added", func() {
+			s := NewRoundRobinStreamScheduler()
+			s.Add(1, newStreamPriority(7, false))
+			s.Add(2, newStreamPriority(0, false))
+			s.Requeue(s.Pop(), newStreamPriority(7, false), 100)
+			s.Add(3, defaultStreamPriority)
+			Expect(popAll(s)).To(Equal([]protocol.StreamID{2, 1, 3}))
+		})
+
+		It("resets", func() {
+			s := NewRoundRobinStreamScheduler()
+			s.Add(1, defaultStreamPriority)
+			s.Reset()
+			Expect(s.Len()).To(BeZero())
+		})
+	})
+
+	Context("strict priority", func() {
+		var s StreamScheduler
+
+		BeforeEach(func() {
+			s = NewStrictPriorityStreamScheduler()
+		})
+
+		It("serves streams with lower urgency first", func() {
+			s.Add(1, newStreamPriority(5, true))
+			s.Add(2, newStreamPriority(1, true))
+			s.Add(3, newStreamPriority(3, true))
+			s.Add(4, newStreamPriority(1, true))
+			Expect(popAll(s)).To(Equal([]protocol.StreamID{2, 4, 3, 1}))
+		})
+
+		It("interleaves incremental streams", func() {
+			s.Add(1, defaultStreamPriority)
+			s.Add(2, defaultStreamPriority)
+			Expect(s.Pop()).To(Equal(protocol.StreamID(1)))
+			s.Requeue(1, defaultStreamPriority, 1000)
+			Expect(s.Pop()).To(Equal(protocol.StreamID(2)))
+			s.Requeue(2, defaultStreamPriority, 1000)
+			Expect(s.Pop()).To(Equal(protocol.StreamID(1)))
+		})
+
+		It("sends non-incremental streams one after the other", func() {
+			prio := newStreamPriority(3, false)
+			s.Add(1, prio)
+			s.Add(2, prio)
+			Expect(s.Pop()).To(Equal(protocol.StreamID(1)))
+			s.Requeue(1, prio, 1000)
+			Expect(s.Pop()).To(Equal(protocol.StreamID(1)))
+			s.Requeue(1, prio, 1000)
+			Expect(popAll(s)).To(Equal([]protocol.StreamID{1, 2}))
+		})
+
+		It("changes the priority of a queued stream", func() {
+			s.Add(1, defaultStreamPriority)
+			s.Add(2, defaultStreamPriority)
+			s.SetPriority(2, newStreamPriority(0, true))
+			Expect(popAll(s)).To(Equal([]protocol.StreamID{2, 1}))
+		})
+
+		It("ignores priority changes of streams that are not queued", func() {
+			s.Add(1, defaultStreamPriority)
+			s.SetPriority(2, newStreamPriority(0, true))
+			Expect(s.Len()).To(Equal(1))
+			Expect(popAll(s)).To(Equal([]protocol.StreamID{1}))
+		})
+	})
+
+	Context("weighted fair", func() {
+		var s StreamScheduler
+
+		BeforeEach(func() {
+			s = NewWeightedFairStreamScheduler()
+		})
+
+		It("shares the bandwidth according to the urgency", func() {
+			high := newStreamPriority(0, true) // weight 8
+			low := newStreamPriority(6, true)  // weight 2
+			s.Add(1, high)
+			s.Add(2, low)
+			sent := make(map[protocol.StreamID]int64)
+			for i := 0; i < 1000; i++ {
+				id := s.Pop()
+				sent[id] += 1000
+				if id == 1 {
+					s.Requeue(id, high, 1000)
+				} else {
+					s.Requeue(id, low, 1000)
+				}
+			}
+			Expect(sent[1]).To(BeNumerically("~", 4*sent[2], 4000))
+		})
+
+		It("serves streams with the same virtual finish time in the order they were queued", func() {
+			for i := 1; i <= 5; i++ {
+				s.Add(protocol.StreamID(i), defaultStreamPriority)
+			}
+			Expect(popAll(s)).To(Equal([]protocol.StreamID{1, 2, 3, 4, 5}))
+		})
+
+		It("doesn't let new streams claim bandwidth they didn't use", func() {
+			s.Add(1, defaultStreamPriority)
+			for i := 0; i < 100; i++ {
+				Expect(s.Pop()).To(Equal(protocol.StreamID(1)))
+				s.Requeue(1, defaultStreamPriority, 1000)
+			}
+			s.Add(2, defaultStreamPriority)
+			Expect(s.Pop()).To(Equal(protocol.StreamID(2)))
+			s.Requeue(2, defaultStreamPriority, 1000)
+			Expect(s.Pop()).To(Equal(protocol.StreamID(1)))
+			s.Requeue(1, defaultStreamPriority, 1000)
+			Expect(s.Pop()).To(Equal(protocol.StreamID(2)))
+		})
+
+		It("resets", func() {
+			s.Add(1, defaultStreamPriority)
+			s.Reset()
+			Expect(s.Len()).To(BeZero())
+		})
+	})
+})
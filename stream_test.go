@@ -77,6 +77,21 @@ var _ = Describe("Stream", func() {
 		})
 	})
 
+	It("combines the statistics of the send and the receive side", func() {
+		mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+		Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+		str.sendStream.mutex.Lock()
+		str.sendStream.writeOffset = 42
+		str.sendStream.blockedByConnection = true
+		str.sendStream.mutex.Unlock()
+		mockFC.EXPECT().ReceiveWindow().Return(protocol.ByteCount(100))
+		stats := str.Stats()
+		Expect(stats.BytesWritten).To(BeEquivalentTo(42))
+		Expect(stats.BlockedByConnectionFlowControl).To(BeTrue())
+		Expect(stats.BytesReceived).To(BeEquivalentTo(6))
+		Expect(stats.ReceiveWindow).To(BeEquivalentTo(94))
+	})
+
 	Context("completing", func() {
 		It("is not completed when only the receive side is completed", func() {
 			// don't EXPECT a call to mockSender.onStreamCompleted()
@@ -384,8 +384,10 @@ var _ = Describe("Streams Map", func() {
 
 					Expect(flowControllers).To(HaveKey(str.StreamID()))
 					flowControllers[str.StreamID()].EXPECT().UpdateSendWindow(protocol.ByteCount(4321))
+					flowControllers[str.StreamID()].EXPECT().SendWindowSize()
 					Expect(flowControllers).To(HaveKey(unistr.StreamID()))
 					flowControllers[unistr.StreamID()].EXPECT().UpdateSendWindow(protocol.ByteCount(1234))
+					flowControllers[unistr.StreamID()].EXPECT().SendWindowSize()
 
 					m.UpdateLimits(&wire.TransportParameters{
 						MaxBidiStreamNum:               1000,
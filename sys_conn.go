@@ -47,6 +47,18 @@ func wrapConn(pc net.PacketConn) (rawConn, error) {
 	return newConn(c)
 }
 
+// connCapabilities are the capabilities of a rawConn.
+type connCapabilities struct {
+	// ECN says if the ECN bits of outgoing packets can be set.
+	ECN bool
+	// GSO says if multiple packets can be sent in a single sendmsg call, using Generic Segmentation Offload.
+	GSO bool
+}
+
+// maxGSOSegments is the maximum number of packets that can be sent in a single sendmsg call using GSO.
+// This is UDP_MAX_SEGMENTS in the Linux kernel.
+const maxGSOSegments = 64
+
 // The basicConn is the most trivial implementation of a connection.
 // It reads a single packet from the underlying net.PacketConn.
 // It is used when
@@ -75,6 +87,8 @@ func (c *basicConn) ReadPacket() (*receivedPacket, error) {
 	}, nil
 }
 
-func (c *basicConn) WritePacket(b []byte, addr net.Addr, _ []byte) (n int, err error) {
+func (c *basicConn) WritePacket(b []byte, addr net.Addr, _ []byte, _ uint16, _ protocol.ECN) (n int, err error) {
 	return c.PacketConn.WriteTo(b, addr)
 }
+
+func (c *basicConn) capabilities() connCapabilities { return connCapabilities{} }
@@ -19,3 +19,6 @@ const (
 // ReadBatch only returns a single packet on OSX,
 // see https://godoc.org/golang.org/x/net/ipv4#PacketConn.ReadBatch.
 const batchSize = 1
+
+// Sending ECN-marked packets is not implemented on this platform yet.
+const supportsSendingECN = false
@@ -19,3 +19,6 @@ const (
 )
 
 const batchSize = 8
+
+// Sending ECN-marked packets is not implemented on this platform yet.
+const supportsSendingECN = false
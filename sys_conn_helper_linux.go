@@ -2,7 +2,15 @@
 
 package quic
 
-import "golang.org/x/sys/unix"
+import (
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
 
 const msgTypeIPTOS = unix.IP_TOS
 
@@ -16,4 +24,65 @@ const (
 	msgTypeIPv6PKTINFO = unix.IPV6_PKTINFO
 )
 
+// Linux allows setting the ECN bits of outgoing packets using the IP_TOS and IPV6_TCLASS control messages,
+// also for IPv4 packets sent on dual-stack sockets.
+const supportsSendingECN = true
+
 const batchSize = 8 // needs to smaller than MaxUint8 (otherwise the type of oobConn.readPos has to be changed)
+
+// UDP_SEGMENT and UDP_GRO, as defined in linux/udp.h.
+// They are not yet defined by the version of golang.org/x/sys we're using.
+const (
+	udpSegment = 103
+	udpGRO     = 104
+)
+
+// isGSOSupported checks if the kernel supports UDP_SEGMENT (available since Linux 4.18).
+// GSO can be disabled by setting the QUIC_GO_DISABLE_GSO environment variable.
+func isGSOSupported(conn syscall.RawConn) bool {
+	if disabled, err := strconv.ParseBool(os.Getenv("QUIC_GO_DISABLE_GSO")); err == nil && disabled {
+		return false
+	}
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		_, serr = unix.GetsockoptInt(int(fd), unix.IPPROTO_UDP, udpSegment)
+	}); err != nil {
+		return false
+	}
+	return serr == nil
+}
+
+// enableGRO enables UDP Generic Receive Offload (available since Linux 5.0).
+// GRO can be disabled by setting the QUIC_GO_DISABLE_GRO environment variable.
+func enableGRO(conn syscall.RawConn) bool {
+	if disabled, err := strconv.ParseBool(os.Getenv("QUIC_GO_DISABLE_GRO")); err == nil && disabled {
+		return false
+	}
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_UDP, udpGRO, 1)
+	}); err != nil {
+		return false
+	}
+	return serr == nil
+}
+
+// appendUDPSegmentSizeMsg appends a control message that tells the kernel to split b into segments of size bytes.
+func appendUDPSegmentSizeMsg(b []byte, size uint16) []byte {
+	startLen := len(b)
+	const dataLen = 2 // payload is a uint16
+	b = append(b, make([]byte, unix.CmsgSpace(dataLen))...)
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
+	h.Level = unix.IPPROTO_UDP
+	h.Type = udpSegment
+	h.SetLen(unix.CmsgLen(dataLen))
+	*(*uint16)(unsafe.Pointer(&b[startLen+unix.CmsgSpace(0)])) = size
+	return b
+}
+
+// isGSOError says if sending a packet using GSO failed because the network interface doesn't support it.
+// EIO is returned by udp_send_skb() if the device driver doesn't have TX checksum offloading enabled,
+// which is a hard requirement of UDP_SEGMENT.
+func isGSOError(err error) bool {
+	return errors.Is(err, unix.EIO)
+}
@@ -0,0 +1,18 @@
+//go:build darwin || freebsd
+
+package quic
+
+import "syscall"
+
+// Sending packets using GSO and receiving packets using GRO is only supported on Linux.
+
+// never used, since GRO is never enabled
+const udpGRO = 0
+
+func isGSOSupported(syscall.RawConn) bool { return false }
+
+func enableGRO(syscall.RawConn) bool { return false }
+
+func appendUDPSegmentSizeMsg(b []byte, _ uint16) []byte { return b }
+
+func isGSOError(error) bool { return false }
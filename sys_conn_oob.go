@@ -9,6 +9,7 @@ import (
 	"net"
 	"syscall"
 	"time"
+	"unsafe"
 
 	"golang.org/x/net/ipv4"
 	"golang.org/x/net/ipv6"
@@ -21,6 +22,8 @@ import (
 const (
 	ecnMask       = 0x3
 	oobBufferSize = 128
+	// The kernel coalesces up to 64 KB into a single GRO super-datagram.
+	groBufferSize = 1 << 16
 )
 
 // Contrary to what the naming suggests, the ipv{4,6}.Message is not dependent on the IP version.
@@ -61,6 +64,18 @@ type oobConn struct {
 	// Packets received from the kernel, but not yet returned by ReadPacket().
 	messages []ipv4.Message
 	buffers  [batchSize]*packetBuffer
+
+	cap connCapabilities
+	// Set when sending a packet using GSO failed, even though the kernel supports GSO.
+	gsoFailed utils.AtomicBool
+
+	// If GRO is enabled, the kernel might coalesce multiple datagrams into a single message.
+	// The messages are then read into buffers owned by the oobConn,
+	// and the individual packets are copied into their own packet buffers.
+	gro bool
+	// Packets split off from a coalesced message, but not yet returned by ReadPacket().
+	groPackets []*receivedPacket
+	groPos     int
 }
 
 var _ rawConn = &oobConn{}
@@ -132,18 +147,36 @@ func newConn(c OOBCapablePacketConn) (*oobConn, error) {
 		batchConn:            bc,
 		messages:             msgs,
 		readPos:              batchSize,
+		cap:                  connCapabilities{ECN: supportsSendingECN, GSO: isGSOSupported(rawConn)},
+		gro:                  enableGRO(rawConn),
+	}
+	if oobConn.cap.GSO {
+		utils.DefaultLogger.Debugf("Activating sending of packets using GSO.")
+	}
+	if oobConn.gro {
+		utils.DefaultLogger.Debugf("Activating receiving of packets using GRO.")
 	}
 	for i := 0; i < batchSize; i++ {
 		oobConn.messages[i].OOB = make([]byte, oobBufferSize)
+		if oobConn.gro {
+			oobConn.messages[i].Buffers[0] = make([]byte, groBufferSize)
+		}
 	}
 	return oobConn, nil
 }
 
 func (c *oobConn) ReadPacket() (*receivedPacket, error) {
+	if c.groPos < len(c.groPackets) {
+		p := c.groPackets[c.groPos]
+		c.groPackets[c.groPos] = nil
+		c.groPos++
+		return p, nil
+	}
 	if len(c.messages) == int(c.readPos) { // all messages read. Read the next batch of messages.
 		c.messages = c.messages[:batchSize]
 		// replace buffers data buffers up to the packet that has been consumed during the last ReadBatch call
-		for i := uint8(0); i < c.readPos; i++ {
+		// If GRO is enabled, the buffers are reused.
+		for i := uint8(0); i < c.readPos && !c.gro; i++ {
 			buffer := getPacketBuffer()
 			buffer.Data = buffer.Data[:protocol.MaxPacketBufferSize]
 			c.buffers[i] = buffer
@@ -166,6 +199,7 @@ func (c *oobConn) ReadPacket() (*receivedPacket, error) {
 	var ecn protocol.ECN
 	var destIP net.IP
 	var ifIndex uint32
+	var segmentSize int
 	for len(data) > 0 {
 		hdr, body, remainder, err := unix.ParseOneSocketControlMessage(data)
 		if err != nil {
@@ -210,6 +244,11 @@ func (c *oobConn) ReadPacket() (*receivedPacket, error) {
 				}
 			}
 		}
+		if c.gro && hdr.Level == unix.IPPROTO_UDP && hdr.Type == udpGRO && len(body) == 4 {
+			// The kernel coalesced multiple datagrams.
+			// The control message contains the segment size, as an int in native byte order.
+			segmentSize = int(*(*int32)(unsafe.Pointer(&body[0])))
+		}
 		data = remainder
 	}
 	var info *packetInfo
@@ -219,6 +258,9 @@ func (c *oobConn) ReadPacket() (*receivedPacket, error) {
 			ifIndex: ifIndex,
 		}
 	}
+	if c.gro {
+		return c.splitCoalescedMessage(msg, segmentSize, ecn, info), nil
+	}
 	return &receivedPacket{
 		remoteAddr: msg.Addr,
 		rcvTime:    time.Now(),
@@ -229,9 +271,106 @@ func (c *oobConn) ReadPacket() (*receivedPacket, error) {
 	}, nil
 }
 
-func (c *oobConn) WritePacket(b []byte, addr net.Addr, oob []byte) (n int, err error) {
-	n, _, err = c.OOBCapablePacketConn.WriteMsgUDP(b, oob, addr.(*net.UDPAddr))
-	return n, err
+// splitCoalescedMessage splits a message read from a GRO-enabled socket into individual packets.
+// All packets but the last one have segmentSize bytes. A segmentSize of 0 means that the message wasn't coalesced.
+// It returns the first packet, and queues the remaining packets, which are then returned by the next calls to ReadPacket.
+func (c *oobConn) splitCoalescedMessage(msg ipv4.Message, segmentSize int, ecn protocol.ECN, info *packetInfo) *receivedPacket {
+	data := msg.Buffers[0][:msg.N]
+	if segmentSize == 0 {
+		segmentSize = len(data)
+	}
+	rcvTime := time.Now()
+	c.groPackets = c.groPackets[:0]
+	c.groPos = 0
+	for {
+		l := utils.Min(len(data), segmentSize)
+		buffer := getPacketBuffer()
+		// The packet size should not exceed protocol.MaxPacketBufferSize bytes
+		// If it does, we only copy a truncated packet, which will then end up undecryptable
+		buffer.Data = buffer.Data[:copy(buffer.Data[:cap(buffer.Data)], data[:l])]
+		c.groPackets = append(c.groPackets, &receivedPacket{
+			remoteAddr: msg.Addr,
+			rcvTime:    rcvTime,
+			data:       buffer.Data,
+			ecn:        ecn,
+			info:       info,
+			buffer:     buffer,
+		})
+		data = data[l:]
+		if len(data) == 0 {
+			break
+		}
+	}
+	p := c.groPackets[0]
+	c.groPackets[0] = nil
+	c.groPos = 1
+	return p
+}
+
+func (c *oobConn) WritePacket(b []byte, addr net.Addr, oob []byte, gsoSize uint16, ecn protocol.ECN) (n int, err error) {
+	udpAddr := addr.(*net.UDPAddr)
+	// Make sure to not modify the oob slice passed in, it might be used concurrently.
+	oob = oob[:len(oob):len(oob)]
+	if ecn != protocol.ECNNon {
+		if !c.cap.ECN {
+			panic("tried to send an ECN-marked packet on a connection that doesn't support ECN")
+		}
+		if udpAddr.IP.To4() != nil {
+			oob = appendECNMsg(oob, unix.IPPROTO_IP, unix.IP_TOS, ecn)
+		} else {
+			oob = appendECNMsg(oob, unix.IPPROTO_IPV6, unix.IPV6_TCLASS, ecn)
+		}
+	}
+	if gsoSize == 0 {
+		n, _, err = c.OOBCapablePacketConn.WriteMsgUDP(b, oob, udpAddr)
+		return n, err
+	}
+	if !c.capabilities().GSO {
+		panic("tried to send multiple packets on a connection that doesn't support GSO")
+	}
+	n, _, err = c.OOBCapablePacketConn.WriteMsgUDP(b, appendUDPSegmentSizeMsg(oob, gsoSize), udpAddr)
+	if err == nil || !isGSOError(err) {
+		return n, err
+	}
+	// The kernel supports GSO, but the network interface doesn't (e.g. because it doesn't support checksum offloading).
+	// Disable GSO for all future packets, and send out the packets one by one.
+	if !c.gsoFailed.Get() {
+		c.gsoFailed.Set(true)
+		utils.DefaultLogger.Infof("Sending packets using GSO failed (%s). Disabling GSO.", err)
+	}
+	n = 0
+	for len(b) > 0 {
+		l := utils.Min(len(b), int(gsoSize))
+		m, _, err := c.OOBCapablePacketConn.WriteMsgUDP(b[:l], oob, udpAddr)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		b = b[l:]
+	}
+	return n, nil
+}
+
+func (c *oobConn) capabilities() connCapabilities {
+	caps := c.cap
+	if c.gsoFailed.Get() {
+		caps.GSO = false
+	}
+	return caps
+}
+
+// appendECNMsg appends a control message that sets the ECN bits of an outgoing packet.
+// Both IP_TOS and IPV6_TCLASS take an int. Since we only set the ECN bits, the DSCP is 0.
+func appendECNMsg(b []byte, level, typ int32, ecn protocol.ECN) []byte {
+	startLen := len(b)
+	const dataLen = 4
+	b = append(b, make([]byte, unix.CmsgSpace(dataLen))...)
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
+	h.Level = level
+	h.Type = typ
+	h.SetLen(unix.CmsgLen(dataLen))
+	*(*int32)(unsafe.Pointer(&b[startLen+unix.CmsgSpace(0)])) = int32(ecn)
+	return b
 }
 
 func (info *packetInfo) OOB() []byte {
@@ -3,9 +3,12 @@
 package quic
 
 import (
+	"encoding/binary"
 	"fmt"
 	"net"
+	"os"
 	"time"
+	"unsafe"
 
 	"golang.org/x/net/ipv4"
 	"golang.org/x/sys/unix"
@@ -130,6 +133,120 @@ var _ = Describe("OOB Conn Test", func() {
 		})
 	})
 
+	Context("sending ECN-marked packets", func() {
+		BeforeEach(func() {
+			if !supportsSendingECN {
+				Skip("sending ECN-marked packets is not supported on this platform")
+			}
+		})
+
+		It("sets the ECN bits", func() {
+			conn, packetChan := runServer("udp", "0.0.0.0:0")
+			defer conn.Close()
+			port := conn.LocalAddr().(*net.UDPAddr).Port
+
+			udpConn, err := net.ListenUDP("udp", nil)
+			Expect(err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			sendConn, err := newConn(udpConn)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sendConn.capabilities().ECN).To(BeTrue())
+
+			// IPv4, sent on a dual-stack socket
+			_, err = sendConn.WritePacket([]byte("foo"), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port}, nil, 0, protocol.ECT0)
+			Expect(err).ToNot(HaveOccurred())
+			var p *receivedPacket
+			Eventually(packetChan).Should(Receive(&p))
+			Expect(p.data).To(Equal([]byte("foo")))
+			Expect(p.ecn).To(Equal(protocol.ECT0))
+
+			// IPv6
+			_, err = sendConn.WritePacket([]byte("bar"), &net.UDPAddr{IP: net.IPv6loopback, Port: port}, nil, 0, protocol.ECT1)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(packetChan).Should(Receive(&p))
+			Expect(p.data).To(Equal([]byte("bar")))
+			Expect(p.ecn).To(Equal(protocol.ECT1))
+
+			// packets that are not ECN-marked
+			_, err = sendConn.WritePacket([]byte("baz"), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port}, nil, 0, protocol.ECNNon)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(packetChan).Should(Receive(&p))
+			Expect(p.data).To(Equal([]byte("baz")))
+			Expect(p.ecn).To(Equal(protocol.ECNNon))
+		})
+
+		It("sets the ECN bits in addition to the packet info", func() {
+			conn, packetChan := runServer("udp4", "127.0.0.1:0")
+			defer conn.Close()
+
+			udpConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero})
+			Expect(err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			sendConn, err := newConn(udpConn)
+			Expect(err).ToNot(HaveOccurred())
+			info := &packetInfo{addr: net.IPv4(127, 0, 0, 1)}
+			oob := info.OOB()
+			_, err = sendConn.WritePacket([]byte("foobar"), conn.LocalAddr(), oob, 0, protocol.ECT0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(oob).To(Equal(info.OOB())) // make sure the OOB data wasn't modified
+			var p *receivedPacket
+			Eventually(packetChan).Should(Receive(&p))
+			Expect(p.data).To(Equal([]byte("foobar")))
+			Expect(p.ecn).To(Equal(protocol.ECT0))
+		})
+	})
+
+	Context("sending packets using GSO, and receiving packets using GRO", func() {
+		It("sends multiple packets in a single call", func() {
+			conn, packetChan := runServer("udp4", "127.0.0.1:0")
+			defer conn.Close()
+
+			udpConn, err := net.ListenUDP("udp4", nil)
+			Expect(err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			sendConn, err := newConn(udpConn)
+			Expect(err).ToNot(HaveOccurred())
+			if !sendConn.capabilities().GSO {
+				Skip("GSO is not supported on this platform")
+			}
+
+			// If the receiving socket has GRO enabled, the kernel might deliver the packets as a single coalesced message.
+			n, err := sendConn.WritePacket([]byte("foobarbaz!"), conn.LocalAddr(), nil, 3, protocol.ECT0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(10))
+			for _, data := range []string{"foo", "bar", "baz", "!"} {
+				var p *receivedPacket
+				Eventually(packetChan).Should(Receive(&p))
+				Expect(p.data).To(Equal([]byte(data)))
+				Expect(p.ecn).To(Equal(protocol.ECT0))
+			}
+		})
+
+		It("doesn't use GSO if it is disabled using the environment variable", func() {
+			os.Setenv("QUIC_GO_DISABLE_GSO", "true")
+			defer os.Unsetenv("QUIC_GO_DISABLE_GSO")
+
+			udpConn, err := net.ListenUDP("udp4", nil)
+			Expect(err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			sendConn, err := newConn(udpConn)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sendConn.capabilities().GSO).To(BeFalse())
+		})
+
+		It("doesn't use GRO if it is disabled using the environment variable", func() {
+			os.Setenv("QUIC_GO_DISABLE_GRO", "true")
+			defer os.Unsetenv("QUIC_GO_DISABLE_GRO")
+
+			udpConn, err := net.ListenUDP("udp4", nil)
+			Expect(err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			conn, err := newConn(udpConn)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conn.gro).To(BeFalse())
+		})
+	})
+
 	Context("Packet Info conn", func() {
 		sendPacket := func(network string, addr *net.UDPAddr) net.Addr {
 			conn, err := net.DialUDP(network, nil, addr)
@@ -209,6 +326,10 @@ var _ = Describe("OOB Conn Test", func() {
 		})
 
 		It("reads multiple messages in one batch", func() {
+			// with GRO, the read buffers are allocated differently
+			os.Setenv("QUIC_GO_DISABLE_GRO", "true")
+			defer os.Unsetenv("QUIC_GO_DISABLE_GRO")
+
 			const numMsgRead = batchSize/2 + 1
 			var counter int
 			batchConn.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ms []ipv4.Message, flags int) (int, error) {
@@ -238,5 +359,48 @@ var _ = Describe("OOB Conn Test", func() {
 				Expect(string(p.data)).To(Equal(fmt.Sprintf("message %d", i)))
 			}
 		})
+
+		It("splits messages coalesced by GRO", func() {
+			addr, err := net.ResolveUDPAddr("udp", "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			udpConn, err := net.ListenUDP("udp", addr)
+			Expect(err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			oobConn, err := newConn(udpConn)
+			Expect(err).ToNot(HaveOccurred())
+			if !oobConn.gro {
+				Skip("GRO is not supported on this platform")
+			}
+			oobConn.batchConn = batchConn
+
+			remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+			batchConn.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ms []ipv4.Message, flags int) (int, error) {
+				Expect(ms[0].Buffers[0]).To(HaveLen(groBufferSize))
+				// a coalesced message, with a segment size of 3
+				ms[0].N = copy(ms[0].Buffers[0], "foobarbaz!")
+				oob := make([]byte, unix.CmsgSpace(4))
+				h := (*unix.Cmsghdr)(unsafe.Pointer(&oob[0]))
+				h.Level = unix.IPPROTO_UDP
+				h.Type = udpGRO
+				h.SetLen(unix.CmsgLen(4))
+				binary.LittleEndian.PutUint32(oob[unix.CmsgSpace(0):], 3)
+				ms[0].NN = copy(ms[0].OOB, oob)
+				ms[0].Addr = remoteAddr
+				// a message that wasn't coalesced
+				ms[1].N = copy(ms[1].Buffers[0], "lorem ipsum")
+				ms[1].NN = 0
+				ms[1].Addr = remoteAddr
+				return 2, nil
+			})
+
+			for _, data := range []string{"foo", "bar", "baz", "!", "lorem ipsum"} {
+				p, err := oobConn.ReadPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(p.data)).To(Equal(data))
+				Expect(p.remoteAddr).To(Equal(remoteAddr))
+				Expect(p.buffer.Data).To(HaveCap(int(protocol.MaxPacketBufferSize)))
+				p.buffer.Release()
+			}
+		})
 	})
 })
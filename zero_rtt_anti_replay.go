@@ -0,0 +1,61 @@
+package quic
+
+import (
+	"sync"
+	"time"
+)
+
+type zeroRTTAntiReplayCache struct {
+	mutex sync.Mutex
+
+	window time.Duration
+	// Ticket IDs are stored in two generations, which are rotated every window.
+	// A ticket ID is therefore remembered for at least one window after it was used.
+	lastRotation      time.Time
+	current, previous map[string]struct{}
+}
+
+var _ ZeroRTTAntiReplay = &zeroRTTAntiReplayCache{}
+
+// NewZeroRTTAntiReplayCache creates a new in-memory cache of session tickets used for 0-RTT.
+// Every session ticket is accepted only once. Session tickets issued more than window ago are rejected,
+// which allows the cache to forget about tickets after the window has passed.
+// The memory usage is proportional to the number of 0-RTT connection attempts within (up to) two windows.
+func NewZeroRTTAntiReplayCache(window time.Duration) ZeroRTTAntiReplay {
+	return &zeroRTTAntiReplayCache{
+		window:       window,
+		lastRotation: time.Now(),
+		current:      make(map[string]struct{}),
+		previous:     make(map[string]struct{}),
+	}
+}
+
+func (c *zeroRTTAntiReplayCache) Accept(ticketID []byte, issuedAt time.Time) bool {
+	now := time.Now()
+	// Tickets from the future might still be valid after we forgot about them.
+	if issuedAt.After(now) || now.Sub(issuedAt) > c.window {
+		return false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if since := now.Sub(c.lastRotation); since >= c.window {
+		if since >= 2*c.window {
+			c.previous = make(map[string]struct{})
+		} else {
+			c.previous = c.current
+		}
+		c.current = make(map[string]struct{})
+		c.lastRotation = now
+	}
+	id := string(ticketID)
+	if _, ok := c.current[id]; ok {
+		return false
+	}
+	if _, ok := c.previous[id]; ok {
+		return false
+	}
+	c.current[id] = struct{}{}
+	return true
+}
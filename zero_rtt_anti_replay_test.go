@@ -0,0 +1,60 @@
+package quic
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("0-RTT Anti-Replay Cache", func() {
+	const window = time.Minute
+	var c *zeroRTTAntiReplayCache
+
+	BeforeEach(func() {
+		c = NewZeroRTTAntiReplayCache(window).(*zeroRTTAntiReplayCache)
+	})
+
+	It("accepts every ticket only once", func() {
+		Expect(c.Accept([]byte("foo"), time.Now())).To(BeTrue())
+		Expect(c.Accept([]byte("bar"), time.Now())).To(BeTrue())
+		Expect(c.Accept([]byte("foo"), time.Now())).To(BeFalse())
+		Expect(c.Accept([]byte("bar"), time.Now())).To(BeFalse())
+	})
+
+	It("rejects tickets that are too old", func() {
+		Expect(c.Accept([]byte("foo"), time.Now().Add(-window-time.Second))).To(BeFalse())
+		Expect(c.Accept([]byte("bar"), time.Now().Add(-window+time.Second))).To(BeTrue())
+	})
+
+	It("rejects tickets from the future", func() {
+		Expect(c.Accept([]byte("foo"), time.Now().Add(time.Second))).To(BeFalse())
+	})
+
+	It("remembers tickets after a rotation", func() {
+		Expect(c.Accept([]byte("foo"), time.Now())).To(BeTrue())
+		c.lastRotation = c.lastRotation.Add(-window)
+		Expect(c.Accept([]byte("bar"), time.Now())).To(BeTrue())
+		Expect(c.current).To(HaveLen(1))
+		Expect(c.previous).To(HaveLen(1))
+		Expect(c.Accept([]byte("foo"), time.Now())).To(BeFalse())
+	})
+
+	It("forgets tickets after two rotations", func() {
+		Expect(c.Accept([]byte("foo"), time.Now())).To(BeTrue())
+		c.lastRotation = c.lastRotation.Add(-window)
+		Expect(c.Accept([]byte("bar"), time.Now())).To(BeTrue())
+		c.lastRotation = c.lastRotation.Add(-window)
+		Expect(c.Accept([]byte("baz"), time.Now())).To(BeTrue())
+		Expect(c.previous).To(HaveKey("bar"))
+		Expect(c.previous).ToNot(HaveKey("foo"))
+	})
+
+	It("forgets all tickets if no ticket was used for two windows", func() {
+		Expect(c.Accept([]byte("foo"), time.Now())).To(BeTrue())
+		c.lastRotation = c.lastRotation.Add(-2 * window)
+		Expect(c.Accept([]byte("bar"), time.Now())).To(BeTrue())
+		Expect(c.previous).To(BeEmpty())
+		Expect(c.current).To(HaveLen(1))
+	})
+})